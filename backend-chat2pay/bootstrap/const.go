@@ -30,10 +30,78 @@ const (
 
 	OrderServiceName        = "order.service"
 	OrderRepositoryName     = "order.repository"
+	OrderExpirerName        = "order_expirer.service"
 	ChatMessageRepositoryName = "chat_message.repository"
 	ChatHandlerName         = "chat.handler"
 
-	RajaOngkirName = "rajaongkir.package"
+	SearchServiceName           = "search.service"
+	SearchHandlerName           = "search.handler"
+	SearchSynonymRepositoryName = "search_synonym.repository"
+
+	CategoryServiceName    = "category.service"
+	CategoryHandlerName    = "category.handler"
+	CategoryRepositoryName = "category.repository"
+
+	ProductImageServiceName    = "product_image.service"
+	ProductImageHandlerName    = "product_image.handler"
+	ProductImageRepositoryName = "product_image.repository"
+
+	ProductVariantServiceName    = "product_variant.service"
+	ProductVariantHandlerName    = "product_variant.handler"
+	ProductVariantRepositoryName = "product_variant.repository"
+
+	ProductImportServiceName = "product_import.service"
+	ProductImportHandlerName = "product_import.handler"
+	EmbeddingQueueName       = "embedding_queue.service"
+	ProductSchedulerName     = "product_scheduler.service"
+
+	ProductExportServiceName = "product_export.service"
+	ProductExportHandlerName = "product_export.handler"
+
+	OutletServiceName    = "outlet.service"
+	OutletHandlerName    = "outlet.handler"
+	OutletRepositoryName = "outlet.repository"
+
+	StockServiceName            = "stock.service"
+	StockHandlerName            = "stock.handler"
+	StockMovementRepositoryName = "stock_movement.repository"
+	StockAlertRepositoryName    = "stock_alert.repository"
+
+	MerchantNotificationServiceName    = "merchant_notification.service"
+	MerchantNotificationHandlerName    = "merchant_notification.handler"
+	MerchantNotificationRepositoryName = "merchant_notification.repository"
+
+	DiscountServiceName    = "discount.service"
+	DiscountHandlerName    = "discount.handler"
+	DiscountRepositoryName = "discount.repository"
+
+	ProductReviewServiceName    = "product_review.service"
+	ProductReviewHandlerName    = "product_review.handler"
+	ProductReviewRepositoryName = "product_review.repository"
+
+	ProductFAQServiceName    = "product_faq.service"
+	ProductFAQHandlerName    = "product_faq.handler"
+	ProductFAQRepositoryName = "product_faq.repository"
+
+	WishlistServiceName    = "wishlist.service"
+	WishlistHandlerName    = "wishlist.handler"
+	WishlistRepositoryName = "wishlist.repository"
+	WishlistWatcherName    = "wishlist_watcher.service"
+
+	CartServiceName    = "cart.service"
+	CartHandlerName    = "cart.handler"
+	CartRepositoryName = "cart.repository"
+
+	IdempotencyRepositoryName = "idempotency.repository"
+
+	CustomerNotificationServiceName    = "customer_notification.service"
+	CustomerNotificationHandlerName    = "customer_notification.handler"
+	CustomerNotificationRepositoryName = "customer_notification.repository"
+
+	StoragePackageName = "storage.package"
+
+	RajaOngkirName     = "rajaongkir.package"
+	ShippingQuoterName = "shipping_quoter.package"
 
 	LLMPackageName = "llm.package"
 )
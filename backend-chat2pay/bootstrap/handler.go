@@ -3,6 +3,7 @@ package bootstrap
 import (
 	"chat2pay/config/yaml"
 	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/pkg/shipping"
 	"chat2pay/internal/repositories"
 	"chat2pay/internal/service"
 	"github.com/sarulabs/di/v2"
@@ -42,7 +43,8 @@ func LoadHandler() *[]di.Def {
 			Name: ShippingHandlerName,
 			Build: func(ctn di.Container) (interface{}, error) {
 				cfg := ctn.Get(ConfigDefName).(*yaml.Config)
-				return handlers.NewShippingHandler(cfg.RajaOngkir.APIKey), nil
+				quoter := ctn.Get(ShippingQuoterName).(*shipping.Quoter)
+				return handlers.NewShippingHandler(cfg.RajaOngkir.APIKey, quoter), nil
 			},
 		},
 		{
@@ -59,5 +61,112 @@ func LoadHandler() *[]di.Def {
 				return handlers.NewChatHandler(chatRepo), nil
 			},
 		},
+		{
+			Name: SearchHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				searchService := ctn.Get(SearchServiceName).(service.SearchService)
+				return handlers.NewSearchHandler(searchService), nil
+			},
+		},
+		{
+			Name: StockHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				stockService := ctn.Get(StockServiceName).(service.StockService)
+				return handlers.NewStockHandler(stockService), nil
+			},
+		},
+		{
+			Name: MerchantNotificationHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				notificationService := ctn.Get(MerchantNotificationServiceName).(service.MerchantNotificationService)
+				return handlers.NewMerchantNotificationHandler(notificationService), nil
+			},
+		},
+		{
+			Name: DiscountHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				discountService := ctn.Get(DiscountServiceName).(service.DiscountService)
+				return handlers.NewDiscountHandler(discountService), nil
+			},
+		},
+		{
+			Name: ProductReviewHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				reviewService := ctn.Get(ProductReviewServiceName).(service.ProductReviewService)
+				return handlers.NewProductReviewHandler(reviewService), nil
+			},
+		},
+		{
+			Name: ProductFAQHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				faqService := ctn.Get(ProductFAQServiceName).(service.ProductFAQService)
+				return handlers.NewProductFAQHandler(faqService), nil
+			},
+		},
+		{
+			Name: WishlistHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				wishlistService := ctn.Get(WishlistServiceName).(service.WishlistService)
+				return handlers.NewWishlistHandler(wishlistService), nil
+			},
+		},
+		{
+			Name: CartHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				cartService := ctn.Get(CartServiceName).(service.CartService)
+				orderService := ctn.Get(OrderServiceName).(service.OrderService)
+				return handlers.NewCartHandler(cartService, orderService), nil
+			},
+		},
+		{
+			Name: CustomerNotificationHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				notificationService := ctn.Get(CustomerNotificationServiceName).(service.CustomerNotificationService)
+				return handlers.NewCustomerNotificationHandler(notificationService), nil
+			},
+		},
+		{
+			Name: OutletHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				outletService := ctn.Get(OutletServiceName).(service.OutletService)
+				return handlers.NewOutletHandler(outletService), nil
+			},
+		},
+		{
+			Name: CategoryHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				categoryService := ctn.Get(CategoryServiceName).(service.CategoryService)
+				return handlers.NewCategoryHandler(categoryService), nil
+			},
+		},
+		{
+			Name: ProductImageHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productImageService := ctn.Get(ProductImageServiceName).(service.ProductImageService)
+				return handlers.NewProductImageHandler(productImageService), nil
+			},
+		},
+		{
+			Name: ProductImportHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productImportService := ctn.Get(ProductImportServiceName).(service.ProductImportService)
+				return handlers.NewProductImportHandler(productImportService), nil
+			},
+		},
+		{
+			Name: ProductExportHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				cfg := ctn.Get(ConfigDefName).(*yaml.Config)
+				productExportService := ctn.Get(ProductExportServiceName).(service.ProductExportService)
+				return handlers.NewProductExportHandler(productExportService, cfg), nil
+			},
+		},
+		{
+			Name: ProductVariantHandlerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productVariantService := ctn.Get(ProductVariantServiceName).(service.ProductVariantService)
+				return handlers.NewProductVariantHandler(productVariantService), nil
+			},
+		},
 	}
 }
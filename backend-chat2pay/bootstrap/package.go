@@ -8,6 +8,8 @@ import (
 	"chat2pay/internal/pkg/rajaongkir"
 	"chat2pay/internal/pkg/llm"
 	"chat2pay/internal/pkg/redis"
+	"chat2pay/internal/pkg/shipping"
+	"chat2pay/internal/pkg/storage"
 	"github.com/sarulabs/di/v2"
 )
 
@@ -28,5 +30,19 @@ func LoadPackage() *[]di.Def {
 				return rajaongkir.NewRajaOngkir(config.RajaOngkir.APIKey), nil
 			},
 		},
+		{
+			Name: ShippingQuoterName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return shipping.NewQuoter(config.RajaOngkir.APIKey), nil
+			},
+		},
+		{
+			Name: StoragePackageName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return storage.NewStorage(config.Storage)
+			},
+		},
 	}
 }
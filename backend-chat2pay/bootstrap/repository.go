@@ -44,5 +44,95 @@ func NewRepository() *[]di.Def {
 				return repositories.NewChatMessageRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
 			},
 		},
+		{
+			Name: SearchSynonymRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewSearchSynonymRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: CategoryRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewCategoryRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: ProductImageRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewProductImageRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: OutletRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewOutletRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: StockMovementRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewStockMovementRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: StockAlertRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewStockAlertRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: MerchantNotificationRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewMerchantNotificationRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: DiscountRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewDiscountRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: ProductReviewRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewProductReviewRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: ProductFAQRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewProductFAQRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: WishlistRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewWishlistRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: IdempotencyRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewIdempotencyRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: CartRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewCartRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: CustomerNotificationRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewCustomerNotificationRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
+		{
+			Name: ProductVariantRepositoryName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return repositories.NewProductVariantRepository(ctn.Get(DatabaseAdapter).(*sqlx.DB)), nil
+			},
+		},
 	}
 }
@@ -6,6 +6,8 @@ import (
 	// "chat2pay/internal/pkg/llm/mistral"
 	"chat2pay/internal/pkg/llm"
 	"chat2pay/internal/pkg/redis"
+	"chat2pay/internal/pkg/shipping"
+	"chat2pay/internal/pkg/storage"
 	"chat2pay/internal/repositories"
 	"chat2pay/internal/service"
 	"github.com/sarulabs/di/v2"
@@ -25,10 +27,20 @@ func LoadService() *[]di.Def {
 			Build: func(ctn di.Container) (interface{}, error) {
 				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
 				merchantRepo := ctn.Get(MerchantRepositoryName).(repositories.MerchantRepository)
+				categoryRepo := ctn.Get(CategoryRepositoryName).(repositories.CategoryRepository)
+				imageRepo := ctn.Get(ProductImageRepositoryName).(repositories.ProductImageRepository)
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				synonymRepo := ctn.Get(SearchSynonymRepositoryName).(repositories.SearchSynonymRepository)
+				alertRepo := ctn.Get(StockAlertRepositoryName).(repositories.StockAlertRepository)
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				reviewRepo := ctn.Get(ProductReviewRepositoryName).(repositories.ProductReviewRepository)
+				faqRepo := ctn.Get(ProductFAQRepositoryName).(repositories.ProductFAQRepository)
+				wishlistService := ctn.Get(WishlistServiceName).(service.WishlistService)
+				cartService := ctn.Get(CartServiceName).(service.CartService)
 				llm := ctn.Get(LLMPackageName).(llm.LLM)
 				redisClient := ctn.Get(RedisAdapter).(redis.RedisClient)
 				config := ctn.Get(ConfigDefName).(*yaml.Config)
-				return service.NewProductService(productRepo, merchantRepo, llm, redisClient, config), nil
+				return service.NewProductService(productRepo, merchantRepo, categoryRepo, imageRepo, variantRepo, synonymRepo, alertRepo, discountRepo, reviewRepo, faqRepo, wishlistService, cartService, llm, redisClient, config), nil
 			},
 		},
 		{
@@ -55,8 +67,195 @@ func LoadService() *[]di.Def {
 			Build: func(ctn di.Container) (interface{}, error) {
 				orderRepo := ctn.Get(OrderRepositoryName).(repositories.OrderRepository)
 				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				outletRepo := ctn.Get(OutletRepositoryName).(repositories.OutletRepository)
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				cartRepo := ctn.Get(CartRepositoryName).(repositories.CartRepository)
+				merchantRepo := ctn.Get(MerchantRepositoryName).(repositories.MerchantRepository)
+				quoter := ctn.Get(ShippingQuoterName).(*shipping.Quoter)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewOrderService(config, orderRepo, productRepo, variantRepo, outletRepo, discountRepo, cartRepo, merchantRepo, quoter), nil
+			},
+		},
+		{
+			Name: StockServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				stockRepo := ctn.Get(StockMovementRepositoryName).(repositories.StockMovementRepository)
+				alertRepo := ctn.Get(StockAlertRepositoryName).(repositories.StockAlertRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				outletRepo := ctn.Get(OutletRepositoryName).(repositories.OutletRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewStockService(stockRepo, alertRepo, productRepo, variantRepo, outletRepo, config), nil
+			},
+		},
+		{
+			Name: MerchantNotificationServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				notificationRepo := ctn.Get(MerchantNotificationRepositoryName).(repositories.MerchantNotificationRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewMerchantNotificationService(notificationRepo, config), nil
+			},
+		},
+		{
+			Name: DiscountServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				categoryRepo := ctn.Get(CategoryRepositoryName).(repositories.CategoryRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewDiscountService(discountRepo, productRepo, categoryRepo, config), nil
+			},
+		},
+		{
+			Name: ProductReviewServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				reviewRepo := ctn.Get(ProductReviewRepositoryName).(repositories.ProductReviewRepository)
+				orderRepo := ctn.Get(OrderRepositoryName).(repositories.OrderRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				storage := ctn.Get(StoragePackageName).(storage.Storage)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductReviewService(reviewRepo, orderRepo, productRepo, storage, config), nil
+			},
+		},
+		{
+			Name: ProductFAQServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				faqRepo := ctn.Get(ProductFAQRepositoryName).(repositories.ProductFAQRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				llm := ctn.Get(LLMPackageName).(llm.LLM)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductFAQService(faqRepo, productRepo, llm, config), nil
+			},
+		},
+		{
+			Name: WishlistServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				wishlistRepo := ctn.Get(WishlistRepositoryName).(repositories.WishlistRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				imageRepo := ctn.Get(ProductImageRepositoryName).(repositories.ProductImageRepository)
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewWishlistService(wishlistRepo, productRepo, imageRepo, discountRepo, config), nil
+			},
+		},
+		{
+			Name: CartServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				cartRepo := ctn.Get(CartRepositoryName).(repositories.CartRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				merchantRepo := ctn.Get(MerchantRepositoryName).(repositories.MerchantRepository)
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewCartService(cartRepo, productRepo, variantRepo, merchantRepo, discountRepo, config), nil
+			},
+		},
+		{
+			Name: WishlistWatcherName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				wishlistRepo := ctn.Get(WishlistRepositoryName).(repositories.WishlistRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				discountRepo := ctn.Get(DiscountRepositoryName).(repositories.DiscountRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewWishlistWatcher(wishlistRepo, productRepo, discountRepo, config), nil
+			},
+		},
+		{
+			Name: CustomerNotificationServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				notificationRepo := ctn.Get(CustomerNotificationRepositoryName).(repositories.CustomerNotificationRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewCustomerNotificationService(notificationRepo, config), nil
+			},
+		},
+		{
+			Name: OutletServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				outletRepo := ctn.Get(OutletRepositoryName).(repositories.OutletRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewOutletService(outletRepo, productRepo, variantRepo, config), nil
+			},
+		},
+		{
+			Name: SearchServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				synonymRepo := ctn.Get(SearchSynonymRepositoryName).(repositories.SearchSynonymRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewSearchService(synonymRepo, productRepo, config), nil
+			},
+		},
+		{
+			Name: CategoryServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				categoryRepo := ctn.Get(CategoryRepositoryName).(repositories.CategoryRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewCategoryService(categoryRepo, config), nil
+			},
+		},
+		{
+			Name: ProductImageServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				imageRepo := ctn.Get(ProductImageRepositoryName).(repositories.ProductImageRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				storage := ctn.Get(StoragePackageName).(storage.Storage)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductImageService(imageRepo, productRepo, storage, config), nil
+			},
+		},
+		{
+			Name: EmbeddingQueueName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productService := ctn.Get(ProductServiceName).(service.ProductService)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewEmbeddingQueue(productService, config), nil
+			},
+		},
+		{
+			Name: ProductSchedulerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductScheduler(productRepo, config), nil
+			},
+		},
+		{
+			Name: OrderExpirerName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				orderRepo := ctn.Get(OrderRepositoryName).(repositories.OrderRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewOrderExpirer(orderRepo, config), nil
+			},
+		},
+		{
+			Name: ProductImportServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				categoryRepo := ctn.Get(CategoryRepositoryName).(repositories.CategoryRepository)
+				embeddingQueue := ctn.Get(EmbeddingQueueName).(service.EmbeddingQueue)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductImportService(productRepo, categoryRepo, embeddingQueue, config), nil
+			},
+		},
+		{
+			Name: ProductExportServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				config := ctn.Get(ConfigDefName).(*yaml.Config)
+				return service.NewProductExportService(productRepo, config), nil
+			},
+		},
+		{
+			Name: ProductVariantServiceName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				variantRepo := ctn.Get(ProductVariantRepositoryName).(repositories.ProductVariantRepository)
+				productRepo := ctn.Get(ProductRepositoryName).(repositories.ProductRepository)
+				productService := ctn.Get(ProductServiceName).(service.ProductService)
 				config := ctn.Get(ConfigDefName).(*yaml.Config)
-				return service.NewOrderService(config, orderRepo, productRepo), nil
+				return service.NewProductVariantService(variantRepo, productRepo, productService, config), nil
 			},
 		},
 	}
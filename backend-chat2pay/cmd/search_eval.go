@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"chat2pay/bootstrap"
+	"chat2pay/config/yaml"
+	"chat2pay/internal/pkg/searcheval"
+	"chat2pay/internal/service"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/urfave/cli/v3"
+)
+
+func SearchEval(ctn *di.Container) []*cli.Command {
+	cmd := []*cli.Command{}
+	cmd = append(cmd, &cli.Command{
+		Name:  "search-eval",
+		Usage: "Evaluate product search against a golden dataset (recall@k, MRR, nDCG)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "dataset",
+				Usage:    "path to the golden dataset (YAML or JSON)",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "k",
+				Usage: "cut-off rank for recall and nDCG",
+				Value: 10,
+			},
+			&cli.StringFlag{
+				Name:  "provider",
+				Usage: "override llm.provider from the config, e.g. offline",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "write the JSON report to this file",
+			},
+			&cli.StringFlag{
+				Name:  "baseline",
+				Usage: "JSON report of a previous run to compare against",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			config := ctn.Get(bootstrap.ConfigDefName).(*yaml.Config)
+			if provider := c.String("provider"); provider != "" {
+				// The config is shared, so this must happen before the LLM package is built
+				config.LLM.Provider = provider
+			}
+
+			dataset, checksum, err := searcheval.LoadDataset(c.String("dataset"))
+			if err != nil {
+				return err
+			}
+
+			var baseline *searcheval.Report
+			if path := c.String("baseline"); path != "" {
+				raw, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				baseline = &searcheval.Report{}
+				if err := json.Unmarshal(raw, baseline); err != nil {
+					return fmt.Errorf("invalid baseline report: %w", err)
+				}
+			}
+
+			productService := ctn.Get(bootstrap.ProductServiceName).(service.ProductService)
+
+			report := &searcheval.Report{
+				Dataset:         dataset.Name,
+				DatasetChecksum: checksum,
+				Provider:        config.LLM.Provider,
+				K:               c.Int("k"),
+			}
+
+			for _, q := range dataset.Queries {
+				products, err := productService.Retrieve(ctx, q.Query)
+				if err != nil {
+					// A failed query scores zero, so it still weighs on the
+					// overall scores
+					report.Queries = append(report.Queries, searcheval.QueryResult{
+						ID:       q.ID,
+						Query:    q.Query,
+						Expected: q.Expected,
+						Error:    err.Error(),
+					})
+					continue
+				}
+
+				hits := make([]searcheval.Hit, len(products))
+				for i, p := range products {
+					hits[i] = searcheval.Hit{ID: p.ID}
+					if p.SKU != nil {
+						hits[i].SKU = *p.SKU
+					}
+				}
+
+				report.Queries = append(report.Queries, searcheval.Score(q, hits, report.K))
+			}
+			report.Overall = searcheval.Summarize(report.Queries)
+
+			report.Print(os.Stdout, baseline)
+
+			if path := c.String("output"); path != "" {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, b, 0o644); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+
+	return cmd
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"chat2pay/bootstrap"
+	"chat2pay/internal/repositories"
+	"chat2pay/internal/service"
+	"context"
+	"fmt"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/urfave/cli/v3"
+)
+
+func SearchReindex(ctn *di.Container) []*cli.Command {
+	cmd := []*cli.Command{}
+	cmd = append(cmd, &cli.Command{
+		Name:  "search-reindex",
+		Usage: "Rebuild the embeddings of every product with the current document settings",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			productRepo := ctn.Get(bootstrap.ProductRepositoryName).(repositories.ProductRepository)
+			productService := ctn.Get(bootstrap.ProductServiceName).(service.ProductService)
+
+			const pageSize = 100
+			var done, failed int
+			for offset := 0; ; offset += pageSize {
+				products, err := productRepo.FindAll(ctx, "", pageSize, offset)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range products {
+					if err := productService.Reembed(ctx, p.ID); err != nil {
+						fmt.Printf("failed to reindex %s: %v\n", p.ID, err)
+						failed++
+						continue
+					}
+					done++
+				}
+
+				if len(products) < pageSize {
+					break
+				}
+			}
+
+			fmt.Printf("reindexed %d products, %d failed\n", done, failed)
+			return nil
+		},
+	})
+
+	return cmd
+}
@@ -6,13 +6,17 @@ import (
 )
 
 type Config struct {
-	App    App    `yaml:"app,omitempty" json:"app"`
-	DB     DB     `yaml:"db" json:"db"`
-	Redis  Redis  `yaml:"redis"  json:"redis"`
-	JWT    JWT    `yaml:"jwt" json:"jwt"`
-	Logger Logger `yaml:"logger" json:"logger"`
-	LLM    LLM    `yaml:"llm" json:"llm"`
+	App        App        `yaml:"app,omitempty" json:"app"`
+	DB         DB         `yaml:"db" json:"db"`
+	Redis      Redis      `yaml:"redis"  json:"redis"`
+	JWT        JWT        `yaml:"jwt" json:"jwt"`
+	Logger     Logger     `yaml:"logger" json:"logger"`
+	LLM        LLM        `yaml:"llm" json:"llm"`
 	RajaOngkir RajaOngkir `yaml:"rajaongkir" json:"rajaongkir"`
+	Search     Search     `yaml:"search" json:"search"`
+	Storage    Storage    `yaml:"storage" json:"storage"`
+	Order      Order      `yaml:"order" json:"order"`
+	Platform   Platform   `yaml:"platform" json:"platform"`
 }
 
 type App struct {
@@ -46,7 +50,7 @@ type DB struct {
 
 type LLM struct {
 	Provider string  `yaml:"provider" json:"provider"`
-	Kolosal  Kolosal `yaml:"kolosal"json:"kolosal"`
+	Kolosal  Kolosal `yaml:"kolosal" json:"kolosal"`
 	Gemini   Gemini  `yaml:"gemini" json:"gemini"`
 	OpenAI   OpenAI  `yaml:"open_ai" json:"open_ai"`
 	Mistral  Mistral `yaml:"mistral" json:"mistral"`
@@ -74,6 +78,64 @@ type OpenAI struct {
 	APIKey string `yaml:"api_key" json:"api_key"`
 }
 
+type Search struct {
+	Document SearchDocument `yaml:"document" json:"document"`
+	// Aggregation combines the scores of a product's chunks: max (default) or mean
+	Aggregation string `yaml:"aggregation" json:"aggregation"`
+}
+
+type SearchDocument struct {
+	// Fields lists what goes into the embedded text, in order. Empty means all fields.
+	Fields       []string  `yaml:"fields" json:"fields"`
+	PriceBands   []float64 `yaml:"price_bands" json:"price_bands"`
+	ChunkSize    int       `yaml:"chunk_size" json:"chunk_size"`
+	ChunkOverlap int       `yaml:"chunk_overlap" json:"chunk_overlap"`
+}
+
+type Storage struct {
+	Driver string       `yaml:"driver" json:"driver"` // local (default) or s3
+	Local  LocalStorage `yaml:"local" json:"local"`
+	S3     S3Storage    `yaml:"s3" json:"s3"`
+	Image  ImageUpload  `yaml:"image" json:"image"`
+}
+
+type LocalStorage struct {
+	Dir     string `yaml:"dir" json:"dir"`
+	BaseURL string `yaml:"base_url" json:"base_url"`
+}
+
+type S3Storage struct {
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	Region    string `yaml:"region" json:"region"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	AccessKey string `yaml:"access_key" json:"access_key"`
+	SecretKey string `yaml:"secret_key" json:"secret_key"`
+	UseSSL    bool   `yaml:"use_ssl" json:"use_ssl"`
+	// BaseURL is the public URL of the bucket, e.g. a CDN. Defaults to the endpoint.
+	BaseURL string `yaml:"base_url" json:"base_url"`
+}
+
+type ImageUpload struct {
+	MaxSizeKB     int `yaml:"max_size_kb" json:"max_size_kb"`
+	MaxPerProduct int `yaml:"max_per_product" json:"max_per_product"`
+	ThumbnailSize int `yaml:"thumbnail_size" json:"thumbnail_size"`
+}
+
+type Order struct {
+	// PaymentWindowMinutes is how long an order waits for payment before it
+	// is cancelled. Defaults to a day.
+	PaymentWindowMinutes int `yaml:"payment_window_minutes" json:"payment_window_minutes"`
+	// IdempotencyKeyMinutes is how long the response of a request is replayed
+	// for retries under the same Idempotency-Key. Defaults to a day.
+	IdempotencyKeyMinutes int `yaml:"idempotency_key_minutes" json:"idempotency_key_minutes"`
+}
+
+type Platform struct {
+	// AdminUserIDs are the merchant users who also manage the data shared by
+	// every shop, such as the category tree.
+	AdminUserIDs []string `yaml:"admin_user_ids" json:"admin_user_ids"`
+}
+
 type JWT struct {
 	Key           string `yaml:"key" json:"key"`
 	ExpiredMinute int    `yaml:"expired_minute" json:"expired_minute"`
@@ -0,0 +1,184 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id"` // required when the product has variants
+	Quantity  int    `json:"quantity"`   // defaults to 1
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity"` // 0 removes the item
+}
+
+type CartItemResponse struct {
+	ID            string   `json:"id"`
+	ProductID     string   `json:"product_id"`
+	ProductName   string   `json:"product_name"`
+	VariantID     *string  `json:"variant_id,omitempty"`
+	VariantName   *string  `json:"variant_name,omitempty"`
+	Image         *string  `json:"image,omitempty"`
+	Price         float64  `json:"price"`                    // the price charged now
+	OriginalPrice *float64 `json:"original_price,omitempty"` // the price before the discount
+	Quantity      int      `json:"quantity"`
+	Stock         int      `json:"stock"`
+	Available     bool     `json:"available"` // whether it can be ordered in this quantity
+	Subtotal      float64  `json:"subtotal"`
+}
+
+type CartMerchantResponse struct {
+	MerchantID   string             `json:"merchant_id"`
+	MerchantName string             `json:"merchant_name"`
+	Items        []CartItemResponse `json:"items"`
+	Subtotal     float64            `json:"subtotal"` // of the available items
+}
+
+type CartResponse struct {
+	Merchants []CartMerchantResponse `json:"merchants"`
+	ItemCount int                    `json:"item_count"`
+	Subtotal  float64                `json:"subtotal"`  // of the available items
+	Available bool                   `json:"available"` // whether the cart can be checked out as it is
+}
+
+// CartFulfillmentRequest asks which outlet of each merchant in the cart
+// would ship to the destination, so each order's shipping can be quoted.
+type CartFulfillmentRequest struct {
+	ShippingCity      string   `json:"shipping_city"`
+	ShippingCityID    string   `json:"shipping_city_id"`
+	ShippingProvince  string   `json:"shipping_province"`
+	ShippingLatitude  *float64 `json:"shipping_latitude"`
+	ShippingLongitude *float64 `json:"shipping_longitude"`
+}
+
+type CartFulfillmentResponse struct {
+	MerchantID   string `json:"merchant_id"`
+	MerchantName string `json:"merchant_name"`
+	FulfillmentResponse
+}
+
+type CheckoutRequest struct {
+	ShippingAddress    string                    `json:"shipping_address" validate:"required"`
+	ShippingCity       string                    `json:"shipping_city" validate:"required"`
+	ShippingCityID     string                    `json:"shipping_city_id" validate:"required"`
+	ShippingProvince   string                    `json:"shipping_province" validate:"required"`
+	ShippingPostalCode string                    `json:"shipping_postal_code"`
+	ShippingLatitude   *float64                  `json:"shipping_latitude"`
+	ShippingLongitude  *float64                  `json:"shipping_longitude"`
+	Shipments          []CheckoutShipmentRequest `json:"shipments" validate:"required,min=1"` // one per merchant in the cart
+}
+
+// CheckoutShipmentRequest is the courier service chosen for the order of
+// one merchant, out of the quote for the origin and weight given by POST
+// /cart/fulfillment. The order is charged what that service is quoted.
+type CheckoutShipmentRequest struct {
+	MerchantID     string `json:"merchant_id" validate:"required"`
+	Courier        string `json:"courier" validate:"required"`
+	CourierService string `json:"courier_service" validate:"required"`
+	Notes          string `json:"notes"`
+}
+
+type CheckoutResponse struct {
+	ID           string          `json:"id"`
+	Orders       []OrderResponse `json:"orders"`
+	Subtotal     float64         `json:"subtotal"`
+	ShippingCost float64         `json:"shipping_cost"`
+	Total        float64         `json:"total"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ToCartItemResponse describes the item with its product and variant, which
+// must be loaded. The discount of the product is the one running for the
+// price of the item.
+func ToCartItemResponse(item *entities.CartItem) CartItemResponse {
+	product := item.Product
+
+	response := CartItemResponse{
+		ID:          item.ID,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		VariantID:   item.VariantID,
+		Image:       product.Image,
+		Price:       product.Price,
+		Quantity:    item.Quantity,
+		Stock:       product.Stock,
+	}
+	if item.Variant != nil {
+		response.VariantName = &item.Variant.Name
+		response.Price = item.Variant.PriceOf(product)
+		response.Stock = item.Variant.Stock
+	}
+	if product.Discount != nil {
+		originalPrice := response.Price
+		response.OriginalPrice = &originalPrice
+		response.Price = product.Discounted(response.Price)
+	}
+
+	response.Available = product.Status == entities.ProductStatusActive && response.Stock >= item.Quantity
+	response.Subtotal = response.Price * float64(item.Quantity)
+
+	return response
+}
+
+// ToCartResponse groups the items by merchant, in the order the merchants
+// were first added to the cart. Items without a product are left out.
+func ToCartResponse(items []entities.CartItem, merchants map[string]*entities.Merchant) CartResponse {
+	response := CartResponse{Merchants: []CartMerchantResponse{}, Available: true}
+	groups := map[string]int{}
+
+	for i := range items {
+		item := &items[i]
+		if item.Product == nil {
+			continue
+		}
+
+		merchantId := item.Product.MerchantID
+		group, ok := groups[merchantId]
+		if !ok {
+			merchant := CartMerchantResponse{MerchantID: merchantId, Items: []CartItemResponse{}}
+			if m := merchants[merchantId]; m != nil {
+				merchant.MerchantName = m.Name
+			}
+			group = len(response.Merchants)
+			groups[merchantId] = group
+			response.Merchants = append(response.Merchants, merchant)
+		}
+
+		itemResponse := ToCartItemResponse(item)
+		response.Merchants[group].Items = append(response.Merchants[group].Items, itemResponse)
+		response.ItemCount += item.Quantity
+
+		if !itemResponse.Available {
+			response.Available = false
+			continue
+		}
+		response.Merchants[group].Subtotal += itemResponse.Subtotal
+		response.Subtotal += itemResponse.Subtotal
+	}
+
+	if len(response.Merchants) == 0 {
+		response.Available = false
+	}
+
+	return response
+}
+
+func ToCheckoutResponse(checkout *entities.Checkout) CheckoutResponse {
+	response := CheckoutResponse{
+		ID:           checkout.ID,
+		Orders:       make([]OrderResponse, len(checkout.Orders)),
+		Subtotal:     checkout.Subtotal,
+		ShippingCost: checkout.ShippingCost,
+		Total:        checkout.Total,
+		CreatedAt:    checkout.CreatedAt,
+	}
+
+	for i := range checkout.Orders {
+		response.Orders[i] = ToOrderResponse(&checkout.Orders[i])
+	}
+
+	return response
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+type CategoryRequest struct {
+	Name            string                    `json:"name" validate:"required"`
+	ParentID        *string                   `json:"parent_id"`
+	AttributeSchema *entities.AttributeSchema `json:"attribute_schema"` // kept on update when omitted
+}
+
+type CategoryResponse struct {
+	ID              string                   `json:"id"`
+	Name            string                   `json:"name"`
+	ParentID        *string                  `json:"parent_id,omitempty"`
+	AttributeSchema entities.AttributeSchema `json:"attribute_schema"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+}
+
+type CategoryTreeResponse struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Children []CategoryTreeResponse `json:"children"`
+}
+
+func ToCategoryResponse(category *entities.ProductCategory) CategoryResponse {
+	return CategoryResponse{
+		ID:              category.ID,
+		Name:            category.Name,
+		ParentID:        category.ParentID,
+		AttributeSchema: category.AttributeSchema,
+		CreatedAt:       category.CreatedAt,
+		UpdatedAt:       category.UpdatedAt,
+	}
+}
+
+func ToCategoryListResponse(categories []entities.ProductCategory) []CategoryResponse {
+	responses := make([]CategoryResponse, len(categories))
+	for i, category := range categories {
+		responses[i] = ToCategoryResponse(&category)
+	}
+	return responses
+}
+
+// ToBreadcrumbResponse expects the categories ordered from the root down.
+func ToBreadcrumbResponse(categories []entities.ProductCategory) []ProductCategorySimple {
+	responses := make([]ProductCategorySimple, len(categories))
+	for i, category := range categories {
+		responses[i] = ProductCategorySimple{ID: category.ID, Name: category.Name}
+	}
+	return responses
+}
+
+// ToCategoryTree nests a flat list of categories under their parents. Each
+// level keeps the order of the given list.
+func ToCategoryTree(categories []entities.ProductCategory) []CategoryTreeResponse {
+	children := map[string][]entities.ProductCategory{}
+	known := map[string]bool{}
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	roots := []entities.ProductCategory{}
+	for _, category := range categories {
+		if category.ParentID == nil || !known[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(nodes []entities.ProductCategory) []CategoryTreeResponse
+	build = func(nodes []entities.ProductCategory) []CategoryTreeResponse {
+		tree := make([]CategoryTreeResponse, len(nodes))
+		for i, node := range nodes {
+			tree[i] = CategoryTreeResponse{
+				ID:       node.ID,
+				Name:     node.Name,
+				Children: build(children[node.ID]),
+			}
+		}
+		return tree
+	}
+
+	return build(roots)
+}
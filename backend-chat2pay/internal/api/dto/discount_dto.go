@@ -0,0 +1,158 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/pricing"
+	"time"
+)
+
+// DiscountRequest sets a discount on either a product or a category. Value
+// is a percentage or an amount in Rupiah, depending on Type.
+type DiscountRequest struct {
+	Name       string    `json:"name" validate:"required"`
+	ProductID  *string   `json:"product_id"`
+	CategoryID *string   `json:"category_id"`
+	Type       string    `json:"type" validate:"required"` // percentage or fixed
+	Value      float64   `json:"value" validate:"required"`
+	StartsAt   time.Time `json:"starts_at" validate:"required"`
+	EndsAt     time.Time `json:"ends_at" validate:"required"`
+}
+
+type DiscountResponse struct {
+	ID         string    `json:"id"`
+	ProductID  *string   `json:"product_id,omitempty"`
+	CategoryID *string   `json:"category_id,omitempty"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Value      float64   `json:"value"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	State      string    `json:"state"`           // running, scheduled or ended
+	Label      string    `json:"label,omitempty"` // e.g. "diskon 20% sampai Minggu", while running
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DiscountListResponse struct {
+	Discounts []DiscountResponse `json:"discounts"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
+}
+
+// ProductDiscountResponse is the discount running for a product.
+type ProductDiscountResponse struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Value  float64   `json:"value"`
+	EndsAt time.Time `json:"ends_at"`
+	Label  string    `json:"label"`
+}
+
+type PriceChangeResponse struct {
+	Price         float64   `json:"price"`
+	PreviousPrice *float64  `json:"previous_price,omitempty"`
+	Source        string    `json:"source"` // manual, import or opening
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PriceHistoryResponse struct {
+	ProductID string                `json:"product_id"`
+	Price     float64               `json:"price"`
+	Changes   []PriceChangeResponse `json:"changes"`
+	Total     int64                 `json:"total"`
+	Page      int                   `json:"page"`
+	Limit     int                   `json:"limit"`
+}
+
+func (r *DiscountRequest) ToEntity(merchantId string) entities.Discount {
+	return entities.Discount{
+		MerchantID: merchantId,
+		ProductID:  r.ProductID,
+		CategoryID: r.CategoryID,
+		Name:       r.Name,
+		Type:       r.Type,
+		Value:      r.Value,
+		StartsAt:   r.StartsAt,
+		EndsAt:     r.EndsAt,
+	}
+}
+
+func ToDiscountResponse(discount *entities.Discount, now time.Time) DiscountResponse {
+	response := DiscountResponse{
+		ID:         discount.ID,
+		ProductID:  discount.ProductID,
+		CategoryID: discount.CategoryID,
+		Name:       discount.Name,
+		Type:       discount.Type,
+		Value:      discount.Value,
+		StartsAt:   discount.StartsAt,
+		EndsAt:     discount.EndsAt,
+		State:      entities.DiscountStateRunning,
+		CreatedAt:  discount.CreatedAt,
+		UpdatedAt:  discount.UpdatedAt,
+	}
+
+	switch {
+	case now.Before(discount.StartsAt):
+		response.State = entities.DiscountStateScheduled
+	case !now.Before(discount.EndsAt):
+		response.State = entities.DiscountStateEnded
+	default:
+		response.Label = pricing.Label(*discount, now)
+	}
+
+	return response
+}
+
+func ToDiscountListResponse(discounts []entities.Discount, total int64, page, limit int) DiscountListResponse {
+	now := time.Now()
+	responses := make([]DiscountResponse, len(discounts))
+	for i, discount := range discounts {
+		responses[i] = ToDiscountResponse(&discount, now)
+	}
+
+	return DiscountListResponse{
+		Discounts: responses,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+	}
+}
+
+func ToProductDiscountResponse(discount *entities.Discount) *ProductDiscountResponse {
+	if discount == nil {
+		return nil
+	}
+
+	return &ProductDiscountResponse{
+		ID:     discount.ID,
+		Name:   discount.Name,
+		Type:   discount.Type,
+		Value:  discount.Value,
+		EndsAt: discount.EndsAt,
+		Label:  pricing.Label(*discount, time.Now()),
+	}
+}
+
+func ToPriceHistoryResponse(product *entities.Product, changes []entities.PriceChange, total int64, page, limit int) PriceHistoryResponse {
+	responses := make([]PriceChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = PriceChangeResponse{
+			Price:         change.Price,
+			PreviousPrice: change.PreviousPrice,
+			Source:        change.Source,
+			CreatedAt:     change.CreatedAt,
+		}
+	}
+
+	return PriceHistoryResponse{
+		ProductID: product.ID,
+		Price:     product.Price,
+		Changes:   responses,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+	}
+}
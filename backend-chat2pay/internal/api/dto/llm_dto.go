@@ -4,8 +4,11 @@ import "chat2pay/internal/entities"
 
 type (
 	LLMResponse struct {
-		Products []ProductResponse `json:"products"`
-		Message  string            `json:"message"`
+		Products       []ProductResponse        `json:"products"`
+		Message        string                   `json:"message"`
+		Similar        []ProductResponse        `json:"similar,omitempty"`
+		BoughtTogether []ProductResponse        `json:"bought_together,omitempty"`
+		Variants       *ProductVariantsResponse `json:"variants,omitempty"`
 	}
 )
 
@@ -23,7 +26,7 @@ func ToLLM(products *[]entities.Product, message string) LLMResponse {
 	}
 
 	return LLMResponse{
-		nil,
-		message,
+		Products: nil,
+		Message:  message,
 	}
 }
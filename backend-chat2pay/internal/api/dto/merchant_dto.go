@@ -10,6 +10,8 @@ type MerchantRequest struct {
 	LegalName string `json:"legal_name"`
 	Email     string `json:"email" validate:"required,email"`
 	Phone     string `json:"phone"`
+	CityID    string `json:"city_id"` // shipping provider city id, the shipping origin when the merchant has no outlets
+	CityName  string `json:"city_name"`
 	Status    string `json:"status"`
 }
 
@@ -19,6 +21,8 @@ type MerchantResponse struct {
 	LegalName *string   `json:"legal_name,omitempty"`
 	Email     string    `json:"email"`
 	Phone     *string   `json:"phone,omitempty"`
+	CityID    *string   `json:"city_id,omitempty"`
+	CityName  *string   `json:"city_name,omitempty"`
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -38,6 +42,8 @@ func ToMerchantResponse(merchant *entities.Merchant) MerchantResponse {
 		LegalName: merchant.LegalName,
 		Email:     merchant.Email,
 		Phone:     merchant.Phone,
+		CityID:    merchant.CityID,
+		CityName:  merchant.CityName,
 		Status:    merchant.Status,
 		CreatedAt: merchant.CreatedAt,
 		UpdatedAt: merchant.UpdatedAt,
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+type MerchantNotificationResponse struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Message       string     `json:"message"`
+	ReferenceType *string    `json:"reference_type,omitempty"`
+	ReferenceID   *string    `json:"reference_id,omitempty"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type MerchantNotificationListResponse struct {
+	Notifications []MerchantNotificationResponse `json:"notifications"`
+	Unread        int64                          `json:"unread"`
+	Total         int64                          `json:"total"`
+	Page          int                            `json:"page"`
+	Limit         int                            `json:"limit"`
+}
+
+func ToMerchantNotificationListResponse(notifications []entities.MerchantNotification, unread, total int64, page, limit int) MerchantNotificationListResponse {
+	responses := make([]MerchantNotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = MerchantNotificationResponse{
+			ID:            notification.ID,
+			Type:          notification.Type,
+			Title:         notification.Title,
+			Message:       notification.Message,
+			ReferenceType: notification.ReferenceType,
+			ReferenceID:   notification.ReferenceID,
+			ReadAt:        notification.ReadAt,
+			CreatedAt:     notification.CreatedAt,
+		}
+	}
+
+	return MerchantNotificationListResponse{
+		Notifications: responses,
+		Unread:        unread,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+	}
+}
@@ -14,13 +14,34 @@ type CreateOrderRequest struct {
 	ShippingPostalCode string           `json:"shipping_postal_code"`
 	Courier          string             `json:"courier" validate:"required"`
 	CourierService   string             `json:"courier_service" validate:"required"`
-	ShippingCost     float64            `json:"shipping_cost" validate:"required"`
-	ShippingEtd      string             `json:"shipping_etd"`
+	ShippingLatitude  *float64          `json:"shipping_latitude"` // optional, picks the nearest outlet more precisely
+	ShippingLongitude *float64          `json:"shipping_longitude"`
 	Notes            string             `json:"notes"`
 }
 
+// FulfillmentRequest asks which outlet would ship the items, so the shipping
+// cost can be quoted from its city before the order is placed.
+type FulfillmentRequest struct {
+	Items             []OrderItemRequest `json:"items" validate:"required,min=1"`
+	ShippingCity      string             `json:"shipping_city"`
+	ShippingCityID    string             `json:"shipping_city_id"`
+	ShippingProvince  string             `json:"shipping_province"`
+	ShippingLatitude  *float64           `json:"shipping_latitude"`
+	ShippingLongitude *float64           `json:"shipping_longitude"`
+}
+
+// FulfillmentResponse has no outlet when the merchant does not use outlets.
+type FulfillmentResponse struct {
+	OutletID     *string `json:"outlet_id"`
+	OutletName   *string `json:"outlet_name"`
+	OriginCity   *string `json:"origin_city"`
+	OriginCityID *string `json:"origin_city_id"` // use as origin for GET /shipping/cost
+	Weight       int     `json:"weight"`         // grams, use as weight for GET /shipping/cost
+}
+
 type OrderItemRequest struct {
 	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id"` // required when the product has variants
 	Quantity  int    `json:"quantity" validate:"required,min=1"`
 }
 
@@ -29,10 +50,16 @@ type UpdateOrderStatusRequest struct {
 	TrackingNumber string `json:"tracking_number"`
 }
 
+type CancelOrderRequest struct {
+	Reason string `json:"reason"` // required when the merchant cancels
+}
+
 type OrderResponse struct {
 	ID                 string              `json:"id"`
 	CustomerID         string              `json:"customer_id"`
 	MerchantID         string              `json:"merchant_id"`
+	OutletID           *string             `json:"outlet_id,omitempty"`
+	CheckoutID         *string             `json:"checkout_id,omitempty"` // set for orders placed together from the cart
 	Status             string              `json:"status"`
 	Subtotal           float64             `json:"subtotal"`
 	ShippingCost       float64             `json:"shipping_cost"`
@@ -50,18 +77,24 @@ type OrderResponse struct {
 	PaymentURL         *string             `json:"payment_url,omitempty"`
 	PaidAt             *time.Time          `json:"paid_at,omitempty"`
 	Notes              *string             `json:"notes,omitempty"`
+	CancelReason       *string             `json:"cancel_reason,omitempty"`
+	CancelledAt        *time.Time          `json:"cancelled_at,omitempty"`
 	Items              []OrderItemResponse `json:"items,omitempty"`
 	CreatedAt          time.Time           `json:"created_at"`
 	UpdatedAt          time.Time           `json:"updated_at"`
 }
 
 type OrderItemResponse struct {
-	ID           string  `json:"id"`
-	ProductID    string  `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	ProductPrice float64 `json:"product_price"`
-	Quantity     int     `json:"quantity"`
-	Subtotal     float64 `json:"subtotal"`
+	ID            string   `json:"id"`
+	ProductID     string   `json:"product_id"`
+	ProductName   string   `json:"product_name"`
+	VariantID     *string  `json:"variant_id,omitempty"`
+	VariantName   *string  `json:"variant_name,omitempty"`
+	ProductPrice  float64  `json:"product_price"`            // the price charged
+	OriginalPrice *float64 `json:"original_price,omitempty"` // the price before the discount
+	DiscountID    *string  `json:"discount_id,omitempty"`
+	Quantity      int      `json:"quantity"`
+	Subtotal      float64  `json:"subtotal"`
 }
 
 type OrderListResponse struct {
@@ -76,6 +109,8 @@ func ToOrderResponse(order *entities.Order) OrderResponse {
 		ID:                 order.ID,
 		CustomerID:         order.CustomerID,
 		MerchantID:         order.MerchantID,
+		OutletID:           order.OutletID,
+		CheckoutID:         order.CheckoutID,
 		Status:             order.Status,
 		Subtotal:           order.Subtotal,
 		ShippingCost:       order.ShippingCost,
@@ -93,6 +128,8 @@ func ToOrderResponse(order *entities.Order) OrderResponse {
 		PaymentURL:         order.PaymentURL,
 		PaidAt:             order.PaidAt,
 		Notes:              order.Notes,
+		CancelReason:       order.CancelReason,
+		CancelledAt:        order.CancelledAt,
 		CreatedAt:          order.CreatedAt,
 		UpdatedAt:          order.UpdatedAt,
 	}
@@ -101,12 +138,16 @@ func ToOrderResponse(order *entities.Order) OrderResponse {
 		resp.Items = make([]OrderItemResponse, len(order.Items))
 		for i, item := range order.Items {
 			resp.Items[i] = OrderItemResponse{
-				ID:           item.ID,
-				ProductID:    item.ProductID,
-				ProductName:  item.ProductName,
-				ProductPrice: item.ProductPrice,
-				Quantity:     item.Quantity,
-				Subtotal:     item.Subtotal,
+				ID:            item.ID,
+				ProductID:     item.ProductID,
+				ProductName:   item.ProductName,
+				VariantID:     item.VariantID,
+				VariantName:   item.VariantName,
+				ProductPrice:  item.ProductPrice,
+				OriginalPrice: item.OriginalPrice,
+				DiscountID:    item.DiscountID,
+				Quantity:      item.Quantity,
+				Subtotal:      item.Subtotal,
 			}
 		}
 	}
@@ -128,3 +169,35 @@ func ToOrderListResponse(orders []entities.Order, total int64, page, limit int)
 
 	return resp
 }
+
+type OrderStatusEntryResponse struct {
+	FromStatus *string   `json:"from_status"` // null for the entry of a new order
+	ToStatus   string    `json:"to_status"`
+	ActorType  string    `json:"actor_type"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrderTimelineResponse struct {
+	OrderID string                     `json:"order_id"`
+	Status  string                     `json:"status"`
+	History []OrderStatusEntryResponse `json:"history"`
+}
+
+func ToOrderTimelineResponse(order *entities.Order, history []entities.OrderStatusHistory) OrderTimelineResponse {
+	resp := OrderTimelineResponse{
+		OrderID: order.ID,
+		Status:  order.Status,
+		History: make([]OrderStatusEntryResponse, len(history)),
+	}
+
+	for i, entry := range history {
+		resp.History[i] = OrderStatusEntryResponse{
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			ActorType:  entry.ActorType,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+
+	return resp
+}
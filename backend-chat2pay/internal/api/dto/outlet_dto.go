@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+type OutletRequest struct {
+	Name      string   `json:"name" validate:"required"`
+	Address   string   `json:"address"`
+	City      string   `json:"city"`
+	CityID    string   `json:"city_id"` // shipping provider city id, the shipping origin
+	Province  string   `json:"province"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Phone     string   `json:"phone"`
+	Status    string   `json:"status"` // active (default) or inactive
+}
+
+type OutletResponse struct {
+	ID         string    `json:"id"`
+	MerchantID string    `json:"merchant_id"`
+	Name       string    `json:"name"`
+	Address    *string   `json:"address,omitempty"`
+	City       *string   `json:"city,omitempty"`
+	CityID     *string   `json:"city_id,omitempty"`
+	Province   *string   `json:"province,omitempty"`
+	Latitude   *float64  `json:"latitude,omitempty"`
+	Longitude  *float64  `json:"longitude,omitempty"`
+	Phone      *string   `json:"phone,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type OutletStockRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id"` // required when the product has variants
+	Stock     int    `json:"stock" validate:"gte=0"`
+}
+
+// SetOutletStocksRequest sets the stock of the listed products at an
+// outlet. Products left out keep their current outlet stock.
+type SetOutletStocksRequest struct {
+	Items []OutletStockRequest `json:"items" validate:"required,min=1"`
+}
+
+type OutletStockResponse struct {
+	ProductID   string    `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	VariantID   *string   `json:"variant_id,omitempty"`
+	VariantName *string   `json:"variant_name,omitempty"`
+	Stock       int       `json:"stock"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func ToOutletResponse(outlet *entities.Outlet) OutletResponse {
+	return OutletResponse{
+		ID:         outlet.ID,
+		MerchantID: outlet.MerchantID,
+		Name:       outlet.Name,
+		Address:    outlet.Address,
+		City:       outlet.City,
+		CityID:     outlet.CityID,
+		Province:   outlet.Province,
+		Latitude:   outlet.Latitude,
+		Longitude:  outlet.Longitude,
+		Phone:      outlet.Phone,
+		Status:     outlet.Status,
+		CreatedAt:  outlet.CreatedAt,
+		UpdatedAt:  outlet.UpdatedAt,
+	}
+}
+
+func ToOutletListResponse(outlets []entities.Outlet) []OutletResponse {
+	responses := make([]OutletResponse, len(outlets))
+	for i, outlet := range outlets {
+		responses[i] = ToOutletResponse(&outlet)
+	}
+	return responses
+}
+
+func ToOutletStockResponses(stocks []entities.OutletStock) []OutletStockResponse {
+	responses := make([]OutletStockResponse, len(stocks))
+	for i, stock := range stocks {
+		responses[i] = OutletStockResponse{
+			ProductID:   stock.ProductID,
+			ProductName: stock.ProductName,
+			VariantID:   stock.VariantID,
+			VariantName: stock.VariantName,
+			Stock:       stock.Stock,
+			UpdatedAt:   stock.UpdatedAt,
+		}
+	}
+	return responses
+}
@@ -6,47 +6,76 @@ import (
 )
 
 type AskProduct struct {
-	SessionId string `json:"connection_id"`
-	Prompt    string `json:"prompt"`
+	SessionId  string `json:"connection_id"`
+	Prompt     string `json:"prompt"`
+	MerchantID string `json:"merchant_id,omitempty"` // store the customer is chatting with
+	ProductID  string `json:"product_id,omitempty"`  // product the customer is looking at
+	VariantID  string `json:"variant_id,omitempty"`  // variant of it the customer picked
+	CategoryID string `json:"category_id,omitempty"` // limits the search to a category
+	CustomerID string `json:"-"`                     // taken from the token when the customer is logged in
+}
+
+// ProductListQuery holds the filters of a product listing.
+type ProductListQuery struct {
+	MerchantID string
+	Query      string
+	CategoryID string            // includes the descendant categories
+	Status     string            // empty lists all but archived products
+	Attributes map[string]string // attribute filter parameters, e.g. attr.ram=16
 }
 
 type ProductRequest struct {
-	MerchantID  string  `json:"merchant_id" validate:"required"`
-	OutletID    *string `json:"outlet_id"`
-	CategoryID  *string `json:"category_id"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description"`
-	SKU         string  `json:"sku"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" validate:"gte=0"`
-	Status      string  `json:"status"`
-	Image       string  `json:"image"`
-	Weight      int     `json:"weight"`
-	Length      int     `json:"length"`
-	Width       int     `json:"width"`
-	Height      int     `json:"height"`
+	MerchantID        string                     `json:"-"` // taken from the token
+	UserID            string                     `json:"-"` // the merchant user, taken from the token
+	OutletID          *string                    `json:"outlet_id"`
+	CategoryID        *string                    `json:"category_id"`
+	Name              string                     `json:"name" validate:"required"`
+	Description       string                     `json:"description"`
+	SKU               string                     `json:"sku"`
+	Price             float64                    `json:"price" validate:"required,gt=0"`
+	Stock             int                        `json:"stock" validate:"gte=0"`
+	LowStockThreshold int                        `json:"low_stock_threshold" validate:"gte=0"` // 0 only alerts when sold out
+	Attributes        entities.ProductAttributes `json:"attributes"`                           // checked against the category attribute schema
+	Status            string                     `json:"status"`                               // draft, active or inactive; deleting archives
+	Weight            int                        `json:"weight"`
+	Length            int                        `json:"length"`
+	Width             int                        `json:"width"`
+	Height            int                        `json:"height"`
+	PublishAt         *time.Time                 `json:"publish_at"`   // the product goes active at this time
+	UnpublishAt       *time.Time                 `json:"unpublish_at"` // the product goes inactive at this time
 }
 
 type ProductResponse struct {
-	ID          string                 `json:"id"`
-	MerchantID  string                 `json:"merchant_id"`
-	OutletID    *string                `json:"outlet_id,omitempty"`
-	CategoryID  *string                `json:"category_id,omitempty"`
-	Category    *ProductCategorySimple `json:"category,omitempty"`
-	Name        string                 `json:"name"`
-	Description *string                `json:"description,omitempty"`
-	SKU         *string                `json:"sku,omitempty"`
-	Price       float64                `json:"price"`
-	Stock       int                    `json:"stock"`
-	Status      string                 `json:"status"`
-	Image       *string                `json:"image,omitempty"`
-	Weight      int                    `json:"weight"`
-	Length      int                    `json:"length"`
-	Width       int                    `json:"width"`
-	Height      int                    `json:"height"`
-	Images      []ProductImageResponse `json:"images,omitempty"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	ID                string                     `json:"id"`
+	MerchantID        string                     `json:"merchant_id"`
+	OutletID          *string                    `json:"outlet_id,omitempty"`
+	CategoryID        *string                    `json:"category_id,omitempty"`
+	Category          *ProductCategorySimple     `json:"category,omitempty"`
+	Name              string                     `json:"name"`
+	Description       *string                    `json:"description,omitempty"`
+	SKU               *string                    `json:"sku,omitempty"`
+	Price             float64                    `json:"price"`
+	EffectivePrice    float64                    `json:"effective_price"` // the price after the discount running now
+	Discount          *ProductDiscountResponse   `json:"discount,omitempty"`
+	Rating            float64                    `json:"rating"` // average over the reviews, 0 without reviews
+	ReviewCount       int                        `json:"review_count"`
+	Stock             int                        `json:"stock"`
+	LowStockThreshold int                        `json:"low_stock_threshold"`
+	Attributes        entities.ProductAttributes `json:"attributes,omitempty"`
+	Status            string                     `json:"status"`
+	Image             *string                    `json:"image,omitempty"`
+	Weight            int                        `json:"weight"`
+	Length            int                        `json:"length"`
+	Width             int                        `json:"width"`
+	Height            int                        `json:"height"`
+	Images            []ProductImageResponse     `json:"images,omitempty"`
+	Options           []ProductOptionResponse    `json:"options,omitempty"`
+	Variants          []ProductVariantResponse   `json:"variants,omitempty"`
+	PublishAt         *time.Time                 `json:"publish_at,omitempty"`
+	UnpublishAt       *time.Time                 `json:"unpublish_at,omitempty"`
+	ArchivedAt        *time.Time                 `json:"archived_at,omitempty"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
 }
 
 type ProductCategorySimple struct {
@@ -55,9 +84,15 @@ type ProductCategorySimple struct {
 }
 
 type ProductImageResponse struct {
-	ID        string `json:"id"`
-	ImageURL  string `json:"image_url"`
-	IsPrimary bool   `json:"is_primary"`
+	ID           string `json:"id"`
+	ImageURL     string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Position     int    `json:"position"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required"`
 }
 
 type ProductListResponse struct {
@@ -69,23 +104,32 @@ type ProductListResponse struct {
 
 func ToProductResponse(product *entities.Product) ProductResponse {
 	response := ProductResponse{
-		ID:          product.ID,
-		MerchantID:  product.MerchantID,
-		OutletID:    product.OutletID,
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Description: product.Description,
-		SKU:         product.SKU,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Status:      product.Status,
-		Image:       product.Image,
-		Weight:      product.Weight,
-		Length:      product.Length,
-		Width:       product.Width,
-		Height:      product.Height,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ID:                product.ID,
+		MerchantID:        product.MerchantID,
+		OutletID:          product.OutletID,
+		CategoryID:        product.CategoryID,
+		Name:              product.Name,
+		Description:       product.Description,
+		SKU:               product.SKU,
+		Price:             product.Price,
+		EffectivePrice:    product.Discounted(product.Price),
+		Discount:          ToProductDiscountResponse(product.Discount),
+		Rating:            product.Rating.Average,
+		ReviewCount:       product.Rating.Count,
+		Stock:             product.Stock,
+		LowStockThreshold: product.LowStockThreshold,
+		Attributes:        product.Attributes,
+		Status:            product.Status,
+		Image:             product.Image,
+		Weight:            product.Weight,
+		Length:            product.Length,
+		Width:             product.Width,
+		Height:            product.Height,
+		PublishAt:         product.PublishAt,
+		UnpublishAt:       product.UnpublishAt,
+		ArchivedAt:        product.ArchivedAt,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
 	}
 
 	if product.Category != nil {
@@ -96,20 +140,39 @@ func ToProductResponse(product *entities.Product) ProductResponse {
 	}
 
 	if len(product.Images) > 0 {
-		images := make([]ProductImageResponse, len(product.Images))
-		for i, img := range product.Images {
-			images[i] = ProductImageResponse{
-				ID:        img.ID,
-				ImageURL:  img.ImageURL,
-				IsPrimary: img.IsPrimary,
-			}
-		}
-		response.Images = images
+		response.Images = ToProductImageResponses(product.Images)
+	}
+
+	if len(product.Variants) > 0 {
+		response.Options = ToProductOptionResponses(product.Options)
+		response.Variants = ToProductVariantResponses(product)
 	}
 
 	return response
 }
 
+func ToProductImageResponses(images []entities.ProductImage) []ProductImageResponse {
+	responses := make([]ProductImageResponse, len(images))
+	for i, img := range images {
+		responses[i] = ProductImageResponse{
+			ID:           img.ID,
+			ImageURL:     img.ImageURL,
+			ThumbnailURL: img.ThumbnailURL,
+			Position:     img.Position,
+			IsPrimary:    img.IsPrimary,
+		}
+	}
+	return responses
+}
+
+func ToProductResponses(products []entities.Product) []ProductResponse {
+	productResponses := make([]ProductResponse, len(products))
+	for i, product := range products {
+		productResponses[i] = ToProductResponse(&product)
+	}
+	return productResponses
+}
+
 func ToProductListResponse(products []entities.Product, total int64, page, limit int) ProductListResponse {
 	productResponses := make([]ProductResponse, len(products))
 	for i, product := range products {
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+// ProductFAQRequest sets a FAQ entry for a product, or for the whole store
+// when ProductID is empty.
+type ProductFAQRequest struct {
+	ProductID *string `json:"product_id"`
+	Question  string  `json:"question" validate:"required"`
+	Answer    string  `json:"answer" validate:"required"`
+}
+
+type ProductFAQResponse struct {
+	ID        string    `json:"id"`
+	ProductID *string   `json:"product_id,omitempty"` // empty for the whole store
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ProductFAQListResponse struct {
+	FAQs  []ProductFAQResponse `json:"faqs"`
+	Total int64                `json:"total"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+}
+
+func (r *ProductFAQRequest) ToEntity(merchantId string) entities.ProductFAQ {
+	return entities.ProductFAQ{
+		MerchantID: merchantId,
+		ProductID:  r.ProductID,
+		Question:   r.Question,
+		Answer:     r.Answer,
+	}
+}
+
+func ToProductFAQResponse(faq *entities.ProductFAQ) ProductFAQResponse {
+	return ProductFAQResponse{
+		ID:        faq.ID,
+		ProductID: faq.ProductID,
+		Question:  faq.Question,
+		Answer:    faq.Answer,
+		CreatedAt: faq.CreatedAt,
+		UpdatedAt: faq.UpdatedAt,
+	}
+}
+
+func ToProductFAQListResponse(faqs []entities.ProductFAQ, total int64, page, limit int) ProductFAQListResponse {
+	responses := make([]ProductFAQResponse, len(faqs))
+	for i := range faqs {
+		responses[i] = ToProductFAQResponse(&faqs[i])
+	}
+
+	return ProductFAQListResponse{
+		FAQs:  responses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+type ImportRowResult struct {
+	Line   int      `json:"line"`
+	SKU    string   `json:"sku"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"` // create, update or error
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReport describes what an import did, or would do on a dry run,
+// with every row of the file.
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Invalid int               `json:"invalid"`
+	Rows    []ImportRowResult `json:"rows"`
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+// ProductReviewRequest reviews a product of a delivered order. It is sent as
+// multipart/form-data when it comes with photos.
+type ProductReviewRequest struct {
+	OrderID   string `json:"order_id" form:"order_id" validate:"required"`
+	ProductID string `json:"product_id" form:"product_id" validate:"required"`
+	Rating    int    `json:"rating" form:"rating" validate:"required,min=1,max=5"`
+	Body      string `json:"body" form:"body"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" validate:"required"`
+}
+
+type ProductReviewResponse struct {
+	ID            string                       `json:"id"`
+	ProductID     string                       `json:"product_id"`
+	ProductName   string                       `json:"product_name"`
+	OrderID       string                       `json:"order_id"`
+	CustomerName  string                       `json:"customer_name"`
+	Rating        int                          `json:"rating"`
+	Body          *string                      `json:"body,omitempty"`
+	Images        []ProductReviewImageResponse `json:"images"`
+	MerchantReply *string                      `json:"merchant_reply,omitempty"`
+	RepliedAt     *time.Time                   `json:"replied_at,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+type ProductReviewImageResponse struct {
+	ImageURL     string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+type ProductReviewListResponse struct {
+	Rating  *ProductRatingResponse  `json:"rating,omitempty"` // for the reviews of one product
+	Reviews []ProductReviewResponse `json:"reviews"`
+	Total   int64                   `json:"total"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+}
+
+type ProductRatingResponse struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+func ToProductReviewResponse(review *entities.ProductReview) ProductReviewResponse {
+	images := make([]ProductReviewImageResponse, len(review.Images))
+	for i, image := range review.Images {
+		images[i] = ProductReviewImageResponse{
+			ImageURL:     image.ImageURL,
+			ThumbnailURL: image.ThumbnailURL,
+		}
+	}
+
+	return ProductReviewResponse{
+		ID:            review.ID,
+		ProductID:     review.ProductID,
+		ProductName:   review.ProductName,
+		OrderID:       review.OrderID,
+		CustomerName:  review.CustomerName,
+		Rating:        review.Rating,
+		Body:          review.Body,
+		Images:        images,
+		MerchantReply: review.MerchantReply,
+		RepliedAt:     review.RepliedAt,
+		CreatedAt:     review.CreatedAt,
+	}
+}
+
+func ToProductReviewListResponse(reviews []entities.ProductReview, rating *entities.ProductRating, total int64, page, limit int) ProductReviewListResponse {
+	responses := make([]ProductReviewResponse, len(reviews))
+	for i, review := range reviews {
+		responses[i] = ToProductReviewResponse(&review)
+	}
+
+	response := ProductReviewListResponse{
+		Reviews: responses,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}
+	if rating != nil {
+		response.Rating = &ProductRatingResponse{Average: rating.Average, Count: rating.Count}
+	}
+
+	return response
+}
@@ -0,0 +1,85 @@
+package dto
+
+import "chat2pay/internal/entities"
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Values []string `json:"values" validate:"required,min=1"`
+}
+
+type ProductVariantRequest struct {
+	ID       string            `json:"id"` // empty for a new variant
+	Options  map[string]string `json:"options" validate:"required"`
+	SKU      string            `json:"sku"`
+	Price    *float64          `json:"price"` // defaults to the product price
+	Stock    int               `json:"stock" validate:"gte=0"`
+	Weight   *int              `json:"weight"` // defaults to the product weight
+	ImageURL string            `json:"image_url"`
+}
+
+// SaveVariantsRequest is the complete variant setup of a product. Variants
+// missing from the request are removed.
+type SaveVariantsRequest struct {
+	Options  []ProductOptionRequest  `json:"options"`
+	Variants []ProductVariantRequest `json:"variants"`
+}
+
+type ProductOptionResponse struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariantResponse struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Options        map[string]string `json:"options"`
+	SKU            *string           `json:"sku,omitempty"`
+	Price          float64           `json:"price"`
+	EffectivePrice float64           `json:"effective_price"`
+	Stock          int               `json:"stock"`
+	Weight         int               `json:"weight"`
+	ImageURL       *string           `json:"image_url,omitempty"`
+}
+
+type ProductVariantsResponse struct {
+	Options  []ProductOptionResponse  `json:"options"`
+	Variants []ProductVariantResponse `json:"variants"`
+}
+
+func ToProductOptionResponses(options []entities.ProductOption) []ProductOptionResponse {
+	responses := make([]ProductOptionResponse, len(options))
+	for i, option := range options {
+		responses[i] = ProductOptionResponse{
+			Name:   option.Name,
+			Values: option.Values,
+		}
+	}
+	return responses
+}
+
+// ToProductVariantResponses resolves the price and weight of each variant
+// against its product, and the price after the discount of the product.
+func ToProductVariantResponses(product *entities.Product) []ProductVariantResponse {
+	responses := make([]ProductVariantResponse, len(product.Variants))
+	for i, variant := range product.Variants {
+		responses[i] = ProductVariantResponse{
+			ID:             variant.ID,
+			Name:           variant.Name,
+			Options:        variant.Options,
+			SKU:            variant.SKU,
+			Price:          variant.PriceOf(product),
+			EffectivePrice: product.Discounted(variant.PriceOf(product)),
+			Stock:          variant.Stock,
+			Weight:         variant.WeightOf(product),
+			ImageURL:       variant.ImageURL,
+		}
+	}
+	return responses
+}
+
+func ToProductVariantsResponse(product *entities.Product) ProductVariantsResponse {
+	return ProductVariantsResponse{
+		Options:  ToProductOptionResponses(product.Options),
+		Variants: ToProductVariantResponses(product),
+	}
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/querynorm"
+	"time"
+)
+
+type SynonymRequest struct {
+	Term     string   `json:"term" validate:"required"`
+	Synonyms []string `json:"synonyms" validate:"required,min=1"`
+}
+
+type SynonymResponse struct {
+	ID        string     `json:"id,omitempty"`
+	Term      string     `json:"term"`
+	Synonyms  []string   `json:"synonyms"`
+	Replace   bool       `json:"replace"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type SynonymListResponse struct {
+	Global   []SynonymResponse `json:"global"`
+	Merchant []SynonymResponse `json:"merchant"`
+}
+
+type NormalizeQueryResponse struct {
+	Query      string `json:"query"`
+	Normalized string `json:"normalized"`
+}
+
+func ToSynonymResponse(synonym *entities.SearchSynonym) SynonymResponse {
+	return SynonymResponse{
+		ID:        synonym.ID,
+		Term:      synonym.Term,
+		Synonyms:  synonym.Synonyms,
+		CreatedAt: &synonym.CreatedAt,
+	}
+}
+
+func ToSynonymListResponse(global querynorm.Dictionary, synonyms []entities.SearchSynonym) SynonymListResponse {
+	response := SynonymListResponse{
+		Global:   make([]SynonymResponse, 0, len(global)),
+		Merchant: make([]SynonymResponse, 0, len(synonyms)),
+	}
+
+	for _, e := range global {
+		response.Global = append(response.Global, SynonymResponse{
+			Term:     e.Term,
+			Synonyms: e.Synonyms,
+			Replace:  e.Replace,
+		})
+	}
+	for i := range synonyms {
+		response.Merchant = append(response.Merchant, ToSynonymResponse(&synonyms[i]))
+	}
+
+	return response
+}
@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+// StockAdjustmentRequest changes the stock of a product, of one variant when
+// the product has variants, at one outlet when it is stocked per outlet.
+type StockAdjustmentRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id"`
+	OutletID  string `json:"outlet_id"`
+	Type      string `json:"type"`      // restock or adjustment (default)
+	Quantity  int    `json:"quantity"`  // change in stock, negative to take stock out
+	Reference string `json:"reference"` // e.g. a supplier invoice number
+	Note      string `json:"note"`
+}
+
+type StockMovementResponse struct {
+	ID            string    `json:"id"`
+	ProductID     string    `json:"product_id"`
+	ProductName   string    `json:"product_name,omitempty"`
+	VariantID     *string   `json:"variant_id,omitempty"`
+	VariantName   *string   `json:"variant_name,omitempty"`
+	OutletID      *string   `json:"outlet_id,omitempty"`
+	OutletName    *string   `json:"outlet_name,omitempty"`
+	Type          string    `json:"type"`
+	Quantity      int       `json:"quantity"`
+	StockAfter    int       `json:"stock_after"`
+	ActorType     string    `json:"actor_type"`
+	ActorID       *string   `json:"actor_id,omitempty"`
+	ReferenceType *string   `json:"reference_type,omitempty"`
+	ReferenceID   *string   `json:"reference_id,omitempty"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type StockMovementListResponse struct {
+	Movements []StockMovementResponse `json:"movements"`
+	Total     int64                   `json:"total"`
+	Page      int                     `json:"page"`
+	Limit     int                     `json:"limit"`
+}
+
+func ToStockMovementResponse(movement *entities.StockMovement) StockMovementResponse {
+	return StockMovementResponse{
+		ID:            movement.ID,
+		ProductID:     movement.ProductID,
+		ProductName:   movement.ProductName,
+		VariantID:     movement.VariantID,
+		VariantName:   movement.VariantName,
+		OutletID:      movement.OutletID,
+		OutletName:    movement.OutletName,
+		Type:          movement.Type,
+		Quantity:      movement.Quantity,
+		StockAfter:    movement.StockAfter,
+		ActorType:     movement.ActorType,
+		ActorID:       movement.ActorID,
+		ReferenceType: movement.ReferenceType,
+		ReferenceID:   movement.ReferenceID,
+		Note:          movement.Note,
+		CreatedAt:     movement.CreatedAt,
+	}
+}
+
+func ToStockMovementListResponse(movements []entities.StockMovement, total int64, page, limit int) StockMovementListResponse {
+	responses := make([]StockMovementResponse, len(movements))
+	for i, movement := range movements {
+		responses[i] = ToStockMovementResponse(&movement)
+	}
+
+	return StockMovementListResponse{
+		Movements: responses,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+	}
+}
+
+type StockAlertResponse struct {
+	ID           string     `json:"id"`
+	ProductID    string     `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	Type         string     `json:"type"`
+	Stock        int        `json:"stock"` // stock when the alert was raised
+	Threshold    int        `json:"threshold"`
+	CurrentStock int        `json:"current_stock"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+type StockAlertListResponse struct {
+	Alerts []StockAlertResponse `json:"alerts"`
+	Total  int64                `json:"total"`
+	Page   int                  `json:"page"`
+	Limit  int                  `json:"limit"`
+}
+
+// ChatStockMissReportResponse lists how often the chat could not offer each
+// product because it was out of stock, since a point in time.
+type ChatStockMissReportResponse struct {
+	Products []ChatStockMissResponse `json:"products"`
+	Since    time.Time               `json:"since"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	Limit    int                     `json:"limit"`
+}
+
+type ChatStockMissResponse struct {
+	ProductID   string    `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Stock       int       `json:"stock"`
+	Misses      int64     `json:"misses"`   // searches that matched the product
+	Sessions    int64     `json:"sessions"` // distinct chat sessions
+	LastMissAt  time.Time `json:"last_miss_at"`
+}
+
+func ToStockAlertListResponse(alerts []entities.StockAlert, total int64, page, limit int) StockAlertListResponse {
+	responses := make([]StockAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = StockAlertResponse{
+			ID:           alert.ID,
+			ProductID:    alert.ProductID,
+			ProductName:  alert.ProductName,
+			Type:         alert.Type,
+			Stock:        alert.Stock,
+			Threshold:    alert.Threshold,
+			CurrentStock: alert.CurrentStock,
+			CreatedAt:    alert.CreatedAt,
+			ResolvedAt:   alert.ResolvedAt,
+		}
+	}
+
+	return StockAlertListResponse{
+		Alerts: responses,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	}
+}
+
+func ToChatStockMissReportResponse(summaries []entities.ChatStockMissSummary, since time.Time, total int64, page, limit int) ChatStockMissReportResponse {
+	responses := make([]ChatStockMissResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = ChatStockMissResponse{
+			ProductID:   summary.ProductID,
+			ProductName: summary.ProductName,
+			Stock:       summary.Stock,
+			Misses:      summary.Misses,
+			Sessions:    summary.Sessions,
+			LastMissAt:  summary.LastMissAt,
+		}
+	}
+
+	return ChatStockMissReportResponse{
+		Products: responses,
+		Since:    since,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"chat2pay/internal/entities"
+	"time"
+)
+
+type WishlistRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+}
+
+type WishlistItemResponse struct {
+	ID         string          `json:"id"`
+	Product    ProductResponse `json:"product"`
+	AddedPrice float64         `json:"added_price"` // effective price when added
+	PriceDrop  float64         `json:"price_drop"`  // how much cheaper it is now than when added, 0 when it is not
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type WishlistResponse struct {
+	Items []WishlistItemResponse `json:"items"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+}
+
+// ToWishlistItemResponse describes the item with its product, which must
+// be loaded.
+func ToWishlistItemResponse(item *entities.WishlistItem) WishlistItemResponse {
+	product := ToProductResponse(item.Product)
+
+	response := WishlistItemResponse{
+		ID:         item.ID,
+		Product:    product,
+		AddedPrice: item.AddedPrice,
+		CreatedAt:  item.CreatedAt,
+	}
+	if product.EffectivePrice < item.AddedPrice {
+		response.PriceDrop = item.AddedPrice - product.EffectivePrice
+	}
+
+	return response
+}
+
+func ToWishlistResponse(items []entities.WishlistItem, total int64, page, limit int) WishlistResponse {
+	responses := make([]WishlistItemResponse, 0, len(items))
+	for i := range items {
+		if items[i].Product == nil {
+			continue
+		}
+		responses = append(responses, ToWishlistItemResponse(&items[i]))
+	}
+
+	return WishlistResponse{
+		Items: responses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandler struct {
+	cartService  service.CartService
+	orderService service.OrderService
+}
+
+func NewCartHandler(cartService service.CartService, orderService service.OrderService) *CartHandler {
+	return &CartHandler{
+		cartService:  cartService,
+		orderService: orderService,
+	}
+}
+
+// GetCart godoc
+// @Summary Get Cart
+// @Description Mendapatkan keranjang customer, dikelompokkan per merchant, dengan harga dan stok produk saat ini
+// @Tags Cart
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.CartResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart [get]
+func (h *CartHandler) GetCart(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	response := h.cartService.GetCart(c.Context(), customerIDVal.(string))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// AddItem godoc
+// @Summary Add to Cart
+// @Description Memasukkan produk ke keranjang. Bila produk atau variannya sudah ada, jumlahnya ditambah.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.AddCartItemRequest true "Product"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.CartResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart/items [post]
+func (h *CartHandler) AddItem(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.AddCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.cartService.AddItem(c.Context(), customerIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// UpdateItem godoc
+// @Summary Update Cart Item
+// @Description Mengubah jumlah item di keranjang. Jumlah 0 menghapus item.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Cart item ID"
+// @Param request body dto.UpdateCartItemRequest true "Quantity"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.CartResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart/items/{id} [patch]
+func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.UpdateCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.cartService.UpdateItem(c.Context(), customerIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// RemoveItem godoc
+// @Summary Remove from Cart
+// @Description Menghapus item dari keranjang
+// @Tags Cart
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Cart item ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.CartResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart/items/{id} [delete]
+func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	response := h.cartService.RemoveItem(c.Context(), customerIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetFulfillment godoc
+// @Summary Get Cart Fulfillment
+// @Description Mendapatkan outlet pengirim dan berat barang untuk tiap merchant di keranjang, untuk menghitung ongkir tiap pesanan
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.CartFulfillmentRequest true "Destination"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.CartFulfillmentResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart/fulfillment [post]
+func (h *CartHandler) GetFulfillment(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.CartFulfillmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.orderService.GetCartFulfillment(c.Context(), customerIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Checkout godoc
+// @Summary Checkout Cart
+// @Description Membuat satu pesanan per merchant dari isi keranjang dalam satu checkout, dengan pengiriman yang dipilih untuk tiap merchant
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Key that makes retries of this request replay its first response"
+// @Param request body dto.CheckoutRequest true "Shipping"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.CheckoutResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /customer/cart/checkout [post]
+func (h *CartHandler) Checkout(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.CheckoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.orderService.Checkout(c.Context(), customerIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
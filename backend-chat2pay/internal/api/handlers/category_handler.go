@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CategoryHandler struct {
+	categoryService service.CategoryService
+}
+
+func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// Create godoc
+// @Summary Create Category
+// @Description Membuat kategori produk baru, opsional di bawah kategori induk
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.CategoryRequest true "Category data"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.CategoryResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Router /categories [post]
+func (h *CategoryHandler) Create(c *fiber.Ctx) error {
+	var req dto.CategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.categoryService.Create(c.Context(), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetAll godoc
+// @Summary Get All Categories
+// @Description Mendapatkan daftar semua kategori produk
+// @Tags Categories
+// @Produce json
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.CategoryResponse}
+// @Router /categories [get]
+func (h *CategoryHandler) GetAll(c *fiber.Ctx) error {
+	response := h.categoryService.GetAll(c.Context())
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetTree godoc
+// @Summary Get Category Tree
+// @Description Mendapatkan kategori produk dalam bentuk pohon
+// @Tags Categories
+// @Produce json
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.CategoryTreeResponse}
+// @Router /categories/tree [get]
+func (h *CategoryHandler) GetTree(c *fiber.Ctx) error {
+	response := h.categoryService.GetTree(c.Context())
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetById godoc
+// @Summary Get Category by ID
+// @Description Mendapatkan detail kategori berdasarkan ID
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.CategoryResponse}
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetById(c *fiber.Ctx) error {
+	response := h.categoryService.GetById(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetBreadcrumb godoc
+// @Summary Get Category Breadcrumb
+// @Description Mendapatkan jalur kategori dari kategori teratas sampai kategori ini
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductCategorySimple}
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /categories/{id}/breadcrumb [get]
+func (h *CategoryHandler) GetBreadcrumb(c *fiber.Ctx) error {
+	response := h.categoryService.GetBreadcrumb(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Update godoc
+// @Summary Update Category
+// @Description Mengubah nama atau kategori induk
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Category ID"
+// @Param request body dto.CategoryRequest true "Category data"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.CategoryResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) Update(c *fiber.Ctx) error {
+	var req dto.CategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.categoryService.Update(c.Context(), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Delete godoc
+// @Summary Delete Category
+// @Description Menghapus kategori yang tidak memiliki subkategori
+// @Tags Categories
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Category ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
+	response := h.categoryService.Delete(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
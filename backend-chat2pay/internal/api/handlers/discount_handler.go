@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/repositories"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type DiscountHandler struct {
+	discountService service.DiscountService
+}
+
+func NewDiscountHandler(discountService service.DiscountService) *DiscountHandler {
+	return &DiscountHandler{
+		discountService: discountService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get Discounts
+// @Description Mendapatkan daftar diskon merchant, yang mulai paling akhir lebih dulu
+// @Tags Discount
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id query string false "Product ID"
+// @Param category_id query string false "Category ID"
+// @Param state query string false "running, scheduled or ended"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.DiscountListResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/discounts [get]
+func (h *DiscountHandler) GetAll(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	filter := repositories.DiscountFilter{
+		ProductID:  c.Query("product_id"),
+		CategoryID: c.Query("category_id"),
+		State:      c.Query("state"),
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.discountService.GetAll(c.Context(), merchantIDVal.(string), filter, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetById godoc
+// @Summary Get Discount by ID
+// @Description Mendapatkan detail diskon
+// @Tags Discount
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Discount ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.DiscountResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/discounts/{id} [get]
+func (h *DiscountHandler) GetById(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.discountService.GetById(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Create godoc
+// @Summary Create Discount
+// @Description Membuat diskon terjadwal untuk satu produk atau satu kategori (termasuk sub-kategori). Type percentage atau fixed (Rupiah). Bila beberapa diskon berlaku, harga termurah yang dipakai.
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.DiscountRequest true "Discount"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.DiscountResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/discounts [post]
+func (h *DiscountHandler) Create(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.DiscountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.discountService.Create(c.Context(), merchantIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Update godoc
+// @Summary Update Discount
+// @Description Mengubah diskon. Pesanan yang sudah dibuat tetap memakai harga saat dipesan.
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Discount ID"
+// @Param request body dto.DiscountRequest true "Discount"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.DiscountResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/discounts/{id} [put]
+func (h *DiscountHandler) Update(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.DiscountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.discountService.Update(c.Context(), merchantIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Delete godoc
+// @Summary Delete Discount
+// @Description Menghapus diskon, diskon yang sedang berjalan langsung berhenti
+// @Tags Discount
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Discount ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/discounts/{id} [delete]
+func (h *DiscountHandler) Delete(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.discountService.Delete(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetPriceHistory godoc
+// @Summary Get Price History
+// @Description Mendapatkan riwayat perubahan harga dasar produk (manual atau import), terbaru lebih dulu
+// @Tags Discount
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.PriceHistoryResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/products/{id}/price-history [get]
+func (h *DiscountHandler) GetPriceHistory(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.discountService.GetPriceHistory(c.Context(), merchantIDVal.(string), c.Params("id"), page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type MerchantNotificationHandler struct {
+	notificationService service.MerchantNotificationService
+}
+
+func NewMerchantNotificationHandler(notificationService service.MerchantNotificationService) *MerchantNotificationHandler {
+	return &MerchantNotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get Merchant Notifications
+// @Description Mendapatkan notifikasi merchant, seperti peringatan stok, terbaru lebih dulu
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.MerchantNotificationListResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/notifications [get]
+func (h *MerchantNotificationHandler) GetAll(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	unread, _ := strconv.ParseBool(c.Query("unread", "false"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.notificationService.GetAll(c.Context(), merchantIDVal.(string), unread, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// MarkRead godoc
+// @Summary Mark Notification Read
+// @Description Menandai notifikasi sebagai sudah dibaca
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Notification ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=string}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/notifications/{id}/read [put]
+func (h *MerchantNotificationHandler) MarkRead(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.notificationService.MarkRead(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// MarkAllRead godoc
+// @Summary Mark All Notifications Read
+// @Description Menandai semua notifikasi sebagai sudah dibaca
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=string}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/notifications/read [put]
+func (h *MerchantNotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.notificationService.MarkAllRead(c.Context(), merchantIDVal.(string))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
@@ -23,6 +23,7 @@ func NewOrderHandler(orderService service.OrderService) *OrderHandler {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Key that makes retries of this request replay its first response"
 // @Param request body dto.CreateOrderRequest true "Order data"
 // @Success 201 {object} presenter.SuccessResponseSwagger
 // @Router /orders [post]
@@ -42,18 +43,89 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 	return c.Status(result.Code).JSON(result)
 }
 
+// GetFulfillment godoc
+// @Summary Get fulfillment outlet
+// @Description Find the outlet that would ship the items, its city is the origin for the shipping cost
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.FulfillmentRequest true "Items and destination"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.FulfillmentResponse}
+// @Router /orders/fulfillment [post]
+func (h *OrderHandler) GetFulfillment(c *fiber.Ctx) error {
+	var req dto.FulfillmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	result := h.orderService.GetFulfillment(c.Context(), &req)
+	return c.Status(result.Code).JSON(result)
+}
+
 // GetOrder godoc
 // @Summary Get order by ID
-// @Description Get order details
+// @Description Get order details of the current customer
 // @Tags Orders
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Order ID"
 // @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
 // @Router /orders/{id} [get]
 func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+	customerID := customerIDVal.(string)
+
+	id := c.Params("id")
+	result := h.orderService.GetCustomerOrder(c.Context(), customerID, id)
+	return c.Status(result.Code).JSON(result)
+}
+
+// GetOrderTimeline godoc
+// @Summary Get order timeline
+// @Description Get the status changes of an order of the current customer, oldest first
+// @Tags Orders
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.OrderTimelineResponse}
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /orders/{id}/timeline [get]
+func (h *OrderHandler) GetOrderTimeline(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+	customerID := customerIDVal.(string)
+
+	id := c.Params("id")
+	result := h.orderService.GetOrderTimeline(c.Context(), customerID, id)
+	return c.Status(result.Code).JSON(result)
+}
+
+// GetMerchantOrder godoc
+// @Summary Get merchant order by ID
+// @Description Get order details of the current merchant
+// @Tags Orders
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /orders/merchant/{id} [get]
+func (h *OrderHandler) GetMerchantOrder(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+	merchantID := merchantIDVal.(string)
+
 	id := c.Params("id")
-	result := h.orderService.GetOrderByID(c.Context(), id)
+	result := h.orderService.GetMerchantOrder(c.Context(), merchantID, id)
 	return c.Status(result.Code).JSON(result)
 }
 
@@ -105,7 +177,7 @@ func (h *OrderHandler) GetMerchantOrders(c *fiber.Ctx) error {
 
 // UpdateOrderStatus godoc
 // @Summary Update order status
-// @Description Update order status (merchant only)
+// @Description Update order status (merchant only). Orders go pending, paid, processing, shipped, delivered and can be cancelled until shipped. A tracking number ships a processing order.
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -113,8 +185,17 @@ func (h *OrderHandler) GetMerchantOrders(c *fiber.Ctx) error {
 // @Param id path string true "Order ID"
 // @Param request body dto.UpdateOrderStatusRequest true "Status data"
 // @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
 // @Router /orders/{id}/status [patch]
 func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+	merchantID := merchantIDVal.(string)
+
 	id := c.Params("id")
 
 	var req dto.UpdateOrderStatusRequest
@@ -123,10 +204,73 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 	}
 
 	if req.TrackingNumber != "" {
-		result := h.orderService.UpdateTrackingNumber(c.Context(), id, req.TrackingNumber)
+		result := h.orderService.UpdateTrackingNumber(c.Context(), merchantID, id, req.TrackingNumber)
 		return c.Status(result.Code).JSON(result)
 	}
 
-	result := h.orderService.UpdateOrderStatus(c.Context(), id, req.Status)
+	result := h.orderService.UpdateOrderStatus(c.Context(), merchantID, id, req.Status)
+	return c.Status(result.Code).JSON(result)
+}
+
+// CancelOrder godoc
+// @Summary Cancel order
+// @Description Cancel an order of the current customer that is still waiting for payment, its stock is returned
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID"
+// @Param request body dto.CancelOrderRequest false "Optional reason"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+	customerID := customerIDVal.(string)
+
+	var req dto.CancelOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(presenter.ErrorResponse(err))
+		}
+	}
+
+	result := h.orderService.CancelOrder(c.Context(), customerID, c.Params("id"), &req)
+	return c.Status(result.Code).JSON(result)
+}
+
+// CancelMerchantOrder godoc
+// @Summary Cancel merchant order
+// @Description Cancel an order of the current merchant that has not been shipped, with a reason shown to the customer. Its stock is returned
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Order ID"
+// @Param request body dto.CancelOrderRequest true "Reason"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /orders/merchant/{id}/cancel [post]
+func (h *OrderHandler) CancelMerchantOrder(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+	merchantID := merchantIDVal.(string)
+
+	var req dto.CancelOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	result := h.orderService.CancelMerchantOrder(c.Context(), merchantID, userID, c.Params("id"), &req)
 	return c.Status(result.Code).JSON(result)
 }
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OutletHandler struct {
+	outletService service.OutletService
+}
+
+func NewOutletHandler(outletService service.OutletService) *OutletHandler {
+	return &OutletHandler{
+		outletService: outletService,
+	}
+}
+
+// Create godoc
+// @Summary Create Outlet
+// @Description Membuat outlet baru untuk merchant. city_id dipakai sebagai kota asal pengiriman.
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.OutletRequest true "Outlet data"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.OutletResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets [post]
+func (h *OutletHandler) Create(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.OutletRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.outletService.Create(c.Context(), merchantIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetAll godoc
+// @Summary Get Outlets
+// @Description Mendapatkan daftar outlet milik merchant
+// @Tags Outlets
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.OutletResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets [get]
+func (h *OutletHandler) GetAll(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.outletService.GetAll(c.Context(), merchantIDVal.(string))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetById godoc
+// @Summary Get Outlet by ID
+// @Description Mendapatkan detail outlet berdasarkan ID
+// @Tags Outlets
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Outlet ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.OutletResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets/{id} [get]
+func (h *OutletHandler) GetById(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.outletService.GetById(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Update godoc
+// @Summary Update Outlet
+// @Description Mengubah data outlet. Stok outlet yang tidak aktif tidak dihitung dan tidak dipakai untuk pesanan.
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Outlet ID"
+// @Param request body dto.OutletRequest true "Outlet data"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.OutletResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets/{id} [put]
+func (h *OutletHandler) Update(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.OutletRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.outletService.Update(c.Context(), merchantIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Delete godoc
+// @Summary Delete Outlet
+// @Description Menghapus outlet. Outlet yang masih memiliki stok tidak dapat dihapus.
+// @Tags Outlets
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Outlet ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=string}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets/{id} [delete]
+func (h *OutletHandler) Delete(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.outletService.Delete(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetStocks godoc
+// @Summary Get Outlet Stocks
+// @Description Mendapatkan stok produk di outlet
+// @Tags Outlets
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Outlet ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.OutletStockResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets/{id}/stocks [get]
+func (h *OutletHandler) GetStocks(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.outletService.GetStocks(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// SetStocks godoc
+// @Summary Set Outlet Stocks
+// @Description Mengatur stok produk di outlet. Produk dengan varian diatur per varian. Stok produk adalah total stok di semua outlet aktif.
+// @Tags Outlets
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Outlet ID"
+// @Param request body dto.SetOutletStocksRequest true "Stock per product or variant"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.OutletStockResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/outlets/{id}/stocks [put]
+func (h *OutletHandler) SetStocks(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.SetOutletStocksRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	response := h.outletService.SetStocks(c.Context(), merchantIDVal.(string), userID, c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
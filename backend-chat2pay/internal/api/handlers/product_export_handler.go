@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bufio"
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/pkg/catalogexport"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/service"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+	"time"
+)
+
+type ProductExportHandler struct {
+	productExportService service.ProductExportService
+	cfg                  *yaml.Config
+}
+
+func NewProductExportHandler(productExportService service.ProductExportService, cfg *yaml.Config) *ProductExportHandler {
+	return &ProductExportHandler{
+		productExportService: productExportService,
+		cfg:                  cfg,
+	}
+}
+
+// Export godoc
+// @Summary Export Products
+// @Description Export seluruh katalog merchant dalam format CSV atau JSON. Data dikirim secara streaming.
+// @Tags Products
+// @Produce text/csv
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "csv or json" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 500 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/products/export [get]
+func (h *ProductExportHandler) Export(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+	merchantID := merchantIDVal.(string)
+
+	format := strings.ToLower(c.Query("format", catalogexport.FormatCSV))
+	if format != catalogexport.FormatCSV && format != catalogexport.FormatJSON {
+		return c.Status(400).JSON(presenter.ErrorResponse(catalogexport.ErrUnsupportedFormat))
+	}
+
+	log := logger.NewLog("product_export_handler_export", h.cfg.Logger.Enable)
+
+	// The query runs before the body is streamed, so a failure can still be
+	// answered with an error status
+	export, err := h.productExportService.Open(c.Context(), merchantID, format)
+	if err != nil {
+		log.Error(fmt.Sprintf("error opening product export: %v", err))
+		return c.Status(500).JSON(presenter.ErrorResponse(errors.New("failed to export products")))
+	}
+
+	c.Set(fiber.HeaderContentType, catalogexport.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products-%s.%s"`, time.Now().Format("20060102"), format))
+
+	// Once streaming has started the status is sent, so a later error can
+	// only be logged; a client that goes away fails the next write.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Stream(w); err != nil {
+			log.Error(fmt.Sprintf("error streaming product export: %v", err))
+			return
+		}
+		if err := w.Flush(); err != nil {
+			log.Error(fmt.Sprintf("error flushing product export: %v", err))
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/repositories"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type ProductFAQHandler struct {
+	faqService service.ProductFAQService
+}
+
+func NewProductFAQHandler(faqService service.ProductFAQService) *ProductFAQHandler {
+	return &ProductFAQHandler{
+		faqService: faqService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get FAQs
+// @Description Mendapatkan daftar FAQ merchant, terbaru lebih dulu
+// @Tags FAQ
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id query string false "Product ID"
+// @Param store query bool false "Only entries for the whole store"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductFAQListResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/faqs [get]
+func (h *ProductFAQHandler) GetAll(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	storeOnly, _ := strconv.ParseBool(c.Query("store", "false"))
+	filter := repositories.ProductFAQFilter{
+		ProductID: c.Query("product_id"),
+		StoreOnly: storeOnly,
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.faqService.GetAll(c.Context(), merchantIDVal.(string), filter, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetById godoc
+// @Summary Get FAQ by ID
+// @Description Mendapatkan detail FAQ
+// @Tags FAQ
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "FAQ ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductFAQResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/faqs/{id} [get]
+func (h *ProductFAQHandler) GetById(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.faqService.GetById(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Create godoc
+// @Summary Create FAQ
+// @Description Membuat FAQ untuk satu produk, atau untuk seluruh toko bila product_id kosong. Asisten chat mengutip jawabannya apa adanya bila pertanyaan customer sangat mirip.
+// @Tags FAQ
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.ProductFAQRequest true "FAQ"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.ProductFAQResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/faqs [post]
+func (h *ProductFAQHandler) Create(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.ProductFAQRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.faqService.Create(c.Context(), merchantIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Update godoc
+// @Summary Update FAQ
+// @Description Mengubah FAQ
+// @Tags FAQ
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "FAQ ID"
+// @Param request body dto.ProductFAQRequest true "FAQ"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductFAQResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/faqs/{id} [put]
+func (h *ProductFAQHandler) Update(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.ProductFAQRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.faqService.Update(c.Context(), merchantIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Delete godoc
+// @Summary Delete FAQ
+// @Description Menghapus FAQ
+// @Tags FAQ
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "FAQ ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/faqs/{id} [delete]
+func (h *ProductFAQHandler) Delete(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.faqService.Delete(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"chat2pay/internal/api/dto"
 	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
 	"chat2pay/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"strconv"
@@ -32,11 +33,18 @@ func NewProductHandler(productService service.ProductService) *ProductHandler {
 // @Security BearerAuth
 // @Router /products [post]
 func (h *ProductHandler) Create(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
 	var req dto.ProductRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(presenter.ErrorResponse(err))
 	}
+	req.MerchantID = merchantIDVal.(string)
+	req.UserID, _ = c.Locals("user_id").(string)
 
 	response := h.productService.Create(c.Context(), &req)
 
@@ -48,11 +56,21 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 }
 
 func (h *ProductHandler) CreateMultiple(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
 	var req []dto.ProductRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(presenter.ErrorResponse(err))
 	}
+	userID, _ := c.Locals("user_id").(string)
+	for i := range req {
+		req[i].MerchantID = merchantIDVal.(string)
+		req[i].UserID = userID
+	}
 
 	response := h.productService.CreateMultiple(c.Context(), &req)
 
@@ -65,11 +83,14 @@ func (h *ProductHandler) CreateMultiple(c *fiber.Ctx) error {
 
 // GetAll godoc
 // @Summary Get All Products
-// @Description Mendapatkan daftar produk berdasarkan merchant
+// @Description Mendapatkan daftar produk aktif berdasarkan merchant
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param merchant_id query string true "Merchant ID"
+// @Param q query string false "Keyword, slang and synonyms are expanded"
+// @Param category_id query string false "Category ID, includes subcategories"
+// @Param attr.key query string false "Attribute filter, e.g. attr.ram=16GB; attr.key.min and attr.key.max bound a number"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductListResponse}
@@ -82,7 +103,54 @@ func (h *ProductHandler) GetAll(c *fiber.Ctx) error {
 		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
 	}
 
-	response := h.productService.GetAll(c.Context(), c.Query("merchant_id"), page, limit)
+	response := h.productService.GetAll(c.Context(), &dto.ProductListQuery{
+		MerchantID: c.Query("merchant_id"),
+		Query:      c.Query("q"),
+		CategoryID: c.Query("category_id"),
+		Status:     entities.ProductStatusActive,
+		Attributes: c.Queries(),
+	}, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetMerchantProducts godoc
+// @Summary Get Merchant Products
+// @Description Mendapatkan daftar produk milik merchant yang login, termasuk draft dan inactive
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param q query string false "Keyword, slang and synonyms are expanded"
+// @Param category_id query string false "Category ID, includes subcategories"
+// @Param status query string false "draft, active, inactive or archived; archived products are left out by default"
+// @Param attr.key query string false "Attribute filter, e.g. attr.ram=16GB; attr.key.min and attr.key.max bound a number"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductListResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Security BearerAuth
+// @Router /merchant/products [get]
+func (h *ProductHandler) GetMerchantProducts(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	response := h.productService.GetAll(c.Context(), &dto.ProductListQuery{
+		MerchantID: merchantIDVal.(string),
+		Query:      c.Query("q"),
+		CategoryID: c.Query("category_id"),
+		Status:     c.Query("status"),
+		Attributes: c.Queries(),
+	}, page, limit)
 
 	if response.Errors != nil {
 		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
@@ -107,7 +175,89 @@ func (h *ProductHandler) GetById(c *fiber.Ctx) error {
 		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
 	}
 
-	response := h.productService.GetById(c.Context(), c.Params("id"))
+	response := h.productService.GetById(c.Context(), "", c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetMerchantProduct godoc
+// @Summary Get Merchant Product by ID
+// @Description Mendapatkan detail produk milik merchant yang login, apa pun statusnya
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Security BearerAuth
+// @Router /merchant/products/{id} [get]
+func (h *ProductHandler) GetMerchantProduct(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	if c.Params("id") == "" {
+		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
+	}
+
+	response := h.productService.GetById(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetSimilar godoc
+// @Summary Get Similar Products
+// @Description Mendapatkan produk serupa berdasarkan embedding produk
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/similar [get]
+func (h *ProductHandler) GetSimilar(c *fiber.Ctx) error {
+	if c.Params("id") == "" {
+		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
+	}
+
+	response := h.productService.GetSimilar(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetBoughtTogether godoc
+// @Summary Get Frequently Bought Together Products
+// @Description Mendapatkan produk yang sering dibeli bersamaan dalam satu order
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/bought-together [get]
+func (h *ProductHandler) GetBoughtTogether(c *fiber.Ctx) error {
+	if c.Params("id") == "" {
+		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
+	}
+
+	response := h.productService.GetBoughtTogether(c.Context(), c.Params("id"))
 
 	if response.Errors != nil {
 		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
@@ -132,6 +282,11 @@ func (h *ProductHandler) GetById(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Router /products/{id} [put]
 func (h *ProductHandler) Update(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
 	if c.Params("id") == "" {
 		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
 	}
@@ -140,8 +295,10 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(presenter.ErrorResponse(err))
 	}
+	req.MerchantID = merchantIDVal.(string)
+	req.UserID, _ = c.Locals("user_id").(string)
 
-	response := h.productService.Update(c.Context(), c.Params("id"), &req)
+	response := h.productService.Update(c.Context(), req.MerchantID, c.Params("id"), &req)
 
 	if response.Errors != nil {
 		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
@@ -165,11 +322,44 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Router /products/{id} [delete]
 func (h *ProductHandler) Delete(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
 	if c.Params("id") == "" {
 		return c.Status(400).JSON(presenter.ErrorResponse(fiber.ErrBadRequest))
 	}
 
-	response := h.productService.Delete(c.Context(), c.Params("id"))
+	response := h.productService.Delete(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Restore godoc
+// @Summary Restore Product
+// @Description Mengembalikan produk yang sudah dihapus. Produk kembali dengan status inactive
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/restore [post]
+func (h *ProductHandler) Restore(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.productService.Restore(c.Context(), merchantIDVal.(string), c.Params("id"))
 
 	if response.Errors != nil {
 		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
@@ -180,10 +370,11 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 
 // AskProduct godoc
 // @Summary Ask Product (AI Search)
-// @Description Mencari produk menggunakan AI/LLM dengan natural language query
+// @Description Mencari produk menggunakan AI/LLM dengan natural language query. Token customer opsional, dibutuhkan untuk menyimpan produk ke wishlist lewat chat.
 // @Tags AI-LLM
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer token"
 // @Param request body dto.AskProduct true "Prompt pencarian"
 // @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.LLMResponse}
 // @Failure 400 {object} presenter.ErrorResponseSwagger
@@ -196,6 +387,10 @@ func (h *ProductHandler) AskProduct(c *fiber.Ctx) error {
 		return c.Status(400).JSON(presenter.ErrorResponse(err))
 	}
 
+	if customerID, ok := c.Locals("customer_id").(string); ok {
+		req.CustomerID = customerID
+	}
+
 	response := h.productService.AskProduct(c.Context(), &req)
 
 	if response.Errors != nil {
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImageHandler struct {
+	productImageService service.ProductImageService
+}
+
+func NewProductImageHandler(productImageService service.ProductImageService) *ProductImageHandler {
+	return &ProductImageHandler{
+		productImageService: productImageService,
+	}
+}
+
+// Upload godoc
+// @Summary Upload Product Images
+// @Description Upload satu atau beberapa gambar produk (jpeg, png, webp). Gambar pertama produk menjadi gambar utama.
+// @Tags Product Images
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param images formData file true "Image files"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductImageResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/images [post]
+func (h *ProductImageHandler) Upload(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.productImageService.Upload(c.Context(), merchantIDVal.(string), c.Params("id"), form.File["images"])
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetAll godoc
+// @Summary Get Product Images
+// @Description Mendapatkan galeri gambar produk sesuai urutan
+// @Tags Product Images
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductImageResponse}
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/images [get]
+func (h *ProductImageHandler) GetAll(c *fiber.Ctx) error {
+	response := h.productImageService.GetAll(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// SetPrimary godoc
+// @Summary Set Primary Product Image
+// @Description Menjadikan gambar sebagai gambar utama produk
+// @Tags Product Images
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductImageResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/images/{imageId}/primary [patch]
+func (h *ProductImageHandler) SetPrimary(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.productImageService.SetPrimary(c.Context(), merchantIDVal.(string), c.Params("id"), c.Params("imageId"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Reorder godoc
+// @Summary Reorder Product Images
+// @Description Mengubah urutan galeri, image_ids harus berisi semua gambar produk
+// @Tags Product Images
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body dto.ReorderImagesRequest true "Image order"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=[]dto.ProductImageResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/images/order [put]
+func (h *ProductImageHandler) Reorder(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.ReorderImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.productImageService.Reorder(c.Context(), merchantIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Delete godoc
+// @Summary Delete Product Image
+// @Description Menghapus gambar produk. Jika gambar utama dihapus, gambar berikutnya menjadi gambar utama.
+// @Tags Product Images
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/images/{imageId} [delete]
+func (h *ProductImageHandler) Delete(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.productImageService.Delete(c.Context(), merchantIDVal.(string), c.Params("id"), c.Params("imageId"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
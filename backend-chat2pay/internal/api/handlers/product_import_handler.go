@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImportHandler struct {
+	productImportService service.ProductImportService
+}
+
+func NewProductImportHandler(productImportService service.ProductImportService) *ProductImportHandler {
+	return &ProductImportHandler{
+		productImportService: productImportService,
+	}
+}
+
+// Import godoc
+// @Summary Import Products
+// @Description Import katalog dari file CSV atau XLSX. Kolom wajib: sku, name, price. Produk dengan SKU yang sudah ada akan diperbarui. Gunakan dry_run=true untuk melihat laporan per baris tanpa menyimpan.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Validate only"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ImportReport}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 422 {object} presenter.ErrorResponseSwagger{data=dto.ImportReport}
+// @Router /products/import [post]
+func (h *ProductImportHandler) Import(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.productImportService.Import(c.Context(), merchantIDVal.(string), userID, file, c.QueryBool("dry_run"))
+
+	if response.Errors != nil {
+		// A rejected import still carries the row report
+		if response.Data != nil {
+			return c.Status(response.Code).JSON(response)
+		}
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/repositories"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+type ProductReviewHandler struct {
+	reviewService service.ProductReviewService
+}
+
+func NewProductReviewHandler(reviewService service.ProductReviewService) *ProductReviewHandler {
+	return &ProductReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// Create godoc
+// @Summary Create Product Review
+// @Description Memberi ulasan produk dari pesanan yang sudah diterima (delivered): rating 1-5, teks, dan maksimal 5 foto. Satu ulasan per produk per pesanan. Kirim sebagai multipart/form-data bila ada foto.
+// @Tags Review
+// @Accept json,mpfd
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.ProductReviewRequest true "Review"
+// @Param images formData file false "Photos"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.ProductReviewResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Failure 409 {object} presenter.ErrorResponseSwagger
+// @Router /reviews [post]
+func (h *ProductReviewHandler) Create(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.ProductReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	var files []*multipart.FileHeader
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return c.Status(400).JSON(presenter.ErrorResponse(err))
+		}
+		files = form.File["images"]
+	}
+
+	response := h.reviewService.Create(c.Context(), customerIDVal.(string), &req, files)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetByProduct godoc
+// @Summary Get Product Reviews
+// @Description Mendapatkan ulasan produk beserta rata-rata rating, terbaru lebih dulu
+// @Tags Review
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param rating query int false "Only reviews with this many stars"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductReviewListResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/reviews [get]
+func (h *ProductReviewHandler) GetByProduct(c *fiber.Ctx) error {
+	rating, _ := strconv.Atoi(c.Query("rating", "0"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.reviewService.GetByProduct(c.Context(), c.Params("id"), rating, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetByCustomer godoc
+// @Summary Get My Reviews
+// @Description Mendapatkan ulasan yang ditulis customer, terbaru lebih dulu
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductReviewListResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /reviews/customer [get]
+func (h *ProductReviewHandler) GetByCustomer(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.reviewService.GetByCustomer(c.Context(), customerIDVal.(string), page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetByMerchant godoc
+// @Summary Get Merchant Reviews
+// @Description Mendapatkan ulasan produk-produk merchant, terbaru lebih dulu
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id query string false "Product ID"
+// @Param rating query int false "Only reviews with this many stars"
+// @Param unreplied query bool false "Only reviews without a reply"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductReviewListResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/reviews [get]
+func (h *ProductReviewHandler) GetByMerchant(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	rating, _ := strconv.Atoi(c.Query("rating", "0"))
+	unreplied, _ := strconv.ParseBool(c.Query("unreplied", "false"))
+	filter := repositories.ProductReviewFilter{
+		ProductID: c.Query("product_id"),
+		Rating:    rating,
+		Unreplied: unreplied,
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.reviewService.GetByMerchant(c.Context(), merchantIDVal.(string), filter, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Reply godoc
+// @Summary Reply to Review
+// @Description Membalas ulasan produk merchant. Membalas lagi akan mengganti balasan sebelumnya.
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review ID"
+// @Param request body dto.ReviewReplyRequest true "Reply"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductReviewResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/reviews/{id}/reply [put]
+func (h *ProductReviewHandler) Reply(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.ReviewReplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.reviewService.Reply(c.Context(), merchantIDVal.(string), c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
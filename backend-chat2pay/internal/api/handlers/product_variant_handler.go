@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductVariantHandler struct {
+	productVariantService service.ProductVariantService
+}
+
+func NewProductVariantHandler(productVariantService service.ProductVariantService) *ProductVariantHandler {
+	return &ProductVariantHandler{
+		productVariantService: productVariantService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get Product Variants
+// @Description Mendapatkan pilihan (misalnya warna dan ukuran) beserta varian produk
+// @Tags Product Variants
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductVariantsResponse}
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/variants [get]
+func (h *ProductVariantHandler) GetAll(c *fiber.Ctx) error {
+	response := h.productVariantService.GetAll(c.Context(), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Save godoc
+// @Summary Save Product Variants
+// @Description Menyimpan seluruh pilihan dan varian produk. Varian tanpa id dibuat baru, varian yang tidak dikirim akan dihapus.
+// @Tags Product Variants
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param request body dto.SaveVariantsRequest true "Options and variants"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ProductVariantsResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /products/{id}/variants [put]
+func (h *ProductVariantHandler) Save(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.SaveVariantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	response := h.productVariantService.Save(c.Context(), merchantIDVal.(string), userID, c.Params("id"), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
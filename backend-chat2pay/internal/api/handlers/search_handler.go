@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// GetSynonyms godoc
+// @Summary Get search synonyms
+// @Description Daftar sinonim global dan sinonim tambahan milik merchant
+// @Tags Search
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.SynonymListResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /search/synonyms [get]
+func (h *SearchHandler) GetSynonyms(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.searchService.GetSynonyms(c.Context(), merchantIDVal.(string))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// CreateSynonym godoc
+// @Summary Create search synonym
+// @Description Menambah atau mengganti sinonim untuk sebuah kata pencarian
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.SynonymRequest true "Synonym data"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.SynonymResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Router /search/synonyms [post]
+func (h *SearchHandler) CreateSynonym(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.SynonymRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.searchService.CreateSynonym(c.Context(), merchantIDVal.(string), &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// DeleteSynonym godoc
+// @Summary Delete search synonym
+// @Description Menghapus sinonim milik merchant
+// @Tags Search
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Synonym ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 403 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /search/synonyms/{id} [delete]
+func (h *SearchHandler) DeleteSynonym(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.searchService.DeleteSynonym(c.Context(), merchantIDVal.(string), c.Params("id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// NormalizeQuery godoc
+// @Summary Preview query normalization
+// @Description Menampilkan hasil normalisasi query dengan sinonim merchant
+// @Tags Search
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param q query string true "Search query"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.NormalizeQueryResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /search/normalize [get]
+func (h *SearchHandler) NormalizeQuery(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	response := h.searchService.NormalizeQuery(c.Context(), merchantIDVal.(string), c.Query("q"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
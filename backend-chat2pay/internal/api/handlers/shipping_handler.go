@@ -3,17 +3,20 @@ package handlers
 import (
 	"chat2pay/internal/api/presenter"
 	"chat2pay/internal/pkg/rajaongkir"
+	"chat2pay/internal/pkg/shipping"
 	"github.com/gofiber/fiber/v2"
 	"strconv"
 )
 
 type ShippingHandler struct {
 	rajaOngkir *rajaongkir.RajaOngkir
+	quoter     *shipping.Quoter
 	useAPI     bool
 }
 
-func NewShippingHandler(apiKey string) *ShippingHandler {
+func NewShippingHandler(apiKey string, quoter *shipping.Quoter) *ShippingHandler {
 	handler := &ShippingHandler{
+		quoter: quoter,
 		useAPI: apiKey != "",
 	}
 	if apiKey != "" {
@@ -36,24 +39,6 @@ type City struct {
 	PostalCode string `json:"postal_code"`
 }
 
-type ShippingCost struct {
-	Service     string `json:"service"`
-	Description string `json:"description"`
-	Cost        []Cost `json:"cost"`
-}
-
-type Cost struct {
-	Value int    `json:"value"`
-	Etd   string `json:"etd"`
-	Note  string `json:"note"`
-}
-
-type CostResult struct {
-	Code  string         `json:"code"`
-	Name  string         `json:"name"`
-	Costs []ShippingCost `json:"costs"`
-}
-
 // Mock data for provinces
 var mockProvinces = []Province{
 	{ProvinceID: "1", Province: "Bali"},
@@ -630,103 +615,9 @@ func (h *ShippingHandler) GetCost(c *fiber.Ctx) error {
 	courier := c.Query("courier", "")
 	
 	weight, _ := strconv.Atoi(weightStr)
-	if weight <= 0 {
-		weight = 1000
-	}
 
-	// Try API first
-	if h.useAPI && h.rajaOngkir != nil && origin != "" && destination != "" {
-		var apiResults []rajaongkir.CostResult
-		var err error
-		
-		if courier != "" {
-			apiResults, err = h.rajaOngkir.GetCost(origin, destination, weight, courier)
-		} else {
-			apiResults, err = h.rajaOngkir.GetAllCouriers(origin, destination, weight)
-		}
-		
-		if err == nil && len(apiResults) > 0 {
-			// Convert to local type
-			results := make([]CostResult, len(apiResults))
-			for i, r := range apiResults {
-				costs := make([]ShippingCost, len(r.Costs))
-				for j, sc := range r.Costs {
-					costArr := make([]Cost, len(sc.Cost))
-					for k, c := range sc.Cost {
-						costArr[k] = Cost{Value: c.Value, Etd: c.Etd, Note: c.Note}
-					}
-					costs[j] = ShippingCost{
-						Service:     sc.Service,
-						Description: sc.Description,
-						Cost:        costArr,
-					}
-				}
-				results[i] = CostResult{
-					Code:  r.Code,
-					Name:  r.Name,
-					Costs: costs,
-				}
-			}
-			return c.JSON(presenter.SuccessResponse(results))
-		}
-	}
-
-	// Fallback to mock data
-	baseCost := weight * 10
-	if baseCost < 9000 {
-		baseCost = 9000
-	}
-
-	results := []CostResult{
-		{
-			Code: "jne",
-			Name: "Jalur Nugraha Ekakurir (JNE)",
-			Costs: []ShippingCost{
-				{
-					Service:     "REG",
-					Description: "Layanan Reguler",
-					Cost:        []Cost{{Value: baseCost, Etd: "2-3", Note: ""}},
-				},
-				{
-					Service:     "YES",
-					Description: "Yakin Esok Sampai",
-					Cost:        []Cost{{Value: baseCost + 10000, Etd: "1", Note: ""}},
-				},
-			},
-		},
-		{
-			Code: "tiki",
-			Name: "Citra Van Titipan Kilat (TIKI)",
-			Costs: []ShippingCost{
-				{
-					Service:     "REG",
-					Description: "Regular Service",
-					Cost:        []Cost{{Value: baseCost - 1000, Etd: "3-4", Note: ""}},
-				},
-				{
-					Service:     "ONS",
-					Description: "Over Night Service",
-					Cost:        []Cost{{Value: baseCost + 8000, Etd: "1", Note: ""}},
-				},
-			},
-		},
-		{
-			Code: "sicepat",
-			Name: "SiCepat Express",
-			Costs: []ShippingCost{
-				{
-					Service:     "REG",
-					Description: "Reguler",
-					Cost:        []Cost{{Value: baseCost - 2000, Etd: "2-3", Note: ""}},
-				},
-				{
-					Service:     "BEST",
-					Description: "Besok Sampai Tujuan",
-					Cost:        []Cost{{Value: baseCost + 5000, Etd: "1", Note: ""}},
-				},
-			},
-		},
-	}
+	// The same quote is checked when the order is placed
+	results := h.quoter.Quote(origin, destination, weight, courier)
 
 	return c.JSON(presenter.SuccessResponse(results))
 }
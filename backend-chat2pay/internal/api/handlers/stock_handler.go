@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/repositories"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type StockHandler struct {
+	stockService service.StockService
+}
+
+func NewStockHandler(stockService service.StockService) *StockHandler {
+	return &StockHandler{
+		stockService: stockService,
+	}
+}
+
+// Adjust godoc
+// @Summary Adjust Stock
+// @Description Menambah (restock) atau mengoreksi stok produk. Quantity adalah perubahan stok, negatif untuk mengurangi. Produk dengan varian diatur per varian, produk dengan stok outlet diatur per outlet.
+// @Tags Stock
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.StockAdjustmentRequest true "Adjustment"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.StockMovementResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/stock/adjustments [post]
+func (h *StockHandler) Adjust(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	var req dto.StockAdjustmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	response := h.stockService.Adjust(c.Context(), merchantIDVal.(string), userID, &req)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetMovements godoc
+// @Summary Get Stock Movements
+// @Description Mendapatkan riwayat perubahan stok (penjualan, pembatalan, restock, koreksi, import), terbaru lebih dulu
+// @Tags Stock
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id query string false "Product ID"
+// @Param variant_id query string false "Variant ID"
+// @Param outlet_id query string false "Outlet ID"
+// @Param type query string false "sale, cancellation_return, restock, adjustment or import"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.StockMovementListResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/stock/movements [get]
+func (h *StockHandler) GetMovements(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	filter := repositories.StockMovementFilter{
+		ProductID: c.Query("product_id"),
+		VariantID: c.Query("variant_id"),
+		OutletID:  c.Query("outlet_id"),
+		Type:      c.Query("type"),
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.stockService.GetMovements(c.Context(), merchantIDVal.(string), filter, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetAlerts godoc
+// @Summary Get Stock Alerts
+// @Description Mendapatkan peringatan stok menipis (low_stock) dan stok habis (out_of_stock), terbaru lebih dulu. Peringatan selesai saat stok kembali di atas batas.
+// @Tags Stock
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id query string false "Product ID"
+// @Param type query string false "low_stock or out_of_stock"
+// @Param open query bool false "Only alerts that are not resolved yet"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.StockAlertListResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/stock/alerts [get]
+func (h *StockHandler) GetAlerts(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	open, _ := strconv.ParseBool(c.Query("open", "false"))
+	filter := repositories.StockAlertFilter{
+		ProductID: c.Query("product_id"),
+		Type:      c.Query("type"),
+		Open:      open,
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.stockService.GetAlerts(c.Context(), merchantIDVal.(string), filter, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// GetChatMisses godoc
+// @Summary Get Sold Out in Chat Report
+// @Description Laporan produk yang cocok dengan pencarian di chat tetapi tidak bisa ditawarkan karena stok habis, paling sering lebih dulu
+// @Tags Stock
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param days query int false "Report period in days" default(30)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.ChatStockMissReportResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /merchant/stock/chat-misses [get]
+func (h *StockHandler) GetChatMisses(c *fiber.Ctx) error {
+	merchantIDVal := c.Locals("merchant_id")
+	if merchantIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: merchant_id not found")))
+	}
+
+	days, _ := strconv.Atoi(c.Query("days", "30"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.stockService.GetChatMisses(c.Context(), merchantIDVal.(string), days, page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type WishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandler(wishlistService service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get Wishlist
+// @Description Mendapatkan wishlist customer dengan harga dan stok produk saat ini, terbaru lebih dulu
+// @Tags Wishlist
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.WishlistResponse}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Router /customer/wishlist [get]
+func (h *WishlistHandler) GetAll(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	response := h.wishlistService.GetAll(c.Context(), customerIDVal.(string), page, limit)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Add godoc
+// @Summary Add to Wishlist
+// @Description Menyimpan produk ke wishlist. Customer mendapat notifikasi bila harga efektifnya turun atau stoknya tersedia lagi.
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body dto.WishlistRequest true "Product"
+// @Success 201 {object} presenter.SuccessResponseSwagger{data=dto.WishlistItemResponse}
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=dto.WishlistItemResponse}
+// @Failure 400 {object} presenter.ErrorResponseSwagger
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /customer/wishlist [post]
+func (h *WishlistHandler) Add(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	var req dto.WishlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(presenter.ErrorResponse(err))
+	}
+
+	response := h.wishlistService.Add(c.Context(), customerIDVal.(string), req.ProductID)
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
+
+// Remove godoc
+// @Summary Remove from Wishlist
+// @Description Menghapus produk dari wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} presenter.SuccessResponseSwagger{data=string}
+// @Failure 401 {object} presenter.ErrorResponseSwagger
+// @Failure 404 {object} presenter.ErrorResponseSwagger
+// @Router /customer/wishlist/{product_id} [delete]
+func (h *WishlistHandler) Remove(c *fiber.Ctx) error {
+	customerIDVal := c.Locals("customer_id")
+	if customerIDVal == nil {
+		return c.Status(401).JSON(presenter.ErrorResponse(fiber.NewError(401, "Unauthorized: customer_id not found")))
+	}
+
+	response := h.wishlistService.Remove(c.Context(), customerIDVal.(string), c.Params("product_id"))
+
+	if response.Errors != nil {
+		return c.Status(response.Code).JSON(presenter.ErrorResponse(response.Errors))
+	}
+
+	return c.Status(response.Code).JSON(presenter.SuccessResponse(response.Data))
+}
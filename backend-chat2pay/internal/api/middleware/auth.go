@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"chat2pay/internal/entities"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"strings"
@@ -63,6 +64,19 @@ func CustomerAuthMiddleware(jwtSecret string) fiber.Handler {
 	}
 }
 
+// OptionalCustomerAuthMiddleware lets requests without a token through as
+// anonymous. A token, when sent, must be a valid customer token.
+func OptionalCustomerAuthMiddleware(jwtSecret string) fiber.Handler {
+	customerAuth := CustomerAuthMiddleware(jwtSecret)
+
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return customerAuth(c)
+	}
+}
+
 func MerchantAuthMiddleware(jwtSecret string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
@@ -112,6 +126,9 @@ func MerchantAuthMiddleware(jwtSecret string) fiber.Handler {
 		if merchantID, ok := claims["merchant_id"].(string); ok {
 			c.Locals("merchant_id", merchantID)
 		}
+		if merchantRole, ok := claims["merchant_role"].(string); ok {
+			c.Locals("merchant_role", merchantRole)
+		}
 		if userID, ok := claims["user_id"].(string); ok {
 			c.Locals("user_id", userID)
 		}
@@ -122,3 +139,42 @@ func MerchantAuthMiddleware(jwtSecret string) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// RequirePlatformAdmin lets the request through when the merchant user is
+// one of the platform admins. It runs after MerchantAuthMiddleware.
+func RequirePlatformAdmin(adminUserIDs []string) fiber.Handler {
+	admins := map[string]bool{}
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if userID, _ := c.Locals("user_id").(string); userID != "" && admins[userID] {
+			return c.Next()
+		}
+
+		return c.Status(403).JSON(fiber.Map{
+			"status": false,
+			"error":  "Forbidden: only platform admins can do this",
+		})
+	}
+}
+
+// RequireMerchantRole lets the request through when the merchant user has
+// one of the roles. It runs after MerchantAuthMiddleware; tokens issued
+// before roles were added to them have to log in again.
+func RequireMerchantRole(roles ...entities.MerchantUserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("merchant_role").(string)
+		for _, allowed := range roles {
+			if role == string(allowed) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(403).JSON(fiber.Map{
+			"status": false,
+			"error":  "Forbidden: your role is not allowed to do this",
+		})
+	}
+}
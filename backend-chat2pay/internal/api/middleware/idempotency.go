@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+)
+
+// Idempotency makes a request sent with an Idempotency-Key header run once:
+// repeating it with the same key replays the first response instead of
+// running it again. Keys are kept per customer or merchant and per
+// endpoint, and reusing a key for a different body is rejected. Requests
+// without the header run as usual. It must come after the auth middleware.
+func Idempotency(repo repositories.IdempotencyRepository, cfg *yaml.Config) fiber.Handler {
+	ttl := defaultIdempotencyKeyTTL
+	if minutes := cfg.Order.IdempotencyKeyMinutes; minutes > 0 {
+		ttl = time.Duration(minutes) * time.Minute
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(400).JSON(presenter.ErrorResponse(fiber.NewError(400, fmt.Sprintf("%s is longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))))
+		}
+
+		log := logger.NewLog("idempotency_middleware", cfg.Logger.Enable)
+
+		hash := sha256.Sum256(c.Body())
+		reservation := &entities.IdempotencyKey{
+			Scope:       idempotencyScope(c),
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		existing, err := repo.Reserve(c.Context(), reservation)
+		if err != nil {
+			log.Error(fmt.Sprintf("error reserving idempotency key: %v", err))
+			return c.Status(500).JSON(presenter.ErrorResponse(fiber.NewError(500, "failed to check "+IdempotencyKeyHeader)))
+		}
+
+		if existing != nil {
+			switch {
+			case existing.RequestHash != reservation.RequestHash:
+				return c.Status(422).JSON(presenter.ErrorResponse(fiber.NewError(422, IdempotencyKeyHeader+" was already used for a different request")))
+			case existing.StatusCode == nil:
+				return c.Status(409).JSON(presenter.ErrorResponse(fiber.NewError(409, "a request with this "+IdempotencyKeyHeader+" is still running, retry later")))
+			}
+
+			c.Set(IdempotentReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(*existing.StatusCode).Send(existing.ResponseBody)
+		}
+
+		// The key is kept even if the client goes away, the request may have
+		// been carried out
+		ctx := context.Background()
+
+		// Failed requests free the key, so the same request can be retried
+		if err = c.Next(); err != nil {
+			if releaseErr := repo.Release(ctx, reservation.Scope, key); releaseErr != nil {
+				log.Error(fmt.Sprintf("error releasing idempotency key: %v", releaseErr))
+			}
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= 500 {
+			err = repo.Release(ctx, reservation.Scope, key)
+		} else {
+			body := append([]byte(nil), c.Response().Body()...)
+			err = repo.Complete(ctx, reservation.Scope, key, status, body)
+		}
+		if err != nil {
+			log.Error(fmt.Sprintf("error storing idempotency key: %v", err))
+		}
+
+		return nil
+	}
+}
+
+// idempotencyScope keeps the keys of each customer or merchant, and of each
+// endpoint, apart.
+func idempotencyScope(c *fiber.Ctx) string {
+	owner := "anonymous"
+	if customerID, ok := c.Locals("customer_id").(string); ok {
+		owner = "customer:" + customerID
+	} else if merchantID, ok := c.Locals("merchant_id").(string); ok {
+		owner = "merchant:" + merchantID
+	}
+
+	return owner + " " + c.Method() + " " + c.Route().Path
+}
@@ -5,21 +5,30 @@ import (
 	"chat2pay/config/yaml"
 	_ "chat2pay/docs"
 	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
 	"chat2pay/internal/api/routes"
+	"chat2pay/internal/pkg/storage"
+	"chat2pay/internal/repositories"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
 	"github.com/sarulabs/di/v2"
 )
 
+// uploadBodyLimit leaves room for several product images in one request,
+// the size of each image is checked by the service.
+const uploadBodyLimit = 64 * 1024 * 1024
+
 func NewRouter(ctn di.Container) *fiber.App {
-	router := fiber.New()
+	router := fiber.New(fiber.Config{
+		BodyLimit: uploadBodyLimit,
+	})
 
 	// CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Idempotency-Key",
 		AllowCredentials: false,
 	}))
 
@@ -40,15 +49,40 @@ func NewRouter(ctn di.Container) *fiber.App {
 	// Routes
 	config := ctn.Get(bootstrap.ConfigDefName).(*yaml.Config)
 
+	// Retried order placements replay their first response
+	idempotency := middleware.Idempotency(ctn.Get(bootstrap.IdempotencyRepositoryName).(repositories.IdempotencyRepository), config)
+
+	// Uploaded files when they are stored on the local filesystem
+	if local, ok := ctn.Get(bootstrap.StoragePackageName).(*storage.LocalStorage); ok {
+		router.Static(local.BaseURL, local.Dir)
+	}
+
 	routes.AuthRouter(
 		api,
 		ctn.Get(bootstrap.MerchantAuthHandlerName).(*handlers.MerchantAuthHandler),
 		ctn.Get(bootstrap.CustomerAuthHandlerName).(*handlers.CustomerAuthHandler),
 	)
-	routes.ProductRouter(api, ctn.Get(bootstrap.ProductHandlerName).(*handlers.ProductHandler))
+	// Registered ahead of the product routes, which would otherwise take
+	// "export" for a product id
+	routes.ProductExportRouter(api, ctn.Get(bootstrap.ProductExportHandlerName).(*handlers.ProductExportHandler), config.JWT.Key)
+	routes.ProductRouter(api, ctn.Get(bootstrap.ProductHandlerName).(*handlers.ProductHandler), config.JWT.Key)
 	routes.ShippingRouter(api, ctn.Get(bootstrap.ShippingHandlerName).(*handlers.ShippingHandler))
-	routes.OrderRouter(api, ctn.Get(bootstrap.OrderHandlerName).(*handlers.OrderHandler), config.JWT.Key)
+	routes.OrderRouter(api, ctn.Get(bootstrap.OrderHandlerName).(*handlers.OrderHandler), config.JWT.Key, idempotency)
 	routes.ChatRouter(api, ctn.Get(bootstrap.ChatHandlerName).(*handlers.ChatHandler), config.JWT.Key)
+	routes.ProductImportRouter(api, ctn.Get(bootstrap.ProductImportHandlerName).(*handlers.ProductImportHandler), config.JWT.Key)
+	routes.ProductVariantRouter(api, ctn.Get(bootstrap.ProductVariantHandlerName).(*handlers.ProductVariantHandler), config.JWT.Key)
+	routes.ProductImageRouter(api, ctn.Get(bootstrap.ProductImageHandlerName).(*handlers.ProductImageHandler), config.JWT.Key)
+	routes.OutletRouter(api, ctn.Get(bootstrap.OutletHandlerName).(*handlers.OutletHandler), config.JWT.Key)
+	routes.StockRouter(api, ctn.Get(bootstrap.StockHandlerName).(*handlers.StockHandler), config.JWT.Key)
+	routes.MerchantNotificationRouter(api, ctn.Get(bootstrap.MerchantNotificationHandlerName).(*handlers.MerchantNotificationHandler), config.JWT.Key)
+	routes.CategoryRouter(api, ctn.Get(bootstrap.CategoryHandlerName).(*handlers.CategoryHandler), config.JWT.Key, config.Platform.AdminUserIDs)
+	routes.DiscountRouter(api, ctn.Get(bootstrap.DiscountHandlerName).(*handlers.DiscountHandler), config.JWT.Key)
+	routes.ProductReviewRouter(api, ctn.Get(bootstrap.ProductReviewHandlerName).(*handlers.ProductReviewHandler), config.JWT.Key)
+	routes.ProductFAQRouter(api, ctn.Get(bootstrap.ProductFAQHandlerName).(*handlers.ProductFAQHandler), config.JWT.Key)
+	routes.WishlistRouter(api, ctn.Get(bootstrap.WishlistHandlerName).(*handlers.WishlistHandler), config.JWT.Key)
+	routes.CartRouter(api, ctn.Get(bootstrap.CartHandlerName).(*handlers.CartHandler), config.JWT.Key, idempotency)
+	routes.CustomerNotificationRouter(api, ctn.Get(bootstrap.CustomerNotificationHandlerName).(*handlers.CustomerNotificationHandler), config.JWT.Key)
+	routes.SearchRouter(api, ctn.Get(bootstrap.SearchHandlerName).(*handlers.SearchHandler), config.JWT.Key)
 
 	// Socket
 	handlers.NewSocketEvent(router, ctn)
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func CartRouter(router fiber.Router, handler *handlers.CartHandler, jwtSecret string, idempotency fiber.Handler) {
+	cart := router.Group("/customer/cart")
+
+	customerAuth := middleware.CustomerAuthMiddleware(jwtSecret)
+
+	// Customer routes
+	cart.Get("/", customerAuth, handler.GetCart)
+	cart.Post("/items", customerAuth, handler.AddItem)
+	cart.Patch("/items/:id", customerAuth, handler.UpdateItem)
+	cart.Delete("/items/:id", customerAuth, handler.RemoveItem)
+	cart.Post("/fulfillment", customerAuth, handler.GetFulfillment)
+	cart.Post("/checkout", customerAuth, idempotency, handler.Checkout)
+}
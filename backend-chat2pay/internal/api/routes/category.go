@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func CategoryRouter(router fiber.Router, handler *handlers.CategoryHandler, jwtSecret string, platformAdmins []string) {
+	categories := router.Group("/categories")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	// Every shop files its products under the same tree
+	platformAdmin := middleware.RequirePlatformAdmin(platformAdmins)
+
+	// Public routes
+	categories.Get("/", handler.GetAll)
+	categories.Get("/tree", handler.GetTree)
+	categories.Get("/:id", handler.GetById)
+	categories.Get("/:id/breadcrumb", handler.GetBreadcrumb)
+
+	// Platform admin routes
+	categories.Post("/", merchantAuth, platformAdmin, handler.Create)
+	categories.Put("/:id", merchantAuth, platformAdmin, handler.Update)
+	categories.Delete("/:id", merchantAuth, platformAdmin, handler.Delete)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func CustomerNotificationRouter(router fiber.Router, handler *handlers.CustomerNotificationHandler, jwtSecret string) {
+	notifications := router.Group("/customer/notifications")
+
+	customerAuth := middleware.CustomerAuthMiddleware(jwtSecret)
+
+	// Customer routes
+	notifications.Get("/", customerAuth, handler.GetAll)
+	notifications.Put("/read", customerAuth, handler.MarkAllRead)
+	notifications.Put("/:id/read", customerAuth, handler.MarkRead)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func DiscountRouter(router fiber.Router, handler *handlers.DiscountHandler, jwtSecret string) {
+	discounts := router.Group("/merchant/discounts")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Merchant routes
+	discounts.Get("/", merchantAuth, handler.GetAll)
+	discounts.Get("/:id", merchantAuth, handler.GetById)
+	discounts.Post("/", merchantAuth, catalogManager, handler.Create)
+	discounts.Put("/:id", merchantAuth, catalogManager, handler.Update)
+	discounts.Delete("/:id", merchantAuth, catalogManager, handler.Delete)
+
+	router.Get("/merchant/products/:id/price-history", merchantAuth, handler.GetPriceHistory)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func MerchantNotificationRouter(router fiber.Router, handler *handlers.MerchantNotificationHandler, jwtSecret string) {
+	notifications := router.Group("/merchant/notifications")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+
+	// Merchant routes
+	notifications.Get("/", merchantAuth, handler.GetAll)
+	notifications.Put("/read", merchantAuth, handler.MarkAllRead)
+	notifications.Put("/:id/read", merchantAuth, handler.MarkRead)
+}
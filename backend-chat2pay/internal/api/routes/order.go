@@ -6,7 +6,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func OrderRouter(router fiber.Router, handler *handlers.OrderHandler, jwtSecret string) {
+func OrderRouter(router fiber.Router, handler *handlers.OrderHandler, jwtSecret string, idempotency fiber.Handler) {
 	orders := router.Group("/orders")
 
 	customerAuth := middleware.CustomerAuthMiddleware(jwtSecret)
@@ -14,14 +14,18 @@ func OrderRouter(router fiber.Router, handler *handlers.OrderHandler, jwtSecret
 
 	// Static routes MUST come before parameterized routes
 	// Customer routes
-	orders.Post("/", customerAuth, handler.CreateOrder)
+	orders.Post("/", customerAuth, idempotency, handler.CreateOrder)
+	orders.Post("/fulfillment", customerAuth, handler.GetFulfillment)
 	orders.Get("/customer", customerAuth, handler.GetCustomerOrders)
 
 	// Merchant routes
 	orders.Get("/merchant", merchantAuth, handler.GetMerchantOrders)
-	orders.Get("/merchant/:id", merchantAuth, handler.GetOrder)
+	orders.Get("/merchant/:id", merchantAuth, handler.GetMerchantOrder)
+	orders.Post("/merchant/:id/cancel", merchantAuth, handler.CancelMerchantOrder)
 	orders.Patch("/:id/status", merchantAuth, handler.UpdateOrderStatus)
 
 	// Parameterized routes LAST
+	orders.Get("/:id/timeline", customerAuth, handler.GetOrderTimeline)
+	orders.Post("/:id/cancel", customerAuth, handler.CancelOrder)
 	orders.Get("/:id", customerAuth, handler.GetOrder)
 }
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func OutletRouter(router fiber.Router, handler *handlers.OutletHandler, jwtSecret string) {
+	outlets := router.Group("/merchant/outlets")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Merchant routes
+	outlets.Get("/", merchantAuth, handler.GetAll)
+	outlets.Post("/", merchantAuth, catalogManager, handler.Create)
+	outlets.Get("/:id", merchantAuth, handler.GetById)
+	outlets.Put("/:id", merchantAuth, catalogManager, handler.Update)
+	outlets.Delete("/:id", merchantAuth, catalogManager, handler.Delete)
+	outlets.Get("/:id/stocks", merchantAuth, handler.GetStocks)
+	outlets.Put("/:id/stocks", merchantAuth, catalogManager, handler.SetStocks)
+}
@@ -2,21 +2,33 @@ package routes
 
 import (
 	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
 	"github.com/gofiber/fiber/v2"
 )
 
-func ProductRouter(router fiber.Router, handler *handlers.ProductHandler) {
+func ProductRouter(router fiber.Router, handler *handlers.ProductHandler, jwtSecret string) {
 	products := router.Group("/products")
 
-	// Public routes
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	optionalCustomerAuth := middleware.OptionalCustomerAuthMiddleware(jwtSecret)
+	// Staff keep stock up to date through the stock routes, the catalog
+	// itself is managed by owners and admins
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Public routes, they only show active products
 	products.Get("/", handler.GetAll)
 	products.Get("/:id", handler.GetById)
-	products.Post("/", handler.Create)
-	products.Post("/multiple", handler.CreateMultiple)
-	products.Post("/ask", handler.AskProduct)
+	products.Get("/:id/similar", handler.GetSimilar)
+	products.Get("/:id/bought-together", handler.GetBoughtTogether)
+	products.Post("/ask", optionalCustomerAuth, handler.AskProduct)
 
-	//// Protected routes - merchant only
-	//products.Post("/", jwt.JWTProtected(authMdwr), jwt.RequireRole("merchant"), handler.Create)
-	//products.Put("/:id", jwt.JWTProtected(authMdwr), jwt.RequireRole("merchant"), handler.Update)
-	//products.Delete("/:id", jwt.JWTProtected(authMdwr), jwt.RequireRole("merchant"), handler.Delete)
+	// Merchant routes
+	router.Get("/merchant/products", merchantAuth, handler.GetMerchantProducts)
+	router.Get("/merchant/products/:id", merchantAuth, handler.GetMerchantProduct)
+	products.Post("/", merchantAuth, catalogManager, handler.Create)
+	products.Post("/multiple", merchantAuth, catalogManager, handler.CreateMultiple)
+	products.Put("/:id", merchantAuth, catalogManager, handler.Update)
+	products.Delete("/:id", merchantAuth, catalogManager, handler.Delete)
+	products.Post("/:id/restore", merchantAuth, catalogManager, handler.Restore)
 }
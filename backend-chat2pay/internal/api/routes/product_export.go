@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductExportRouter(router fiber.Router, handler *handlers.ProductExportHandler, jwtSecret string) {
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+
+	// Merchant routes
+	router.Get("/merchant/products/export", merchantAuth, handler.Export)
+}
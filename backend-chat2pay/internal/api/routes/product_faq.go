@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductFAQRouter(router fiber.Router, handler *handlers.ProductFAQHandler, jwtSecret string) {
+	faqs := router.Group("/merchant/faqs")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Merchant routes
+	faqs.Get("/", merchantAuth, handler.GetAll)
+	faqs.Get("/:id", merchantAuth, handler.GetById)
+	faqs.Post("/", merchantAuth, catalogManager, handler.Create)
+	faqs.Put("/:id", merchantAuth, catalogManager, handler.Update)
+	faqs.Delete("/:id", merchantAuth, catalogManager, handler.Delete)
+}
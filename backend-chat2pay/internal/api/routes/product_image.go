@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductImageRouter(router fiber.Router, handler *handlers.ProductImageHandler, jwtSecret string) {
+	images := router.Group("/products/:id/images")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Public routes
+	images.Get("/", handler.GetAll)
+
+	// Merchant routes
+	images.Post("/", merchantAuth, catalogManager, handler.Upload)
+	images.Put("/order", merchantAuth, catalogManager, handler.Reorder)
+	images.Patch("/:imageId/primary", merchantAuth, catalogManager, handler.SetPrimary)
+	images.Delete("/:imageId", merchantAuth, catalogManager, handler.Delete)
+}
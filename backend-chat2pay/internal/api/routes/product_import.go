@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductImportRouter(router fiber.Router, handler *handlers.ProductImportHandler, jwtSecret string) {
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Merchant routes
+	router.Post("/products/import", merchantAuth, catalogManager, handler.Import)
+}
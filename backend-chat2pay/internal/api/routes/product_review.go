@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductReviewRouter(router fiber.Router, handler *handlers.ProductReviewHandler, jwtSecret string) {
+	customerAuth := middleware.CustomerAuthMiddleware(jwtSecret)
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+
+	// Public routes
+	router.Get("/products/:id/reviews", handler.GetByProduct)
+
+	// Customer routes
+	router.Post("/reviews", customerAuth, handler.Create)
+	router.Get("/reviews/customer", customerAuth, handler.GetByCustomer)
+
+	// Merchant routes
+	router.Get("/merchant/reviews", merchantAuth, handler.GetByMerchant)
+	router.Put("/merchant/reviews/:id/reply", merchantAuth, handler.Reply)
+}
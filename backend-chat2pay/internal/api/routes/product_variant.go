@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProductVariantRouter(router fiber.Router, handler *handlers.ProductVariantHandler, jwtSecret string) {
+	variants := router.Group("/products/:id/variants")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Public routes
+	variants.Get("/", handler.GetAll)
+
+	// Merchant routes
+	variants.Put("/", merchantAuth, catalogManager, handler.Save)
+}
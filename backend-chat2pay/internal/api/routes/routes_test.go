@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/middlewares/jwt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret"
+
+// merchantToken signs a token the way merchant login does.
+func merchantToken(t *testing.T, userID string, role entities.MerchantUserRole) string {
+	auth := jwt.NewAuthMiddleware(&yaml.Config{JWT: yaml.JWT{Key: testJWTSecret}})
+	token, err := auth.GenerateTokenWithMerchant(userID, "merchant-1", userID+"@example.com", "merchant", string(role))
+	require.NoError(t, err)
+	return *token
+}
+
+// send makes a request with a malformed JSON body. Requests let through the
+// route guards reach the handler and are turned away by its body parsing
+// with 400, before any service is called.
+func send(t *testing.T, app *fiber.App, method, path, token string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader("{"))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestCategoryRouter(t *testing.T) {
+	app := fiber.New()
+	CategoryRouter(app, handlers.NewCategoryHandler(nil), testJWTSecret, []string{"platform-admin"})
+
+	writes := []struct{ method, path string }{
+		{"POST", "/categories"},
+		{"PUT", "/categories/c1"},
+		{"DELETE", "/categories/c1"},
+	}
+
+	t.Run("Merchants cannot change the shared tree", func(t *testing.T) {
+		owner := merchantToken(t, "owner-1", entities.RoleOwner)
+		for _, w := range writes {
+			assert.Equal(t, 403, send(t, app, w.method, w.path, owner), w.method+" "+w.path)
+		}
+		assert.Equal(t, 401, send(t, app, "POST", "/categories", ""))
+	})
+
+	t.Run("Platform admins can", func(t *testing.T) {
+		admin := merchantToken(t, "platform-admin", entities.RoleStaff)
+		assert.Equal(t, 400, send(t, app, "POST", "/categories", admin))
+		assert.Equal(t, 400, send(t, app, "PUT", "/categories/c1", admin))
+	})
+}
+
+func TestMerchantWriteRoutes(t *testing.T) {
+	app := fiber.New()
+	ProductRouter(app, handlers.NewProductHandler(nil), testJWTSecret)
+	ProductImportRouter(app, handlers.NewProductImportHandler(nil), testJWTSecret)
+	ProductVariantRouter(app, handlers.NewProductVariantHandler(nil), testJWTSecret)
+	ProductImageRouter(app, handlers.NewProductImageHandler(nil), testJWTSecret)
+	OutletRouter(app, handlers.NewOutletHandler(nil), testJWTSecret)
+	DiscountRouter(app, handlers.NewDiscountHandler(nil), testJWTSecret)
+	ProductFAQRouter(app, handlers.NewProductFAQHandler(nil), testJWTSecret)
+	SearchRouter(app, handlers.NewSearchHandler(nil), testJWTSecret)
+
+	writes := []struct{ method, path string }{
+		{"POST", "/products"},
+		{"POST", "/products/multiple"},
+		{"PUT", "/products/p1"},
+		{"DELETE", "/products/p1"},
+		{"POST", "/products/p1/restore"},
+		{"POST", "/products/import"},
+		{"PUT", "/products/p1/variants"},
+		{"POST", "/products/p1/images"},
+		{"PUT", "/products/p1/images/order"},
+		{"PATCH", "/products/p1/images/i1/primary"},
+		{"DELETE", "/products/p1/images/i1"},
+		{"POST", "/merchant/outlets"},
+		{"PUT", "/merchant/outlets/o1"},
+		{"DELETE", "/merchant/outlets/o1"},
+		{"PUT", "/merchant/outlets/o1/stocks"},
+		{"POST", "/merchant/discounts"},
+		{"PUT", "/merchant/discounts/d1"},
+		{"DELETE", "/merchant/discounts/d1"},
+		{"POST", "/merchant/faqs"},
+		{"PUT", "/merchant/faqs/f1"},
+		{"DELETE", "/merchant/faqs/f1"},
+		{"POST", "/search/synonyms"},
+		{"DELETE", "/search/synonyms/s1"},
+	}
+
+	t.Run("Staff cannot change the catalog", func(t *testing.T) {
+		staff := merchantToken(t, "staff-1", entities.RoleStaff)
+		for _, w := range writes {
+			assert.Equal(t, 403, send(t, app, w.method, w.path, staff), w.method+" "+w.path)
+		}
+	})
+
+	t.Run("Requires a merchant token", func(t *testing.T) {
+		for _, w := range writes {
+			assert.Equal(t, 401, send(t, app, w.method, w.path, ""), w.method+" "+w.path)
+		}
+		assert.Equal(t, 401, send(t, app, "GET", "/merchant/products", ""))
+		assert.Equal(t, 401, send(t, app, "GET", "/merchant/products/p1", ""))
+	})
+
+	t.Run("Owners and admins can", func(t *testing.T) {
+		owner := merchantToken(t, "owner-1", entities.RoleOwner)
+		admin := merchantToken(t, "admin-1", entities.RoleAdmin)
+		assert.Equal(t, 400, send(t, app, "PUT", "/products/p1/variants", owner))
+		assert.Equal(t, 400, send(t, app, "PUT", "/merchant/outlets/o1/stocks", admin))
+		assert.Equal(t, 400, send(t, app, "POST", "/search/synonyms", admin))
+	})
+}
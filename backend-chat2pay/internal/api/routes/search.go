@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"chat2pay/internal/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SearchRouter(router fiber.Router, handler *handlers.SearchHandler, jwtSecret string) {
+	search := router.Group("/search")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+	catalogManager := middleware.RequireMerchantRole(entities.RoleOwner, entities.RoleAdmin)
+
+	// Merchant routes
+	search.Get("/synonyms", merchantAuth, handler.GetSynonyms)
+	search.Post("/synonyms", merchantAuth, catalogManager, handler.CreateSynonym)
+	search.Delete("/synonyms/:id", merchantAuth, catalogManager, handler.DeleteSynonym)
+	search.Get("/normalize", merchantAuth, handler.NormalizeQuery)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func StockRouter(router fiber.Router, handler *handlers.StockHandler, jwtSecret string) {
+	stock := router.Group("/merchant/stock")
+
+	merchantAuth := middleware.MerchantAuthMiddleware(jwtSecret)
+
+	// Merchant routes
+	stock.Post("/adjustments", merchantAuth, handler.Adjust)
+	stock.Get("/movements", merchantAuth, handler.GetMovements)
+	stock.Get("/alerts", merchantAuth, handler.GetAlerts)
+	stock.Get("/chat-misses", merchantAuth, handler.GetChatMisses)
+}
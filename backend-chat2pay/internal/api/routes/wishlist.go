@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"chat2pay/internal/api/handlers"
+	"chat2pay/internal/api/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func WishlistRouter(router fiber.Router, handler *handlers.WishlistHandler, jwtSecret string) {
+	wishlist := router.Group("/customer/wishlist")
+
+	customerAuth := middleware.CustomerAuthMiddleware(jwtSecret)
+
+	// Customer routes
+	wishlist.Get("/", customerAuth, handler.GetAll)
+	wishlist.Post("/", customerAuth, handler.Add)
+	wishlist.Delete("/:product_id", customerAuth, handler.Remove)
+}
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// CartItem is a product, or a variant of it, a customer is about to order.
+// Product and Variant are loaded as they are now for the cart view.
+type CartItem struct {
+	ID         string          `json:"id" db:"id"`
+	CustomerID string          `json:"customer_id" db:"customer_id"`
+	ProductID  string          `json:"product_id" db:"product_id"`
+	VariantID  *string         `json:"variant_id,omitempty" db:"variant_id"`
+	Quantity   int             `json:"quantity" db:"quantity"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+	Product    *Product        `json:"product,omitempty" db:"-"`
+	Variant    *ProductVariant `json:"variant,omitempty" db:"-"`
+}
+
+// Checkout groups the orders placed together from a cart, one per merchant.
+type Checkout struct {
+	ID           string    `json:"id" db:"id"`
+	CustomerID   string    `json:"customer_id" db:"customer_id"`
+	Subtotal     float64   `json:"subtotal" db:"subtotal"`
+	ShippingCost float64   `json:"shipping_cost" db:"shipping_cost"`
+	Total        float64   `json:"total" db:"total"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	Orders       []Order   `json:"orders,omitempty" db:"-"`
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+
+	DiscountStateRunning   = "running"
+	DiscountStateScheduled = "scheduled"
+	DiscountStateEnded     = "ended"
+
+	PriceSourceManual  = "manual"
+	PriceSourceImport  = "import"
+	PriceSourceOpening = "opening"
+)
+
+// Discount lowers the price of a product, or of the products of its merchant
+// in a category and its subcategories, from StartsAt until EndsAt. Value is
+// a percentage or an amount in Rupiah, depending on Type.
+type Discount struct {
+	ID         string    `json:"id" db:"id"`
+	MerchantID string    `json:"merchant_id" db:"merchant_id"`
+	ProductID  *string   `json:"product_id,omitempty" db:"product_id"`
+	CategoryID *string   `json:"category_id,omitempty" db:"category_id"`
+	Name       string    `json:"name" db:"name"`
+	Type       string    `json:"type" db:"type"`
+	Value      float64   `json:"value" db:"value"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Apply returns the price after the discount, rounded to whole Rupiah and
+// never below zero.
+func (d Discount) Apply(price float64) float64 {
+	discounted := price - d.Value
+	if d.Type == DiscountTypePercentage {
+		discounted = price * (100 - d.Value) / 100
+	}
+
+	return math.Max(0, math.Round(discounted))
+}
+
+// ProductDiscount is a discount running for a product, set on the product
+// itself or on one of its categories.
+type ProductDiscount struct {
+	AppliesTo string `db:"applies_to"`
+	Discount
+}
+
+// PriceChange is one change of the base price of a product. PreviousPrice
+// is nil for its first price.
+type PriceChange struct {
+	ID            string    `json:"id" db:"id"`
+	ProductID     string    `json:"product_id" db:"product_id"`
+	Price         float64   `json:"price" db:"price"`
+	PreviousPrice *float64  `json:"previous_price,omitempty" db:"previous_price"`
+	Source        string    `json:"source" db:"source"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
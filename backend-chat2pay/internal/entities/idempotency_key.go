@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// IdempotencyKey is a request made under an Idempotency-Key header, and the
+// response it got. Scope keeps the keys of different clients and endpoints
+// apart; StatusCode is nil while the request is still running.
+type IdempotencyKey struct {
+	Scope        string    `json:"scope" db:"scope"`
+	Key          string    `json:"key" db:"key"`
+	RequestHash  string    `json:"request_hash" db:"request_hash"`
+	StatusCode   *int      `json:"status_code,omitempty" db:"status_code"`
+	ResponseBody []byte    `json:"-" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
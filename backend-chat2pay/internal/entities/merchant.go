@@ -16,6 +16,8 @@ type Merchant struct {
 	LegalName *string   `db:"legal_name" json:"legal_name,omitempty"`
 	Email     string    `db:"email" json:"email"`
 	Phone     *string   `db:"phone" json:"phone,omitempty"`
+	CityID    *string   `db:"city_id" json:"city_id,omitempty"` // shipping provider city id, the shipping origin without outlets
+	CityName  *string   `db:"city_name" json:"city_name,omitempty"`
 	Status    string    `db:"status" json:"status"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
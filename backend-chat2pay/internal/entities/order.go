@@ -6,6 +6,8 @@ type Order struct {
 	ID                  string       `json:"id" db:"id"`
 	CustomerID          string       `json:"customer_id" db:"customer_id"`
 	MerchantID          string       `json:"merchant_id" db:"merchant_id"`
+	OutletID            *string      `json:"outlet_id,omitempty" db:"outlet_id"`
+	CheckoutID          *string      `json:"checkout_id,omitempty" db:"checkout_id"`
 	Status              string       `json:"status" db:"status"`
 	Subtotal            float64      `json:"subtotal" db:"subtotal"`
 	ShippingCost        float64      `json:"shipping_cost" db:"shipping_cost"`
@@ -24,6 +26,8 @@ type Order struct {
 	PaymentURL          *string      `json:"payment_url,omitempty" db:"payment_url"`
 	PaidAt              *time.Time   `json:"paid_at,omitempty" db:"paid_at"`
 	Notes               *string      `json:"notes,omitempty" db:"notes"`
+	CancelReason        *string      `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	CancelledAt         *time.Time   `json:"cancelled_at,omitempty" db:"cancelled_at"`
 	CreatedAt           time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time    `json:"updated_at" db:"updated_at"`
 	Items               []OrderItem  `json:"items,omitempty" db:"-"`
@@ -32,15 +36,19 @@ type Order struct {
 }
 
 type OrderItem struct {
-	ID           string    `json:"id" db:"id"`
-	OrderID      string    `json:"order_id" db:"order_id"`
-	ProductID    string    `json:"product_id" db:"product_id"`
-	ProductName  string    `json:"product_name" db:"product_name"`
-	ProductPrice float64   `json:"product_price" db:"product_price"`
-	Quantity     int       `json:"quantity" db:"quantity"`
-	Subtotal     float64   `json:"subtotal" db:"subtotal"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	Product      *Product  `json:"product,omitempty" db:"-"`
+	ID            string    `json:"id" db:"id"`
+	OrderID       string    `json:"order_id" db:"order_id"`
+	ProductID     string    `json:"product_id" db:"product_id"`
+	ProductName   string    `json:"product_name" db:"product_name"`
+	VariantID     *string   `json:"variant_id,omitempty" db:"variant_id"`
+	VariantName   *string   `json:"variant_name,omitempty" db:"variant_name"`
+	ProductPrice  float64   `json:"product_price" db:"product_price"`             // the price charged
+	OriginalPrice *float64  `json:"original_price,omitempty" db:"original_price"` // the price before the discount
+	DiscountID    *string   `json:"discount_id,omitempty" db:"discount_id"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	Subtotal      float64   `json:"subtotal" db:"subtotal"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	Product       *Product  `json:"product,omitempty" db:"-"`
 }
 
 const (
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+const (
+	OrderActorCustomer = "customer"
+	OrderActorMerchant = "merchant"
+	OrderActorSystem   = "system"
+
+	NotificationOrderCancelled = "order_cancelled"
+)
+
+// OrderStatusHistory is one change of the status of an order, and who made
+// it. FromStatus is nil for the entry of a new order.
+type OrderStatusHistory struct {
+	ID         string    `json:"id" db:"id"`
+	OrderID    string    `json:"order_id" db:"order_id"`
+	FromStatus *string   `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	ActorType  string    `json:"actor_type" db:"actor_type"`
+	ActorID    *string   `json:"actor_id,omitempty" db:"actor_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
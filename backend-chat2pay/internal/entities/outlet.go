@@ -2,17 +2,37 @@ package entities
 
 import "time"
 
+const (
+	OutletStatusActive   = "active"
+	OutletStatusInactive = "inactive"
+)
+
 type Outlet struct {
-	ID         string    `gorm:"primaryKey;autoIncrement" json:"id"`
-	MerchantID string    `gorm:"not null;index:idx_outlets_merchant_id" json:"merchant_id"`
-	Name       string    `gorm:"type:varchar(150);not null" json:"name"`
-	Address    *string   `gorm:"type:text" json:"address,omitempty"`
-	City       *string   `gorm:"type:varchar(100)" json:"city,omitempty"`
-	Latitude   *float64  `gorm:"type:decimal(10,7)" json:"latitude,omitempty"`
-	Longitude  *float64  `gorm:"type:decimal(10,7)" json:"longitude,omitempty"`
-	Phone      *string   `gorm:"type:varchar(50)" json:"phone,omitempty"`
-	Status     string    `gorm:"type:outlet_status;not null;default:'active'" json:"status"`
-	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	Merchant   Merchant  `gorm:"foreignKey:MerchantID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID         string    `gorm:"primaryKey;autoIncrement" json:"id" db:"id"`
+	MerchantID string    `gorm:"not null;index:idx_outlets_merchant_id" json:"merchant_id" db:"merchant_id"`
+	Name       string    `gorm:"type:varchar(150);not null" json:"name" db:"name"`
+	Address    *string   `gorm:"type:text" json:"address,omitempty" db:"address"`
+	City       *string   `gorm:"type:varchar(100)" json:"city,omitempty" db:"city"`
+	CityID     *string   `gorm:"type:varchar(20)" json:"city_id,omitempty" db:"city_id"`
+	Province   *string   `gorm:"type:varchar(100)" json:"province,omitempty" db:"province"`
+	Latitude   *float64  `gorm:"type:decimal(10,7)" json:"latitude,omitempty" db:"latitude"`
+	Longitude  *float64  `gorm:"type:decimal(10,7)" json:"longitude,omitempty" db:"longitude"`
+	Phone      *string   `gorm:"type:varchar(50)" json:"phone,omitempty" db:"phone"`
+	Status     string    `gorm:"type:outlet_status;not null;default:'active'" json:"status" db:"status"`
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at" db:"updated_at"`
+	Merchant   Merchant  `gorm:"foreignKey:MerchantID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" db:"-"`
+}
+
+// OutletStock is the stock an outlet holds of a product, or of one variant
+// when VariantID is set. ProductName and VariantName are read for listings.
+type OutletStock struct {
+	ID          string    `json:"id" db:"id"`
+	OutletID    string    `json:"outlet_id" db:"outlet_id"`
+	ProductID   string    `json:"product_id" db:"product_id"`
+	VariantID   *string   `json:"variant_id,omitempty" db:"variant_id"`
+	Stock       int       `json:"stock" db:"stock"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ProductName string    `json:"product_name" db:"product_name"`
+	VariantName *string   `json:"variant_name,omitempty" db:"variant_name"`
 }
@@ -4,36 +4,61 @@ import (
 	"time"
 )
 
+const (
+	ProductStatusDraft    = "draft"
+	ProductStatusActive   = "active"
+	ProductStatusInactive = "inactive"
+	ProductStatusArchived = "archived"
+)
+
 type (
 	Product struct {
-		ID          string           `json:"id" db:"id"`
-		MerchantID  string           `json:"merchant_id" db:"merchant_id"`
-		OutletID    *string          `json:"outlet_id,omitempty" db:"outlet_id"`
-		CategoryID  *string          `json:"category_id,omitempty" db:"category_id"`
-		Name        string           `json:"name" db:"name"`
-		Description *string          `json:"description,omitempty" db:"description"`
-		SKU         *string          `json:"sku,omitempty" db:"sku"`
-		Price       float64          `json:"price" db:"price"`
-		Stock       int              `json:"stock" db:"stock"`
-		Status      string           `json:"status" db:"status"`
-		Image       *string          `json:"image,omitempty" db:"image"`
-		Weight      int              `json:"weight" db:"weight"`
-		Length      int              `json:"length" db:"length"`
-		Width       int              `json:"width" db:"width"`
-		Height      int              `json:"height" db:"height"`
-		CreatedAt   time.Time        `json:"created_at" db:"created_at"`
-		UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
-		Merchant    *Merchant        `json:"merchant" db:"-"`
-		Outlet      *Outlet          `json:"outlet" db:"-"`
-		Category    *ProductCategory `json:"category" db:"-"`
-		Images      []ProductImage   `json:"images" db:"-"`
+		ID                string            `json:"id" db:"id"`
+		MerchantID        string            `json:"merchant_id" db:"merchant_id"`
+		OutletID          *string           `json:"outlet_id,omitempty" db:"outlet_id"`
+		CategoryID        *string           `json:"category_id,omitempty" db:"category_id"`
+		Name              string            `json:"name" db:"name"`
+		Description       *string           `json:"description,omitempty" db:"description"`
+		SKU               *string           `json:"sku,omitempty" db:"sku"`
+		Price             float64           `json:"price" db:"price"`
+		Stock             int               `json:"stock" db:"stock"`
+		LowStockThreshold int               `json:"low_stock_threshold" db:"low_stock_threshold"`
+		Attributes        ProductAttributes `json:"attributes" db:"attributes"`
+		Status            string            `json:"status" db:"status"`
+		Image             *string           `json:"image,omitempty" db:"image"`
+		Weight            int               `json:"weight" db:"weight"`
+		Length            int               `json:"length" db:"length"`
+		Width             int               `json:"width" db:"width"`
+		Height            int               `json:"height" db:"height"`
+		PublishAt         *time.Time        `json:"publish_at,omitempty" db:"publish_at"`
+		UnpublishAt       *time.Time        `json:"unpublish_at,omitempty" db:"unpublish_at"`
+		ArchivedAt        *time.Time        `json:"archived_at,omitempty" db:"archived_at"`
+		CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+		UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+		Merchant          *Merchant         `json:"merchant" db:"-"`
+		Outlet            *Outlet           `json:"outlet" db:"-"`
+		Category          *ProductCategory  `json:"category" db:"-"`
+		Images            []ProductImage    `json:"images" db:"-"`
+		Options           []ProductOption   `json:"options" db:"-"`
+		Variants          []ProductVariant  `json:"variants" db:"-"`
+		Discount          *Discount         `json:"discount,omitempty" db:"-"` // the discount running now
+		Rating            ProductRating     `json:"rating" db:"-"`
 	}
 
 	ProductEmbedding struct {
 		ID         string    `json:"id"`
 		ProductId  string    `json:"product_id"`
+		ChunkIndex int       `json:"chunk_index"`
 		Content    string    `json:"content"`
 		Embedding  []float32 `json:"embedding" pg:"type:vector(3)"`
 		Similarity float64   `json:"distance"`
 	}
 )
+
+// Discounted returns the price after the discount running for the product.
+func (p *Product) Discounted(price float64) float64 {
+	if p.Discount == nil {
+		return price
+	}
+	return p.Discount.Apply(price)
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	AttributeTypeText    = "text"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+type (
+	// ProductAttributes holds the specifications of a product keyed by
+	// attribute key, e.g. {"ram": 16, "bahan": "Katun"}. Values are strings,
+	// numbers or booleans. It is stored as JSONB.
+	ProductAttributes map[string]interface{}
+
+	// AttributeDefinition describes one attribute of a category schema.
+	// Values lists the allowed values of an enum.
+	AttributeDefinition struct {
+		Key      string   `json:"key"`
+		Label    string   `json:"label"`
+		Type     string   `json:"type"`
+		Unit     string   `json:"unit,omitempty"`
+		Values   []string `json:"values,omitempty"`
+		Required bool     `json:"required,omitempty"`
+	}
+
+	// AttributeSchema lists the attributes expected of the products in a
+	// category. It is stored as JSONB.
+	AttributeSchema []AttributeDefinition
+)
+
+func (a ProductAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *ProductAttributes) Scan(src interface{}) error {
+	data, err := jsonbBytes(src)
+	if err != nil || data == nil {
+		*a = ProductAttributes{}
+		return err
+	}
+
+	return json.Unmarshal(data, a)
+}
+
+func (s AttributeSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *AttributeSchema) Scan(src interface{}) error {
+	data, err := jsonbBytes(src)
+	if err != nil || data == nil {
+		*s = AttributeSchema{}
+		return err
+	}
+
+	return json.Unmarshal(data, s)
+}
+
+func jsonbBytes(src interface{}) ([]byte, error) {
+	switch v := src.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case nil:
+		return nil, nil
+	}
+
+	return nil, errors.New("unsupported type for jsonb column")
+}
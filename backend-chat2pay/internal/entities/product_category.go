@@ -3,10 +3,11 @@ package entities
 import "time"
 
 type ProductCategory struct {
-	ID        string           `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string           `gorm:"type:varchar(150);not null" json:"name"`
-	ParentID  *string          `gorm:"index:idx_product_categories_parent_id" json:"parent_id,omitempty"`
-	CreatedAt time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	Parent    *ProductCategory `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	ID              string           `gorm:"primaryKey;autoIncrement" json:"id" db:"id"`
+	Name            string           `gorm:"type:varchar(150);not null" json:"name" db:"name"`
+	ParentID        *string          `gorm:"index:idx_product_categories_parent_id" json:"parent_id,omitempty" db:"parent_id"`
+	AttributeSchema AttributeSchema  `gorm:"type:jsonb" json:"attribute_schema" db:"attribute_schema"`
+	CreatedAt       time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at" db:"updated_at"`
+	Parent          *ProductCategory `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" db:"-"`
 }
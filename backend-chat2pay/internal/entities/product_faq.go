@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// ProductFAQ is a question customers often ask with the answer of the
+// merchant, for one product or, without ProductID, for the whole store.
+// Similarity is read when matching a customer question.
+type ProductFAQ struct {
+	ID         string    `json:"id" db:"id"`
+	MerchantID string    `json:"merchant_id" db:"merchant_id"`
+	ProductID  *string   `json:"product_id,omitempty" db:"product_id"`
+	Question   string    `json:"question" db:"question"`
+	Answer     string    `json:"answer" db:"answer"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	Similarity float64   `json:"-" db:"similarity"`
+}
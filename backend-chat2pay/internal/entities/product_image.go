@@ -3,10 +3,14 @@ package entities
 import "time"
 
 type ProductImage struct {
-	ID        string    `gorm:"primaryKey;autoIncrement" json:"id"`
-	ProductID string    `gorm:"not null;index:idx_product_images_product_id" json:"product_id"`
-	ImageURL  string    `gorm:"type:text;not null" json:"image_url"`
-	IsPrimary bool      `gorm:"type:boolean;not null;default:false" json:"is_primary"`
-	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	Product   Product   `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID           string    `gorm:"primaryKey;autoIncrement" json:"id" db:"id"`
+	ProductID    string    `gorm:"not null;index:idx_product_images_product_id" json:"product_id" db:"product_id"`
+	ImageURL     string    `gorm:"type:text;not null" json:"image_url" db:"image_url"`
+	ThumbnailURL string    `gorm:"type:text;not null" json:"thumbnail_url" db:"thumbnail_url"`
+	StorageKey   string    `gorm:"type:text;not null" json:"-" db:"storage_key"`
+	ThumbnailKey string    `gorm:"type:text;not null" json:"-" db:"thumbnail_key"`
+	Position     int       `gorm:"not null;default:0" json:"position" db:"position"`
+	IsPrimary    bool      `gorm:"type:boolean;not null;default:false" json:"is_primary" db:"is_primary"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at" db:"created_at"`
+	Product      Product   `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" db:"-"`
 }
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+const NotificationReview = "review"
+
+// ProductReview is the rating a customer gave a product from a delivered
+// order, with an optional text, photos and the reply of the merchant.
+// CustomerName and ProductName are read for listings.
+type ProductReview struct {
+	ID            string               `json:"id" db:"id"`
+	ProductID     string               `json:"product_id" db:"product_id"`
+	OrderID       string               `json:"order_id" db:"order_id"`
+	CustomerID    string               `json:"customer_id" db:"customer_id"`
+	Rating        int                  `json:"rating" db:"rating"`
+	Body          *string              `json:"body,omitempty" db:"body"`
+	MerchantReply *string              `json:"merchant_reply,omitempty" db:"merchant_reply"`
+	RepliedAt     *time.Time           `json:"replied_at,omitempty" db:"replied_at"`
+	CreatedAt     time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at" db:"updated_at"`
+	CustomerName  string               `json:"customer_name" db:"customer_name"`
+	ProductName   string               `json:"product_name" db:"product_name"`
+	Images        []ProductReviewImage `json:"images" db:"-"`
+}
+
+type ProductReviewImage struct {
+	ID           string    `json:"id" db:"id"`
+	ReviewID     string    `json:"review_id" db:"review_id"`
+	ImageURL     string    `json:"image_url" db:"image_url"`
+	ThumbnailURL string    `json:"thumbnail_url" db:"thumbnail_url"`
+	StorageKey   string    `json:"-" db:"storage_key"`
+	ThumbnailKey string    `json:"-" db:"thumbnail_key"`
+	Position     int       `json:"position" db:"position"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ProductRating is the average rating of a product over its reviews.
+type ProductRating struct {
+	ProductID string  `json:"product_id" db:"product_id"`
+	Average   float64 `json:"average" db:"average"`
+	Count     int     `json:"count" db:"count"`
+}
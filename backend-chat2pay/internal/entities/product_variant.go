@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+type (
+	// ProductOption is an option axis of a product such as color or size.
+	ProductOption struct {
+		ID        string         `json:"id" db:"id"`
+		ProductID string         `json:"product_id" db:"product_id"`
+		Name      string         `json:"name" db:"name"`
+		Values    pq.StringArray `json:"values" db:"option_values"`
+		Position  int            `json:"position" db:"position"`
+	}
+
+	// ProductVariant is a sellable combination of option values with its own
+	// SKU and stock. Price and weight fall back to the product when unset.
+	ProductVariant struct {
+		ID        string         `json:"id" db:"id"`
+		ProductID string         `json:"product_id" db:"product_id"`
+		Name      string         `json:"name" db:"name"`
+		Options   VariantOptions `json:"options" db:"options"`
+		SKU       *string        `json:"sku,omitempty" db:"sku"`
+		Price     *float64       `json:"price,omitempty" db:"price"`
+		Stock     int            `json:"stock" db:"stock"`
+		Weight    *int           `json:"weight,omitempty" db:"weight"`
+		ImageURL  *string        `json:"image_url,omitempty" db:"image_url"`
+		Position  int            `json:"position" db:"position"`
+		CreatedAt time.Time      `json:"created_at" db:"created_at"`
+		UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+	}
+
+	// VariantOptions maps an option name to the chosen value, e.g.
+	// {"Warna": "Hitam", "Ukuran": "M"}. It is stored as JSONB.
+	VariantOptions map[string]string
+)
+
+// PriceOf returns the variant price, or the product price when the variant
+// does not override it.
+func (v ProductVariant) PriceOf(product *Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// WeightOf returns the variant weight, or the product weight when the
+// variant does not override it.
+func (v ProductVariant) WeightOf(product *Product) int {
+	if v.Weight != nil {
+		return *v.Weight
+	}
+	return product.Weight
+}
+
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(o)
+}
+
+func (o *VariantOptions) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*o = VariantOptions{}
+		return nil
+	default:
+		return errors.New("unsupported type for variant options")
+	}
+
+	return json.Unmarshal(data, o)
+}
@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+// SearchSynonym is a merchant's addition to the global search dictionary.
+type SearchSynonym struct {
+	ID         string         `json:"id" db:"id"`
+	MerchantID string         `json:"merchant_id" db:"merchant_id"`
+	Term       string         `json:"term" db:"term"`
+	Synonyms   pq.StringArray `json:"synonyms" db:"synonyms"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+const (
+	StockAlertLowStock   = "low_stock"
+	StockAlertOutOfStock = "out_of_stock"
+
+	NotificationStockAlert = "stock_alert"
+)
+
+// StockAlert is raised when the stock of a product falls to or below its
+// low stock threshold, or runs out. It is resolved once stock is back above
+// the threshold. ProductName and CurrentStock are read for listings.
+type StockAlert struct {
+	ID           string     `json:"id" db:"id"`
+	MerchantID   string     `json:"merchant_id" db:"merchant_id"`
+	ProductID    string     `json:"product_id" db:"product_id"`
+	Type         string     `json:"type" db:"type"`
+	Stock        int        `json:"stock" db:"stock"`
+	Threshold    int        `json:"threshold" db:"threshold"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ProductName  string     `json:"product_name" db:"product_name"`
+	CurrentStock int        `json:"current_stock" db:"current_stock"`
+}
+
+type MerchantNotification struct {
+	ID            string     `json:"id" db:"id"`
+	MerchantID    string     `json:"merchant_id" db:"merchant_id"`
+	Type          string     `json:"type" db:"type"`
+	Title         string     `json:"title" db:"title"`
+	Message       string     `json:"message" db:"message"`
+	ReferenceType *string    `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID   *string    `json:"reference_id,omitempty" db:"reference_id"`
+	ReadAt        *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ChatStockMissSummary counts how often the chat could not offer a product
+// because it was out of stock.
+type ChatStockMissSummary struct {
+	ProductID   string    `json:"product_id" db:"product_id"`
+	ProductName string    `json:"product_name" db:"product_name"`
+	Stock       int       `json:"stock" db:"stock"`
+	Misses      int64     `json:"misses" db:"misses"`
+	Sessions    int64     `json:"sessions" db:"sessions"`
+	LastMissAt  time.Time `json:"last_miss_at" db:"last_miss_at"`
+}
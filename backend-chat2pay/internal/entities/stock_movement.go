@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+const (
+	StockMovementSale               = "sale"
+	StockMovementCancellationReturn = "cancellation_return"
+	StockMovementRestock            = "restock"
+	StockMovementAdjustment         = "adjustment"
+	StockMovementImport             = "import"
+
+	StockActorMerchant = "merchant"
+	StockActorCustomer = "customer"
+	StockActorSystem   = "system"
+)
+
+// StockMovement is one change to the stock held at a level: an outlet when
+// OutletID is set, else a variant when VariantID is set, else the product.
+// Quantity is the change, negative when stock goes out. ProductName,
+// VariantName and OutletName are read for listings.
+type StockMovement struct {
+	ID            string    `json:"id" db:"id"`
+	ProductID     string    `json:"product_id" db:"product_id"`
+	VariantID     *string   `json:"variant_id,omitempty" db:"variant_id"`
+	OutletID      *string   `json:"outlet_id,omitempty" db:"outlet_id"`
+	Type          string    `json:"type" db:"type"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	StockAfter    int       `json:"stock_after" db:"stock_after"`
+	ActorType     string    `json:"actor_type" db:"actor_type"`
+	ActorID       *string   `json:"actor_id,omitempty" db:"actor_id"`
+	ReferenceType *string   `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID   *string   `json:"reference_id,omitempty" db:"reference_id"`
+	Note          *string   `json:"note,omitempty" db:"note"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ProductName   string    `json:"product_name" db:"product_name"`
+	VariantName   *string   `json:"variant_name,omitempty" db:"variant_name"`
+	OutletName    *string   `json:"outlet_name,omitempty" db:"outlet_name"`
+}
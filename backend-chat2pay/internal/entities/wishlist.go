@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+const (
+	NotificationPriceDrop   = "price_drop"
+	NotificationBackInStock = "back_in_stock"
+)
+
+// WishlistItem is a product a customer saved for later. LastPrice and
+// InStock are the effective price and stock last seen, the customer is
+// notified of a lower price or a restock since then.
+type WishlistItem struct {
+	ID         string    `json:"id" db:"id"`
+	CustomerID string    `json:"customer_id" db:"customer_id"`
+	ProductID  string    `json:"product_id" db:"product_id"`
+	AddedPrice float64   `json:"added_price" db:"added_price"`
+	LastPrice  float64   `json:"last_price" db:"last_price"`
+	InStock    bool      `json:"in_stock" db:"in_stock"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	Product    *Product  `json:"product,omitempty" db:"-"`
+}
+
+type CustomerNotification struct {
+	ID            string     `json:"id" db:"id"`
+	CustomerID    string     `json:"customer_id" db:"customer_id"`
+	Type          string     `json:"type" db:"type"`
+	Title         string     `json:"title" db:"title"`
+	Message       string     `json:"message" db:"message"`
+	ReferenceType *string    `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID   *string    `json:"reference_id,omitempty" db:"reference_id"`
+	ReadAt        *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
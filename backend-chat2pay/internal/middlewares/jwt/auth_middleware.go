@@ -10,15 +10,16 @@ import (
 
 type AuthMiddleware interface {
 	GenerateToken(userID string, email string, role string) (*string, error)
-	GenerateTokenWithMerchant(userID string, merchantID string, email string, role string) (*string, error)
+	GenerateTokenWithMerchant(userID string, merchantID string, email string, role string, merchantRole string) (*string, error)
 	ValidateToken(tokenString string) (*Claims, error)
 }
 
 type Claims struct {
-	UserID     string `json:"user_id"`
-	MerchantID string `json:"merchant_id,omitempty"`
-	Email      string `json:"email"`
-	Role       string `json:"role"`
+	UserID       string `json:"user_id"`
+	MerchantID   string `json:"merchant_id,omitempty"`
+	MerchantRole string `json:"merchant_role,omitempty"` // owner, admin or staff
+	Email        string `json:"email"`
+	Role         string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -54,14 +55,15 @@ func (m *authMiddleware) GenerateToken(userID string, email string, role string)
 	return &tokenString, nil
 }
 
-func (m *authMiddleware) GenerateTokenWithMerchant(userID string, merchantID string, email string, role string) (*string, error) {
+func (m *authMiddleware) GenerateTokenWithMerchant(userID string, merchantID string, email string, role string, merchantRole string) (*string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 
 	claims := &Claims{
-		UserID:     userID,
-		MerchantID: merchantID,
-		Email:      email,
-		Role:       role,
+		UserID:       userID,
+		MerchantID:   merchantID,
+		MerchantRole: merchantRole,
+		Email:        email,
+		Role:         role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,387 @@
+package attributes
+
+import (
+	"chat2pay/internal/entities"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxAttributes is the number of attributes a product can have.
+	MaxAttributes = 50
+	// MaxKeyLength and MaxTextLength bound the size of an attribute.
+	MaxKeyLength  = 50
+	MaxTextLength = 255
+
+	// FilterPrefix marks the query parameters that filter on an attribute,
+	// e.g. attr.ram=16 or attr.ram.min=8.
+	FilterPrefix = "attr."
+)
+
+var (
+	keyPattern    = regexp.MustCompile(`^[a-z0-9_]+$`)
+	numberPattern = regexp.MustCompile(`^(-?\d+(?:[.,]\d+)?)\s*([a-zA-Z%"]*)$`)
+
+	trueWords  = map[string]bool{"true": true, "ya": true, "yes": true, "ada": true, "1": true}
+	falseWords = map[string]bool{"false": true, "tidak": true, "no": true, "tidak ada": true, "0": true}
+)
+
+// NormalizeKey turns a key such as "Ukuran Layar" into "ukuran_layar".
+func NormalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.Join(strings.FieldsFunc(key, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+// ValidateSchema checks a category schema and returns it with normalized
+// keys and types. A label defaults to the key.
+func ValidateSchema(schema entities.AttributeSchema) (entities.AttributeSchema, error) {
+	if len(schema) > MaxAttributes {
+		return nil, fmt.Errorf("a schema can have at most %d attributes", MaxAttributes)
+	}
+
+	normalized := make(entities.AttributeSchema, 0, len(schema))
+	seen := map[string]bool{}
+	for _, def := range schema {
+		def.Key = NormalizeKey(def.Key)
+		if err := checkKey(def.Key); err != nil {
+			return nil, err
+		}
+		if seen[def.Key] {
+			return nil, fmt.Errorf("attribute %s is defined twice", def.Key)
+		}
+		seen[def.Key] = true
+
+		def.Label = strings.TrimSpace(def.Label)
+		if def.Label == "" {
+			def.Label = def.Key
+		}
+		def.Unit = strings.TrimSpace(def.Unit)
+
+		def.Type = strings.ToLower(strings.TrimSpace(def.Type))
+		if def.Type == "" {
+			def.Type = entities.AttributeTypeText
+		}
+		switch def.Type {
+		case entities.AttributeTypeText, entities.AttributeTypeNumber, entities.AttributeTypeBoolean:
+			def.Values = nil
+		case entities.AttributeTypeEnum:
+			values := []string{}
+			for _, value := range def.Values {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			if len(values) == 0 {
+				return nil, fmt.Errorf("attribute %s needs the values of the enum", def.Key)
+			}
+			def.Values = values
+		default:
+			return nil, fmt.Errorf("attribute %s has unknown type %s, use text, number, boolean or enum", def.Key, def.Type)
+		}
+
+		normalized = append(normalized, def)
+	}
+
+	return normalized, nil
+}
+
+// Merge combines the schemas of a category and its ancestors, given root
+// first. A subcategory can redefine an attribute of its parent.
+func Merge(schemas ...entities.AttributeSchema) entities.AttributeSchema {
+	merged := entities.AttributeSchema{}
+	index := map[string]int{}
+	for _, schema := range schemas {
+		for _, def := range schema {
+			if i, ok := index[def.Key]; ok {
+				merged[i] = def
+				continue
+			}
+			index[def.Key] = len(merged)
+			merged = append(merged, def)
+		}
+	}
+
+	return merged
+}
+
+// Validate checks the attributes of a product against the schema of its
+// category and returns them with normalized keys and typed values. Numbers
+// may be given as text with their unit, e.g. "16 GB". Attributes the schema
+// does not define are kept as free text, numbers or booleans.
+func Validate(values entities.ProductAttributes, schema entities.AttributeSchema) (entities.ProductAttributes, error) {
+	if len(values) > MaxAttributes {
+		return nil, fmt.Errorf("a product can have at most %d attributes", MaxAttributes)
+	}
+
+	defs := map[string]entities.AttributeDefinition{}
+	for _, def := range schema {
+		defs[def.Key] = def
+	}
+
+	normalized := entities.ProductAttributes{}
+	for key, value := range values {
+		normalizedKey := NormalizeKey(key)
+		if err := checkKey(normalizedKey); err != nil {
+			return nil, err
+		}
+		if _, ok := normalized[normalizedKey]; ok {
+			return nil, fmt.Errorf("attribute %s is given twice", normalizedKey)
+		}
+		if isEmpty(value) {
+			continue
+		}
+
+		var err error
+		if def, ok := defs[normalizedKey]; ok {
+			value, err = typed(def, value)
+		} else {
+			value, err = free(normalizedKey, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		normalized[normalizedKey] = value
+	}
+
+	for _, def := range schema {
+		if _, ok := normalized[def.Key]; def.Required && !ok {
+			return nil, fmt.Errorf("attribute %s is required", def.Key)
+		}
+	}
+
+	return normalized, nil
+}
+
+func checkKey(key string) error {
+	switch {
+	case key == "":
+		return errors.New("attribute key is required")
+	case len(key) > MaxKeyLength:
+		return fmt.Errorf("attribute key %s is longer than %d characters", key, MaxKeyLength)
+	case !keyPattern.MatchString(key):
+		return fmt.Errorf("attribute key %s may only contain letters, digits and underscores", key)
+	}
+	return nil
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+func typed(def entities.AttributeDefinition, value interface{}) (interface{}, error) {
+	switch def.Type {
+	case entities.AttributeTypeNumber:
+		if n, ok := number(value, def.Unit); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("attribute %s must be a number", def.Key)
+
+	case entities.AttributeTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			word := strings.ToLower(strings.TrimSpace(v))
+			if trueWords[word] {
+				return true, nil
+			}
+			if falseWords[word] {
+				return false, nil
+			}
+		}
+		return nil, fmt.Errorf("attribute %s must be true or false", def.Key)
+
+	case entities.AttributeTypeEnum:
+		text := strings.TrimSpace(fmt.Sprint(value))
+		for _, allowed := range def.Values {
+			if strings.EqualFold(allowed, text) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("attribute %s must be one of %s", def.Key, strings.Join(def.Values, ", "))
+	}
+
+	return free(def.Key, fmt.Sprint(value))
+}
+
+func free(key string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		v = strings.TrimSpace(v)
+		if len(v) > MaxTextLength {
+			return nil, fmt.Errorf("attribute %s is longer than %d characters", key, MaxTextLength)
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("attribute %s must be text, a number or a boolean", key)
+}
+
+// number reads a number, or text holding a number followed by the given
+// unit or by no unit.
+func number(value interface{}, unit string) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		match := numberPattern.FindStringSubmatch(strings.TrimSpace(v))
+		if match == nil || (match[2] != "" && !strings.EqualFold(match[2], strings.ReplaceAll(unit, " ", ""))) {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+// Format describes the attributes for a person or a model to read, e.g.
+// "RAM: 16 GB, Bahan: Katun". Attributes of the schema come first in its
+// order, with their label and unit, the others follow by key.
+func Format(values entities.ProductAttributes, schema entities.AttributeSchema) string {
+	parts := []string{}
+	done := map[string]bool{}
+	for _, def := range schema {
+		if value, ok := values[def.Key]; ok {
+			parts = append(parts, def.Label+": "+formatValue(value, def.Unit))
+			done[def.Key] = true
+		}
+	}
+
+	rest := []string{}
+	for key := range values {
+		if !done[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		parts = append(parts, strings.ReplaceAll(key, "_", " ")+": "+formatValue(values[key], ""))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func formatValue(value interface{}, unit string) string {
+	var text string
+	switch v := value.(type) {
+	case bool:
+		text = "tidak"
+		if v {
+			text = "ya"
+		}
+	case float64:
+		if v == math.Trunc(v) {
+			text = strconv.FormatFloat(v, 'f', 0, 64)
+		} else {
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if unit != "" {
+		return text + " " + unit
+	}
+	return text
+}
+
+// Filter narrows down products on one attribute. Value matches text case
+// insensitively, and numbers when it holds one, e.g. "16GB" matches 16.
+// Min and Max bound a number.
+type Filter struct {
+	Key    string
+	Value  string
+	Number *float64
+	Min    *float64
+	Max    *float64
+}
+
+// ParseFilters reads the attribute filters from query parameters, e.g.
+// attr.ram=16GB, attr.layar.min=13 and attr.layar.max=15. Other parameters
+// are ignored. Filters on the same key are combined.
+func ParseFilters(params map[string]string) ([]Filter, error) {
+	byKey := map[string]*Filter{}
+	keys := []string{}
+
+	for param, value := range params {
+		if !strings.HasPrefix(param, FilterPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(param, FilterPrefix)
+
+		bound := ""
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name, bound = name[:i], name[i+1:]
+		}
+
+		key := NormalizeKey(name)
+		if err := checkKey(key); err != nil {
+			return nil, err
+		}
+
+		filter, ok := byKey[key]
+		if !ok {
+			filter = &Filter{Key: key}
+			byKey[key] = filter
+			keys = append(keys, key)
+		}
+
+		value = strings.TrimSpace(value)
+		switch bound {
+		case "":
+			filter.Value = value
+			if n, ok := number(value, unitOf(value)); ok {
+				filter.Number = &n
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", param)
+			}
+			if bound == "min" {
+				filter.Min = &n
+			} else {
+				filter.Max = &n
+			}
+		default:
+			return nil, fmt.Errorf("unknown attribute filter %s, use %s<key>, .min or .max", param, FilterPrefix)
+		}
+	}
+
+	sort.Strings(keys)
+	filters := make([]Filter, len(keys))
+	for i, key := range keys {
+		filters[i] = *byKey[key]
+	}
+
+	return filters, nil
+}
+
+// unitOf returns the unit a filter value is written with, so any unit is
+// accepted when filtering.
+func unitOf(value string) string {
+	if match := numberPattern.FindStringSubmatch(value); match != nil {
+		return match[2]
+	}
+	return ""
+}
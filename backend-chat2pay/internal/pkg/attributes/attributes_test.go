@@ -0,0 +1,158 @@
+package attributes
+
+import (
+	"chat2pay/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var laptopSchema = entities.AttributeSchema{
+	{Key: "ram", Label: "RAM", Type: entities.AttributeTypeNumber, Unit: "GB", Required: true},
+	{Key: "layar", Label: "Ukuran layar", Type: entities.AttributeTypeNumber, Unit: "inch"},
+	{Key: "touchscreen", Label: "Layar sentuh", Type: entities.AttributeTypeBoolean},
+	{Key: "os", Label: "Sistem operasi", Type: entities.AttributeTypeEnum, Values: []string{"Windows", "macOS", "Linux"}},
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Run("Normalizes keys, types and labels", func(t *testing.T) {
+		schema, err := ValidateSchema(entities.AttributeSchema{
+			{Key: " Ukuran Layar ", Type: "NUMBER", Unit: "inch"},
+			{Key: "bahan"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.AttributeSchema{
+			{Key: "ukuran_layar", Label: "ukuran_layar", Type: entities.AttributeTypeNumber, Unit: "inch"},
+			{Key: "bahan", Label: "bahan", Type: entities.AttributeTypeText},
+		}, schema)
+	})
+
+	t.Run("Rejects invalid definitions", func(t *testing.T) {
+		cases := map[string]entities.AttributeSchema{
+			"duplicate key": {{Key: "ram"}, {Key: "RAM"}},
+			"unknown type":  {{Key: "ram", Type: "integer"}},
+			"empty enum":    {{Key: "os", Type: entities.AttributeTypeEnum, Values: []string{" "}}},
+			"invalid key":   {{Key: "ram.size"}},
+		}
+
+		for name, schema := range cases {
+			_, err := ValidateSchema(schema)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	parent := entities.AttributeSchema{
+		{Key: "merek", Label: "Merek"},
+		{Key: "garansi", Label: "Garansi", Type: entities.AttributeTypeNumber, Unit: "bulan"},
+	}
+	child := entities.AttributeSchema{
+		{Key: "garansi", Label: "Garansi resmi", Type: entities.AttributeTypeNumber, Unit: "bulan", Required: true},
+		{Key: "ram", Label: "RAM"},
+	}
+
+	merged := Merge(parent, child)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "merek", merged[0].Key)
+	assert.Equal(t, "Garansi resmi", merged[1].Label)
+	assert.True(t, merged[1].Required)
+	assert.Equal(t, "ram", merged[2].Key)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Types values by the schema", func(t *testing.T) {
+		values, err := Validate(entities.ProductAttributes{
+			"RAM":         "16 GB",
+			"layar":       14.0,
+			"touchscreen": "ya",
+			"os":          "windows",
+			"Warna Body":  " Silver ",
+			"baterai":     "",
+		}, laptopSchema)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ProductAttributes{
+			"ram":         16.0,
+			"layar":       14.0,
+			"touchscreen": true,
+			"os":          "Windows",
+			"warna_body":  "Silver",
+		}, values)
+	})
+
+	t.Run("Rejects values of the wrong type", func(t *testing.T) {
+		cases := map[string]entities.ProductAttributes{
+			"not a number":   {"ram": "enam belas"},
+			"wrong unit":     {"ram": "16 MB"},
+			"not a boolean":  {"ram": 16.0, "touchscreen": "mungkin"},
+			"not in enum":    {"ram": 16.0, "os": "ChromeOS"},
+			"nested value":   {"ram": 16.0, "port": []interface{}{"usb"}},
+			"missing ram":    {"os": "Linux"},
+			"duplicate keys": {"ram": 16.0, "Ukuran-Layar": "14", "ukuran layar": "15"},
+		}
+
+		for name, values := range cases {
+			_, err := Validate(values, laptopSchema)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("Without schema everything is free", func(t *testing.T) {
+		values, err := Validate(entities.ProductAttributes{"Bahan": "Katun", "tebal": 2.5}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ProductAttributes{"bahan": "Katun", "tebal": 2.5}, values)
+	})
+}
+
+func TestFormat(t *testing.T) {
+	values := entities.ProductAttributes{
+		"ram":         16.0,
+		"layar":       14.5,
+		"touchscreen": false,
+		"warna_body":  "Silver",
+	}
+
+	assert.Equal(t,
+		"RAM: 16 GB, Ukuran layar: 14.5 inch, Layar sentuh: tidak, warna body: Silver",
+		Format(values, laptopSchema),
+	)
+	assert.Equal(t, "", Format(nil, laptopSchema))
+}
+
+func TestParseFilters(t *testing.T) {
+	t.Run("Reads values and bounds", func(t *testing.T) {
+		filters, err := ParseFilters(map[string]string{
+			"attr.ram":       "16GB",
+			"attr.layar.min": "13",
+			"attr.layar.max": "15.6",
+			"attr.Bahan":     "Katun",
+			"page":           "1",
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, filters, 3)
+
+		assert.Equal(t, "bahan", filters[0].Key)
+		assert.Equal(t, "Katun", filters[0].Value)
+		assert.Nil(t, filters[0].Number)
+
+		assert.Equal(t, "layar", filters[1].Key)
+		assert.Equal(t, 13.0, *filters[1].Min)
+		assert.Equal(t, 15.6, *filters[1].Max)
+
+		assert.Equal(t, "ram", filters[2].Key)
+		assert.Equal(t, 16.0, *filters[2].Number)
+	})
+
+	t.Run("Rejects invalid filters", func(t *testing.T) {
+		_, err := ParseFilters(map[string]string{"attr.ram.min": "banyak"})
+		assert.Error(t, err)
+
+		_, err = ParseFilters(map[string]string{"attr.ram.avg": "8"})
+		assert.Error(t, err)
+	})
+}
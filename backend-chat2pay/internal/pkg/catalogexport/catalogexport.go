@@ -0,0 +1,183 @@
+package catalogexport
+
+import (
+	"chat2pay/internal/entities"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var ErrUnsupportedFormat = errors.New("format must be csv or json")
+
+// Header is the CSV header. It uses the import column names, so an export
+// can be edited and imported again; the extra columns are ignored on import.
+var Header = []string{
+	"id", "sku", "name", "description", "price", "stock", "status", "category_id", "category",
+	"weight", "length", "width", "height", "image_urls", "created_at", "updated_at",
+}
+
+// Record is one exported product.
+type Record struct {
+	ID          string    `json:"id"`
+	SKU         *string   `json:"sku"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Price       float64   `json:"price"`
+	Stock       int       `json:"stock"`
+	Status      string    `json:"status"`
+	CategoryID  *string   `json:"category_id"`
+	Category    *string   `json:"category"`
+	Weight      int       `json:"weight"`
+	Length      int       `json:"length"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	ImageURLs   []string  `json:"image_urls"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToRecord reads the category name from product.Category and the image urls
+// from product.Images, in gallery order.
+func ToRecord(product *entities.Product) Record {
+	record := Record{
+		ID:          product.ID,
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Status:      product.Status,
+		CategoryID:  product.CategoryID,
+		Weight:      product.Weight,
+		Length:      product.Length,
+		Width:       product.Width,
+		Height:      product.Height,
+		ImageURLs:   make([]string, 0, len(product.Images)),
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
+
+	if product.Category != nil {
+		record.Category = &product.Category.Name
+	}
+	for _, image := range product.Images {
+		record.ImageURLs = append(record.ImageURLs, image.ImageURL)
+	}
+
+	return record
+}
+
+// Writer writes products one at a time, so an export never holds the whole
+// catalog in memory. Close must be called to finish the document.
+type Writer interface {
+	Write(product *entities.Product) error
+	Close() error
+}
+
+func ContentType(format string) string {
+	if format == FormatJSON {
+		return "application/json"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w, enc: json.NewEncoder(w)}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (c *csvWriter) Write(product *entities.Product) error {
+	if !c.started {
+		c.started = true
+		if err := c.w.Write(Header); err != nil {
+			return err
+		}
+	}
+
+	r := ToRecord(product)
+	return c.w.Write([]string{
+		r.ID,
+		deref(r.SKU),
+		r.Name,
+		deref(r.Description),
+		strconv.FormatFloat(r.Price, 'f', -1, 64),
+		strconv.Itoa(r.Stock),
+		r.Status,
+		deref(r.CategoryID),
+		deref(r.Category),
+		strconv.Itoa(r.Weight),
+		strconv.Itoa(r.Length),
+		strconv.Itoa(r.Width),
+		strconv.Itoa(r.Height),
+		strings.Join(r.ImageURLs, " "),
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if !c.started {
+		c.started = true
+		if err := c.w.Write(Header); err != nil {
+			return err
+		}
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes a JSON array, one element per product.
+type jsonWriter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int
+}
+
+func (j *jsonWriter) Write(product *entities.Product) error {
+	sep := ","
+	if j.count == 0 {
+		sep = "["
+	}
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+
+	j.count++
+	return j.enc.Encode(ToRecord(product))
+}
+
+func (j *jsonWriter) Close() error {
+	end := "]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,76 @@
+package catalogexport
+
+import (
+	"bytes"
+	"chat2pay/internal/entities"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testProducts() []entities.Product {
+	sku := "KAOS-01"
+	created := time.Date(2025, 12, 6, 10, 0, 0, 0, time.UTC)
+	return []entities.Product{
+		{
+			ID: "p1", SKU: &sku, Name: "Kaos, Polos", Price: 85000, Stock: 10, Status: "active",
+			Weight: 200, Length: 30, Width: 20, Height: 2,
+			Category:  &entities.ProductCategory{Name: "Kaos"},
+			Images:    []entities.ProductImage{{ImageURL: "/uploads/a.jpg"}, {ImageURL: "/uploads/b.jpg"}},
+			CreatedAt: created, UpdatedAt: created,
+		},
+		{ID: "p2", Name: "Topi", Price: 50000, Status: "inactive", CreatedAt: created, UpdatedAt: created},
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	assert.NoError(t, err)
+
+	for _, p := range testProducts() {
+		assert.NoError(t, w.Write(&p))
+	}
+	assert.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, strings.Join(Header, ","), lines[0])
+	assert.Equal(t, `p1,KAOS-01,"Kaos, Polos",,85000,10,active,,Kaos,200,30,20,2,/uploads/a.jpg /uploads/b.jpg,2025-12-06T10:00:00Z,2025-12-06T10:00:00Z`, lines[1])
+}
+
+func TestJSONWriter(t *testing.T) {
+	t.Run("Array of products", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewWriter(FormatJSON, &buf)
+
+		for _, p := range testProducts() {
+			assert.NoError(t, w.Write(&p))
+		}
+		assert.NoError(t, w.Close())
+
+		var records []Record
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		assert.Len(t, records, 2)
+		assert.Equal(t, []string{"/uploads/a.jpg", "/uploads/b.jpg"}, records[0].ImageURLs)
+		assert.Equal(t, "Kaos", *records[0].Category)
+		assert.Empty(t, records[1].ImageURLs)
+	})
+
+	t.Run("Empty catalog", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewWriter(FormatJSON, &buf)
+
+		assert.NoError(t, w.Close())
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestNewWriter(t *testing.T) {
+	_, err := NewWriter("xml", &bytes.Buffer{})
+
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,312 @@
+package catalogimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns understood in the header row. sku, name and price are required,
+// the order of the columns does not matter.
+const (
+	ColumnSKU         = "sku"
+	ColumnName        = "name"
+	ColumnDescription = "description"
+	ColumnPrice       = "price"
+	ColumnStock       = "stock"
+	ColumnStatus      = "status"
+	ColumnCategoryID  = "category_id"
+	ColumnWeight      = "weight"
+	ColumnLength      = "length"
+	ColumnWidth       = "width"
+	ColumnHeight      = "height"
+)
+
+var (
+	requiredColumns = []string{ColumnSKU, ColumnName, ColumnPrice}
+	knownColumns    = map[string]bool{
+		ColumnSKU: true, ColumnName: true, ColumnDescription: true, ColumnPrice: true, ColumnStock: true,
+		ColumnStatus: true, ColumnCategoryID: true, ColumnWeight: true, ColumnLength: true, ColumnWidth: true,
+		ColumnHeight: true,
+	}
+	statuses = map[string]bool{"draft": true, "active": true, "inactive": true, "archived": true}
+
+	ErrUnsupportedFile = errors.New("file must be a .csv or .xlsx")
+	ErrEmptyFile       = errors.New("file has no rows")
+)
+
+// Row is one product line of an import file. Line is the line number in
+// the file, counting the header as line 1, so it can be shown to the user.
+type Row struct {
+	Line        int
+	SKU         string
+	Name        string
+	Description string
+	Price       float64
+	Stock       int
+	Status      string
+	CategoryID  string
+	Weight      int
+	Length      int
+	Width       int
+	Height      int
+	Errors      []string
+}
+
+// Sheet is a parsed import file. Columns holds the known columns found in
+// the header, so columns left out of the file can be left untouched.
+type Sheet struct {
+	Columns map[string]bool
+	Rows    []Row
+}
+
+func (r *Row) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *Row) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// Parse reads a CSV or XLSX file, picked by the file extension, and
+// validates every row. Problems with a single row are reported on the row;
+// an error is only returned when the file itself cannot be used.
+func Parse(filename string, r io.Reader) (*Sheet, error) {
+	var (
+		records [][]string
+		err     error
+	)
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(r)
+	case ".xlsx":
+		records, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) < 2 {
+		return nil, ErrEmptyFile
+	}
+
+	header := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !knownColumns[name] {
+			continue
+		}
+		if _, exists := header[name]; exists {
+			return nil, fmt.Errorf("column %s appears twice", name)
+		}
+		header[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := header[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	sheet := &Sheet{Columns: map[string]bool{}, Rows: []Row{}}
+	for name := range header {
+		sheet.Columns[name] = true
+	}
+
+	skus := map[string]int{}
+	for i, record := range records[1:] {
+		if blank(record) {
+			continue
+		}
+
+		row := parseRow(i+2, header, record)
+		if row.SKU != "" {
+			key := strings.ToLower(row.SKU)
+			if first, exists := skus[key]; exists {
+				row.addError("sku %s is already used on line %d", row.SKU, first)
+			} else {
+				skus[key] = row.Line
+			}
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+
+	if len(sheet.Rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return sheet, nil
+}
+
+func parseRow(line int, header map[string]int, record []string) Row {
+	row := Row{Line: line, Status: "active"}
+
+	get := func(name string) string {
+		i, ok := header[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.SKU = get(ColumnSKU)
+	if row.SKU == "" {
+		row.addError("sku is required")
+	} else if len(row.SKU) > 100 {
+		row.addError("sku is longer than 100 characters")
+	}
+
+	row.Name = get(ColumnName)
+	if row.Name == "" {
+		row.addError("name is required")
+	} else if len([]rune(row.Name)) > 200 {
+		row.addError("name is longer than 200 characters")
+	}
+
+	row.Description = get(ColumnDescription)
+	row.CategoryID = get(ColumnCategoryID)
+
+	if status := strings.ToLower(get(ColumnStatus)); status != "" {
+		if !statuses[status] {
+			row.addError("status must be draft, active, inactive or archived")
+		}
+		row.Status = status
+	}
+
+	price, err := parseNumber(get(ColumnPrice))
+	switch {
+	case err != nil:
+		row.addError("price is not a number")
+	case price <= 0:
+		row.addError("price must be greater than 0")
+	}
+	row.Price = price
+
+	row.Stock = row.integer(ColumnStock, get(ColumnStock))
+	row.Weight = row.integer(ColumnWeight, get(ColumnWeight))
+	row.Length = row.integer(ColumnLength, get(ColumnLength))
+	row.Width = row.integer(ColumnWidth, get(ColumnWidth))
+	row.Height = row.integer(ColumnHeight, get(ColumnHeight))
+
+	// Shipping needs either all three dimensions or none of them
+	set := 0
+	for _, v := range []int{row.Length, row.Width, row.Height} {
+		if v > 0 {
+			set++
+		}
+	}
+	if set > 0 && set < 3 {
+		row.addError("length, width and height must be filled in together")
+	}
+
+	return row
+}
+
+// integer parses an optional whole number that cannot be negative.
+func (r *Row) integer(column, value string) int {
+	if value == "" {
+		return 0
+	}
+
+	n, err := parseNumber(value)
+	if err != nil || n != float64(int(n)) {
+		r.addError("%s must be a whole number", column)
+		return 0
+	}
+	if n < 0 {
+		r.addError("%s cannot be negative", column)
+		return 0
+	}
+	return int(n)
+}
+
+// parseNumber accepts plain numbers as well as numbers written the
+// Indonesian way, with dots between thousands and a decimal comma, such as
+// "Rp 1.250.000" or "12,5".
+func parseNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "Rp"), "rp")
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+
+	if strings.Contains(value, ",") || thousands(value) {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// thousands reports whether the dots in value separate groups of three
+// digits, as in 85.000.
+func thousands(value string) bool {
+	groups := strings.Split(value, ".")
+	if len(groups) < 2 {
+		return false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readCSV returns one record per line. The csv reader skips blank lines,
+// they are put back as empty records to keep line numbers right.
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records := [][]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, nil)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// readXLSX reads the first sheet of the workbook.
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx: %v", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return file.GetRows(sheets[0])
+}
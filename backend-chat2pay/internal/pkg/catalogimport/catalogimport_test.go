@@ -0,0 +1,105 @@
+package catalogimport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Valid csv", func(t *testing.T) {
+		file := "SKU,Name,Price,Stock,Weight,Length,Width,Height\n" +
+			"KAOS-01,Kaos Polos,\"Rp 85.000\",10,200,30,20,2\n" +
+			"\n" +
+			"KAOS-02,Kaos Hitam,90000,,,,,\n"
+
+		sheet, err := Parse("katalog.csv", strings.NewReader(file))
+
+		assert.NoError(t, err)
+		rows := sheet.Rows
+		assert.Len(t, rows, 2)
+		assert.True(t, sheet.Columns[ColumnWeight])
+		assert.False(t, sheet.Columns[ColumnDescription])
+		assert.True(t, rows[0].Valid())
+		assert.Equal(t, 85000.0, rows[0].Price)
+		assert.Equal(t, 10, rows[0].Stock)
+		assert.Equal(t, "active", rows[0].Status)
+		assert.Equal(t, 4, rows[1].Line)
+	})
+
+	t.Run("Reports every problem of a row", func(t *testing.T) {
+		file := "sku,name,price,stock,length\n" +
+			",Kaos,0,-1,30\n" +
+			"KAOS-01,Kaos,abc,1.5,\n"
+
+		sheet, err := Parse("katalog.csv", strings.NewReader(file))
+
+		assert.NoError(t, err)
+		rows := sheet.Rows
+		assert.Equal(t, []string{
+			"sku is required",
+			"price must be greater than 0",
+			"stock cannot be negative",
+			"length, width and height must be filled in together",
+		}, rows[0].Errors)
+		assert.Equal(t, []string{"price is not a number", "stock must be a whole number"}, rows[1].Errors)
+	})
+
+	t.Run("Indonesian number format", func(t *testing.T) {
+		file := "sku,name,price,weight\nA,Kaos,\"1.250.000\",\"1.000\"\nB,Kaos,\"12,5\",\nC,Kaos,99.5,\n"
+
+		sheet, err := Parse("katalog.csv", strings.NewReader(file))
+
+		assert.NoError(t, err)
+		rows := sheet.Rows
+		assert.Equal(t, 1250000.0, rows[0].Price)
+		assert.Equal(t, 1000, rows[0].Weight)
+		assert.Equal(t, 12.5, rows[1].Price)
+		assert.Equal(t, 99.5, rows[2].Price)
+	})
+
+	t.Run("Duplicate sku", func(t *testing.T) {
+		file := "sku,name,price\nKAOS-01,Kaos,1000\nkaos-01,Kaos Lagi,2000\n"
+
+		sheet, err := Parse("katalog.csv", strings.NewReader(file))
+
+		assert.NoError(t, err)
+		rows := sheet.Rows
+		assert.True(t, rows[0].Valid())
+		assert.Equal(t, []string{"sku kaos-01 is already used on line 2"}, rows[1].Errors)
+	})
+
+	t.Run("Missing required column", func(t *testing.T) {
+		_, err := Parse("katalog.csv", strings.NewReader("sku,name\nKAOS-01,Kaos\n"))
+
+		assert.EqualError(t, err, "missing column price")
+	})
+
+	t.Run("Unsupported file", func(t *testing.T) {
+		_, err := Parse("katalog.pdf", strings.NewReader(""))
+
+		assert.ErrorIs(t, err, ErrUnsupportedFile)
+	})
+
+	t.Run("Valid xlsx", func(t *testing.T) {
+		book := excelize.NewFile()
+		name := book.GetSheetName(0)
+		book.SetSheetRow(name, "A1", &[]interface{}{"sku", "name", "price", "stock"})
+		book.SetSheetRow(name, "A2", &[]interface{}{"SEP-01", "Sepatu Lari", 450000, 5})
+		var buf bytes.Buffer
+		assert.NoError(t, book.Write(&buf))
+
+		sheet, err := Parse("katalog.xlsx", &buf)
+
+		assert.NoError(t, err)
+		rows := sheet.Rows
+		assert.Len(t, rows, 1)
+		assert.True(t, rows[0].Valid())
+		assert.Equal(t, "Sepatu Lari", rows[0].Name)
+		assert.Equal(t, 450000.0, rows[0].Price)
+		assert.Equal(t, 5, rows[0].Stock)
+	})
+}
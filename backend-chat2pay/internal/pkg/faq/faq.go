@@ -0,0 +1,63 @@
+package faq
+
+import (
+	"chat2pay/internal/entities"
+	"strings"
+)
+
+const (
+	// QuoteSimilarity is how close a customer question must be to the
+	// question of an entry for its answer to be given as written.
+	QuoteSimilarity = 0.85
+
+	// MinSimilarity is how close an entry must be to be given to the model
+	// as a reference.
+	MinSimilarity = 0.6
+
+	// MaxEntries bounds the entries given to the model.
+	MaxEntries = 3
+)
+
+// Quote returns the entry answering the question well enough to be quoted
+// verbatim, or nil. Of an entry for the product and one for the whole
+// store matching as well, the one for the product wins.
+func Quote(matches []entities.ProductFAQ) *entities.ProductFAQ {
+	var best *entities.ProductFAQ
+	for i := range matches {
+		match := &matches[i]
+		if match.Similarity < QuoteSimilarity {
+			continue
+		}
+		if best == nil || match.Similarity > best.Similarity ||
+			(match.Similarity == best.Similarity && match.ProductID != nil && best.ProductID == nil) {
+			best = match
+		}
+	}
+
+	return best
+}
+
+// Context lists the entries close to the question for the model answering
+// it, which should keep to the answers of the merchant. Matches are ordered
+// by similarity, best first. It is empty when no entry is close enough.
+func Context(matches []entities.ProductFAQ) string {
+	lines := []string{}
+	for _, match := range matches {
+		if match.Similarity < MinSimilarity {
+			continue
+		}
+		lines = append(lines, "T: "+oneLine(match.Question), "J: "+oneLine(match.Answer))
+		if len(lines) == 2*MaxEntries {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "\n\nFAQ dari penjual (pakai jawaban ini bila relevan, jangan mengarang yang bertentangan):\n" + strings.Join(lines, "\n")
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,53 @@
+package faq
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/ptr"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	store := entities.ProductFAQ{ID: "store", Question: "Garansi berapa lama?", Answer: "Garansi toko 7 hari.", Similarity: 0.9}
+	product := entities.ProductFAQ{ID: "product", ProductID: ptr.To("p1"), Question: "Garansinya berapa lama?", Answer: "Garansi resmi 1 tahun.", Similarity: 0.9}
+	weak := entities.ProductFAQ{ID: "weak", Question: "Ori atau bukan?", Answer: "Original.", Similarity: 0.7}
+
+	t.Run("Quotes nothing below the threshold", func(t *testing.T) {
+		assert.Nil(t, Quote(nil))
+		assert.Nil(t, Quote([]entities.ProductFAQ{weak}))
+	})
+
+	t.Run("Quotes the closest entry", func(t *testing.T) {
+		closer := store
+		closer.Similarity = 0.95
+
+		assert.Equal(t, "store", Quote([]entities.ProductFAQ{product, closer}).ID)
+	})
+
+	t.Run("Prefers the product entry on a tie", func(t *testing.T) {
+		assert.Equal(t, "product", Quote([]entities.ProductFAQ{store, product, weak}).ID)
+	})
+}
+
+func TestContext(t *testing.T) {
+	matches := []entities.ProductFAQ{
+		{Question: "Garansi berapa lama?", Answer: "Garansi resmi\n1 tahun.", Similarity: 0.8},
+		{Question: "Bisa dipasang?", Answer: "Bisa, gratis pemasangan.", Similarity: 0.7},
+		{Question: "Bisa COD?", Answer: "Tidak.", Similarity: 0.3},
+	}
+
+	context := Context(matches)
+	assert.Contains(t, context, "T: Garansi berapa lama?\nJ: Garansi resmi 1 tahun.")
+	assert.Contains(t, context, "Bisa dipasang?")
+	assert.NotContains(t, context, "COD")
+
+	assert.Empty(t, Context(matches[2:]))
+
+	many := []entities.ProductFAQ{}
+	for i := 0; i < MaxEntries+2; i++ {
+		many = append(many, entities.ProductFAQ{Question: "Q", Answer: "A", Similarity: 0.9})
+	}
+	assert.Equal(t, MaxEntries, strings.Count(Context(many), "T: "))
+}
@@ -0,0 +1,93 @@
+package fulfillment
+
+import (
+	"chat2pay/internal/entities"
+	"math"
+	"sort"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+// Destination is where an order is shipped to. CityID is the shipping
+// provider city id; the coordinates are optional.
+type Destination struct {
+	CityID    string
+	City      string
+	Province  string
+	Latitude  *float64
+	Longitude *float64
+}
+
+// Nearest returns the outlet closest to the destination, or nil when there
+// are no outlets. Customers only give a city, so outlets are grouped first:
+// the same city, then the same province, then the rest. Within a group the
+// outlet nearest by coordinates wins when both sides have them, and the
+// name decides the remaining ties so the pick is stable.
+func Nearest(outlets []entities.Outlet, destination Destination) *entities.Outlet {
+	if len(outlets) == 0 {
+		return nil
+	}
+
+	ranked := make([]entities.Outlet, len(outlets))
+	copy(ranked, outlets)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := &ranked[i], &ranked[j]
+
+		if ta, tb := tier(a, destination), tier(b, destination); ta != tb {
+			return ta < tb
+		}
+
+		da, okA := Distance(a, destination)
+		db, okB := Distance(b, destination)
+		switch {
+		case okA && okB && da != db:
+			return da < db
+		case okA != okB:
+			return okA
+		}
+
+		return a.Name < b.Name
+	})
+
+	return &ranked[0]
+}
+
+// Distance is the great-circle distance in kilometres between the outlet
+// and the destination. It reports false when either has no coordinates.
+func Distance(outlet *entities.Outlet, destination Destination) (float64, bool) {
+	if outlet.Latitude == nil || outlet.Longitude == nil || destination.Latitude == nil || destination.Longitude == nil {
+		return 0, false
+	}
+
+	lat1, lat2 := radians(*outlet.Latitude), radians(*destination.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(*destination.Longitude - *outlet.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h)), true
+}
+
+func tier(outlet *entities.Outlet, destination Destination) int {
+	switch {
+	case destination.CityID != "" && outlet.CityID != nil && *outlet.CityID == destination.CityID:
+		return 0
+	case same(outlet.City, destination.City):
+		return 0
+	case same(outlet.Province, destination.Province):
+		return 1
+	}
+	return 2
+}
+
+func same(value *string, other string) bool {
+	if value == nil || other == "" {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(*value), strings.TrimSpace(other))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,72 @@
+package fulfillment
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/ptr"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testOutlets() []entities.Outlet {
+	return []entities.Outlet{
+		{ID: "sby", Name: "Surabaya", City: ptr.To("Surabaya"), CityID: ptr.To("444"), Province: ptr.To("Jawa Timur"),
+			Latitude: ptr.To(-7.2575), Longitude: ptr.To(112.7521)},
+		{ID: "mlg", Name: "Malang", City: ptr.To("Malang"), CityID: ptr.To("256"), Province: ptr.To("Jawa Timur"),
+			Latitude: ptr.To(-7.9666), Longitude: ptr.To(112.6326)},
+		{ID: "jkt", Name: "Jakarta", City: ptr.To("Jakarta Selatan"), CityID: ptr.To("153"), Province: ptr.To("DKI Jakarta"),
+			Latitude: ptr.To(-6.2615), Longitude: ptr.To(106.8106)},
+	}
+}
+
+func TestNearest(t *testing.T) {
+	t.Run("Same city id", func(t *testing.T) {
+		outlet := Nearest(testOutlets(), Destination{CityID: "256"})
+
+		assert.Equal(t, "mlg", outlet.ID)
+	})
+
+	t.Run("Same city name", func(t *testing.T) {
+		outlet := Nearest(testOutlets(), Destination{City: "jakarta selatan"})
+
+		assert.Equal(t, "jkt", outlet.ID)
+	})
+
+	t.Run("Same province before other provinces", func(t *testing.T) {
+		// Sidoarjo is not an outlet city, Surabaya is the closer one in Jawa Timur
+		outlet := Nearest(testOutlets(), Destination{
+			City: "Sidoarjo", Province: "Jawa Timur", Latitude: ptr.To(-7.4478), Longitude: ptr.To(112.7183),
+		})
+
+		assert.Equal(t, "sby", outlet.ID)
+	})
+
+	t.Run("Coordinates across provinces", func(t *testing.T) {
+		outlet := Nearest(testOutlets(), Destination{
+			City: "Bandung", Province: "Jawa Barat", Latitude: ptr.To(-6.9175), Longitude: ptr.To(107.6191),
+		})
+
+		assert.Equal(t, "jkt", outlet.ID)
+	})
+
+	t.Run("Name breaks ties", func(t *testing.T) {
+		outlet := Nearest(testOutlets(), Destination{City: "Denpasar"})
+
+		assert.Equal(t, "jkt", outlet.ID)
+	})
+
+	t.Run("No outlets", func(t *testing.T) {
+		assert.Nil(t, Nearest(nil, Destination{City: "Malang"}))
+	})
+}
+
+func TestDistance(t *testing.T) {
+	outlets := testOutlets()
+
+	km, ok := Distance(&outlets[0], Destination{Latitude: outlets[1].Latitude, Longitude: outlets[1].Longitude})
+	assert.True(t, ok)
+	assert.InDelta(t, 80, km, 5)
+
+	_, ok = Distance(&outlets[0], Destination{City: "Malang"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,56 @@
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	assert.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("Accepts png", func(t *testing.T) {
+		contentType, ext, err := Detect(pngImage(t, 10, 10))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+		assert.Equal(t, ".png", ext)
+	})
+
+	t.Run("Rejects other content", func(t *testing.T) {
+		_, _, err := Detect([]byte("<html>not an image</html>"))
+
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+	})
+}
+
+func TestThumbnail(t *testing.T) {
+	t.Run("Scales down keeping the aspect ratio", func(t *testing.T) {
+		thumb, err := Thumbnail(pngImage(t, 800, 400), 320)
+		assert.NoError(t, err)
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(thumb))
+		assert.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, 320, cfg.Width)
+		assert.Equal(t, 160, cfg.Height)
+	})
+
+	t.Run("Does not scale up", func(t *testing.T) {
+		thumb, err := Thumbnail(pngImage(t, 100, 200), 320)
+		assert.NoError(t, err)
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(thumb))
+		assert.NoError(t, err)
+		assert.Equal(t, 100, cfg.Width)
+		assert.Equal(t, 200, cfg.Height)
+	})
+}
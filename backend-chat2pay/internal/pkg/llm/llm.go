@@ -4,6 +4,7 @@ import (
 	"chat2pay/config/yaml"
 	"chat2pay/internal/pkg/llm/kolosal"
 	"chat2pay/internal/pkg/llm/mistral"
+	"chat2pay/internal/pkg/llm/offline"
 	"chat2pay/internal/pkg/redis"
 	"context"
 	"github.com/tmc/langchaingo/llms"
@@ -44,6 +45,13 @@ func NewLLM(cfg *yaml.Config, redisClient redis.RedisClient) LLM {
 	case "mistral":
 		llmProvider := mistral.NewMistralLLM(cfg.LLM.Mistral.APIKey, redisClient)
 
+		return &llm{
+			llm: llmProvider,
+		}
+
+	case "offline":
+		llmProvider := offline.NewOfflineLLM()
+
 		return &llm{
 			llm: llmProvider,
 		}
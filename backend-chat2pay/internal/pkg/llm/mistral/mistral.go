@@ -120,6 +120,7 @@ func (c *MistralLLM) ClassifyIntent(ctx context.Context, userMessage string) (st
 	- User is asking questions ABOUT a product that was already shown/recommended.
 	- User wants explanation, specs, or reasoning about shown products.
 	- User asks WHY a product was recommended.
+	- User asks about the store itself: payment, shipping, returns, warranty.
 	Examples:
 	"kenapa kamu menyarankan ini?"
 	"speknya apa?"
@@ -130,6 +131,8 @@ func (c *MistralLLM) ClassifyIntent(ctx context.Context, userMessage string) (st
 	"bisa jelasin gak?"
 	"apa bedanya dengan yang lain?"
 	"review nya gimana?"
+	"bisa COD?"
+	"bisa retur kalau rusak?"
 	
 	6. follow_up
 	- User asks for alternatives or modifications to shown products.
@@ -142,11 +145,29 @@ func (c *MistralLLM) ClassifyIntent(ctx context.Context, userMessage string) (st
 	"yang lebih bagus?"
 	"ada alternatif?"
 	
+	7. save_to_wishlist
+	- User wants to keep a shown product for later instead of buying now.
+	Examples:
+	"simpan ke wishlist"
+	"masukin wishlist dulu"
+	"nanti dulu deh, simpan aja"
+	"save for later"
+	
+	8. add_to_cart
+	- User wants to put a shown product in the shopping cart.
+	Examples:
+	"masukin keranjang"
+	"tambah ke keranjang"
+	"aku ambil yang ini"
+	"add to cart"
+	
 	IMPORTANT RULES:
 	- If user asks "kenapa", "mengapa", "apa speknya", "jelaskan" about shown products → "product_question"
 	- If user provides preferences/budget as answer to clarifying question → "product_clarification"  
 	- If user asks for alternatives/more options → "follow_up"
-	- Output MUST be ONLY one of: chit_chat, general_product_request, specific_product_search, product_clarification, product_question, follow_up
+	- If user asks to save a product or mentions a wishlist → "save_to_wishlist"
+	- If user asks to add a product to the cart or mentions "keranjang" → "add_to_cart"
+	- Output MUST be ONLY one of: chit_chat, general_product_request, specific_product_search, product_clarification, product_question, follow_up, save_to_wishlist, add_to_cart
 	- No explanation, no formatting, no JSON. Just the label.`
 
 	messages := []llms.MessageContent{
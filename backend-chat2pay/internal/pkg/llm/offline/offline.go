@@ -0,0 +1,124 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Dimension matches the vector(1024) column of product_embedding.
+const Dimension = 1024
+
+var errChatNotSupported = errors.New("chat is not supported by the offline provider")
+
+// OfflineLLM embeds text locally with feature hashing over words and character
+// trigrams. It needs no network or API key, and the same text always gives the
+// same vector, which makes it suitable for development and search evaluation.
+type OfflineLLM struct {
+	dimension int
+}
+
+// NewOfflineLLM creates a new offline embedding provider.
+func NewOfflineLLM() *OfflineLLM {
+	return &OfflineLLM{
+		dimension: Dimension,
+	}
+}
+
+func (c *OfflineLLM) Chat(ctx context.Context, userMessage string) (string, error) {
+	return "", errChatNotSupported
+}
+
+func (c *OfflineLLM) ChatWithHistory(ctx context.Context, userMessage string) (string, error) {
+	return "", errChatNotSupported
+}
+
+// ClassifyIntent treats every message as a product search, since there is no
+// model available to tell intents apart.
+func (c *OfflineLLM) ClassifyIntent(ctx context.Context, userMessage string) (string, error) {
+	return "specific_product_search", nil
+}
+
+func (c *OfflineLLM) NewConnection(ctx context.Context) error {
+	return nil
+}
+
+func (c *OfflineLLM) GetLastMessageContext(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+// Call implements the [llms.Model] interface.
+func (c *OfflineLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", errChatNotSupported
+}
+
+func (c *OfflineLLM) GenerateContent(
+	ctx context.Context,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	return nil, errChatNotSupported
+}
+
+func (c *OfflineLLM) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = c.embed(text)
+	}
+
+	return embeddings, nil
+}
+
+func (c *OfflineLLM) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return c.embed(text), nil
+}
+
+func (c *OfflineLLM) embed(text string) []float32 {
+	vector := make([]float32, c.dimension)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		c.add(vector, "w:"+word, 1)
+
+		// Character trigrams keep "leptop" close to "laptop"
+		padded := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			c.add(vector, "t:"+string(padded[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+
+	return vector
+}
+
+func (c *OfflineLLM) add(vector []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	// Use one bit of the hash as the sign so collisions cancel out on average
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vector[(sum>>1)%uint64(c.dimension)] += weight
+}
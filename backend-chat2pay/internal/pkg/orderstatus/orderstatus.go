@@ -0,0 +1,36 @@
+package orderstatus
+
+import "chat2pay/internal/entities"
+
+// transitions lists the statuses an order can move to from each status. An
+// order goes pending, paid, processing, shipped, delivered, and can be
+// cancelled until it is shipped. Delivered and cancelled orders are final.
+var transitions = map[string][]string{
+	entities.OrderStatusPending:    {entities.OrderStatusPaid, entities.OrderStatusCancelled},
+	entities.OrderStatusPaid:       {entities.OrderStatusProcessing, entities.OrderStatusCancelled},
+	entities.OrderStatusProcessing: {entities.OrderStatusShipped, entities.OrderStatusCancelled},
+	entities.OrderStatusShipped:    {entities.OrderStatusDelivered},
+	entities.OrderStatusDelivered:  {},
+	entities.OrderStatusCancelled:  {},
+}
+
+// Valid tells whether status is a known order status.
+func Valid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// Next returns the statuses an order in the given status can move to.
+func Next(status string) []string {
+	return transitions[status]
+}
+
+// CanMove tells whether an order can move from one status to the other.
+func CanMove(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package orderstatus
+
+import (
+	"chat2pay/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanMove(t *testing.T) {
+	t.Run("Follows the order flow", func(t *testing.T) {
+		assert.True(t, CanMove(entities.OrderStatusPending, entities.OrderStatusPaid))
+		assert.True(t, CanMove(entities.OrderStatusPaid, entities.OrderStatusProcessing))
+		assert.True(t, CanMove(entities.OrderStatusProcessing, entities.OrderStatusShipped))
+		assert.True(t, CanMove(entities.OrderStatusShipped, entities.OrderStatusDelivered))
+	})
+
+	t.Run("Does not skip or go back", func(t *testing.T) {
+		assert.False(t, CanMove(entities.OrderStatusPending, entities.OrderStatusDelivered))
+		assert.False(t, CanMove(entities.OrderStatusShipped, entities.OrderStatusPaid))
+		assert.False(t, CanMove(entities.OrderStatusPaid, entities.OrderStatusPaid))
+	})
+
+	t.Run("Cancels until shipped", func(t *testing.T) {
+		assert.True(t, CanMove(entities.OrderStatusPending, entities.OrderStatusCancelled))
+		assert.True(t, CanMove(entities.OrderStatusProcessing, entities.OrderStatusCancelled))
+		assert.False(t, CanMove(entities.OrderStatusShipped, entities.OrderStatusCancelled))
+		assert.False(t, CanMove(entities.OrderStatusDelivered, entities.OrderStatusCancelled))
+	})
+
+	t.Run("Keeps final statuses", func(t *testing.T) {
+		assert.Empty(t, Next(entities.OrderStatusDelivered))
+		assert.False(t, CanMove(entities.OrderStatusCancelled, entities.OrderStatusPending))
+	})
+
+	t.Run("Rejects unknown statuses", func(t *testing.T) {
+		assert.False(t, Valid("completed"))
+		assert.False(t, CanMove(entities.OrderStatusPending, "completed"))
+		assert.True(t, Valid(entities.OrderStatusShipped))
+	})
+}
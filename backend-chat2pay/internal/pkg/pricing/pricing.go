@@ -0,0 +1,129 @@
+package pricing
+
+import (
+	"chat2pay/internal/entities"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Location is the time zone discount end dates are told in.
+var Location = time.FixedZone("WIB", 7*60*60)
+
+var (
+	days   = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+	months = []string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"}
+)
+
+// Validate checks the rule of a discount. It does not check that the
+// product or category exists.
+func Validate(d entities.Discount) error {
+	hasProduct := d.ProductID != nil && *d.ProductID != ""
+	hasCategory := d.CategoryID != nil && *d.CategoryID != ""
+	if hasProduct == hasCategory {
+		return errors.New("a discount applies to either a product or a category")
+	}
+
+	switch d.Type {
+	case entities.DiscountTypePercentage:
+		if d.Value <= 0 || d.Value >= 100 {
+			return errors.New("a percentage discount must be between 0 and 100")
+		}
+	case entities.DiscountTypeFixed:
+		if d.Value <= 0 {
+			return errors.New("a fixed discount must be more than 0")
+		}
+	default:
+		return fmt.Errorf("unknown discount type %s, use percentage or fixed", d.Type)
+	}
+
+	if !d.EndsAt.After(d.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	return nil
+}
+
+// Best returns the discount giving the lowest price, or nil when there is
+// none. Of two discounts giving the same price the one lasting longer wins.
+func Best(price float64, discounts []entities.Discount) *entities.Discount {
+	var best *entities.Discount
+	for i := range discounts {
+		d := &discounts[i]
+		if best == nil {
+			best = d
+			continue
+		}
+
+		discounted, bestDiscounted := d.Apply(price), best.Apply(price)
+		if discounted < bestDiscounted || (discounted == bestDiscounted && d.EndsAt.After(best.EndsAt)) {
+			best = d
+		}
+	}
+
+	return best
+}
+
+// Label describes the discount for the customer, e.g. "diskon 20% sampai
+// Minggu" or "potongan Rp 50.000 sampai 31 Des". The end is told as a day
+// of the week when it is within a week of now.
+func Label(d entities.Discount, now time.Time) string {
+	label := "diskon " + formatNumber(d.Value) + "%"
+	if d.Type == entities.DiscountTypeFixed {
+		label = "potongan Rp " + formatNumber(d.Value)
+	}
+
+	return label + " sampai " + until(d.EndsAt, now)
+}
+
+// Rupiah writes a price for the customer, rounded to the rupiah, e.g.
+// "Rp 1.250.000".
+func Rupiah(price float64) string {
+	return "Rp " + formatNumber(math.Round(price))
+}
+
+// until names the last day of a discount. A discount ending at midnight
+// lasts until the day before.
+func until(end, now time.Time) string {
+	last := end.Add(-time.Second).In(Location)
+	now = now.In(Location)
+
+	lastDay := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, Location)
+	daysLeft := int(lastDay.Sub(today).Hours() / 24)
+
+	switch {
+	case daysLeft <= 0:
+		return "hari ini"
+	case daysLeft == 1:
+		return "besok"
+	case daysLeft < 7:
+		return days[last.Weekday()]
+	case last.Year() == now.Year():
+		return fmt.Sprintf("%d %s", last.Day(), months[last.Month()-1])
+	}
+
+	return fmt.Sprintf("%d %s %d", last.Day(), months[last.Month()-1], last.Year())
+}
+
+// formatNumber writes a number the Indonesian way, e.g. 50.000 or 12,5.
+func formatNumber(value float64) string {
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	grouped := []string{}
+	for len(whole) > 3 {
+		grouped = append([]string{whole[len(whole)-3:]}, grouped...)
+		whole = whole[:len(whole)-3]
+	}
+	grouped = append([]string{whole}, grouped...)
+
+	text = strings.Join(grouped, ".")
+	if fraction != "" {
+		text += "," + fraction
+	}
+	return text
+}
@@ -0,0 +1,98 @@
+package pricing
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/ptr"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// now is a Wednesday afternoon in WIB
+var now = time.Date(2025, time.December, 10, 14, 0, 0, 0, Location)
+
+func TestApply(t *testing.T) {
+	percentage := entities.Discount{Type: entities.DiscountTypePercentage, Value: 20}
+	fixed := entities.Discount{Type: entities.DiscountTypeFixed, Value: 50000}
+
+	assert.Equal(t, 8000000.0, percentage.Apply(10000000))
+	assert.Equal(t, 79920.0, percentage.Apply(99900))
+	assert.Equal(t, 49900.0, fixed.Apply(99900))
+	assert.Equal(t, 0.0, fixed.Apply(30000))
+}
+
+func TestValidate(t *testing.T) {
+	valid := entities.Discount{
+		ProductID: ptr.To("p1"),
+		Type:      entities.DiscountTypePercentage,
+		Value:     20,
+		StartsAt:  now,
+		EndsAt:    now.Add(72 * time.Hour),
+	}
+	assert.NoError(t, Validate(valid))
+
+	cases := map[string]func(d *entities.Discount){
+		"no target":         func(d *entities.Discount) { d.ProductID = nil },
+		"two targets":       func(d *entities.Discount) { d.CategoryID = ptr.To("c1") },
+		"unknown type":      func(d *entities.Discount) { d.Type = "bogo" },
+		"full percentage":   func(d *entities.Discount) { d.Value = 100 },
+		"negative amount":   func(d *entities.Discount) { d.Type = entities.DiscountTypeFixed; d.Value = -1 },
+		"ends before start": func(d *entities.Discount) { d.EndsAt = d.StartsAt },
+	}
+
+	for name, change := range cases {
+		d := valid
+		change(&d)
+		assert.Error(t, Validate(d), name)
+	}
+}
+
+func TestBest(t *testing.T) {
+	percentage := entities.Discount{ID: "pct", Type: entities.DiscountTypePercentage, Value: 10, EndsAt: now.Add(time.Hour)}
+	fixed := entities.Discount{ID: "fix", Type: entities.DiscountTypeFixed, Value: 50000, EndsAt: now.Add(time.Hour)}
+	longer := entities.Discount{ID: "long", Type: entities.DiscountTypeFixed, Value: 50000, EndsAt: now.Add(48 * time.Hour)}
+
+	assert.Nil(t, Best(100000, nil))
+	assert.Equal(t, "fix", Best(100000, []entities.Discount{percentage, fixed}).ID)
+	assert.Equal(t, "pct", Best(1000000, []entities.Discount{percentage, fixed}).ID)
+	assert.Equal(t, "long", Best(100000, []entities.Discount{fixed, longer}).ID)
+}
+
+func TestLabel(t *testing.T) {
+	cases := []struct {
+		discount entities.Discount
+		expected string
+	}{
+		{
+			entities.Discount{Type: entities.DiscountTypePercentage, Value: 20, EndsAt: time.Date(2025, time.December, 15, 0, 0, 0, 0, Location)},
+			"diskon 20% sampai Minggu",
+		},
+		{
+			entities.Discount{Type: entities.DiscountTypePercentage, Value: 12.5, EndsAt: now.Add(2 * time.Hour)},
+			"diskon 12,5% sampai hari ini",
+		},
+		{
+			entities.Discount{Type: entities.DiscountTypeFixed, Value: 50000, EndsAt: time.Date(2025, time.December, 11, 21, 0, 0, 0, Location)},
+			"potongan Rp 50.000 sampai besok",
+		},
+		{
+			entities.Discount{Type: entities.DiscountTypeFixed, Value: 1500000, EndsAt: time.Date(2025, time.December, 31, 23, 59, 0, 0, Location)},
+			"potongan Rp 1.500.000 sampai 31 Des",
+		},
+		{
+			entities.Discount{Type: entities.DiscountTypePercentage, Value: 5, EndsAt: time.Date(2026, time.January, 20, 0, 0, 0, 0, time.UTC)},
+			"diskon 5% sampai 20 Jan 2026",
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Label(c.discount, now))
+	}
+}
+
+func TestRupiah(t *testing.T) {
+	assert.Equal(t, "Rp 1.250.000", Rupiah(1250000))
+	assert.Equal(t, "Rp 800", Rupiah(799.5))
+	assert.Equal(t, "Rp 0", Rupiah(0))
+}
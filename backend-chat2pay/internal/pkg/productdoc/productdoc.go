@@ -0,0 +1,293 @@
+package productdoc
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/attributes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	FieldName        = "name"
+	FieldSKU         = "sku"
+	FieldCategory    = "category"
+	FieldMerchant    = "merchant"
+	FieldPrice       = "price"
+	FieldPriceBand   = "price_band"
+	FieldAttributes  = "attributes"
+	FieldOptions     = "options"
+	FieldDescription = "description"
+
+	AggregationMax  = "max"
+	AggregationMean = "mean"
+
+	defaultChunkSize    = 120
+	defaultChunkOverlap = 20
+)
+
+var (
+	defaultFields = []string{
+		FieldName, FieldSKU, FieldCategory, FieldMerchant, FieldPrice, FieldPriceBand, FieldAttributes, FieldOptions, FieldDescription,
+	}
+	defaultPriceBands = []float64{100000, 500000, 1000000, 5000000, 15000000}
+)
+
+// Input is everything the builder knows about a product. Related data is
+// passed in so the builder stays free of database access. AttributeSchema
+// is the merged schema of the product category, for attribute labels and
+// units.
+type Input struct {
+	Product         entities.Product
+	MerchantName    string
+	CategoryPath    []string
+	AttributeSchema entities.AttributeSchema
+}
+
+// Builder turns a product into the text documents that get embedded.
+type Builder struct {
+	fields       []string
+	priceBands   []float64
+	chunkSize    int
+	chunkOverlap int
+}
+
+func NewBuilder(cfg yaml.SearchDocument) *Builder {
+	b := &Builder{
+		fields:       cfg.Fields,
+		priceBands:   append([]float64(nil), cfg.PriceBands...),
+		chunkSize:    cfg.ChunkSize,
+		chunkOverlap: cfg.ChunkOverlap,
+	}
+
+	if len(b.fields) == 0 {
+		b.fields = defaultFields
+	}
+	if len(b.priceBands) == 0 {
+		b.priceBands = defaultPriceBands
+	}
+	sort.Float64s(b.priceBands)
+	if b.chunkSize <= 0 {
+		b.chunkSize = defaultChunkSize
+	}
+	if b.chunkOverlap < 0 || b.chunkOverlap >= b.chunkSize {
+		b.chunkOverlap = defaultChunkOverlap % b.chunkSize
+	}
+
+	return b
+}
+
+// Build returns one document per description chunk. Every document repeats
+// the product header so each chunk can be matched on its own; a product
+// without a description yields a single document.
+func (b *Builder) Build(in Input) []string {
+	var (
+		header      strings.Builder
+		description string
+		withDesc    bool
+	)
+
+	for _, field := range b.fields {
+		switch field {
+		case FieldDescription:
+			withDesc = true
+			if in.Product.Description != nil {
+				description = strings.TrimSpace(*in.Product.Description)
+			}
+		default:
+			if line := b.line(field, in); line != "" {
+				header.WriteString(line)
+				header.WriteString("\n")
+			}
+		}
+	}
+
+	if !withDesc || description == "" {
+		return []string{strings.TrimSpace(header.String())}
+	}
+
+	chunks := b.chunk(description)
+	docs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		docs[i] = header.String() + "Deskripsi: " + chunk
+	}
+
+	return docs
+}
+
+func (b *Builder) line(field string, in Input) string {
+	p := in.Product
+
+	switch field {
+	case FieldName:
+		return "Nama: " + p.Name
+	case FieldSKU:
+		if p.SKU != nil && *p.SKU != "" {
+			return "SKU: " + *p.SKU
+		}
+	case FieldCategory:
+		if len(in.CategoryPath) > 0 {
+			return "Kategori: " + strings.Join(in.CategoryPath, " > ")
+		}
+	case FieldMerchant:
+		if in.MerchantName != "" {
+			return "Toko: " + in.MerchantName
+		}
+	case FieldPrice:
+		return fmt.Sprintf("Harga: Rp %.0f", p.Price)
+	case FieldPriceBand:
+		return "Rentang harga: " + b.PriceBand(p.Price)
+	case FieldAttributes:
+		return specifications(in)
+	case FieldOptions:
+		return options(p)
+	}
+
+	return ""
+}
+
+func specifications(in Input) string {
+	p := in.Product
+
+	parts := []string{}
+	if specs := attributes.Format(p.Attributes, in.AttributeSchema); specs != "" {
+		parts = append(parts, specs)
+	}
+	if p.Weight > 0 {
+		parts = append(parts, fmt.Sprintf("berat %d gram", p.Weight))
+	}
+	if p.Length > 0 && p.Width > 0 && p.Height > 0 {
+		parts = append(parts, fmt.Sprintf("dimensi %dx%dx%d cm", p.Length, p.Width, p.Height))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Spesifikasi: " + strings.Join(parts, ", ")
+}
+
+// options lists the variant choices, so a search for a color finds the
+// product that comes in it.
+func options(p entities.Product) string {
+	parts := []string{}
+	for _, o := range p.Options {
+		if len(o.Values) > 0 {
+			parts = append(parts, o.Name+": "+strings.Join(o.Values, ", "))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Pilihan: " + strings.Join(parts, "; ")
+}
+
+// PriceBand describes which configured band a price falls into, e.g.
+// "Rp 1 juta - Rp 5 juta".
+func (b *Builder) PriceBand(price float64) string {
+	lower := 0.0
+	for _, upper := range b.priceBands {
+		if price < upper {
+			if lower == 0 {
+				return "di bawah " + rupiah(upper)
+			}
+			return rupiah(lower) + " - " + rupiah(upper)
+		}
+		lower = upper
+	}
+
+	return "di atas " + rupiah(lower)
+}
+
+func rupiah(v float64) string {
+	switch {
+	case v >= 1000000:
+		return fmt.Sprintf("Rp %s juta", trimZero(v/1000000))
+	case v >= 1000:
+		return fmt.Sprintf("Rp %s ribu", trimZero(v/1000))
+	}
+	return fmt.Sprintf("Rp %.0f", v)
+}
+
+func trimZero(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", v), "0"), ".")
+}
+
+// chunk splits text into windows of chunkSize words that overlap by
+// chunkOverlap words.
+func (b *Builder) chunk(text string) []string {
+	words := strings.Fields(text)
+	if len(words) <= b.chunkSize {
+		return []string{strings.Join(words, " ")}
+	}
+
+	chunks := []string{}
+	step := b.chunkSize - b.chunkOverlap
+	for start := 0; start < len(words); start += step {
+		end := start + b.chunkSize
+		if end >= len(words) {
+			chunks = append(chunks, strings.Join(words[start:], " "))
+			break
+		}
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+	}
+
+	return chunks
+}
+
+// Aggregate folds chunk-level matches into one score per product and returns
+// at most limit products, best first.
+func Aggregate(matches []entities.ProductEmbedding, method string, limit int) []entities.ProductEmbedding {
+	type score struct {
+		best  entities.ProductEmbedding
+		sum   float64
+		count int
+	}
+
+	scores := map[string]*score{}
+	order := []string{}
+	for _, m := range matches {
+		s, ok := scores[m.ProductId]
+		if !ok {
+			s = &score{best: m}
+			scores[m.ProductId] = s
+			order = append(order, m.ProductId)
+		}
+		if m.Similarity > s.best.Similarity {
+			s.best = m
+		}
+		s.sum += m.Similarity
+		s.count++
+	}
+
+	results := make([]entities.ProductEmbedding, 0, len(order))
+	for _, id := range order {
+		s := scores[id]
+		result := s.best
+		if method == AggregationMean {
+			result.Similarity = s.sum / float64(s.count)
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// CategoryPath walks up the parent chain of a category and returns the names
+// from the root down, e.g. ["Elektronik", "Laptop"].
+func CategoryPath(category *entities.ProductCategory) []string {
+	path := []string{}
+	for c := category; c != nil; c = c.Parent {
+		path = append([]string{c.Name}, path...)
+	}
+	return path
+}
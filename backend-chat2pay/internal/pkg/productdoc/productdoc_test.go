@@ -0,0 +1,128 @@
+package productdoc
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/entities"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	description := "Laptop ringan untuk kerja dan kuliah"
+	sku := "LAP-001"
+	in := Input{
+		Product: entities.Product{
+			Name:        "Zenbook 14",
+			SKU:         &sku,
+			Description: &description,
+			Price:       12500000,
+			Weight:      1200,
+			Length:      31, Width: 22, Height: 2,
+			Options: []entities.ProductOption{
+				{Name: "Warna", Values: []string{"Silver", "Biru"}},
+				{Name: "RAM", Values: []string{"8GB", "16GB"}},
+			},
+		},
+		MerchantName: "Toko Elektronik ABC",
+		CategoryPath: []string{"Elektronik", "Laptop"},
+	}
+
+	t.Run("Default fields", func(t *testing.T) {
+		docs := NewBuilder(yaml.SearchDocument{}).Build(in)
+
+		assert.Len(t, docs, 1)
+		assert.Contains(t, docs[0], "Nama: Zenbook 14")
+		assert.Contains(t, docs[0], "Kategori: Elektronik > Laptop")
+		assert.Contains(t, docs[0], "Toko: Toko Elektronik ABC")
+		assert.Contains(t, docs[0], "Rentang harga: Rp 5 juta - Rp 15 juta")
+		assert.Contains(t, docs[0], "Spesifikasi: berat 1200 gram, dimensi 31x22x2 cm")
+		assert.Contains(t, docs[0], "Pilihan: Warna: Silver, Biru; RAM: 8GB, 16GB")
+		assert.Contains(t, docs[0], "Deskripsi: Laptop ringan")
+		assert.NotContains(t, docs[0], "Brand")
+	})
+
+	t.Run("Configured fields only", func(t *testing.T) {
+		docs := NewBuilder(yaml.SearchDocument{Fields: []string{FieldName, FieldMerchant}}).Build(in)
+
+		assert.Equal(t, []string{"Nama: Zenbook 14\nToko: Toko Elektronik ABC"}, docs)
+	})
+
+	t.Run("Long description is chunked with overlap", func(t *testing.T) {
+		words := make([]string, 25)
+		for i := range words {
+			words[i] = "kata"
+		}
+		words[0], words[24] = "awal", "akhir"
+		long := strings.Join(words, " ")
+		in := in
+		in.Product.Description = &long
+
+		docs := NewBuilder(yaml.SearchDocument{ChunkSize: 10, ChunkOverlap: 2}).Build(in)
+
+		assert.Len(t, docs, 3)
+		for _, doc := range docs {
+			assert.Contains(t, doc, "Nama: Zenbook 14")
+		}
+		assert.Contains(t, docs[0], "Deskripsi: awal")
+		assert.True(t, strings.HasSuffix(docs[2], "akhir"))
+	})
+
+	t.Run("Product attributes come first in the specifications", func(t *testing.T) {
+		in := in
+		in.Product.Attributes = entities.ProductAttributes{"ram": 16.0, "touchscreen": true}
+		in.AttributeSchema = entities.AttributeSchema{
+			{Key: "ram", Label: "RAM", Type: entities.AttributeTypeNumber, Unit: "GB"},
+		}
+
+		docs := NewBuilder(yaml.SearchDocument{}).Build(in)
+
+		assert.Contains(t, docs[0], "Spesifikasi: RAM: 16 GB, touchscreen: ya, berat 1200 gram, dimensi 31x22x2 cm")
+	})
+
+	t.Run("No description", func(t *testing.T) {
+		in := in
+		in.Product.Description = nil
+
+		docs := NewBuilder(yaml.SearchDocument{}).Build(in)
+		assert.Len(t, docs, 1)
+		assert.NotContains(t, docs[0], "Deskripsi")
+	})
+}
+
+func TestBuilder_PriceBand(t *testing.T) {
+	b := NewBuilder(yaml.SearchDocument{PriceBands: []float64{1000000, 500000}})
+
+	assert.Equal(t, "di bawah Rp 500 ribu", b.PriceBand(250000))
+	assert.Equal(t, "Rp 500 ribu - Rp 1 juta", b.PriceBand(750000))
+	assert.Equal(t, "di atas Rp 1 juta", b.PriceBand(2500000))
+}
+
+func TestAggregate(t *testing.T) {
+	matches := []entities.ProductEmbedding{
+		{ProductId: "a", Similarity: 0.9},
+		{ProductId: "b", Similarity: 0.85},
+		{ProductId: "a", Similarity: 0.5},
+		{ProductId: "c", Similarity: 0.6},
+	}
+
+	t.Run("Max", func(t *testing.T) {
+		results := Aggregate(matches, AggregationMax, 10)
+
+		assert.Len(t, results, 3)
+		assert.Equal(t, "a", results[0].ProductId)
+		assert.Equal(t, 0.9, results[0].Similarity)
+	})
+
+	t.Run("Mean", func(t *testing.T) {
+		results := Aggregate(matches, AggregationMean, 10)
+
+		assert.Equal(t, "b", results[0].ProductId)
+		assert.InDelta(t, 0.7, results[1].Similarity, 1e-9)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		assert.Len(t, Aggregate(matches, AggregationMax, 2), 2)
+	})
+}
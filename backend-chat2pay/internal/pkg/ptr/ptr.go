@@ -0,0 +1,7 @@
+package ptr
+
+// To returns a pointer to a copy of v, for optional fields set from
+// literals.
+func To[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,172 @@
+package querynorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Entry maps a term (one or more words) to its synonyms. A replacing entry
+// swaps the term for its first synonym, which suits slang and common
+// misspellings; otherwise the synonyms are added next to the term.
+type Entry struct {
+	Term     string
+	Synonyms []string
+	Replace  bool
+}
+
+type Dictionary []Entry
+
+// DefaultDictionary is the global dictionary applied to every search.
+var DefaultDictionary = Dictionary{
+	// Slang and abbreviations
+	{Term: "hp", Synonyms: []string{"handphone", "smartphone"}},
+	{Term: "hape", Synonyms: []string{"handphone", "smartphone"}, Replace: true},
+	{Term: "ponsel", Synonyms: []string{"handphone", "smartphone"}},
+	{Term: "laptop", Synonyms: []string{"notebook"}},
+	{Term: "leptop", Synonyms: []string{"laptop"}, Replace: true},
+	{Term: "laptob", Synonyms: []string{"laptop"}, Replace: true},
+	{Term: "kompi", Synonyms: []string{"komputer"}, Replace: true},
+	{Term: "pc", Synonyms: []string{"komputer"}},
+	{Term: "tv", Synonyms: []string{"televisi"}},
+	{Term: "tipi", Synonyms: []string{"televisi"}, Replace: true},
+	{Term: "headset", Synonyms: []string{"headphone", "earphone"}},
+	{Term: "hedset", Synonyms: []string{"headset"}, Replace: true},
+	{Term: "earphone", Synonyms: []string{"headset", "earbuds"}},
+	{Term: "tws", Synonyms: []string{"earbuds", "wireless earphone"}},
+	{Term: "powerbank", Synonyms: []string{"power bank"}},
+	{Term: "casan", Synonyms: []string{"charger"}, Replace: true},
+	{Term: "cas", Synonyms: []string{"charger"}, Replace: true},
+	{Term: "kabel data", Synonyms: []string{"kabel usb"}},
+	{Term: "type c", Synonyms: []string{"usb-c", "usb type-c"}},
+	{Term: "tipe c", Synonyms: []string{"type c", "usb-c"}, Replace: true},
+	{Term: "murmer", Synonyms: []string{"murah"}, Replace: true},
+	{Term: "gaming", Synonyms: []string{"game"}},
+	{Term: "ngegame", Synonyms: []string{"gaming"}, Replace: true},
+	{Term: "ngegaming", Synonyms: []string{"gaming"}, Replace: true},
+
+	// Fashion
+	{Term: "sepatu lari", Synonyms: []string{"running shoes", "sepatu olahraga"}},
+	{Term: "sneakers", Synonyms: []string{"sepatu"}},
+	{Term: "sendal", Synonyms: []string{"sandal"}, Replace: true},
+	{Term: "kaos", Synonyms: []string{"t-shirt", "kaos oblong"}},
+	{Term: "kaus", Synonyms: []string{"kaos"}, Replace: true},
+	{Term: "celana jins", Synonyms: []string{"celana jeans"}, Replace: true},
+	{Term: "tas ransel", Synonyms: []string{"backpack"}},
+	{Term: "jaket", Synonyms: []string{"jacket"}},
+}
+
+// Result is a normalized query. Unmatched holds the query words that no
+// dictionary term covered, which are candidates for typo correction.
+type Result struct {
+	Query     string
+	Unmatched []string
+}
+
+// Normalize lowercases the query, splits it into words and applies the
+// dictionaries. Longer terms win over shorter ones, and earlier dictionaries
+// win over later ones for the same term.
+func Normalize(query string, dictionaries ...Dictionary) Result {
+	entries := map[string]Entry{}
+	longest := 1
+	for _, dict := range dictionaries {
+		for _, e := range dict {
+			key := strings.Join(Tokenize(e.Term), " ")
+			if key == "" || len(e.Synonyms) == 0 {
+				continue
+			}
+			if _, exists := entries[key]; exists {
+				continue
+			}
+			entries[key] = e
+			if n := len(strings.Fields(key)); n > longest {
+				longest = n
+			}
+		}
+	}
+
+	tokens := Tokenize(query)
+	out := []string{}
+	seen := map[string]bool{}
+	add := func(s string) {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+
+	result := Result{}
+	for i := 0; i < len(tokens); {
+		matched := false
+		for n := min(longest, len(tokens)-i); n > 0; n-- {
+			phrase := strings.Join(tokens[i:i+n], " ")
+			e, ok := entries[phrase]
+			if !ok {
+				continue
+			}
+
+			if e.Replace {
+				add(e.Synonyms[0])
+			} else {
+				add(phrase)
+				for _, s := range e.Synonyms {
+					add(s)
+				}
+			}
+
+			i += n
+			matched = true
+			break
+		}
+
+		if !matched {
+			add(tokens[i])
+			result.Unmatched = append(result.Unmatched, tokens[i])
+			i++
+		}
+	}
+
+	result.Query = strings.Join(out, " ")
+	return result
+}
+
+// Tokenize lowercases text and splits it into words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// TypoCandidates returns the unmatched words worth checking for typos: long
+// enough to be a word and not a number such as a budget.
+func TypoCandidates(words []string) []string {
+	candidates := []string{}
+	for _, w := range words {
+		if len([]rune(w)) < 4 {
+			continue
+		}
+		if strings.IndexFunc(w, unicode.IsLetter) < 0 {
+			continue
+		}
+		candidates = append(candidates, w)
+	}
+	return candidates
+}
+
+// WithCorrections appends the corrected spelling after each misspelled word,
+// keeping the original in case it was intended.
+func WithCorrections(query string, corrections map[string]string) string {
+	if len(corrections) == 0 {
+		return query
+	}
+
+	words := strings.Fields(query)
+	out := make([]string, 0, len(words)+len(corrections))
+	for _, w := range words {
+		out = append(out, w)
+		if c, ok := corrections[w]; ok {
+			out = append(out, c)
+		}
+	}
+	return strings.Join(out, " ")
+}
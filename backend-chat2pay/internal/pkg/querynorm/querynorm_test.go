@@ -0,0 +1,52 @@
+package querynorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("Expands slang", func(t *testing.T) {
+		result := Normalize("HP murah", DefaultDictionary)
+
+		assert.Equal(t, "hp handphone smartphone murah", result.Query)
+		assert.Equal(t, []string{"murah"}, result.Unmatched)
+	})
+
+	t.Run("Replaces misspelling", func(t *testing.T) {
+		result := Normalize("leptop gaming", DefaultDictionary)
+
+		assert.Equal(t, "laptop gaming game", result.Query)
+		assert.Empty(t, result.Unmatched)
+	})
+
+	t.Run("Longest phrase wins", func(t *testing.T) {
+		result := Normalize("charger type-c 20W", DefaultDictionary)
+
+		assert.Equal(t, "charger type c usb-c usb type-c 20w", result.Query)
+	})
+
+	t.Run("Multi word term", func(t *testing.T) {
+		result := Normalize("sepatu lari ringan", DefaultDictionary)
+
+		assert.Equal(t, "sepatu lari running shoes sepatu olahraga ringan", result.Query)
+	})
+
+	t.Run("Earlier dictionary wins for the same term", func(t *testing.T) {
+		merchant := Dictionary{{Term: "hp", Synonyms: []string{"printer"}}}
+		result := Normalize("hp", merchant, DefaultDictionary)
+
+		assert.Equal(t, "hp printer", result.Query)
+	})
+}
+
+func TestTypoCandidates(t *testing.T) {
+	assert.Equal(t, []string{"kemeja", "sptu"}, TypoCandidates([]string{"kemeja", "di", "15000000", "sptu"}))
+}
+
+func TestWithCorrections(t *testing.T) {
+	query := WithCorrections("sptu lari", map[string]string{"sptu": "sepatu"})
+
+	assert.Equal(t, "sptu sepatu lari", query)
+}
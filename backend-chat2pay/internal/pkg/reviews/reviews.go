@@ -0,0 +1,97 @@
+package reviews
+
+import (
+	"chat2pay/internal/entities"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PriorCount and PriorRating pull the score of a product with few
+	// reviews towards an average product, so a single 5 star review does
+	// not outrank dozens of good ones.
+	PriorCount  = 5
+	PriorRating = 3.5
+
+	// RankWeight is how far reviews can move a product in search, against
+	// a similarity between 0 and 1.
+	RankWeight = 0.05
+
+	// MaxBodyLength bounds the text of a review in a summary.
+	MaxBodyLength = 200
+)
+
+// Score rates a product between 0 and 1 from its reviews, averaged with the
+// prior.
+func Score(rating entities.ProductRating) float64 {
+	count := float64(rating.Count)
+	return (rating.Average*count + PriorRating*PriorCount) / (count + PriorCount) / 5
+}
+
+// Boost adds the reviews of a product to its search similarity. Good
+// reviews raise it and bad ones lower it, a product without reviews keeps
+// its similarity.
+func Boost(similarity float64, rating entities.ProductRating) float64 {
+	return similarity + RankWeight*(Score(rating)-PriorRating/5)
+}
+
+// Rank orders the products by their similarity boosted with their reviews,
+// best first.
+func Rank(products []entities.Product, similarities map[string]float64) {
+	sort.SliceStable(products, func(i, j int) bool {
+		return Boost(similarities[products[i].ID], products[i].Rating) > Boost(similarities[products[j].ID], products[j].Rating)
+	})
+}
+
+// Short describes the rating of a product, e.g. "rating 4,6/5 dari 23
+// ulasan". It is empty when the product has no reviews.
+func Short(rating entities.ProductRating) string {
+	if rating.Count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("rating %s/5 dari %d ulasan", formatAverage(rating.Average), rating.Count)
+}
+
+// Summary describes the reviews of a product for the model answering
+// questions about it: the rating, then the given reviews with their text
+// and the reply of the merchant.
+func Summary(rating entities.ProductRating, recent []entities.ProductReview) string {
+	if rating.Count == 0 {
+		return "Belum ada ulasan pembeli untuk produk ini."
+	}
+
+	lines := []string{fmt.Sprintf("Rating %s dari 5 (%d ulasan).", formatAverage(rating.Average), rating.Count)}
+
+	quoted := []string{}
+	for _, review := range recent {
+		if review.Body == nil || strings.TrimSpace(*review.Body) == "" {
+			continue
+		}
+		line := fmt.Sprintf("- %d/5: %s", review.Rating, truncate(*review.Body))
+		if review.MerchantReply != nil {
+			line += " (balasan penjual: " + truncate(*review.MerchantReply) + ")"
+		}
+		quoted = append(quoted, line)
+	}
+	if len(quoted) > 0 {
+		lines = append(lines, "Ulasan terbaru:")
+		lines = append(lines, quoted...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatAverage(average float64) string {
+	return strings.Replace(strconv.FormatFloat(average, 'f', 1, 64), ".", ",", 1)
+}
+
+func truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= MaxBodyLength {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:MaxBodyLength])) + "..."
+}
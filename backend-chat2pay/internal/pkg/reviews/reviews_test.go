@@ -0,0 +1,82 @@
+package reviews
+
+import (
+	"chat2pay/internal/entities"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	none := entities.ProductRating{}
+	one := entities.ProductRating{Average: 5, Count: 1}
+	many := entities.ProductRating{Average: 4.8, Count: 40}
+	bad := entities.ProductRating{Average: 1.5, Count: 10}
+
+	assert.InDelta(t, PriorRating/5, Score(none), 1e-9)
+	assert.Greater(t, Score(many), Score(one))
+	assert.Less(t, Score(bad), Score(none))
+}
+
+func TestBoost(t *testing.T) {
+	t.Run("Keeps the similarity without reviews", func(t *testing.T) {
+		assert.InDelta(t, 0.8, Boost(0.8, entities.ProductRating{}), 1e-9)
+	})
+
+	t.Run("Moves the similarity by at most the weight", func(t *testing.T) {
+		best := Boost(0.8, entities.ProductRating{Average: 5, Count: 10000})
+		worst := Boost(0.8, entities.ProductRating{Average: 1, Count: 10000})
+
+		assert.Greater(t, best, 0.8)
+		assert.Less(t, worst, 0.8)
+		assert.LessOrEqual(t, best-worst, RankWeight)
+	})
+}
+
+func TestRank(t *testing.T) {
+	products := []entities.Product{
+		{ID: "a", Rating: entities.ProductRating{Average: 2, Count: 30}},
+		{ID: "b", Rating: entities.ProductRating{Average: 4.9, Count: 30}},
+		{ID: "c"},
+		{ID: "d", Rating: entities.ProductRating{Average: 5, Count: 50}},
+	}
+	similarities := map[string]float64{"a": 0.81, "b": 0.80, "c": 0.60, "d": 0.40}
+
+	Rank(products, similarities)
+
+	ids := []string{}
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	// Close matches swap on reviews, a far better match stays ahead
+	assert.Equal(t, []string{"b", "a", "c", "d"}, ids)
+}
+
+func TestShort(t *testing.T) {
+	assert.Equal(t, "rating 4,6/5 dari 23 ulasan", Short(entities.ProductRating{Average: 4.56, Count: 23}))
+	assert.Equal(t, "", Short(entities.ProductRating{}))
+}
+
+func TestSummary(t *testing.T) {
+	body := "Bagus,  cepat\nsampai"
+	empty := " "
+	reply := "Terima kasih kak!"
+	long := strings.Repeat("a", MaxBodyLength+50)
+
+	summary := Summary(entities.ProductRating{Average: 4.5, Count: 12}, []entities.ProductReview{
+		{Rating: 5, Body: &body, MerchantReply: &reply},
+		{Rating: 4, Body: &empty},
+		{Rating: 3},
+		{Rating: 4, Body: &long},
+	})
+
+	assert.Equal(t, strings.Join([]string{
+		"Rating 4,5 dari 5 (12 ulasan).",
+		"Ulasan terbaru:",
+		"- 5/5: Bagus, cepat sampai (balasan penjual: Terima kasih kak!)",
+		"- 4/5: " + strings.Repeat("a", MaxBodyLength) + "...",
+	}, "\n"), summary)
+
+	assert.Equal(t, "Belum ada ulasan pembeli untuk produk ini.", Summary(entities.ProductRating{}, nil))
+}
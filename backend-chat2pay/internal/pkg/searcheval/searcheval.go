@@ -0,0 +1,248 @@
+package searcheval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Dataset is a golden set of queries with the products a good search
+	// should return for them. Expected entries may be product IDs or SKUs.
+	Dataset struct {
+		Name    string `yaml:"name" json:"name"`
+		Queries []Case `yaml:"queries" json:"queries"`
+	}
+
+	Case struct {
+		ID       string   `yaml:"id" json:"id"`
+		Query    string   `yaml:"query" json:"query"`
+		Expected []string `yaml:"expected" json:"expected"`
+	}
+
+	// Hit is one ranked search result, identified by both ID and SKU so that
+	// either can be used in the dataset.
+	Hit struct {
+		ID  string `json:"id"`
+		SKU string `json:"sku,omitempty"`
+	}
+
+	QueryResult struct {
+		ID        string   `json:"id"`
+		Query     string   `json:"query"`
+		Expected  []string `json:"expected"`
+		Retrieved []string `json:"retrieved"`
+		Recall    float64  `json:"recall"`
+		MRR       float64  `json:"mrr"`
+		NDCG      float64  `json:"ndcg"`
+		Error     string   `json:"error,omitempty"`
+	}
+
+	Summary struct {
+		Recall float64 `json:"recall"`
+		MRR    float64 `json:"mrr"`
+		NDCG   float64 `json:"ndcg"`
+	}
+
+	// Report is the output of a run. It records the dataset checksum and the
+	// settings used so that two reports can be told apart and compared.
+	Report struct {
+		Dataset         string        `json:"dataset"`
+		DatasetChecksum string        `json:"dataset_checksum"`
+		Provider        string        `json:"provider"`
+		K               int           `json:"k"`
+		Queries         []QueryResult `json:"queries"`
+		Overall         Summary       `json:"overall"`
+	}
+)
+
+// LoadDataset reads a golden dataset from a YAML (or JSON) file.
+func LoadDataset(path string) (*Dataset, string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var dataset Dataset
+	if err := yaml.Unmarshal(raw, &dataset); err != nil {
+		return nil, "", err
+	}
+
+	if len(dataset.Queries) == 0 {
+		return nil, "", errors.New("dataset has no queries")
+	}
+
+	for i, c := range dataset.Queries {
+		if strings.TrimSpace(c.Query) == "" {
+			return nil, "", fmt.Errorf("query #%d has no text", i+1)
+		}
+		if len(c.Expected) == 0 {
+			return nil, "", fmt.Errorf("query %q has no expected products", c.Query)
+		}
+		if c.ID == "" {
+			dataset.Queries[i].ID = fmt.Sprintf("q%03d", i+1)
+		}
+	}
+
+	sum := sha256.Sum256(raw)
+	return &dataset, hex.EncodeToString(sum[:]), nil
+}
+
+// Score computes recall@k, reciprocal rank and nDCG@k of one ranked result
+// list with binary relevance. A k of 0 or less scores every hit. Returning
+// fewer than k hits does not lower the ideal ranking the hits are compared
+// to, the missing ranks count as misses.
+func Score(c Case, hits []Hit, k int) QueryResult {
+	relevant := make(map[string]bool, len(c.Expected))
+	for _, e := range c.Expected {
+		relevant[e] = true
+	}
+
+	if k <= 0 {
+		k = len(hits)
+	}
+	if k < len(hits) {
+		hits = hits[:k]
+	}
+
+	result := QueryResult{
+		ID:        c.ID,
+		Query:     c.Query,
+		Expected:  c.Expected,
+		Retrieved: make([]string, 0, len(hits)),
+	}
+
+	found := map[string]bool{}
+	var dcg float64
+	for i, hit := range hits {
+		key := matchKey(hit, relevant)
+		if hit.SKU != "" {
+			result.Retrieved = append(result.Retrieved, hit.SKU)
+		} else {
+			result.Retrieved = append(result.Retrieved, hit.ID)
+		}
+
+		if key == "" || found[key] {
+			continue
+		}
+		found[key] = true
+
+		if result.MRR == 0 {
+			result.MRR = 1 / float64(i+1)
+		}
+		dcg += 1 / math.Log2(float64(i+2))
+	}
+
+	result.Recall = float64(len(found)) / float64(len(relevant))
+
+	var idcg float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		result.NDCG = dcg / idcg
+	}
+
+	return result
+}
+
+func matchKey(hit Hit, relevant map[string]bool) string {
+	if relevant[hit.ID] {
+		return hit.ID
+	}
+	if hit.SKU != "" && relevant[hit.SKU] {
+		return hit.SKU
+	}
+	return ""
+}
+
+// Summarize averages the per-query metrics. Queries that failed count as
+// zero so that errors cannot make a run look better.
+func Summarize(results []QueryResult) Summary {
+	var summary Summary
+	if len(results) == 0 {
+		return summary
+	}
+
+	for _, r := range results {
+		summary.Recall += r.Recall
+		summary.MRR += r.MRR
+		summary.NDCG += r.NDCG
+	}
+
+	n := float64(len(results))
+	summary.Recall /= n
+	summary.MRR /= n
+	summary.NDCG /= n
+
+	return summary
+}
+
+// Print writes a per-query table and the overall scores. When baseline is not
+// nil, the change against it is shown next to each metric.
+func (r *Report) Print(w io.Writer, baseline *Report) {
+	previous := map[string]QueryResult{}
+	if baseline != nil {
+		for _, q := range baseline.Queries {
+			previous[q.ID] = q
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID\tQUERY\tRECALL@%d\tMRR\tNDCG@%d\n", r.K, r.K)
+
+	queries := append([]QueryResult(nil), r.Queries...)
+	sort.SliceStable(queries, func(i, j int) bool { return queries[i].ID < queries[j].ID })
+
+	for _, q := range queries {
+		query := q.Query
+		if q.Error != "" {
+			query += " (error: " + q.Error + ")"
+		}
+
+		prev, ok := previous[q.ID]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", q.ID, query,
+			metric(q.Recall, prev.Recall, ok),
+			metric(q.MRR, prev.MRR, ok),
+			metric(q.NDCG, prev.NDCG, ok),
+		)
+	}
+
+	fmt.Fprintf(tw, "\t\t\t\t\n")
+	fmt.Fprintf(tw, "OVERALL\t%d queries\t%s\t%s\t%s\n", len(r.Queries),
+		metric(r.Overall.Recall, overall(baseline).Recall, baseline != nil),
+		metric(r.Overall.MRR, overall(baseline).MRR, baseline != nil),
+		metric(r.Overall.NDCG, overall(baseline).NDCG, baseline != nil),
+	)
+	tw.Flush()
+
+	if baseline != nil && baseline.DatasetChecksum != r.DatasetChecksum {
+		fmt.Fprintln(w, "warning: baseline was produced from a different dataset")
+	}
+	if baseline != nil && baseline.K != r.K {
+		fmt.Fprintf(w, "warning: baseline used k=%d, this run used k=%d\n", baseline.K, r.K)
+	}
+}
+
+func overall(r *Report) Summary {
+	if r == nil {
+		return Summary{}
+	}
+	return r.Overall
+}
+
+func metric(value, previous float64, compare bool) string {
+	if !compare {
+		return fmt.Sprintf("%.3f", value)
+	}
+	return fmt.Sprintf("%.3f (%+.3f)", value, value-previous)
+}
@@ -0,0 +1,112 @@
+package searcheval
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	t.Run("Relevant product at the top", func(t *testing.T) {
+		c := Case{ID: "q1", Query: "laptop gaming", Expected: []string{"LAP-1"}}
+		hits := []Hit{{ID: "a", SKU: "LAP-1"}, {ID: "b", SKU: "LAP-2"}}
+
+		result := Score(c, hits, 5)
+		assert.Equal(t, 1.0, result.Recall)
+		assert.Equal(t, 1.0, result.MRR)
+		assert.InDelta(t, 1.0, result.NDCG, 1e-9)
+		assert.Equal(t, []string{"LAP-1", "LAP-2"}, result.Retrieved)
+	})
+
+	t.Run("Relevant product ranked second, matched by ID", func(t *testing.T) {
+		c := Case{ID: "q2", Query: "hp murah", Expected: []string{"b", "c"}}
+		hits := []Hit{{ID: "a"}, {ID: "b"}, {ID: "d"}}
+
+		result := Score(c, hits, 3)
+		assert.Equal(t, 0.5, result.Recall)
+		assert.Equal(t, 0.5, result.MRR)
+		// dcg = 1/log2(3), idcg = 1 + 1/log2(3)
+		assert.InDelta(t, 0.3869, result.NDCG, 1e-4)
+	})
+
+	t.Run("Only the first k results count", func(t *testing.T) {
+		c := Case{ID: "q3", Query: "sepatu lari", Expected: []string{"c"}}
+		hits := []Hit{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+		result := Score(c, hits, 2)
+		assert.Equal(t, 0.0, result.Recall)
+		assert.Equal(t, 0.0, result.MRR)
+		assert.Equal(t, 0.0, result.NDCG)
+	})
+
+	t.Run("Fewer results than k", func(t *testing.T) {
+		c := Case{ID: "q5", Query: "kamera mirrorless", Expected: []string{"a", "b", "c", "d", "e"}}
+		hits := []Hit{{ID: "a"}}
+
+		result := Score(c, hits, 10)
+		assert.Equal(t, 0.2, result.Recall)
+		assert.Equal(t, 1.0, result.MRR)
+		// dcg = 1, idcg = sum of 1/log2(i+2) for the 5 relevant products
+		assert.InDelta(t, 0.3392, result.NDCG, 1e-4)
+		assert.Equal(t, []string{"a"}, result.Retrieved)
+	})
+
+	t.Run("No results", func(t *testing.T) {
+		c := Case{ID: "q4", Query: "kulkas", Expected: []string{"x"}}
+
+		result := Score(c, nil, 10)
+		assert.Equal(t, 0.0, result.Recall)
+		assert.Empty(t, result.Retrieved)
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]QueryResult{
+		{Recall: 1, MRR: 1, NDCG: 1},
+		{Recall: 0, MRR: 0, NDCG: 0, Error: "timeout"},
+	})
+
+	assert.Equal(t, Summary{Recall: 0.5, MRR: 0.5, NDCG: 0.5}, summary)
+}
+
+func TestLoadDataset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "golden.yaml")
+	err := os.WriteFile(path, []byte(`
+name: sample
+queries:
+  - query: laptop gaming
+    expected: [LAP-1]
+  - id: hp
+    query: hp murah
+    expected: [HP-1, HP-2]
+`), 0o644)
+	assert.NoError(t, err)
+
+	dataset, checksum, err := LoadDataset(path)
+	assert.NoError(t, err)
+	assert.Len(t, checksum, 64)
+	assert.Equal(t, "q001", dataset.Queries[0].ID)
+	assert.Equal(t, "hp", dataset.Queries[1].ID)
+
+	err = os.WriteFile(path, []byte("queries:\n  - query: kosong\n"), 0o644)
+	assert.NoError(t, err)
+
+	_, _, err = LoadDataset(path)
+	assert.Error(t, err)
+}
+
+func TestReportPrint(t *testing.T) {
+	baseline := &Report{K: 5, DatasetChecksum: "abc", Queries: []QueryResult{{ID: "q1", Recall: 0.5}}, Overall: Summary{Recall: 0.5}}
+	report := &Report{K: 5, DatasetChecksum: "abc", Queries: []QueryResult{{ID: "q1", Query: "laptop", Recall: 1}}, Overall: Summary{Recall: 1}}
+
+	var out bytes.Buffer
+	report.Print(&out, baseline)
+
+	assert.Contains(t, out.String(), "1.000 (+0.500)")
+	assert.False(t, strings.Contains(out.String(), "warning"))
+}
@@ -0,0 +1,105 @@
+package shipping
+
+import (
+	"chat2pay/internal/pkg/rajaongkir"
+	"strings"
+)
+
+// DefaultWeight is the weight in grams quoted for packages without a known
+// weight, the shipping provider does not quote empty packages.
+const DefaultWeight = 1000
+
+// Quoter quotes shipping costs from the shipping provider. Without an API
+// key, or when the provider gives no result, it quotes flat rates by weight
+// instead, so GET /shipping/cost and the order checkout always agree on
+// the price of a courier service.
+type Quoter struct {
+	rajaOngkir *rajaongkir.RajaOngkir
+}
+
+func NewQuoter(apiKey string) *Quoter {
+	quoter := &Quoter{}
+	if apiKey != "" {
+		quoter.rajaOngkir = rajaongkir.NewRajaOngkir(apiKey)
+	}
+	return quoter
+}
+
+// Quote returns the courier services shipping a package of the weight, in
+// grams, from the origin to the destination city. An empty courier quotes
+// every courier.
+func (q *Quoter) Quote(origin, destination string, weight int, courier string) []rajaongkir.CostResult {
+	if weight <= 0 {
+		weight = DefaultWeight
+	}
+
+	if q.rajaOngkir != nil && origin != "" && destination != "" {
+		var (
+			results []rajaongkir.CostResult
+			err     error
+		)
+		if courier != "" {
+			results, err = q.rajaOngkir.GetCost(origin, destination, weight, courier)
+		} else {
+			results, err = q.rajaOngkir.GetAllCouriers(origin, destination, weight)
+		}
+		if err == nil && len(results) > 0 {
+			return results
+		}
+	}
+
+	return FlatRates(weight)
+}
+
+// FlatRates are the rates quoted when the shipping provider is not used.
+func FlatRates(weight int) []rajaongkir.CostResult {
+	base := weight * 10
+	if base < 9000 {
+		base = 9000
+	}
+
+	return []rajaongkir.CostResult{
+		{
+			Code: "jne",
+			Name: "Jalur Nugraha Ekakurir (JNE)",
+			Costs: []rajaongkir.ServiceCost{
+				{Service: "REG", Description: "Layanan Reguler", Cost: []rajaongkir.Cost{{Value: base, Etd: "2-3"}}},
+				{Service: "YES", Description: "Yakin Esok Sampai", Cost: []rajaongkir.Cost{{Value: base + 10000, Etd: "1"}}},
+			},
+		},
+		{
+			Code: "tiki",
+			Name: "Citra Van Titipan Kilat (TIKI)",
+			Costs: []rajaongkir.ServiceCost{
+				{Service: "REG", Description: "Regular Service", Cost: []rajaongkir.Cost{{Value: base - 1000, Etd: "3-4"}}},
+				{Service: "ONS", Description: "Over Night Service", Cost: []rajaongkir.Cost{{Value: base + 8000, Etd: "1"}}},
+			},
+		},
+		{
+			Code: "sicepat",
+			Name: "SiCepat Express",
+			Costs: []rajaongkir.ServiceCost{
+				{Service: "REG", Description: "Reguler", Cost: []rajaongkir.Cost{{Value: base - 2000, Etd: "2-3"}}},
+				{Service: "BEST", Description: "Besok Sampai Tujuan", Cost: []rajaongkir.Cost{{Value: base + 5000, Etd: "1"}}},
+			},
+		},
+	}
+}
+
+// Find returns the cost of the service of the courier in the quote, or
+// false when the quote does not offer it. Couriers and services are matched
+// ignoring case.
+func Find(results []rajaongkir.CostResult, courier, service string) (rajaongkir.Cost, bool) {
+	for _, result := range results {
+		if !strings.EqualFold(result.Code, strings.TrimSpace(courier)) {
+			continue
+		}
+		for _, cost := range result.Costs {
+			if strings.EqualFold(cost.Service, strings.TrimSpace(service)) && len(cost.Cost) > 0 {
+				return cost.Cost[0], true
+			}
+		}
+	}
+
+	return rajaongkir.Cost{}, false
+}
@@ -0,0 +1,48 @@
+package shipping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	quoter := NewQuoter("")
+
+	t.Run("Quotes flat rates without the provider", func(t *testing.T) {
+		cost, ok := Find(quoter.Quote("114", "501", 2500, "jne"), "jne", "REG")
+		assert.True(t, ok)
+		assert.Equal(t, 25000, cost.Value)
+		assert.Equal(t, "2-3", cost.Etd)
+	})
+
+	t.Run("Quotes the minimum rate for light packages", func(t *testing.T) {
+		cost, ok := Find(quoter.Quote("114", "501", 300, ""), "tiki", "ONS")
+		assert.True(t, ok)
+		assert.Equal(t, 17000, cost.Value)
+	})
+
+	t.Run("Quotes packages without weight at the default weight", func(t *testing.T) {
+		unknown, _ := Find(quoter.Quote("", "501", 0, ""), "sicepat", "BEST")
+		standard, _ := Find(quoter.Quote("", "501", DefaultWeight, ""), "sicepat", "BEST")
+		assert.Equal(t, standard, unknown)
+	})
+}
+
+func TestFind(t *testing.T) {
+	results := FlatRates(1000)
+
+	t.Run("Ignores case", func(t *testing.T) {
+		cost, ok := Find(results, "JNE", "yes")
+		assert.True(t, ok)
+		assert.Equal(t, 20000, cost.Value)
+	})
+
+	t.Run("Rejects services the courier does not offer", func(t *testing.T) {
+		_, ok := Find(results, "jne", "ONS")
+		assert.False(t, ok)
+
+		_, ok = Find(results, "pos", "REG")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir, "/uploads/")
+	ctx := context.Background()
+
+	t.Run("Put and Delete", func(t *testing.T) {
+		url, err := s.Put(ctx, "products/p1/a.jpg", strings.NewReader("data"), 4, "image/jpeg")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/uploads/products/p1/a.jpg", url)
+
+		content, err := os.ReadFile(filepath.Join(dir, "products", "p1", "a.jpg"))
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(content))
+
+		assert.NoError(t, s.Delete(ctx, "products/p1/a.jpg"))
+		assert.NoError(t, s.Delete(ctx, "products/p1/a.jpg"))
+	})
+
+	t.Run("Rejects keys outside the directory", func(t *testing.T) {
+		_, err := s.Put(ctx, "../escape.jpg", strings.NewReader("data"), 4, "image/jpeg")
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"chat2pay/config/yaml"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Storage keeps uploaded files and returns the public URL they are served
+// from. Keys are slash separated paths such as "products/<id>/<file>.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func NewStorage(cfg yaml.Storage) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Local.Dir, cfg.Local.BaseURL), nil
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
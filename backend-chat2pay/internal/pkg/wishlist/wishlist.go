@@ -0,0 +1,54 @@
+package wishlist
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/pricing"
+	"fmt"
+)
+
+// Notification tells the customer what changed about a wishlisted product
+// since it was last seen. It is nil when there is nothing to tell: the
+// price did not drop and the product did not come back in stock.
+// A restock is told with the price, lower or not, so a product coming back
+// cheaper gives a single notification.
+func Notification(item entities.WishlistItem, product entities.Product, price float64) *entities.CustomerNotification {
+	inStock := product.Stock > 0
+	dropped := price < item.LastPrice
+
+	referenceType := "product"
+	notification := &entities.CustomerNotification{
+		CustomerID:    item.CustomerID,
+		ReferenceType: &referenceType,
+		ReferenceID:   &item.ProductID,
+	}
+
+	switch {
+	case inStock && !item.InStock:
+		notification.Type = entities.NotificationBackInStock
+		notification.Title = product.Name + " tersedia lagi"
+		notification.Message = fmt.Sprintf("%s di wishlist kamu sudah tersedia lagi dengan harga %s.", product.Name, pricing.Rupiah(price))
+		if dropped {
+			notification.Message = fmt.Sprintf("%s di wishlist kamu sudah tersedia lagi, sekarang %s dari sebelumnya %s.",
+				product.Name, pricing.Rupiah(price), pricing.Rupiah(item.LastPrice))
+		}
+	case inStock && dropped:
+		notification.Type = entities.NotificationPriceDrop
+		notification.Title = "Harga " + product.Name + " turun"
+		notification.Message = fmt.Sprintf("%s di wishlist kamu sekarang %s dari sebelumnya %s.",
+			product.Name, pricing.Rupiah(price), pricing.Rupiah(item.LastPrice))
+	default:
+		return nil
+	}
+
+	return notification
+}
+
+// Seen returns the item as it is seen now. The price of a sold out product
+// is not taken, so a restock is told against the price last seen in stock.
+func Seen(item entities.WishlistItem, product entities.Product, price float64) entities.WishlistItem {
+	item.InStock = product.Stock > 0
+	if item.InStock {
+		item.LastPrice = price
+	}
+	return item
+}
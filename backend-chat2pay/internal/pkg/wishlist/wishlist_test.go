@@ -0,0 +1,59 @@
+package wishlist
+
+import (
+	"chat2pay/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotification(t *testing.T) {
+	item := entities.WishlistItem{CustomerID: "c1", ProductID: "p1", LastPrice: 1500000, InStock: true}
+	product := entities.Product{ID: "p1", Name: "Laptop ASUS", Stock: 3}
+
+	t.Run("Tells a price drop", func(t *testing.T) {
+		notification := Notification(item, product, 1250000)
+
+		assert.Equal(t, entities.NotificationPriceDrop, notification.Type)
+		assert.Equal(t, "c1", notification.CustomerID)
+		assert.Equal(t, "p1", *notification.ReferenceID)
+		assert.Contains(t, notification.Message, "Rp 1.250.000 dari sebelumnya Rp 1.500.000")
+	})
+
+	t.Run("Keeps quiet when the price stays or goes up", func(t *testing.T) {
+		assert.Nil(t, Notification(item, product, 1500000))
+		assert.Nil(t, Notification(item, product, 1600000))
+	})
+
+	t.Run("Keeps quiet about a drop while sold out", func(t *testing.T) {
+		soldOut := product
+		soldOut.Stock = 0
+
+		assert.Nil(t, Notification(item, soldOut, 1000000))
+	})
+
+	t.Run("Tells a restock with the price", func(t *testing.T) {
+		outOfStock := item
+		outOfStock.InStock = false
+
+		notification := Notification(outOfStock, product, 1500000)
+		assert.Equal(t, entities.NotificationBackInStock, notification.Type)
+		assert.Contains(t, notification.Message, "harga Rp 1.500.000")
+
+		cheaper := Notification(outOfStock, product, 1250000)
+		assert.Equal(t, entities.NotificationBackInStock, cheaper.Type)
+		assert.Contains(t, cheaper.Message, "Rp 1.250.000 dari sebelumnya Rp 1.500.000")
+	})
+}
+
+func TestSeen(t *testing.T) {
+	item := entities.WishlistItem{LastPrice: 1500000, InStock: true}
+
+	seen := Seen(item, entities.Product{Stock: 0}, 1000000)
+	assert.False(t, seen.InStock)
+	assert.Equal(t, 1500000.0, seen.LastPrice)
+
+	seen = Seen(seen, entities.Product{Stock: 2}, 1000000)
+	assert.True(t, seen.InStock)
+	assert.Equal(t, 1000000.0, seen.LastPrice)
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+type CartRepository interface {
+	Add(ctx context.Context, item *entities.CartItem) error
+	FindOne(ctx context.Context, customerId, id string) (*entities.CartItem, error)
+	FindByCustomerID(ctx context.Context, customerId string) ([]entities.CartItem, error)
+	UpdateQuantity(ctx context.Context, customerId, id string, quantity int) error
+	Remove(ctx context.Context, customerId, id string) (bool, error)
+}
+
+const cartColumns = `id, customer_id, product_id, variant_id, quantity, created_at, updated_at`
+
+type cartRepository struct {
+	DB *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) CartRepository {
+	return &cartRepository{DB: db}
+}
+
+// Add puts the item in the cart of the customer. When the product, or the
+// variant, is already in the cart its quantity is raised instead, and the
+// item is returned with the quantity in the cart.
+func (r *cartRepository) Add(ctx context.Context, item *entities.CartItem) error {
+	query := `
+		INSERT INTO cart_items (customer_id, product_id, variant_id, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (customer_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+		DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = NOW()
+		RETURNING id, quantity, created_at, updated_at;
+	`
+
+	return r.DB.QueryRowContext(ctx, query,
+		item.CustomerID,
+		item.ProductID,
+		item.VariantID,
+		item.Quantity,
+	).Scan(&item.ID, &item.Quantity, &item.CreatedAt, &item.UpdatedAt)
+}
+
+func (r *cartRepository) FindOne(ctx context.Context, customerId, id string) (*entities.CartItem, error) {
+	var item entities.CartItem
+
+	err := r.DB.GetContext(ctx, &item,
+		`SELECT `+cartColumns+` FROM cart_items WHERE customer_id = $1 AND id::text = $2`,
+		customerId, id,
+	)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *cartRepository) FindByCustomerID(ctx context.Context, customerId string) ([]entities.CartItem, error) {
+	items := []entities.CartItem{}
+
+	query := `
+		SELECT ` + cartColumns + `
+		FROM cart_items
+		WHERE customer_id = $1
+		ORDER BY created_at, id;
+	`
+
+	err := r.DB.SelectContext(ctx, &items, query, customerId)
+	return items, err
+}
+
+func (r *cartRepository) UpdateQuantity(ctx context.Context, customerId, id string, quantity int) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE cart_items SET quantity = $1, updated_at = NOW() WHERE customer_id = $2 AND id::text = $3`,
+		quantity, customerId, id,
+	)
+	return err
+}
+
+// Remove takes the item out of the cart of the customer. It reports false
+// when it was not in it.
+func (r *cartRepository) Remove(ctx context.Context, customerId, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx,
+		`DELETE FROM cart_items WHERE customer_id = $1 AND id::text = $2`,
+		customerId, id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error)
+	FindAll(ctx context.Context) ([]entities.ProductCategory, error)
+	FindOneById(ctx context.Context, id string) (*entities.ProductCategory, error)
+	FindAncestors(ctx context.Context, id string) ([]entities.ProductCategory, error)
+	FindDescendantIDs(ctx context.Context, id string) ([]string, error)
+	Update(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error)
+	Delete(ctx context.Context, id string) error
+	CountChildren(ctx context.Context, id string) (int64, error)
+}
+
+type categoryRepository struct {
+	DB *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) CategoryRepository {
+	return &categoryRepository{DB: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error) {
+	query := `
+		INSERT INTO product_categories (id, name, parent_id, attribute_schema)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		category.Name,
+		category.ParentID,
+		category.AttributeSchema,
+	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+
+	return category, err
+}
+
+func (r *categoryRepository) FindAll(ctx context.Context) ([]entities.ProductCategory, error) {
+	categories := []entities.ProductCategory{}
+
+	query := `
+		SELECT id, name, parent_id, attribute_schema, created_at, updated_at
+		FROM product_categories
+		ORDER BY name ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &categories, query)
+	return categories, err
+}
+
+func (r *categoryRepository) FindOneById(ctx context.Context, id string) (*entities.ProductCategory, error) {
+	var category entities.ProductCategory
+
+	query := `
+		SELECT id, name, parent_id, attribute_schema, created_at, updated_at
+		FROM product_categories WHERE id = $1 LIMIT 1;
+	`
+
+	err := r.DB.GetContext(ctx, &category, query, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// FindAncestors returns the category and its ancestors, root first.
+func (r *categoryRepository) FindAncestors(ctx context.Context, id string) ([]entities.ProductCategory, error) {
+	categories := []entities.ProductCategory{}
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, name, parent_id, attribute_schema, created_at, updated_at, 0 AS depth
+			FROM product_categories WHERE id = $1
+			UNION ALL
+			SELECT c.id, c.name, c.parent_id, c.attribute_schema, c.created_at, c.updated_at, a.depth + 1
+			FROM product_categories c
+			JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT id, name, parent_id, attribute_schema, created_at, updated_at
+		FROM ancestors
+		ORDER BY depth DESC;
+	`
+
+	err := r.DB.SelectContext(ctx, &categories, query, id)
+	return categories, err
+}
+
+// FindDescendantIDs returns the id of the category and of every category
+// below it.
+func (r *categoryRepository) FindDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	ids := []string{}
+
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM product_categories WHERE id = $1
+			UNION
+			SELECT c.id
+			FROM product_categories c
+			JOIN descendants d ON c.parent_id = d.id
+		)
+		SELECT id FROM descendants;
+	`
+
+	err := r.DB.SelectContext(ctx, &ids, query, id)
+	return ids, err
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error) {
+	query := `
+		UPDATE product_categories
+		SET name = $1, parent_id = $2, attribute_schema = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		category.Name,
+		category.ParentID,
+		category.AttributeSchema,
+		category.ID,
+	).Scan(&category.UpdatedAt)
+
+	return category, err
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM product_categories WHERE id = $1`, id)
+	return err
+}
+
+func (r *categoryRepository) CountChildren(ctx context.Context, id string) (int64, error) {
+	var count int64
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM product_categories WHERE parent_id = $1`, id)
+	return count, err
+}
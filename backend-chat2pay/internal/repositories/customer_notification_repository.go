@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+// CustomerNotificationRepository reads the notifications of a customer.
+// Notifications are added by notifyCustomer, in the transaction of the
+// change they are about.
+type CustomerNotificationRepository interface {
+	FindByCustomerID(ctx context.Context, customerId string, unreadOnly bool, limit, offset int) ([]entities.CustomerNotification, error)
+	CountByCustomerID(ctx context.Context, customerId string, unreadOnly bool) (int64, error)
+	MarkRead(ctx context.Context, customerId, id string) (bool, error)
+	MarkAllRead(ctx context.Context, customerId string) error
+}
+
+type customerNotificationRepository struct {
+	DB *sqlx.DB
+}
+
+func NewCustomerNotificationRepository(db *sqlx.DB) CustomerNotificationRepository {
+	return &customerNotificationRepository{DB: db}
+}
+
+func (r *customerNotificationRepository) FindByCustomerID(ctx context.Context, customerId string, unreadOnly bool, limit, offset int) ([]entities.CustomerNotification, error) {
+	notifications := []entities.CustomerNotification{}
+
+	query := `
+		SELECT id, customer_id, type, title, message, reference_type, reference_id, read_at, created_at
+		FROM customer_notifications
+		WHERE customer_id = $1
+		AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4;
+	`
+
+	err := r.DB.SelectContext(ctx, &notifications, query, customerId, unreadOnly, limit, offset)
+	return notifications, err
+}
+
+func (r *customerNotificationRepository) CountByCustomerID(ctx context.Context, customerId string, unreadOnly bool) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT COUNT(*) FROM customer_notifications
+		WHERE customer_id = $1
+		AND (NOT $2 OR read_at IS NULL);
+	`
+
+	err := r.DB.GetContext(ctx, &count, query, customerId, unreadOnly)
+	return count, err
+}
+
+// MarkRead marks the notification of the customer as read. It reports
+// false when the customer has no such notification.
+func (r *customerNotificationRepository) MarkRead(ctx context.Context, customerId, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE customer_notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id::text = $1 AND customer_id = $2;
+	`, id, customerId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *customerNotificationRepository) MarkAllRead(ctx context.Context, customerId string) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE customer_notifications SET read_at = NOW() WHERE customer_id = $1 AND read_at IS NULL`,
+		customerId,
+	)
+	return err
+}
+
+// notifyCustomer adds the notification for its customer.
+func notifyCustomer(ctx context.Context, tx *sqlx.Tx, notification *entities.CustomerNotification) error {
+	query := `
+		INSERT INTO customer_notifications (customer_id, type, title, message, reference_type, reference_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		notification.CustomerID,
+		notification.Type,
+		notification.Title,
+		notification.Message,
+		notification.ReferenceType,
+		notification.ReferenceID,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
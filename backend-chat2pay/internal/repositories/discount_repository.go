@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strings"
+)
+
+type DiscountRepository interface {
+	Create(ctx context.Context, discount *entities.Discount) (*entities.Discount, error)
+	Update(ctx context.Context, discount *entities.Discount) (*entities.Discount, error)
+	Delete(ctx context.Context, merchantId, id string) (bool, error)
+	FindOneById(ctx context.Context, merchantId, id string) (*entities.Discount, error)
+	Find(ctx context.Context, filter DiscountFilter, limit, offset int) ([]entities.Discount, error)
+	Count(ctx context.Context, filter DiscountFilter) (int64, error)
+	FindRunningByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductDiscount, error)
+}
+
+// DiscountFilter narrows down a discount listing. Empty fields do not
+// filter. State is running, scheduled or ended.
+type DiscountFilter struct {
+	MerchantID string
+	ProductID  string
+	CategoryID string
+	State      string
+}
+
+func (f DiscountFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s::text = $%d", column, len(args)))
+		}
+	}
+	add("merchant_id", f.MerchantID)
+	add("product_id", f.ProductID)
+	add("category_id", f.CategoryID)
+
+	switch f.State {
+	case entities.DiscountStateRunning:
+		conditions = append(conditions, "starts_at <= NOW() AND ends_at > NOW()")
+	case entities.DiscountStateScheduled:
+		conditions = append(conditions, "starts_at > NOW()")
+	case entities.DiscountStateEnded:
+		conditions = append(conditions, "ends_at <= NOW()")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+const discountColumns = `id, merchant_id, product_id, category_id, name, type, value, starts_at, ends_at, created_at, updated_at`
+
+type discountRepository struct {
+	DB *sqlx.DB
+}
+
+func NewDiscountRepository(db *sqlx.DB) DiscountRepository {
+	return &discountRepository{DB: db}
+}
+
+func (r *discountRepository) Create(ctx context.Context, discount *entities.Discount) (*entities.Discount, error) {
+	query := `
+		INSERT INTO discounts (id, merchant_id, product_id, category_id, name, type, value, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		discount.MerchantID,
+		discount.ProductID,
+		discount.CategoryID,
+		discount.Name,
+		discount.Type,
+		discount.Value,
+		discount.StartsAt,
+		discount.EndsAt,
+	).Scan(&discount.ID, &discount.CreatedAt, &discount.UpdatedAt)
+
+	return discount, err
+}
+
+func (r *discountRepository) Update(ctx context.Context, discount *entities.Discount) (*entities.Discount, error) {
+	query := `
+		UPDATE discounts
+		SET product_id = $1, category_id = $2, name = $3, type = $4, value = $5,
+			starts_at = $6, ends_at = $7, updated_at = NOW()
+		WHERE id = $8 AND merchant_id = $9
+		RETURNING updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		discount.ProductID,
+		discount.CategoryID,
+		discount.Name,
+		discount.Type,
+		discount.Value,
+		discount.StartsAt,
+		discount.EndsAt,
+		discount.ID,
+		discount.MerchantID,
+	).Scan(&discount.UpdatedAt)
+
+	return discount, err
+}
+
+// Delete removes the discount of the merchant. Orders that got the discount
+// keep the price they were charged. It reports false when the merchant has
+// no such discount.
+func (r *discountRepository) Delete(ctx context.Context, merchantId, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM discounts WHERE id::text = $1 AND merchant_id = $2`, id, merchantId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *discountRepository) FindOneById(ctx context.Context, merchantId, id string) (*entities.Discount, error) {
+	var discount entities.Discount
+
+	query := `SELECT ` + discountColumns + ` FROM discounts WHERE id::text = $1 AND merchant_id = $2`
+
+	err := r.DB.GetContext(ctx, &discount, query, id, merchantId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &discount, nil
+}
+
+func (r *discountRepository) Find(ctx context.Context, filter DiscountFilter, limit, offset int) ([]entities.Discount, error) {
+	discounts := []entities.Discount{}
+	where, args := filter.where()
+
+	query := `SELECT ` + discountColumns + ` FROM discounts WHERE ` + where + fmt.Sprintf(`
+		ORDER BY starts_at DESC, id
+		LIMIT $%d OFFSET $%d;
+	`, len(args)+1, len(args)+2)
+
+	err := r.DB.SelectContext(ctx, &discounts, query, append(args, limit, offset)...)
+	return discounts, err
+}
+
+func (r *discountRepository) Count(ctx context.Context, filter DiscountFilter) (int64, error) {
+	var count int64
+	where, args := filter.where()
+
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM discounts WHERE `+where, args...)
+	return count, err
+}
+
+// FindRunningByProductIDs returns the discounts running now for each of the
+// products, set on the product itself or on its category or one of the
+// ancestors of its category. A product can have several.
+func (r *discountRepository) FindRunningByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductDiscount, error) {
+	discounts := []entities.ProductDiscount{}
+	if len(productIds) == 0 {
+		return discounts, nil
+	}
+
+	query := `
+		WITH RECURSIVE product_category_tree AS (
+			SELECT p.id AS product_id, c.id AS category_id, c.parent_id
+			FROM product p
+			JOIN product_categories c ON c.id = p.category_id
+			WHERE p.id = ANY($1)
+			UNION ALL
+			SELECT t.product_id, c.id, c.parent_id
+			FROM product_category_tree t
+			JOIN product_categories c ON c.id = t.parent_id
+		)
+		SELECT
+			p.id AS applies_to, d.id, d.merchant_id, d.product_id, d.category_id, d.name, d.type, d.value,
+			d.starts_at, d.ends_at, d.created_at, d.updated_at
+		FROM product p
+		JOIN discounts d ON d.merchant_id = p.merchant_id
+		WHERE p.id = ANY($1)
+		AND d.starts_at <= NOW() AND d.ends_at > NOW()
+		AND (
+			d.product_id = p.id
+			OR d.category_id IN (SELECT t.category_id FROM product_category_tree t WHERE t.product_id = p.id)
+		);
+	`
+
+	err := r.DB.SelectContext(ctx, &discounts, query, pq.Array(productIds))
+	return discounts, err
+}
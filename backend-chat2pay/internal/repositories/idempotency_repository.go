@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, key *entities.IdempotencyKey) (*entities.IdempotencyKey, error)
+	Complete(ctx context.Context, scope, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+type idempotencyRepository struct {
+	DB *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) IdempotencyRepository {
+	return &idempotencyRepository{DB: db}
+}
+
+// Reserve claims the key for a request about to run. It returns nil when
+// the key was free, or the key as stored when another request holds it,
+// running or done. Expired keys of the scope are cleared first, so a key
+// can be used again once it expires.
+func (r *idempotencyRepository) Reserve(ctx context.Context, key *entities.IdempotencyKey) (*entities.IdempotencyKey, error) {
+	_, err := r.DB.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND expires_at < NOW()`,
+		key.Scope,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO NOTHING
+		RETURNING created_at;
+	`
+	err = r.DB.QueryRowContext(ctx, query, key.Scope, key.Key, key.RequestHash, key.ExpiresAt).Scan(&key.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var existing entities.IdempotencyKey
+	err = r.DB.GetContext(ctx, &existing, `
+		SELECT scope, key, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2`,
+		key.Scope, key.Key,
+	)
+	if err != nil {
+		// Released by its request in the meantime, it is reported as
+		// still running and the retry will find it free
+		if errors.Is(err, sql.ErrNoRows) {
+			return &entities.IdempotencyKey{Scope: key.Scope, Key: key.Key, RequestHash: key.RequestHash}, nil
+		}
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+// Complete stores the response of the request holding the key.
+func (r *idempotencyRepository) Complete(ctx context.Context, scope, key string, statusCode int, body []byte) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE idempotency_keys SET status_code = $1, response_body = $2 WHERE scope = $3 AND key = $4`,
+		statusCode, body, scope, key,
+	)
+	return err
+}
+
+// Release frees the key of a request that failed, so it can be retried.
+func (r *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.DB.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`,
+		scope, key,
+	)
+	return err
+}
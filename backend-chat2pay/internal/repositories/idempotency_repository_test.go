@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyKey(t *testing.T, expiresIn time.Duration) *entities.IdempotencyKey {
+	return &entities.IdempotencyKey{
+		Scope:       "customer:" + uuid.New().String() + " POST /api/orders/",
+		Key:         uuid.New().String(),
+		RequestHash: "a665a45920422f9d417e4867efdc4fb8a04a1f3fff1fa07e998e86f7f7a27ae3",
+		ExpiresAt:   time.Now().Add(expiresIn),
+	}
+}
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	db := testDB(t)
+	repo := NewIdempotencyRepository(db)
+	ctx := context.Background()
+
+	t.Run("Only one of concurrent requests reserves the key", func(t *testing.T) {
+		key := newIdempotencyKey(t, time.Hour)
+		t.Cleanup(func() { repo.Release(ctx, key.Scope, key.Key) })
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			reserved int
+		)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempt := *key
+				existing, err := repo.Reserve(ctx, &attempt)
+				assert.NoError(t, err)
+				if err == nil && existing == nil {
+					mu.Lock()
+					reserved++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, reserved)
+	})
+
+	t.Run("Returns the stored response once completed", func(t *testing.T) {
+		key := newIdempotencyKey(t, time.Hour)
+		t.Cleanup(func() { repo.Release(ctx, key.Scope, key.Key) })
+
+		existing, err := repo.Reserve(ctx, key)
+		require.NoError(t, err)
+		require.Nil(t, existing)
+
+		existing, err = repo.Reserve(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.Nil(t, existing.StatusCode)
+
+		require.NoError(t, repo.Complete(ctx, key.Scope, key.Key, 201, []byte(`{"success":true}`)))
+
+		existing, err = repo.Reserve(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		require.NotNil(t, existing.StatusCode)
+		assert.Equal(t, 201, *existing.StatusCode)
+		assert.Equal(t, `{"success":true}`, string(existing.ResponseBody))
+		assert.Equal(t, key.RequestHash, existing.RequestHash)
+	})
+
+	t.Run("Frees released and expired keys", func(t *testing.T) {
+		released := newIdempotencyKey(t, time.Hour)
+		t.Cleanup(func() { repo.Release(ctx, released.Scope, released.Key) })
+
+		_, err := repo.Reserve(ctx, released)
+		require.NoError(t, err)
+		require.NoError(t, repo.Release(ctx, released.Scope, released.Key))
+
+		existing, err := repo.Reserve(ctx, released)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+
+		expired := newIdempotencyKey(t, -time.Hour)
+		t.Cleanup(func() { repo.Release(ctx, expired.Scope, expired.Key) })
+
+		_, err = repo.Reserve(ctx, expired)
+		require.NoError(t, err)
+		require.NoError(t, repo.Complete(ctx, expired.Scope, expired.Key, 201, []byte(`{}`)))
+
+		expired.ExpiresAt = time.Now().Add(time.Hour)
+		existing, err = repo.Reserve(ctx, expired)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+// MerchantNotificationRepository reads the notifications of a merchant.
+// Notifications are added by notifyMerchant, in the transaction of the
+// change they are about.
+type MerchantNotificationRepository interface {
+	FindByMerchantID(ctx context.Context, merchantId string, unreadOnly bool, limit, offset int) ([]entities.MerchantNotification, error)
+	CountByMerchantID(ctx context.Context, merchantId string, unreadOnly bool) (int64, error)
+	MarkRead(ctx context.Context, merchantId, id string) (bool, error)
+	MarkAllRead(ctx context.Context, merchantId string) error
+}
+
+type merchantNotificationRepository struct {
+	DB *sqlx.DB
+}
+
+func NewMerchantNotificationRepository(db *sqlx.DB) MerchantNotificationRepository {
+	return &merchantNotificationRepository{DB: db}
+}
+
+func (r *merchantNotificationRepository) FindByMerchantID(ctx context.Context, merchantId string, unreadOnly bool, limit, offset int) ([]entities.MerchantNotification, error) {
+	notifications := []entities.MerchantNotification{}
+
+	query := `
+		SELECT id, merchant_id, type, title, message, reference_type, reference_id, read_at, created_at
+		FROM merchant_notifications
+		WHERE merchant_id = $1
+		AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4;
+	`
+
+	err := r.DB.SelectContext(ctx, &notifications, query, merchantId, unreadOnly, limit, offset)
+	return notifications, err
+}
+
+func (r *merchantNotificationRepository) CountByMerchantID(ctx context.Context, merchantId string, unreadOnly bool) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT COUNT(*) FROM merchant_notifications
+		WHERE merchant_id = $1
+		AND (NOT $2 OR read_at IS NULL);
+	`
+
+	err := r.DB.GetContext(ctx, &count, query, merchantId, unreadOnly)
+	return count, err
+}
+
+// MarkRead marks the notification of the merchant as read. It reports
+// false when the merchant has no such notification.
+func (r *merchantNotificationRepository) MarkRead(ctx context.Context, merchantId, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE merchant_notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id::text = $1 AND merchant_id = $2;
+	`, id, merchantId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *merchantNotificationRepository) MarkAllRead(ctx context.Context, merchantId string) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE merchant_notifications SET read_at = NOW() WHERE merchant_id = $1 AND read_at IS NULL`,
+		merchantId,
+	)
+	return err
+}
+
+// notifyMerchant adds the notification for its merchant.
+func notifyMerchant(ctx context.Context, tx *sqlx.Tx, notification *entities.MerchantNotification) error {
+	query := `
+		INSERT INTO merchant_notifications (merchant_id, type, title, message, reference_type, reference_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		notification.MerchantID,
+		notification.Type,
+		notification.Title,
+		notification.Message,
+		notification.ReferenceType,
+		notification.ReferenceID,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
@@ -28,8 +28,8 @@ func NewMerchantRepo(db *sqlx.DB) MerchantRepository {
 
 func (r *merchantRepository) Create(ctx context.Context, merchant *entities.Merchant) (*entities.Merchant, error) {
 	query := `
-		INSERT INTO merchants (name, legal_name, email, phone, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO merchants (name, legal_name, email, phone, city_id, city_name, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at;
 	`
 
@@ -38,6 +38,8 @@ func (r *merchantRepository) Create(ctx context.Context, merchant *entities.Merc
 		merchant.LegalName,
 		merchant.Email,
 		merchant.Phone,
+		merchant.CityID,
+		merchant.CityName,
 		merchant.Status,
 	).Scan(&merchant.ID, &merchant.CreatedAt, &merchant.UpdatedAt)
 
@@ -49,7 +51,7 @@ func (r *merchantRepository) FindOneById(ctx context.Context, id string) (*entit
 
 	query := `
 		SELECT 
-			id, name, legal_name, email, phone, status, created_at, updated_at
+			id, name, legal_name, email, phone, city_id, city_name, status, created_at, updated_at
 		FROM merchants
 		WHERE id = $1
 		LIMIT 1;
@@ -71,7 +73,7 @@ func (r *merchantRepository) FindAll(ctx context.Context, limit, offset int) ([]
 
 	query := `
 		SELECT 
-			id, name, legal_name, email, phone, status, created_at, updated_at
+			id, name, legal_name, email, phone, city_id, city_name, status, created_at, updated_at
 		FROM merchants
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2;
@@ -86,7 +88,7 @@ func (r *merchantRepository) FindOneByEmail(ctx context.Context, email string) (
 
 	query := `
 		SELECT 
-			id, name, legal_name, email, phone, status, created_at, updated_at
+			id, name, legal_name, email, phone, city_id, city_name, status, created_at, updated_at
 		FROM merchants
 		WHERE email = $1
 		LIMIT 1;
@@ -106,8 +108,8 @@ func (r *merchantRepository) FindOneByEmail(ctx context.Context, email string) (
 func (r *merchantRepository) Update(ctx context.Context, merchant *entities.Merchant) (*entities.Merchant, error) {
 	query := `
 		UPDATE merchants
-		SET name=$1, legal_name=$2, email=$3, phone=$4, status=$5, updated_at=NOW()
-		WHERE id = $6
+		SET name=$1, legal_name=$2, email=$3, phone=$4, city_id=$5, city_name=$6, status=$7, updated_at=NOW()
+		WHERE id = $8
 		RETURNING updated_at;
 	`
 
@@ -116,6 +118,8 @@ func (r *merchantRepository) Update(ctx context.Context, merchant *entities.Merc
 		merchant.LegalName,
 		merchant.Email,
 		merchant.Phone,
+		merchant.CityID,
+		merchant.CityName,
 		merchant.Status,
 		merchant.ID,
 	).Scan(&merchant.UpdatedAt)
@@ -3,19 +3,32 @@ package repositories
 import (
 	"chat2pay/internal/entities"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
 )
 
+// ErrOrderStatusChanged is returned for a status change of an order that is
+// no longer in the status the change was made from.
+var ErrOrderStatusChanged = errors.New("order status has changed")
+
 type OrderRepository interface {
-	Create(ctx context.Context, order *entities.Order) error
-	CreateItem(ctx context.Context, item *entities.OrderItem) error
+	Place(ctx context.Context, order *entities.Order, movements []entities.StockMovement) error
+	PlaceCheckout(ctx context.Context, checkout *entities.Checkout, movements []entities.StockMovement, cartItemIds []string) error
 	FindByID(ctx context.Context, id string) (*entities.Order, error)
 	FindByCustomerID(ctx context.Context, customerID string, page, limit int) ([]entities.Order, error)
 	FindByMerchantID(ctx context.Context, merchantID string, page, limit int) ([]entities.Order, error)
 	CountByCustomerID(ctx context.Context, customerID string) (int64, error)
 	CountByMerchantID(ctx context.Context, merchantID string) (int64, error)
 	GetOrderItems(ctx context.Context, orderID string) ([]entities.OrderItem, error)
-	UpdateStatus(ctx context.Context, id, status string) error
+	Transition(ctx context.Context, change *entities.OrderStatusHistory, trackingNumber *string) error
+	FindStatusHistory(ctx context.Context, orderID string) ([]entities.OrderStatusHistory, error)
+	Cancel(ctx context.Context, change *entities.OrderStatusHistory, reason *string) error
+	FindUnpaidBefore(ctx context.Context, before time.Time, limit int) ([]entities.Order, error)
+	Expire(ctx context.Context, orderID string) error
 	UpdatePaymentStatus(ctx context.Context, id, paymentStatus string) error
 	UpdateTrackingNumber(ctx context.Context, id, trackingNumber string) error
 }
@@ -28,18 +41,136 @@ func NewOrderRepository(db *sqlx.DB) OrderRepository {
 	return &orderRepository{DB: db}
 }
 
-func (r *orderRepository) Create(ctx context.Context, order *entities.Order) error {
+// Place creates the order with its items and the first entry of its status
+// history, and takes their stock out through the movements, in a single
+// transaction. Nothing is kept when the stock of any item runs short.
+func (r *orderRepository) Place(ctx context.Context, order *entities.Order, movements []entities.StockMovement) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = placeOrders(ctx, tx, []*entities.Order{order}, movements); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PlaceCheckout creates the checkout with its orders, as Place does for a
+// single order, and takes the ordered items out of the cart, in a single
+// transaction.
+func (r *orderRepository) PlaceCheckout(ctx context.Context, checkout *entities.Checkout, movements []entities.StockMovement, cartItemIds []string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO checkouts (customer_id, subtotal, shipping_cost, total)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at;
+	`
+	err = tx.QueryRowContext(ctx, query,
+		checkout.CustomerID, checkout.Subtotal, checkout.ShippingCost, checkout.Total,
+	).Scan(&checkout.ID, &checkout.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	orders := make([]*entities.Order, len(checkout.Orders))
+	for i := range checkout.Orders {
+		checkout.Orders[i].CheckoutID = &checkout.ID
+		orders[i] = &checkout.Orders[i]
+	}
+
+	if err = placeOrders(ctx, tx, orders, movements); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM cart_items WHERE customer_id = $1 AND id::text = ANY($2)`,
+		checkout.CustomerID, pq.Array(cartItemIds),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// placeOrders creates the orders and takes their stock out. The products
+// are locked first, so concurrent orders for the same stock are placed one
+// after the other.
+func placeOrders(ctx context.Context, tx *sqlx.Tx, orders []*entities.Order, movements []entities.StockMovement) error {
+	productIds := []string{}
+	seen := map[string]bool{}
+	for _, movement := range movements {
+		if !seen[movement.ProductID] {
+			seen[movement.ProductID] = true
+			productIds = append(productIds, movement.ProductID)
+		}
+	}
+
+	if err := lockProducts(ctx, tx, productIds); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return err
+		}
+
+		for i := range order.Items {
+			order.Items[i].OrderID = order.ID
+			if err := insertOrderItem(ctx, tx, &order.Items[i]); err != nil {
+				return err
+			}
+		}
+
+		err := recordStatus(ctx, tx, &entities.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  order.Status,
+			ActorType: entities.OrderActorCustomer,
+			ActorID:   &order.CustomerID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range movements {
+		if err := moveStock(ctx, tx, &movements[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, productId := range productIds {
+		if err := syncOutletStock(ctx, tx, productId); err != nil {
+			return err
+		}
+		if err := checkStockAlert(ctx, tx, productId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertOrder(ctx context.Context, tx *sqlx.Tx, order *entities.Order) error {
 	query := `
 		INSERT INTO orders (
-			id, customer_id, merchant_id, status, subtotal, shipping_cost, total,
+			id, customer_id, merchant_id, outlet_id, checkout_id, status, subtotal, shipping_cost, total,
 			courier, courier_service, shipping_etd, shipping_address, shipping_city,
 			shipping_province, shipping_postal_code, payment_status, notes
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 		)
 	`
-	_, err := r.DB.ExecContext(ctx, query,
-		order.ID, order.CustomerID, order.MerchantID, order.Status,
+	_, err := tx.ExecContext(ctx, query,
+		order.ID, order.CustomerID, order.MerchantID, order.OutletID, order.CheckoutID, order.Status,
 		order.Subtotal, order.ShippingCost, order.Total,
 		order.Courier, order.CourierService, order.ShippingEtd,
 		order.ShippingAddress, order.ShippingCity, order.ShippingProvince,
@@ -48,14 +179,16 @@ func (r *orderRepository) Create(ctx context.Context, order *entities.Order) err
 	return err
 }
 
-func (r *orderRepository) CreateItem(ctx context.Context, item *entities.OrderItem) error {
+func insertOrderItem(ctx context.Context, tx *sqlx.Tx, item *entities.OrderItem) error {
 	query := `
-		INSERT INTO order_items (id, order_id, product_id, product_name, product_price, quantity, subtotal)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO order_items (
+			id, order_id, product_id, variant_id, variant_name, product_name, product_price,
+			original_price, discount_id, quantity, subtotal
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.DB.ExecContext(ctx, query,
-		item.ID, item.OrderID, item.ProductID, item.ProductName,
-		item.ProductPrice, item.Quantity, item.Subtotal,
+	_, err := tx.ExecContext(ctx, query,
+		item.ID, item.OrderID, item.ProductID, item.VariantID, item.VariantName,
+		item.ProductName, item.ProductPrice, item.OriginalPrice, item.DiscountID, item.Quantity, item.Subtotal,
 	)
 	return err
 }
@@ -107,10 +240,250 @@ func (r *orderRepository) GetOrderItems(ctx context.Context, orderID string) ([]
 	return items, err
 }
 
-func (r *orderRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	query := `UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.DB.ExecContext(ctx, query, status, id)
-	return err
+// Transition moves the order from the status the change is made from to
+// the new one and records the change, setting the tracking number when it is
+// given.
+func (r *orderRepository) Transition(ctx context.Context, change *entities.OrderStatusHistory, trackingNumber *string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders SET status = $1, tracking_number = COALESCE($2, tracking_number), updated_at = NOW()
+		WHERE id = $3 AND status = $4`,
+		change.ToStatus, trackingNumber, change.OrderID, change.FromStatus,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrOrderStatusChanged
+	}
+
+	if err = recordStatus(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *orderRepository) FindStatusHistory(ctx context.Context, orderID string) ([]entities.OrderStatusHistory, error) {
+	history := []entities.OrderStatusHistory{}
+	query := `
+		SELECT id, order_id, from_status, to_status, actor_type, actor_id, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at, id;
+	`
+	err := r.DB.SelectContext(ctx, &history, query, orderID)
+	return history, err
+}
+
+// Cancel moves the order to cancelled from the status the change is made
+// from, puts back the stock its items took out and notifies the customer
+// and the merchant, in a single transaction. Only the cancellation that
+// moves the order returns its stock, any other gets ErrOrderStatusChanged.
+func (r *orderRepository) Cancel(ctx context.Context, change *entities.OrderStatusHistory, reason *string) error {
+	return r.cancel(ctx, change, reason, nil)
+}
+
+// FindUnpaidBefore returns up to limit orders still waiting for payment
+// that were placed before the given time, oldest first.
+func (r *orderRepository) FindUnpaidBefore(ctx context.Context, before time.Time, limit int) ([]entities.Order, error) {
+	orders := []entities.Order{}
+	query := `SELECT * FROM orders WHERE status = $1 AND created_at < $2 ORDER BY created_at LIMIT $3`
+	err := r.DB.SelectContext(ctx, &orders, query, entities.OrderStatusPending, before, limit)
+	return orders, err
+}
+
+// Expire cancels the unpaid order on behalf of the system, as Cancel does,
+// and marks its payment as expired.
+func (r *orderRepository) Expire(ctx context.Context, orderID string) error {
+	pending := entities.OrderStatusPending
+	expired := entities.PaymentStatusExpired
+	reason := "not paid in time"
+
+	return r.cancel(ctx, &entities.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: &pending,
+		ToStatus:   entities.OrderStatusCancelled,
+		ActorType:  entities.OrderActorSystem,
+	}, &reason, &expired)
+}
+
+func (r *orderRepository) cancel(ctx context.Context, change *entities.OrderStatusHistory, reason, paymentStatus *string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var order entities.Order
+	err = tx.GetContext(ctx, &order, `
+		UPDATE orders SET
+			status = $1, cancel_reason = $2, cancelled_at = NOW(),
+			payment_status = COALESCE($3, payment_status), updated_at = NOW()
+		WHERE id = $4 AND status = $5
+		RETURNING *`,
+		entities.OrderStatusCancelled, reason, paymentStatus, change.OrderID, change.FromStatus,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderStatusChanged
+		}
+		return err
+	}
+
+	change.ToStatus = entities.OrderStatusCancelled
+	if err = recordStatus(ctx, tx, change); err != nil {
+		return err
+	}
+
+	if err = restoreStock(ctx, tx, &order, change); err != nil {
+		return err
+	}
+
+	if err = notifyCancellation(ctx, tx, &order, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// restoreStock puts back the stock the order took out, at the levels it was
+// taken from, as recorded by its sale movements. Changes the merchant made
+// to how the product is stocked since do not stand in the way.
+func restoreStock(ctx context.Context, tx *sqlx.Tx, order *entities.Order, change *entities.OrderStatusHistory) error {
+	sales := []entities.StockMovement{}
+	err := tx.SelectContext(ctx, &sales, `
+		SELECT product_id, variant_id, outlet_id, quantity
+		FROM stock_movements
+		WHERE reference_type = 'order' AND reference_id = $1 AND type = $2
+		ORDER BY created_at, id`,
+		order.ID, entities.StockMovementSale,
+	)
+	if err != nil {
+		return err
+	}
+
+	productIds := []string{}
+	seen := map[string]bool{}
+	for _, sale := range sales {
+		if !seen[sale.ProductID] {
+			seen[sale.ProductID] = true
+			productIds = append(productIds, sale.ProductID)
+		}
+	}
+
+	if err = lockProducts(ctx, tx, productIds); err != nil {
+		return err
+	}
+
+	referenceType := "order"
+	for _, sale := range sales {
+		movement := entities.StockMovement{
+			ProductID:     sale.ProductID,
+			VariantID:     sale.VariantID,
+			OutletID:      sale.OutletID,
+			Type:          entities.StockMovementCancellationReturn,
+			Quantity:      -sale.Quantity,
+			ActorType:     change.ActorType, // order and stock actors share their names
+			ActorID:       change.ActorID,
+			ReferenceType: &referenceType,
+			ReferenceID:   &order.ID,
+			Note:          order.CancelReason,
+		}
+		if err = returnStock(ctx, tx, &movement); err != nil {
+			return err
+		}
+	}
+
+	for _, productId := range productIds {
+		if err = syncOutletStock(ctx, tx, productId); err != nil {
+			return err
+		}
+		if err = checkStockAlert(ctx, tx, productId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyCancellation tells the customer and the merchant who cancelled the
+// order, and why.
+func notifyCancellation(ctx context.Context, tx *sqlx.Tx, order *entities.Order, change *entities.OrderStatusHistory) error {
+	number := order.ID
+	if len(number) > 8 {
+		number = number[:8]
+	}
+	title := fmt.Sprintf("Order %s cancelled", number)
+
+	reason := ""
+	if order.CancelReason != nil {
+		reason = *order.CancelReason
+	}
+
+	var toCustomer, toMerchant string
+	switch change.ActorType {
+	case entities.OrderActorCustomer:
+		toCustomer = "You cancelled the order."
+		toMerchant = "The customer cancelled the order, its stock has been returned."
+		if reason != "" {
+			toMerchant = fmt.Sprintf("The customer cancelled the order: %s. Its stock has been returned.", reason)
+		}
+	case entities.OrderActorMerchant:
+		toCustomer = fmt.Sprintf("The merchant cancelled the order: %s", reason)
+		toMerchant = fmt.Sprintf("You cancelled the order: %s. Its stock has been returned.", reason)
+	default:
+		toCustomer = "The order was not paid in time and has been cancelled."
+		toMerchant = "The order was not paid in time and has been cancelled, its stock has been returned."
+	}
+
+	referenceType := "order"
+	err := notifyCustomer(ctx, tx, &entities.CustomerNotification{
+		CustomerID:    order.CustomerID,
+		Type:          entities.NotificationOrderCancelled,
+		Title:         title,
+		Message:       toCustomer,
+		ReferenceType: &referenceType,
+		ReferenceID:   &order.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return notifyMerchant(ctx, tx, &entities.MerchantNotification{
+		MerchantID:    order.MerchantID,
+		Type:          entities.NotificationOrderCancelled,
+		Title:         title,
+		Message:       toMerchant,
+		ReferenceType: &referenceType,
+		ReferenceID:   &order.ID,
+	})
+}
+
+// recordStatus adds the change to the status history of the order.
+func recordStatus(ctx context.Context, tx *sqlx.Tx, change *entities.OrderStatusHistory) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, actor_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		change.OrderID,
+		change.FromStatus,
+		change.ToStatus,
+		change.ActorType,
+		change.ActorID,
+	).Scan(&change.ID, &change.CreatedAt)
 }
 
 func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, id, paymentStatus string) error {
@@ -120,7 +493,7 @@ func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, id, paymentSt
 }
 
 func (r *orderRepository) UpdateTrackingNumber(ctx context.Context, id, trackingNumber string) error {
-	query := `UPDATE orders SET tracking_number = $1, status = 'shipped', updated_at = NOW() WHERE id = $2`
+	query := `UPDATE orders SET tracking_number = $1, updated_at = NOW() WHERE id = $2`
 	_, err := r.DB.ExecContext(ctx, query, trackingNumber, id)
 	return err
 }
@@ -0,0 +1,355 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL and migrates it. The
+// tests using it are skipped when it is not set.
+func testDB(t *testing.T) *sqlx.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrations := &migrate.FileMigrationSource{Dir: "../../migration"}
+	_, err = migrate.Exec(db.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	return db
+}
+
+// seedProduct creates a customer, a merchant and a product of the merchant
+// with the given stock, and removes them with their orders afterwards.
+func seedProduct(t *testing.T, db *sqlx.DB, stock int) (customerID, merchantID, productID string) {
+	customerID, merchantID, productID = uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	db.MustExec(`INSERT INTO customers (id, name) VALUES ($1, 'Pembeli')`, customerID)
+	db.MustExec(`INSERT INTO merchants (id, name, email) VALUES ($1, 'Toko', $2)`, merchantID, merchantID+"@example.com")
+	db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock) VALUES ($1, $2, 'Kaos', 50000, $3)`, productID, merchantID, stock)
+
+	t.Cleanup(func() {
+		db.MustExec(`DELETE FROM orders WHERE merchant_id = $1`, merchantID)
+		db.MustExec(`DELETE FROM product WHERE merchant_id = $1`, merchantID)
+		db.MustExec(`DELETE FROM customers WHERE id = $1`, customerID)
+		db.MustExec(`DELETE FROM merchants WHERE id = $1`, merchantID)
+	})
+
+	return customerID, merchantID, productID
+}
+
+func newPlacement(customerID, merchantID string, products map[string]int) (*entities.Order, []entities.StockMovement) {
+	order := &entities.Order{
+		ID:            uuid.New().String(),
+		CustomerID:    customerID,
+		MerchantID:    merchantID,
+		Status:        entities.OrderStatusPending,
+		PaymentStatus: entities.PaymentStatusPending,
+	}
+
+	referenceType := "order"
+	movements := []entities.StockMovement{}
+	for productID, quantity := range products {
+		order.Items = append(order.Items, entities.OrderItem{
+			ID:           uuid.New().String(),
+			ProductID:    productID,
+			ProductName:  "Kaos",
+			ProductPrice: 50000,
+			Quantity:     quantity,
+			Subtotal:     50000 * float64(quantity),
+		})
+		movements = append(movements, entities.StockMovement{
+			ProductID:     productID,
+			Type:          entities.StockMovementSale,
+			Quantity:      -quantity,
+			ActorType:     entities.StockActorCustomer,
+			ActorID:       &customerID,
+			ReferenceType: &referenceType,
+			ReferenceID:   &order.ID,
+		})
+	}
+
+	return order, movements
+}
+
+func TestOrderRepository_PlaceConcurrently(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	customerID, merchantID, productID := seedProduct(t, db, 5)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		placed   int
+		rejected int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 1})
+			err := repo.Place(context.Background(), order, movements)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				placed++
+			case errors.Is(err, ErrInsufficientStock):
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, placed)
+	assert.Equal(t, 15, rejected)
+
+	var stock, orders, history, items, sales int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	require.NoError(t, db.Get(&orders, `SELECT COUNT(*) FROM orders WHERE merchant_id = $1`, merchantID))
+	require.NoError(t, db.Get(&history, `SELECT COUNT(*) FROM order_status_history h JOIN orders o ON o.id = h.order_id WHERE o.merchant_id = $1`, merchantID))
+	require.NoError(t, db.Get(&items, `SELECT COUNT(*) FROM order_items WHERE product_id = $1`, productID))
+	require.NoError(t, db.Get(&sales, `SELECT COUNT(*) FROM stock_movements WHERE product_id = $1 AND type = 'sale'`, productID))
+	assert.Equal(t, 0, stock)
+	assert.Equal(t, 5, orders)
+	assert.Equal(t, 5, history)
+	assert.Equal(t, 5, items)
+	assert.Equal(t, 5, sales)
+}
+
+func TestOrderRepository_PlaceRollsBack(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	customerID, merchantID, plentyID := seedProduct(t, db, 10)
+
+	shortID := uuid.New().String()
+	db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock) VALUES ($1, $2, 'Topi', 50000, 1)`, shortID, merchantID)
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{plentyID: 3, shortID: 2})
+	err := repo.Place(context.Background(), order, movements)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	var plenty, short, orders int
+	require.NoError(t, db.Get(&plenty, `SELECT stock FROM product WHERE id = $1`, plentyID))
+	require.NoError(t, db.Get(&short, `SELECT stock FROM product WHERE id = $1`, shortID))
+	require.NoError(t, db.Get(&orders, `SELECT COUNT(*) FROM orders WHERE id = $1`, order.ID))
+	assert.Equal(t, 10, plenty)
+	assert.Equal(t, 1, short)
+	assert.Equal(t, 0, orders)
+}
+
+func TestOrderRepository_Transition(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+	customerID, merchantID, productID := seedProduct(t, db, 5)
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 1})
+	require.NoError(t, repo.Place(ctx, order, movements))
+
+	pending := entities.OrderStatusPending
+	change := func() *entities.OrderStatusHistory {
+		return &entities.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: &pending,
+			ToStatus:   entities.OrderStatusPaid,
+			ActorType:  entities.OrderActorMerchant,
+			ActorID:    &merchantID,
+		}
+	}
+
+	require.NoError(t, repo.Transition(ctx, change(), nil))
+	assert.ErrorIs(t, repo.Transition(ctx, change(), nil), ErrOrderStatusChanged)
+
+	history, err := repo.FindStatusHistory(ctx, order.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Nil(t, history[0].FromStatus)
+	assert.Equal(t, entities.OrderStatusPending, history[0].ToStatus)
+	assert.Equal(t, entities.OrderActorCustomer, history[0].ActorType)
+	assert.Equal(t, entities.OrderStatusPaid, history[1].ToStatus)
+	assert.Equal(t, entities.OrderActorMerchant, history[1].ActorType)
+}
+
+func TestOrderRepository_PlaceCheckout(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	carts := NewCartRepository(db)
+	ctx := context.Background()
+	customerID, merchantID, productID := seedProduct(t, db, 5)
+	_, otherMerchantID, otherProductID := seedProduct(t, db, 5)
+
+	first := &entities.CartItem{CustomerID: customerID, ProductID: productID, Quantity: 2}
+	second := &entities.CartItem{CustomerID: customerID, ProductID: otherProductID, Quantity: 1}
+	require.NoError(t, carts.Add(ctx, first))
+	require.NoError(t, carts.Add(ctx, second))
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 2})
+	otherOrder, otherMovements := newPlacement(customerID, otherMerchantID, map[string]int{otherProductID: 1})
+	checkout := &entities.Checkout{
+		CustomerID: customerID,
+		Subtotal:   150000,
+		Total:      150000,
+		Orders:     []entities.Order{*order, *otherOrder},
+	}
+
+	err := repo.PlaceCheckout(ctx, checkout, append(movements, otherMovements...), []string{first.ID, second.ID})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.MustExec(`DELETE FROM orders WHERE checkout_id = $1`, checkout.ID)
+		db.MustExec(`DELETE FROM checkouts WHERE id = $1`, checkout.ID)
+	})
+
+	for _, placed := range checkout.Orders {
+		stored, err := repo.FindByID(ctx, placed.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.CheckoutID)
+		assert.Equal(t, checkout.ID, *stored.CheckoutID)
+	}
+
+	cart, err := carts.FindByCustomerID(ctx, customerID)
+	require.NoError(t, err)
+	assert.Empty(t, cart)
+
+	var stock int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	assert.Equal(t, 3, stock)
+}
+
+func TestOrderRepository_Cancel(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+	customerID, merchantID, productID := seedProduct(t, db, 5)
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 2})
+	require.NoError(t, repo.Place(ctx, order, movements))
+
+	// The merchant user who cancels, not the merchant
+	userID := uuid.New().String()
+	pending := entities.OrderStatusPending
+	reason := "out of packaging"
+	change := func() *entities.OrderStatusHistory {
+		return &entities.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: &pending,
+			ActorType:  entities.OrderActorMerchant,
+			ActorID:    &userID,
+		}
+	}
+
+	require.NoError(t, repo.Cancel(ctx, change(), &reason))
+	assert.ErrorIs(t, repo.Cancel(ctx, change(), &reason), ErrOrderStatusChanged)
+
+	cancelled, err := repo.FindByID(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderStatusCancelled, cancelled.Status)
+	require.NotNil(t, cancelled.CancelReason)
+	assert.Equal(t, reason, *cancelled.CancelReason)
+
+	var stock, returns, toCustomer, toMerchant int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	require.NoError(t, db.Get(&returns, `SELECT COUNT(*) FROM stock_movements WHERE reference_id = $1 AND type = 'cancellation_return'`, order.ID))
+	require.NoError(t, db.Get(&toCustomer, `SELECT COUNT(*) FROM customer_notifications WHERE reference_id = $1`, order.ID))
+	require.NoError(t, db.Get(&toMerchant, `SELECT COUNT(*) FROM merchant_notifications WHERE reference_id = $1`, order.ID))
+	assert.Equal(t, 5, stock)
+	assert.Equal(t, 1, returns)
+	assert.Equal(t, 1, toCustomer)
+	assert.Equal(t, 1, toMerchant)
+
+	restored := []entities.StockMovement{}
+	require.NoError(t, db.Select(&restored, `
+		SELECT actor_type, actor_id FROM stock_movements
+		WHERE reference_id = $1 AND type = 'cancellation_return'`, order.ID))
+	require.Len(t, restored, 1)
+	assert.Equal(t, entities.StockActorMerchant, restored[0].ActorType)
+	require.NotNil(t, restored[0].ActorID)
+	assert.Equal(t, userID, *restored[0].ActorID)
+}
+
+func TestOrderRepository_CancelAfterRestocking(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	outlets := NewOutletRepository(db)
+	ctx := context.Background()
+	customerID, merchantID, productID := seedProduct(t, db, 10)
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 2})
+	require.NoError(t, repo.Place(ctx, order, movements))
+
+	// The merchant moves the product to outlet stock while the order is open
+	outlet, err := outlets.Create(ctx, &entities.Outlet{MerchantID: merchantID, Name: "Gudang", Status: entities.OutletStatusActive})
+	require.NoError(t, err)
+	require.NoError(t, outlets.SetStocks(ctx, outlet.ID, []entities.OutletStock{{ProductID: productID, Stock: 6}}, nil))
+
+	pending := entities.OrderStatusPending
+	require.NoError(t, repo.Cancel(ctx, &entities.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: &pending,
+		ActorType:  entities.OrderActorMerchant,
+		ActorID:    &merchantID,
+	}, nil))
+
+	returns := []entities.StockMovement{}
+	require.NoError(t, db.Select(&returns, `
+		SELECT product_id, variant_id, outlet_id, quantity FROM stock_movements
+		WHERE reference_id = $1 AND type = 'cancellation_return'`, order.ID))
+	require.Len(t, returns, 1)
+	assert.Equal(t, 2, returns[0].Quantity)
+	assert.Nil(t, returns[0].OutletID, "returned at the level it was sold from")
+
+	// The product total follows the outlet stock the merchant set
+	var stock int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	assert.Equal(t, 6, stock)
+}
+
+func TestOrderRepository_Expire(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+	customerID, merchantID, productID := seedProduct(t, db, 5)
+
+	order, movements := newPlacement(customerID, merchantID, map[string]int{productID: 3})
+	require.NoError(t, repo.Place(ctx, order, movements))
+
+	unpaid, err := repo.FindUnpaidBefore(ctx, time.Now().Add(time.Minute), 100)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, found := range unpaid {
+		ids = append(ids, found.ID)
+	}
+	assert.Contains(t, ids, order.ID)
+
+	require.NoError(t, repo.Expire(ctx, order.ID))
+	assert.ErrorIs(t, repo.Expire(ctx, order.ID), ErrOrderStatusChanged)
+
+	expired, err := repo.FindByID(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderStatusCancelled, expired.Status)
+	assert.Equal(t, entities.PaymentStatusExpired, expired.PaymentStatus)
+
+	var stock int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	assert.Equal(t, 5, stock)
+}
@@ -0,0 +1,380 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// OutletRepository manages the outlets of a merchant and the stock each
+// outlet holds. Once a product or variant has outlet stock, its own stock is
+// the total held by the active outlets, so listings and search keep working
+// on the product alone.
+type OutletRepository interface {
+	Create(ctx context.Context, outlet *entities.Outlet) (*entities.Outlet, error)
+	FindByMerchantID(ctx context.Context, merchantId string) ([]entities.Outlet, error)
+	FindActiveByMerchantID(ctx context.Context, merchantId string) ([]entities.Outlet, error)
+	FindOneById(ctx context.Context, id string) (*entities.Outlet, error)
+	Update(ctx context.Context, outlet *entities.Outlet) (*entities.Outlet, error)
+	Delete(ctx context.Context, id string) error
+	SumStock(ctx context.Context, id string) (int, error)
+	FindStocksByOutletID(ctx context.Context, outletId string) ([]entities.OutletStock, error)
+	FindStocksByProductIDs(ctx context.Context, productIds []string) ([]entities.OutletStock, error)
+	SetStocks(ctx context.Context, outletId string, stocks []entities.OutletStock, actorId *string) error
+}
+
+var movedToOutlets = "moved to outlet stock"
+
+type outletRepository struct {
+	DB *sqlx.DB
+}
+
+func NewOutletRepository(db *sqlx.DB) OutletRepository {
+	return &outletRepository{DB: db}
+}
+
+const outletColumns = `id, merchant_id, name, address, city, city_id, province, latitude, longitude, phone, status, created_at, updated_at`
+
+func (r *outletRepository) Create(ctx context.Context, outlet *entities.Outlet) (*entities.Outlet, error) {
+	query := `
+		INSERT INTO outlets (
+			id, merchant_id, name, address, city, city_id, province, latitude, longitude, phone, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		outlet.MerchantID,
+		outlet.Name,
+		outlet.Address,
+		outlet.City,
+		outlet.CityID,
+		outlet.Province,
+		outlet.Latitude,
+		outlet.Longitude,
+		outlet.Phone,
+		outlet.Status,
+	).Scan(&outlet.ID, &outlet.CreatedAt, &outlet.UpdatedAt)
+
+	return outlet, err
+}
+
+func (r *outletRepository) FindByMerchantID(ctx context.Context, merchantId string) ([]entities.Outlet, error) {
+	outlets := []entities.Outlet{}
+
+	query := `
+		SELECT ` + outletColumns + `
+		FROM outlets
+		WHERE merchant_id = $1
+		ORDER BY name ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &outlets, query, merchantId)
+	return outlets, err
+}
+
+func (r *outletRepository) FindActiveByMerchantID(ctx context.Context, merchantId string) ([]entities.Outlet, error) {
+	outlets := []entities.Outlet{}
+
+	query := `
+		SELECT ` + outletColumns + `
+		FROM outlets
+		WHERE merchant_id = $1 AND status = 'active'
+		ORDER BY name ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &outlets, query, merchantId)
+	return outlets, err
+}
+
+func (r *outletRepository) FindOneById(ctx context.Context, id string) (*entities.Outlet, error) {
+	var outlet entities.Outlet
+
+	query := `
+		SELECT ` + outletColumns + `
+		FROM outlets
+		WHERE id = $1
+		LIMIT 1;
+	`
+
+	err := r.DB.GetContext(ctx, &outlet, query, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &outlet, nil
+}
+
+// Update saves the outlet. Only active outlets count towards product stock,
+// so a status change brings the stock of its products up to date.
+func (r *outletRepository) Update(ctx context.Context, outlet *entities.Outlet) (*entities.Outlet, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previousStatus string
+	if err = tx.GetContext(ctx, &previousStatus, `SELECT status FROM outlets WHERE id = $1 FOR UPDATE`, outlet.ID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE outlets SET
+			name = $1, address = $2, city = $3, city_id = $4, province = $5,
+			latitude = $6, longitude = $7, phone = $8, status = $9, updated_at = NOW()
+		WHERE id = $10
+		RETURNING updated_at;
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		outlet.Name,
+		outlet.Address,
+		outlet.City,
+		outlet.CityID,
+		outlet.Province,
+		outlet.Latitude,
+		outlet.Longitude,
+		outlet.Phone,
+		outlet.Status,
+		outlet.ID,
+	).Scan(&outlet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if previousStatus != outlet.Status {
+		productIds := []string{}
+		err = tx.SelectContext(ctx, &productIds,
+			`SELECT DISTINCT product_id FROM outlet_stocks WHERE outlet_id = $1 ORDER BY product_id`, outlet.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = lockProducts(ctx, tx, productIds); err != nil {
+			return nil, err
+		}
+		for _, productId := range productIds {
+			if err = syncOutletStock(ctx, tx, productId); err != nil {
+				return nil, err
+			}
+			if err = checkStockAlert(ctx, tx, productId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return outlet, tx.Commit()
+}
+
+// Delete removes the outlet together with its stock rows. The service only
+// deletes outlets that hold no stock, so product totals stay the same.
+func (r *outletRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM outlets WHERE id = $1`, id)
+	return err
+}
+
+func (r *outletRepository) SumStock(ctx context.Context, id string) (int, error) {
+	var total int
+	err := r.DB.GetContext(ctx, &total, `SELECT COALESCE(SUM(stock), 0) FROM outlet_stocks WHERE outlet_id = $1`, id)
+	return total, err
+}
+
+const outletStockColumns = `
+	s.id, s.outlet_id, s.product_id, s.variant_id, s.stock, s.updated_at,
+	p.name AS product_name, v.name AS variant_name
+`
+
+func (r *outletRepository) FindStocksByOutletID(ctx context.Context, outletId string) ([]entities.OutletStock, error) {
+	stocks := []entities.OutletStock{}
+
+	query := `
+		SELECT ` + outletStockColumns + `
+		FROM outlet_stocks s
+		JOIN product p ON p.id = s.product_id
+		LEFT JOIN product_variants v ON v.id = s.variant_id
+		WHERE s.outlet_id = $1
+		ORDER BY p.name ASC, v.position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &stocks, query, outletId)
+	return stocks, err
+}
+
+func (r *outletRepository) FindStocksByProductIDs(ctx context.Context, productIds []string) ([]entities.OutletStock, error) {
+	stocks := []entities.OutletStock{}
+	if len(productIds) == 0 {
+		return stocks, nil
+	}
+
+	query := `
+		SELECT ` + outletStockColumns + `
+		FROM outlet_stocks s
+		JOIN product p ON p.id = s.product_id
+		LEFT JOIN product_variants v ON v.id = s.variant_id
+		WHERE s.product_id::text = ANY($1);
+	`
+
+	err := r.DB.SelectContext(ctx, &stocks, query, pq.Array(productIds))
+	return stocks, err
+}
+
+// SetStocks sets the stock the outlet holds of each given product or
+// variant and updates the product totals, in a single transaction. Each
+// change is recorded in the ledger as an adjustment by the merchant user.
+func (r *outletRepository) SetStocks(ctx context.Context, outletId string, stocks []entities.OutletStock, actorId *string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	productIds := []string{}
+	seen := map[string]bool{}
+	for _, stock := range stocks {
+		if !seen[stock.ProductID] {
+			seen[stock.ProductID] = true
+			productIds = append(productIds, stock.ProductID)
+		}
+	}
+
+	if err = lockProducts(ctx, tx, productIds); err != nil {
+		return err
+	}
+
+	for _, stock := range stocks {
+		// Stock held on the product or variant itself is replaced by the
+		// outlet totals once it is first stocked per outlet
+		direct := &entities.StockMovement{
+			ProductID: stock.ProductID,
+			VariantID: stock.VariantID,
+			Type:      entities.StockMovementAdjustment,
+			ActorType: entities.StockActorMerchant,
+			ActorID:   actorId,
+			Note:      &movedToOutlets,
+		}
+		held, err := levelStock(ctx, tx, direct)
+		switch {
+		case errors.Is(err, ErrOutletRequired):
+		case err != nil:
+			return err
+		case held > 0:
+			direct.Quantity = -held
+			if err = recordMovement(ctx, tx, direct); err != nil {
+				return err
+			}
+		}
+
+		movement := &entities.StockMovement{
+			ProductID: stock.ProductID,
+			VariantID: stock.VariantID,
+			OutletID:  &outletId,
+			Type:      entities.StockMovementAdjustment,
+			ActorType: entities.StockActorMerchant,
+			ActorID:   actorId,
+		}
+
+		current, err := levelStock(ctx, tx, movement)
+		if err != nil {
+			return err
+		}
+
+		// Stock of zero still creates the row, which makes the product
+		// stocked per outlet
+		if current == stock.Stock {
+			if err = upsertOutletStock(ctx, tx, outletId, stock.ProductID, stock.VariantID, stock.Stock); err != nil {
+				return err
+			}
+			continue
+		}
+
+		movement.Quantity = stock.Stock - current
+		if err = moveStock(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	for _, productId := range productIds {
+		if err = syncOutletStock(ctx, tx, productId); err != nil {
+			return err
+		}
+		if err = checkStockAlert(ctx, tx, productId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upsertOutletStock(ctx context.Context, tx *sqlx.Tx, outletId, productId string, variantId *string, quantity int) error {
+	conflict := `(outlet_id, product_id) WHERE variant_id IS NULL`
+	if variantId != nil {
+		conflict = `(outlet_id, variant_id) WHERE variant_id IS NOT NULL`
+	}
+
+	query := `
+		INSERT INTO outlet_stocks (id, outlet_id, product_id, variant_id, stock)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ` + conflict + `
+		DO UPDATE SET stock = EXCLUDED.stock, updated_at = NOW();
+	`
+	_, err := tx.ExecContext(ctx, query, uuid.New().String(), outletId, productId, variantId, quantity)
+	return err
+}
+
+// lockProducts serializes stock changes of the same products. The ids are
+// locked in order so two transactions cannot wait on each other.
+func lockProducts(ctx context.Context, tx *sqlx.Tx, productIds []string) error {
+	if len(productIds) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx,
+		`SELECT id FROM product WHERE id::text = ANY($1) ORDER BY id FOR UPDATE`,
+		pq.Array(productIds),
+	)
+	return err
+}
+
+// syncOutletStock sets the stock of each variant, and of the product when it
+// has no variants, to the total held by active outlets. Products and
+// variants without outlet stock keep their own stock.
+func syncOutletStock(ctx context.Context, tx *sqlx.Tx, productId string) error {
+	variantQuery := `
+		UPDATE product_variants SET stock = s.total, updated_at = NOW()
+		FROM (
+			SELECT os.variant_id, COALESCE(SUM(os.stock) FILTER (WHERE o.status = 'active'), 0) AS total
+			FROM outlet_stocks os
+			JOIN outlets o ON o.id = os.outlet_id
+			WHERE os.product_id = $1 AND os.variant_id IS NOT NULL
+			GROUP BY os.variant_id
+		) s
+		WHERE product_variants.id = s.variant_id;
+	`
+	if _, err := tx.ExecContext(ctx, variantQuery, productId); err != nil {
+		return err
+	}
+
+	productQuery := `
+		UPDATE product SET stock = s.total, updated_at = NOW()
+		FROM (
+			SELECT COALESCE(SUM(os.stock) FILTER (WHERE o.status = 'active'), 0) AS total, COUNT(*) AS entries
+			FROM outlet_stocks os
+			JOIN outlets o ON o.id = os.outlet_id
+			WHERE os.product_id = $1 AND os.variant_id IS NULL
+		) s
+		WHERE product.id = $1 AND s.entries > 0;
+	`
+	if _, err := tx.ExecContext(ctx, productQuery, productId); err != nil {
+		return err
+	}
+
+	return syncVariantStock(ctx, tx, productId)
+}
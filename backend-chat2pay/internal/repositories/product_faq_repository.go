@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pgvector/pgvector-go"
+	"strings"
+)
+
+type ProductFAQRepository interface {
+	Create(ctx context.Context, faq *entities.ProductFAQ, embedding []float32) (*entities.ProductFAQ, error)
+	Update(ctx context.Context, faq *entities.ProductFAQ, embedding []float32) (*entities.ProductFAQ, error)
+	Delete(ctx context.Context, merchantId, id string) (bool, error)
+	FindOneById(ctx context.Context, merchantId, id string) (*entities.ProductFAQ, error)
+	Find(ctx context.Context, filter ProductFAQFilter, limit, offset int) ([]entities.ProductFAQ, error)
+	Count(ctx context.Context, filter ProductFAQFilter) (int64, error)
+	FindMatches(ctx context.Context, vector []float32, merchantId, productId string, limit int) ([]entities.ProductFAQ, error)
+}
+
+// ProductFAQFilter narrows down a FAQ listing. Empty fields do not filter,
+// StoreOnly lists the entries for the whole store.
+type ProductFAQFilter struct {
+	MerchantID string
+	ProductID  string
+	StoreOnly  bool
+}
+
+func (f ProductFAQFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s::text = $%d", column, len(args)))
+		}
+	}
+	add("merchant_id", f.MerchantID)
+	add("product_id", f.ProductID)
+
+	if f.StoreOnly {
+		conditions = append(conditions, "product_id IS NULL")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+const productFAQColumns = `id, merchant_id, product_id, question, answer, created_at, updated_at`
+
+type productFAQRepository struct {
+	DB *sqlx.DB
+}
+
+func NewProductFAQRepository(db *sqlx.DB) ProductFAQRepository {
+	return &productFAQRepository{DB: db}
+}
+
+func (r *productFAQRepository) Create(ctx context.Context, faq *entities.ProductFAQ, embedding []float32) (*entities.ProductFAQ, error) {
+	query := `
+		INSERT INTO product_faqs (id, merchant_id, product_id, question, answer, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		faq.MerchantID,
+		faq.ProductID,
+		faq.Question,
+		faq.Answer,
+		pgvector.NewVector(embedding),
+	).Scan(&faq.ID, &faq.CreatedAt, &faq.UpdatedAt)
+
+	return faq, err
+}
+
+func (r *productFAQRepository) Update(ctx context.Context, faq *entities.ProductFAQ, embedding []float32) (*entities.ProductFAQ, error) {
+	query := `
+		UPDATE product_faqs
+		SET product_id = $1, question = $2, answer = $3, embedding = $4, updated_at = NOW()
+		WHERE id = $5 AND merchant_id = $6
+		RETURNING updated_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		faq.ProductID,
+		faq.Question,
+		faq.Answer,
+		pgvector.NewVector(embedding),
+		faq.ID,
+		faq.MerchantID,
+	).Scan(&faq.UpdatedAt)
+
+	return faq, err
+}
+
+// Delete removes the entry of the merchant. It reports false when the
+// merchant has no such entry.
+func (r *productFAQRepository) Delete(ctx context.Context, merchantId, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM product_faqs WHERE id::text = $1 AND merchant_id = $2`, id, merchantId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *productFAQRepository) FindOneById(ctx context.Context, merchantId, id string) (*entities.ProductFAQ, error) {
+	var faq entities.ProductFAQ
+
+	query := `SELECT ` + productFAQColumns + ` FROM product_faqs WHERE id::text = $1 AND merchant_id = $2`
+
+	err := r.DB.GetContext(ctx, &faq, query, id, merchantId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &faq, nil
+}
+
+func (r *productFAQRepository) Find(ctx context.Context, filter ProductFAQFilter, limit, offset int) ([]entities.ProductFAQ, error) {
+	faqs := []entities.ProductFAQ{}
+	where, args := filter.where()
+
+	query := `SELECT ` + productFAQColumns + ` FROM product_faqs WHERE ` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d;
+	`, len(args)+1, len(args)+2)
+
+	err := r.DB.SelectContext(ctx, &faqs, query, append(args, limit, offset)...)
+	return faqs, err
+}
+
+func (r *productFAQRepository) Count(ctx context.Context, filter ProductFAQFilter) (int64, error) {
+	var count int64
+	where, args := filter.where()
+
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM product_faqs WHERE `+where, args...)
+	return count, err
+}
+
+// FindMatches returns the entries of the merchant for the product or for the
+// whole store whose question is closest to the vector, best first. Without a
+// product only the entries for the whole store are matched.
+func (r *productFAQRepository) FindMatches(ctx context.Context, vector []float32, merchantId, productId string, limit int) ([]entities.ProductFAQ, error) {
+	faqs := []entities.ProductFAQ{}
+
+	query := `
+		SELECT ` + productFAQColumns + `, 1 - (embedding <=> $1) AS similarity
+		FROM product_faqs
+		WHERE merchant_id = $2
+		AND (product_id IS NULL OR product_id::text = $3)
+		AND embedding IS NOT NULL
+		ORDER BY similarity DESC
+		LIMIT $4;
+	`
+
+	err := r.DB.SelectContext(ctx, &faqs, query, pgvector.NewVector(vector), merchantId, productId, limit)
+	return faqs, err
+}
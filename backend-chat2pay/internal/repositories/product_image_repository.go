@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ProductImageRepository manages a product's gallery. Every write keeps
+// exactly one primary image while the product has images, and mirrors the
+// primary image url into product.image.
+type ProductImageRepository interface {
+	Create(ctx context.Context, image *entities.ProductImage) (*entities.ProductImage, error)
+	FindByProductID(ctx context.Context, productId string) ([]entities.ProductImage, error)
+	FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductImage, error)
+	FindOneById(ctx context.Context, productId, id string) (*entities.ProductImage, error)
+	CountByProductID(ctx context.Context, productId string) (int, error)
+	Delete(ctx context.Context, productId, id string) error
+	SetPrimary(ctx context.Context, productId, id string) error
+	Reorder(ctx context.Context, productId string, ids []string) error
+}
+
+type productImageRepository struct {
+	DB *sqlx.DB
+}
+
+func NewProductImageRepository(db *sqlx.DB) ProductImageRepository {
+	return &productImageRepository{DB: db}
+}
+
+// Create appends the image to the end of the gallery. The first image of a
+// product becomes its primary image.
+func (r *productImageRepository) Create(ctx context.Context, image *entities.ProductImage) (*entities.ProductImage, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Serialize gallery changes of the same product
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM product WHERE id = $1 FOR UPDATE`, image.ProductID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO product_images (
+			id, product_id, image_url, thumbnail_url, storage_key, thumbnail_key, position, is_primary
+		)
+		SELECT $1, $2, $3, $4, $5, $6,
+			COALESCE(MAX(position) + 1, 0),
+			NOT COALESCE(BOOL_OR(is_primary), FALSE)
+		FROM product_images
+		WHERE product_id = $2
+		RETURNING position, is_primary, created_at;
+	`
+
+	image.ID = uuid.New().String()
+	err = tx.QueryRowContext(ctx, query,
+		image.ID,
+		image.ProductID,
+		image.ImageURL,
+		image.ThumbnailURL,
+		image.StorageKey,
+		image.ThumbnailKey,
+	).Scan(&image.Position, &image.IsPrimary, &image.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if image.IsPrimary {
+		if err = syncPrimaryImage(ctx, tx, image.ProductID); err != nil {
+			return nil, err
+		}
+	}
+
+	return image, tx.Commit()
+}
+
+func (r *productImageRepository) FindByProductID(ctx context.Context, productId string) ([]entities.ProductImage, error) {
+	images := []entities.ProductImage{}
+
+	query := `
+		SELECT id, product_id, image_url, thumbnail_url, storage_key, thumbnail_key, position, is_primary, created_at
+		FROM product_images
+		WHERE product_id = $1
+		ORDER BY position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &images, query, productId)
+	return images, err
+}
+
+func (r *productImageRepository) FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductImage, error) {
+	images := []entities.ProductImage{}
+	if len(productIds) == 0 {
+		return images, nil
+	}
+
+	query := `
+		SELECT id, product_id, image_url, thumbnail_url, storage_key, thumbnail_key, position, is_primary, created_at
+		FROM product_images
+		WHERE product_id::text = ANY($1)
+		ORDER BY product_id, position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &images, query, pq.Array(productIds))
+	return images, err
+}
+
+func (r *productImageRepository) FindOneById(ctx context.Context, productId, id string) (*entities.ProductImage, error) {
+	var image entities.ProductImage
+
+	query := `
+		SELECT id, product_id, image_url, thumbnail_url, storage_key, thumbnail_key, position, is_primary, created_at
+		FROM product_images
+		WHERE id = $1 AND product_id = $2
+		LIMIT 1;
+	`
+
+	err := r.DB.GetContext(ctx, &image, query, id, productId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+func (r *productImageRepository) CountByProductID(ctx context.Context, productId string) (int, error) {
+	var count int
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, productId)
+	return count, err
+}
+
+// Delete removes the image. When it was the primary image, the first
+// remaining image takes its place.
+func (r *productImageRepository) Delete(ctx context.Context, productId, id string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM product WHERE id = $1 FOR UPDATE`, productId); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM product_images WHERE id = $1 AND product_id = $2`, id, productId); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE product_images SET is_primary = TRUE
+		WHERE id = (
+			SELECT id FROM product_images
+			WHERE product_id = $1
+			ORDER BY position ASC
+			LIMIT 1
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM product_images WHERE product_id = $1 AND is_primary
+		);
+	`
+	if _, err = tx.ExecContext(ctx, query, productId); err != nil {
+		return err
+	}
+
+	if err = syncPrimaryImage(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productImageRepository) SetPrimary(ctx context.Context, productId, id string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM product WHERE id = $1 FOR UPDATE`, productId); err != nil {
+		return err
+	}
+
+	// Clear first, the unique index allows a single primary per product
+	if _, err = tx.ExecContext(ctx, `UPDATE product_images SET is_primary = FALSE WHERE product_id = $1 AND is_primary`, productId); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE product_images SET is_primary = TRUE WHERE id = $1 AND product_id = $2`, id, productId)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = syncPrimaryImage(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reorder sets the position of each image to its index in ids.
+func (r *productImageRepository) Reorder(ctx context.Context, productId string, ids []string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for position, id := range ids {
+		_, err = tx.ExecContext(ctx, `UPDATE product_images SET position = $1 WHERE id = $2 AND product_id = $3`, position, id, productId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// syncPrimaryImage copies the primary image url to product.image, which is
+// what older clients and the chat read.
+func syncPrimaryImage(ctx context.Context, tx *sqlx.Tx, productId string) error {
+	query := `
+		UPDATE product SET image = (
+			SELECT image_url FROM product_images WHERE product_id = $1 AND is_primary
+		), updated_at = NOW()
+		WHERE id = $1;
+	`
+	_, err := tx.ExecContext(ctx, query, productId)
+	return err
+}
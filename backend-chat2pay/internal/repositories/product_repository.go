@@ -2,32 +2,121 @@ package repositories
 
 import (
 	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/attributes"
 	"context"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
+	"strings"
 )
 
 type ProductRepository interface {
-	Create(ctx context.Context, product *entities.Product) (*entities.Product, error)
+	Create(ctx context.Context, product *entities.Product, actorId *string) (*entities.Product, error)
 	FindAll(ctx context.Context, merchantId string, limit, offset int) ([]entities.Product, error)
 	FindByID(ctx context.Context, id string) (*entities.Product, error)
 	FindByIDs(ctx context.Context, ids []string) ([]entities.Product, error)
 	FindOneById(ctx context.Context, id string) (*entities.Product, error)
+	FindActiveById(ctx context.Context, id string) (*entities.Product, error)
 	FindByCategoryId(ctx context.Context, categoryId string, limit, offset int) ([]entities.Product, error)
-	Update(ctx context.Context, product *entities.Product) (*entities.Product, error)
-	Delete(ctx context.Context, id string) error
-	UpdateStock(ctx context.Context, id string, quantity int) error
+	Update(ctx context.Context, product *entities.Product, actorId *string) (*entities.Product, error)
+	Archive(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	PublishDue(ctx context.Context) ([]string, error)
+	UnpublishDue(ctx context.Context) ([]string, error)
 	Count(ctx context.Context, merchantId string) (int64, error)
+	Search(ctx context.Context, filter ProductFilter, limit, offset int) ([]entities.Product, error)
+	CountSearch(ctx context.Context, filter ProductFilter) (int64, error)
+	CorrectTerms(ctx context.Context, merchantId string, words []string) (map[string]string, error)
+	FindBoughtTogether(ctx context.Context, productId string, limit int) ([]entities.Product, error)
+	FindIDsBySKU(ctx context.Context, merchantId string, skus []string) (map[string]string, error)
+	UpsertBySKU(ctx context.Context, products []entities.Product, columns []string, actorId *string) ([]string, error)
+	QueryByMerchant(ctx context.Context, merchantId string) (*ProductRows, error)
+	FindPriceHistory(ctx context.Context, productId string, limit, offset int) ([]entities.PriceChange, error)
+	CountPriceHistory(ctx context.Context, productId string) (int64, error)
 
 	CreateProductEmbedding(ctx context.Context, embedding *entities.ProductEmbedding) error
+	ReplaceProductEmbeddings(ctx context.Context, productId string, embeddings []entities.ProductEmbedding) error
 	GetProductEmbedding(ctx context.Context, vector []float32) (*entities.ProductEmbedding, error)
-	GetProductEmbeddingList(ctx context.Context, vector []float32) ([]entities.ProductEmbedding, error)
-	GetProductEmbeddingListWithPrice(ctx context.Context, vector []float32, maxPrice float64) ([]entities.ProductEmbedding, error)
+	GetProductEmbeddingList(ctx context.Context, vector []float32, categoryIds []string) ([]entities.ProductEmbedding, error)
+	GetProductEmbeddingListWithPrice(ctx context.Context, vector []float32, maxPrice float64, categoryIds []string) ([]entities.ProductEmbedding, error)
+	GetSimilarProductEmbeddingList(ctx context.Context, productId string) ([]entities.ProductEmbedding, error)
+	FindOutOfStockMatches(ctx context.Context, vector []float32, maxPrice float64, categoryIds []string, limit int) ([]string, error)
 }
 
+// ProductFilter narrows down a product listing. Empty fields do not filter,
+// except that archived products are only listed when Status asks for them.
+type ProductFilter struct {
+	MerchantID  string
+	Status      string
+	Terms       []string // any of the terms must appear in the name
+	CategoryIDs []string // the product must be in one of the categories
+	Attributes  []attributes.Filter
+}
+
+func (f ProductFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conditions = append(conditions, fmt.Sprintf("status::text = $%d", len(args)))
+	} else {
+		conditions = append(conditions, "status <> 'archived'::public.product_status_enum")
+	}
+
+	if f.MerchantID != "" {
+		args = append(args, f.MerchantID)
+		conditions = append(conditions, fmt.Sprintf("merchant_id::text = $%d", len(args)))
+	}
+	if len(f.Terms) > 0 {
+		args = append(args, pq.Array(likePatterns(f.Terms)))
+		conditions = append(conditions, fmt.Sprintf("lower(name) LIKE ANY($%d)", len(args)))
+	}
+	if len(f.CategoryIDs) > 0 {
+		args = append(args, pq.Array(f.CategoryIDs))
+		conditions = append(conditions, fmt.Sprintf("category_id::text = ANY($%d)", len(args)))
+	}
+	for _, attribute := range f.Attributes {
+		args = append(args, attribute.Key)
+		key := len(args)
+		// Casting is only safe on values stored as numbers
+		isNumber := fmt.Sprintf("jsonb_typeof(attributes->$%d) = 'number'", key)
+
+		if attribute.Value != "" {
+			args = append(args, attribute.Value, attribute.Number)
+			conditions = append(conditions, fmt.Sprintf(
+				"CASE WHEN %s THEN (attributes->>$%d)::numeric = $%d::numeric ELSE lower(attributes->>$%d) = lower($%d) END",
+				isNumber, key, len(args), key, len(args)-1,
+			))
+		}
+		if attribute.Min != nil {
+			args = append(args, *attribute.Min)
+			conditions = append(conditions, fmt.Sprintf(
+				"CASE WHEN %s THEN (attributes->>$%d)::numeric >= $%d::numeric ELSE FALSE END", isNumber, key, len(args),
+			))
+		}
+		if attribute.Max != nil {
+			args = append(args, *attribute.Max)
+			conditions = append(conditions, fmt.Sprintf(
+				"CASE WHEN %s THEN (attributes->>$%d)::numeric <= $%d::numeric ELSE FALSE END", isNumber, key, len(args),
+			))
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// typoSimilarityThreshold is the minimum trigram similarity between a query
+// word and a word from a product name for the latter to count as a correction.
+const typoSimilarityThreshold = 0.4
+
+// embeddingCandidateLimit is the number of chunk matches fetched per search.
+// Several chunks may belong to the same product, so it is larger than the
+// number of products shown to the user.
+const embeddingCandidateLimit = 50
+
 type productRepository struct {
 	DB *sqlx.DB
 }
@@ -38,15 +127,24 @@ func NewProductRepo(db *sqlx.DB) ProductRepository {
 	}
 }
 
-func (r *productRepository) Create(ctx context.Context, product *entities.Product) (*entities.Product, error) {
+// Create inserts the product, records its initial stock in the ledger and
+// raises a stock alert when it starts at or below its threshold.
+func (r *productRepository) Create(ctx context.Context, product *entities.Product, actorId *string) (*entities.Product, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO product (
-		    id, merchant_id, outlet_id, category_id, name, description, sku, price, stock, status, image
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		    id, merchant_id, outlet_id, category_id, name, description, sku, price, stock, status, image,
+		    weight, length, width, height, low_stock_threshold, attributes, publish_at, unpublish_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
 		RETURNING id, created_at, updated_at;
 	`
 
-	err := r.DB.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		uuid.New().String(),
 		product.MerchantID,
 		product.OutletID,
@@ -58,9 +156,32 @@ func (r *productRepository) Create(ctx context.Context, product *entities.Produc
 		product.Stock,
 		product.Status,
 		product.Image,
+		product.Weight,
+		product.Length,
+		product.Width,
+		product.Height,
+		product.LowStockThreshold,
+		product.Attributes,
+		product.PublishAt,
+		product.UnpublishAt,
 	).Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = recordProductStock(ctx, tx, product, entities.StockMovementAdjustment, 0, actorId); err != nil {
+		return nil, err
+	}
+
+	if err = recordPriceChange(ctx, tx, product.ID, product.Price, nil, entities.PriceSourceManual); err != nil {
+		return nil, err
+	}
 
-	return product, err
+	if err = checkStockAlert(ctx, tx, product.ID); err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
 }
 
 func (r *productRepository) FindAll(ctx context.Context, merchantId string, limit, offset int) ([]entities.Product, error) {
@@ -73,7 +194,8 @@ func (r *productRepository) FindAll(ctx context.Context, merchantId string, limi
 		query = `
 			SELECT 
 				id, merchant_id, outlet_id, category_id, name, description, sku,
-				price, stock, status, image, created_at, updated_at
+				price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
 			FROM product 
 			ORDER BY created_at DESC
 			LIMIT $1 OFFSET $2;
@@ -83,7 +205,8 @@ func (r *productRepository) FindAll(ctx context.Context, merchantId string, limi
 		query = `
 			SELECT 
 				id, merchant_id, outlet_id, category_id, name, description, sku,
-				price, stock, status, image, created_at, updated_at
+				price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
 			FROM product 
 			WHERE merchant_id = $1
 			ORDER BY created_at DESC
@@ -101,7 +224,8 @@ func (r *productRepository) FindByIDs(ctx context.Context, ids []string) ([]enti
 	query := `
 		SELECT 
 			id, merchant_id, outlet_id, category_id, name, description, sku,
-			price, stock, status, image, created_at, updated_at
+			price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
 		FROM product 
 		WHERE id = ANY($1)
 		ORDER BY created_at DESC;
@@ -123,8 +247,17 @@ func (r *productRepository) FindByIDs(ctx context.Context, ids []string) ([]enti
 			&p.SKU,
 			&p.Price,
 			&p.Stock,
+			&p.LowStockThreshold,
 			&p.Status,
 			&p.Image,
+			&p.Weight,
+			&p.Length,
+			&p.Width,
+			&p.Height,
+			&p.Attributes,
+			&p.PublishAt,
+			&p.UnpublishAt,
+			&p.ArchivedAt,
 			&p.CreatedAt,
 			&p.UpdatedAt); err != nil {
 			return nil, err
@@ -141,7 +274,8 @@ func (r *productRepository) FindOneById(ctx context.Context, id string) (*entiti
 	query := `
 		SELECT 
 			id, merchant_id, outlet_id, category_id, name, description, sku,
-			price, stock, status, image, created_at, updated_at
+			price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
 		FROM product WHERE id = $1 LIMIT 1;
 	`
 
@@ -155,8 +289,17 @@ func (r *productRepository) FindOneById(ctx context.Context, id string) (*entiti
 		&p.SKU,
 		&p.Price,
 		&p.Stock,
+		&p.LowStockThreshold,
 		&p.Status,
 		&p.Image,
+		&p.Weight,
+		&p.Length,
+		&p.Width,
+		&p.Height,
+		&p.Attributes,
+		&p.PublishAt,
+		&p.UnpublishAt,
+		&p.ArchivedAt,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -171,6 +314,17 @@ func (r *productRepository) FindOneById(ctx context.Context, id string) (*entiti
 	return &p, nil
 }
 
+// FindActiveById returns the product only while it is active, which is how
+// customers are allowed to see it. Drafts, inactive and archived products
+// are reported as not found.
+func (r *productRepository) FindActiveById(ctx context.Context, id string) (*entities.Product, error) {
+	product, err := r.FindOneById(ctx, id)
+	if err != nil || product == nil || product.Status != entities.ProductStatusActive {
+		return nil, err
+	}
+	return product, nil
+}
+
 func (r *productRepository) FindByID(ctx context.Context, id string) (*entities.Product, error) {
 	return r.FindOneById(ctx, id)
 }
@@ -181,7 +335,8 @@ func (r *productRepository) FindByCategoryId(ctx context.Context, categoryId str
 	query := `
 		SELECT 
 			id, merchant_id, outlet_id, category_id, name, description, sku,
-			price, stock, status, image, created_at, updated_at
+			price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
 		FROM product 
 		WHERE category_id = $1
 		ORDER BY created_at DESC
@@ -192,17 +347,41 @@ func (r *productRepository) FindByCategoryId(ctx context.Context, categoryId str
 	return products, err
 }
 
-func (r *productRepository) Update(ctx context.Context, product *entities.Product) (*entities.Product, error) {
+// Update saves the product. A product with variants or outlet stock keeps
+// the total of those as its stock; any other change of stock is recorded in
+// the ledger, as is a change of price in the price history. Its stock alert
+// follows the stock and threshold saved. The image is left to the gallery.
+func (r *productRepository) Update(ctx context.Context, product *entities.Product, actorId *string) (*entities.Product, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previous struct {
+		Stock int     `db:"stock"`
+		Price float64 `db:"price"`
+	}
+	if err = tx.GetContext(ctx, &previous, `SELECT stock, price FROM product WHERE id = $1 FOR UPDATE`, product.ID); err != nil {
+		return nil, err
+	}
+
 	query := `
 		UPDATE product
 		SET merchant_id=$1, outlet_id=$2, category_id=$3, name=$4,
-			description=$5, sku=$6, price=$7, stock=$8, status=$9, 
+			description=$5, sku=$6, price=$7,
+			stock=CASE
+				WHEN EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = product.id)
+				OR EXISTS (SELECT 1 FROM outlet_stocks s WHERE s.product_id = product.id)
+				THEN product.stock ELSE $8 END,
+			status=$9, weight=$10, length=$11, width=$12, height=$13,
+			low_stock_threshold=$14, attributes=$15, publish_at=$16, unpublish_at=$17,
 			updated_at = NOW()
-		WHERE id=$10
-		RETURNING updated_at;
+		WHERE id=$18
+		RETURNING stock, image, updated_at;
 	`
 
-	err := r.DB.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		product.MerchantID,
 		product.OutletID,
 		product.CategoryID,
@@ -212,25 +391,140 @@ func (r *productRepository) Update(ctx context.Context, product *entities.Produc
 		product.Price,
 		product.Stock,
 		product.Status,
+		product.Weight,
+		product.Length,
+		product.Width,
+		product.Height,
+		product.LowStockThreshold,
+		product.Attributes,
+		product.PublishAt,
+		product.UnpublishAt,
 		product.ID,
-	).Scan(&product.UpdatedAt)
+	).Scan(&product.Stock, &product.Image, &product.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
 
-	return product, err
+	if err = recordProductStock(ctx, tx, product, entities.StockMovementAdjustment, previous.Stock, actorId); err != nil {
+		return nil, err
+	}
+
+	if err = recordPriceChange(ctx, tx, product.ID, product.Price, &previous.Price, entities.PriceSourceManual); err != nil {
+		return nil, err
+	}
+
+	if err = checkStockAlert(ctx, tx, product.ID); err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
 }
 
-func (r *productRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, id)
-	return err
+// recordProductStock records a change of the stock held on the product
+// itself, from previousStock to product.Stock, made by the merchant user.
+func recordProductStock(ctx context.Context, tx *sqlx.Tx, product *entities.Product, movementType string, previousStock int, actorId *string) error {
+	if product.Stock == previousStock {
+		return nil
+	}
+
+	return recordMovement(ctx, tx, &entities.StockMovement{
+		ProductID:  product.ID,
+		Type:       movementType,
+		Quantity:   product.Stock - previousStock,
+		StockAfter: product.Stock,
+		ActorType:  entities.StockActorMerchant,
+		ActorID:    actorId,
+	})
 }
 
-func (r *productRepository) UpdateStock(ctx context.Context, id string, quantity int) error {
-	_, err := r.DB.ExecContext(ctx,
-		`UPDATE product SET stock = $1, updated_at = NOW() WHERE id = $2`,
-		quantity, id,
+// recordPriceChange adds the price of the product to its price history,
+// unless it is the same as the previous price.
+func recordPriceChange(ctx context.Context, tx *sqlx.Tx, productId string, price float64, previousPrice *float64, source string) error {
+	if previousPrice != nil && *previousPrice == price {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO price_history (product_id, price, previous_price, source) VALUES ($1, $2, $3, $4)`,
+		productId, price, previousPrice, source,
 	)
 	return err
 }
 
+func (r *productRepository) FindPriceHistory(ctx context.Context, productId string, limit, offset int) ([]entities.PriceChange, error) {
+	changes := []entities.PriceChange{}
+
+	query := `
+		SELECT id, product_id, price, previous_price, source, created_at
+		FROM price_history
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3;
+	`
+
+	err := r.DB.SelectContext(ctx, &changes, query, productId, limit, offset)
+	return changes, err
+}
+
+func (r *productRepository) CountPriceHistory(ctx context.Context, productId string) (int64, error) {
+	var count int64
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM price_history WHERE product_id = $1`, productId)
+	return count, err
+}
+
+// Archive soft deletes the product. It is hidden from listings and search,
+// but kept for the orders that refer to it. Its schedule is cancelled.
+func (r *productRepository) Archive(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE product
+		SET status = 'archived', archived_at = NOW(), publish_at = NULL, unpublish_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status <> 'archived';
+	`, id)
+	return err
+}
+
+// Restore brings an archived product back as inactive, so the merchant can
+// review it before publishing it again.
+func (r *productRepository) Restore(ctx context.Context, id string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE product SET status = 'inactive', archived_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'archived';
+	`, id)
+	return err
+}
+
+// PublishDue activates the products whose publish time has passed and
+// returns their ids.
+func (r *productRepository) PublishDue(ctx context.Context) ([]string, error) {
+	ids := []string{}
+
+	query := `
+		UPDATE product SET status = 'active', publish_at = NULL, updated_at = NOW()
+		WHERE publish_at <= NOW() AND status <> 'archived'
+		RETURNING id;
+	`
+
+	err := r.DB.SelectContext(ctx, &ids, query)
+	return ids, err
+}
+
+// UnpublishDue deactivates the products whose unpublish time has passed and
+// returns their ids.
+func (r *productRepository) UnpublishDue(ctx context.Context) ([]string, error) {
+	ids := []string{}
+
+	query := `
+		UPDATE product
+		SET status = CASE WHEN status = 'active' THEN 'inactive'::public.product_status_enum ELSE status END,
+			unpublish_at = NULL, updated_at = NOW()
+		WHERE unpublish_at <= NOW() AND status <> 'archived'
+		RETURNING id;
+	`
+
+	err := r.DB.SelectContext(ctx, &ids, query)
+	return ids, err
+}
+
 func (r *productRepository) Count(ctx context.Context, merchantId string) (int64, error) {
 	var count int64
 	var err error
@@ -244,6 +538,356 @@ func (r *productRepository) Count(ctx context.Context, merchantId string) (int64
 	return count, err
 }
 
+// Search lists the products matching the filter. When the filter has terms
+// the products are ranked by trigram similarity of their name to the terms,
+// otherwise the newest come first.
+func (r *productRepository) Search(ctx context.Context, filter ProductFilter, limit, offset int) ([]entities.Product, error) {
+	products := []entities.Product{}
+
+	where, args := filter.where()
+	order := "created_at DESC"
+	if len(filter.Terms) > 0 {
+		args = append(args, strings.Join(filter.Terms, " "))
+		order = fmt.Sprintf("similarity(lower(name), $%d) DESC, created_at DESC", len(args))
+	}
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT 
+			id, merchant_id, outlet_id, category_id, name, description, sku,
+			price, stock, low_stock_threshold, status, image, weight, length, width, height, attributes,
+			publish_at, unpublish_at, archived_at, created_at, updated_at
+		FROM product 
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d;
+	`, where, order, len(args)-1, len(args))
+
+	err := r.DB.SelectContext(ctx, &products, query, args...)
+	return products, err
+}
+
+func (r *productRepository) CountSearch(ctx context.Context, filter ProductFilter) (int64, error) {
+	var count int64
+
+	where, args := filter.where()
+	err := r.DB.GetContext(ctx, &count, fmt.Sprintf(`SELECT COUNT(*) FROM product WHERE %s`, where), args...)
+	return count, err
+}
+
+// CorrectTerms looks up the closest word in active product names for each of
+// the given words. Words that already appear in a product name, or that have
+// no close match, are left out of the result. Only the names holding a word
+// close to the given one are read, found with the trigram index on names.
+func (r *productRepository) CorrectTerms(ctx context.Context, merchantId string, words []string) (map[string]string, error) {
+	corrections := map[string]string{}
+	if len(words) == 0 {
+		return corrections, nil
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The <% operator matches at this threshold, a name holding a word with
+	// the similarity wanted below always passes it
+	_, err = tx.ExecContext(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, fmt.Sprint(typoSimilarityThreshold))
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.word, v.word
+		FROM unnest($2::text[]) AS t(word)
+		CROSS JOIN LATERAL (
+			SELECT w.word
+			FROM product p
+			CROSS JOIN LATERAL regexp_split_to_table(lower(p.name), '[^[:alnum:]]+') AS w(word)
+			WHERE t.word <% lower(p.name)
+			AND p.status = 'active'::public.product_status_enum
+			AND ($1 = '' OR p.merchant_id::text = $1)
+			AND w.word <> '' AND similarity(w.word, t.word) >= $3
+			ORDER BY similarity(w.word, t.word) DESC, w.word ASC
+			LIMIT 1
+		) v
+		WHERE v.word <> t.word;
+	`
+
+	rows, err := tx.QueryContext(ctx, query, merchantId, pq.Array(words), typoSimilarityThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var word, correction string
+		if err := rows.Scan(&word, &correction); err != nil {
+			return nil, err
+		}
+		corrections[word] = correction
+	}
+
+	return corrections, rows.Err()
+}
+
+// FindBoughtTogether returns the products that appear most often in the same
+// orders as the given product. Cancelled orders are ignored.
+func (r *productRepository) FindBoughtTogether(ctx context.Context, productId string, limit int) ([]entities.Product, error) {
+	products := []entities.Product{}
+
+	query := `
+		SELECT 
+			p.id, p.merchant_id, p.outlet_id, p.category_id, p.name, p.description, p.sku,
+			p.price, p.stock, p.low_stock_threshold, p.status, p.image, p.weight, p.length, p.width, p.height, p.attributes,
+			p.publish_at, p.unpublish_at, p.archived_at, p.created_at, p.updated_at
+		FROM (
+			SELECT other.product_id, COUNT(DISTINCT other.order_id) AS order_count
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			JOIN order_items other ON other.order_id = oi.order_id AND other.product_id <> oi.product_id
+			WHERE oi.product_id = $1
+			AND o.status <> $2
+			GROUP BY other.product_id
+		) together
+		JOIN product p ON p.id = together.product_id
+		WHERE p.status = 'active'::public.product_status_enum
+		AND p.stock > 0
+		ORDER BY together.order_count DESC, p.created_at DESC
+		LIMIT $3;
+	`
+
+	err := r.DB.SelectContext(ctx, &products, query, productId, entities.OrderStatusCancelled, limit)
+	return products, err
+}
+
+// FindIDsBySKU maps each of the given SKUs that the merchant already uses to
+// the product id.
+func (r *productRepository) FindIDsBySKU(ctx context.Context, merchantId string, skus []string) (map[string]string, error) {
+	ids := map[string]string{}
+	if len(skus) == 0 {
+		return ids, nil
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT sku, id FROM product WHERE merchant_id = $1 AND sku = ANY($2)`,
+		merchantId, pq.Array(skus),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sku, id string
+		if err := rows.Scan(&sku, &id); err != nil {
+			return nil, err
+		}
+		ids[sku] = id
+	}
+
+	return ids, rows.Err()
+}
+
+// upsertColumns are the columns an upsert may overwrite on an existing
+// product. The stock of a product with variants or outlet stock is left to
+// those.
+var upsertColumns = map[string]string{
+	"name":        "name = EXCLUDED.name",
+	"description": "description = EXCLUDED.description",
+	"category_id": "category_id = EXCLUDED.category_id",
+	"price":       "price = EXCLUDED.price",
+	"stock":       "stock = CASE WHEN EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = product.id) OR EXISTS (SELECT 1 FROM outlet_stocks s WHERE s.product_id = product.id) THEN product.stock ELSE EXCLUDED.stock END",
+	"status":      "status = EXCLUDED.status, archived_at = CASE WHEN EXCLUDED.status = 'archived' THEN COALESCE(product.archived_at, NOW()) END",
+	"weight":      "weight = EXCLUDED.weight",
+	"length":      "length = EXCLUDED.length",
+	"width":       "width = EXCLUDED.width",
+	"height":      "height = EXCLUDED.height",
+}
+
+// UpsertBySKU creates or updates the products, matched on merchant and SKU,
+// in a single transaction. Existing products only get the given columns
+// overwritten. Stock and price changes are recorded in the ledger and the
+// price history as an import.
+// It returns the product ids in the order of products.
+func (r *productRepository) UpsertBySKU(ctx context.Context, products []entities.Product, columns []string, actorId *string) ([]string, error) {
+	set := []string{"updated_at = NOW()"}
+	for _, column := range columns {
+		if clause, ok := upsertColumns[column]; ok {
+			set = append(set, clause)
+		}
+	}
+
+	// previous reads the stock and price before the statement, they are
+	// NULL for a new product
+	query := `
+		WITH previous AS (
+			SELECT stock, price FROM product WHERE merchant_id = $2 AND sku = $7 FOR UPDATE
+		)
+		INSERT INTO product (
+		    id, merchant_id, outlet_id, category_id, name, description, sku, price, stock, status, image,
+		    weight, length, width, height, low_stock_threshold, attributes, archived_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,
+			CASE WHEN $10 = 'archived'::public.product_status_enum THEN NOW() END)
+		ON CONFLICT (merchant_id, sku) DO UPDATE SET ` + strings.Join(set, ", ") + `
+		RETURNING id, stock, price, COALESCE((SELECT stock FROM previous), 0), (SELECT price FROM previous);
+	`
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	ids := make([]string, len(products))
+	for i, product := range products {
+		var previousStock int
+		var previousPrice *float64
+		err = stmt.QueryRowContext(ctx,
+			uuid.New().String(),
+			product.MerchantID,
+			product.OutletID,
+			product.CategoryID,
+			product.Name,
+			product.Description,
+			product.SKU,
+			product.Price,
+			product.Stock,
+			product.Status,
+			product.Image,
+			product.Weight,
+			product.Length,
+			product.Width,
+			product.Height,
+			product.LowStockThreshold,
+			product.Attributes,
+		).Scan(&ids[i], &product.Stock, &product.Price, &previousStock, &previousPrice)
+		if err != nil {
+			return nil, fmt.Errorf("sku %s: %w", *product.SKU, err)
+		}
+
+		product.ID = ids[i]
+		if err = recordProductStock(ctx, tx, &product, entities.StockMovementImport, previousStock, actorId); err != nil {
+			return nil, fmt.Errorf("sku %s: %w", *product.SKU, err)
+		}
+
+		if err = recordPriceChange(ctx, tx, product.ID, product.Price, previousPrice, entities.PriceSourceImport); err != nil {
+			return nil, fmt.Errorf("sku %s: %w", *product.SKU, err)
+		}
+
+		if err = checkStockAlert(ctx, tx, product.ID); err != nil {
+			return nil, fmt.Errorf("sku %s: %w", *product.SKU, err)
+		}
+	}
+
+	return ids, tx.Commit()
+}
+
+// QueryByMerchant runs the query for every product of the merchant, with
+// its category and gallery. The rows are read one at a time from the
+// database and must be closed.
+func (r *productRepository) QueryByMerchant(ctx context.Context, merchantId string) (*ProductRows, error) {
+	query := `
+		SELECT
+			p.id, p.merchant_id, p.outlet_id, p.category_id, p.name, p.description, p.sku,
+			p.price, p.stock, p.status, p.image, p.weight, p.length, p.width, p.height, p.created_at, p.updated_at,
+			c.name,
+			ARRAY(
+				SELECT pi.image_url FROM product_images pi
+				WHERE pi.product_id = p.id
+				ORDER BY pi.position ASC
+			)
+		FROM product p
+		LEFT JOIN product_categories c ON c.id = p.category_id
+		WHERE p.merchant_id = $1
+		ORDER BY p.created_at ASC, p.id ASC;
+	`
+
+	rows, err := r.DB.QueryxContext(ctx, query, merchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProductRows{rows: rows}, nil
+}
+
+// ProductRows iterates over the products read by QueryByMerchant.
+type ProductRows struct {
+	rows *sqlx.Rows
+}
+
+// Next prepares the next product, returning false at the end of the rows or
+// on an error, which Err then reports.
+func (r *ProductRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *ProductRows) Product() (*entities.Product, error) {
+	var (
+		p            entities.Product
+		categoryName *string
+		imageURLs    pq.StringArray
+	)
+
+	err := r.rows.Scan(
+		&p.ID,
+		&p.MerchantID,
+		&p.OutletID,
+		&p.CategoryID,
+		&p.Name,
+		&p.Description,
+		&p.SKU,
+		&p.Price,
+		&p.Stock,
+		&p.Status,
+		&p.Image,
+		&p.Weight,
+		&p.Length,
+		&p.Width,
+		&p.Height,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&categoryName,
+		&imageURLs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.CategoryID != nil && categoryName != nil {
+		p.Category = &entities.ProductCategory{ID: *p.CategoryID, Name: *categoryName}
+	}
+	for _, url := range imageURLs {
+		p.Images = append(p.Images, entities.ProductImage{ProductID: p.ID, ImageURL: url})
+	}
+
+	return &p, nil
+}
+
+func (r *ProductRows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *ProductRows) Close() error {
+	return r.rows.Close()
+}
+
+func likePatterns(terms []string) []string {
+	patterns := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(term))
+		patterns = append(patterns, "%"+term+"%")
+	}
+	return patterns
+}
+
 func (r *productRepository) GetProductEmbedding(ctx context.Context, vector []float32) (*entities.ProductEmbedding, error) {
 	query := fmt.Sprintf(`SELECT id, product_id, embedding <-> $1 AS distance
 		FROM product_embedding
@@ -259,7 +903,7 @@ func (r *productRepository) GetProductEmbedding(ctx context.Context, vector []fl
 	return embed, nil
 }
 
-func (r *productRepository) GetProductEmbeddingList(ctx context.Context, vector []float32) ([]entities.ProductEmbedding, error) {
+func (r *productRepository) GetProductEmbeddingList(ctx context.Context, vector []float32, categoryIds []string) ([]entities.ProductEmbedding, error) {
 	embeddingQuery := `
         SELECT 
             pe.id,
@@ -270,6 +914,7 @@ func (r *productRepository) GetProductEmbeddingList(ctx context.Context, vector
         WHERE p.status = 'active'::public.product_status_enum
         AND p.stock > 0
         AND 1 - (pe.embedding <=> $1) > $2  -- Minimum similarity threshold (0.3 = 70% similarity)
+        AND (cardinality($4::text[]) = 0 OR p.category_id::text = ANY($4))
         ORDER BY similarity_score DESC
         LIMIT $3
     `
@@ -280,7 +925,8 @@ func (r *productRepository) GetProductEmbeddingList(ctx context.Context, vector
 	rows, err := r.DB.QueryContext(ctx, embeddingQuery,
 		pgvector.NewVector(vector),
 		0.3, // Minimum 70% similarity
-		10,
+		embeddingCandidateLimit,
+		pq.Array(append([]string{}, categoryIds...)),
 	)
 	if err != nil {
 		return nil, err
@@ -298,7 +944,34 @@ func (r *productRepository) GetProductEmbeddingList(ctx context.Context, vector
 
 	return results, nil
 }
-func (r *productRepository) GetProductEmbeddingListWithPrice(ctx context.Context, vector []float32, maxPrice float64) ([]entities.ProductEmbedding, error) {
+
+// discountedPrice is the price of product p after the best of the discounts
+// running now, set on the product or on its category or one of the
+// ancestors of its category. It matches the price pricing.Best gives with
+// the discounts of FindRunningByProductIDs, so a budget is compared with
+// what the customer pays.
+const discountedPrice = `
+	COALESCE((
+		SELECT MIN(GREATEST(0, ROUND(
+			CASE WHEN d.type = 'percentage' THEN p.price * (100 - d.value) / 100 ELSE p.price - d.value END
+		)))
+		FROM discounts d
+		WHERE d.merchant_id = p.merchant_id
+		AND d.starts_at <= NOW() AND d.ends_at > NOW()
+		AND (
+			d.product_id = p.id
+			OR d.category_id IN (
+				WITH RECURSIVE category_tree AS (
+					SELECT c.id, c.parent_id FROM product_categories c WHERE c.id = p.category_id
+					UNION ALL
+					SELECT c.id, c.parent_id FROM product_categories c JOIN category_tree t ON c.id = t.parent_id
+				)
+				SELECT id FROM category_tree
+			)
+		)
+	), p.price)`
+
+func (r *productRepository) GetProductEmbeddingListWithPrice(ctx context.Context, vector []float32, maxPrice float64, categoryIds []string) ([]entities.ProductEmbedding, error) {
 	embeddingQuery := `
         SELECT 
             pe.id,
@@ -308,8 +981,9 @@ func (r *productRepository) GetProductEmbeddingListWithPrice(ctx context.Context
         JOIN product p ON pe.product_id = p.id
         WHERE p.status = 'active'::public.product_status_enum
         AND p.stock > 0
-        AND p.price <= $2
+        AND ` + discountedPrice + ` <= $2
         AND 1 - (pe.embedding <=> $1) > $3
+        AND (cardinality($5::text[]) = 0 OR p.category_id::text = ANY($5))
         ORDER BY similarity_score DESC
         LIMIT $4
     `
@@ -318,8 +992,84 @@ func (r *productRepository) GetProductEmbeddingListWithPrice(ctx context.Context
 		pgvector.NewVector(vector),
 		maxPrice,
 		0.2, // Lower threshold for price-filtered search
-		10,
+		embeddingCandidateLimit,
+		pq.Array(append([]string{}, categoryIds...)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []entities.ProductEmbedding
+	for rows.Next() {
+		var pe entities.ProductEmbedding
+		if err := rows.Scan(&pe.ID, &pe.ProductId, &pe.Similarity); err != nil {
+			return nil, err
+		}
+		results = append(results, pe)
+	}
+
+	return results, nil
+}
+
+// FindOutOfStockMatches returns the ids of the active products that are out
+// of stock but would otherwise match the search, best match first. It uses
+// the similarity thresholds of the search, a maxPrice of 0 does not filter.
+func (r *productRepository) FindOutOfStockMatches(ctx context.Context, vector []float32, maxPrice float64, categoryIds []string, limit int) ([]string, error) {
+	threshold := 0.3
+	if maxPrice > 0 {
+		threshold = 0.2
+	}
+
+	query := `
+		SELECT p.id
+		FROM product_embedding pe
+		JOIN product p ON pe.product_id = p.id
+		WHERE p.status = 'active'::public.product_status_enum
+		AND p.stock <= 0
+		AND ($2::numeric = 0 OR ` + discountedPrice + ` <= $2::numeric)
+		AND 1 - (pe.embedding <=> $1) > $3
+		AND (cardinality($4::text[]) = 0 OR p.category_id::text = ANY($4))
+		GROUP BY p.id
+		ORDER BY MAX(1 - (pe.embedding <=> $1)) DESC
+		LIMIT $5;
+	`
+
+	ids := []string{}
+	err := r.DB.SelectContext(ctx, &ids, query,
+		pgvector.NewVector(vector),
+		maxPrice,
+		threshold,
+		pq.Array(append([]string{}, categoryIds...)),
+		limit,
 	)
+	return ids, err
+}
+
+// GetSimilarProductEmbeddingList finds the nearest chunks of other in-stock
+// products for every chunk of the given product.
+func (r *productRepository) GetSimilarProductEmbeddingList(ctx context.Context, productId string) ([]entities.ProductEmbedding, error) {
+	embeddingQuery := `
+        SELECT nearest.id, nearest.product_id, nearest.similarity_score
+        FROM product_embedding src
+        CROSS JOIN LATERAL (
+            SELECT 
+                pe.id,
+                pe.product_id,
+                1 - (pe.embedding <=> src.embedding) as similarity_score
+            FROM product_embedding pe
+            JOIN product p ON pe.product_id = p.id
+            WHERE pe.product_id <> src.product_id
+            AND p.status = 'active'::public.product_status_enum
+            AND p.stock > 0
+            ORDER BY pe.embedding <=> src.embedding
+            LIMIT $2
+        ) nearest
+        WHERE src.product_id = $1
+        ORDER BY nearest.similarity_score DESC
+    `
+
+	rows, err := r.DB.QueryContext(ctx, embeddingQuery, productId, embeddingCandidateLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -340,14 +1090,43 @@ func (r *productRepository) GetProductEmbeddingListWithPrice(ctx context.Context
 func (r *productRepository) CreateProductEmbedding(ctx context.Context, embedding *entities.ProductEmbedding) error {
 	query := `
 		INSERT INTO product_embedding (
-			id, product_id, content, embedding
-		) VALUES ($1,$2,$3,$4);
+			id, product_id, chunk_index, content, embedding
+		) VALUES ($1,$2,$3,$4,$5);
 	`
 
-	_, err := r.DB.ExecContext(ctx, query, uuid.New().String(), embedding.ProductId, embedding.Content, pgvector.NewVector(embedding.Embedding))
+	_, err := r.DB.ExecContext(ctx, query, uuid.New().String(), embedding.ProductId, embedding.ChunkIndex, embedding.Content, pgvector.NewVector(embedding.Embedding))
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// ReplaceProductEmbeddings swaps the embeddings of a product for the given
+// chunks in one transaction, so a failed save leaves the previous ones in
+// place and the product stays searchable.
+func (r *productRepository) ReplaceProductEmbeddings(ctx context.Context, productId string, embeddings []entities.ProductEmbedding) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM product_embedding WHERE product_id = $1`, productId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO product_embedding (
+			id, product_id, chunk_index, content, embedding
+		) VALUES ($1,$2,$3,$4,$5);
+	`
+	for _, embedding := range embeddings {
+		_, err = tx.ExecContext(ctx, query, uuid.New().String(), productId, embedding.ChunkIndex, embedding.Content, pgvector.NewVector(embedding.Embedding))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,315 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func productStatus(t *testing.T, db *sqlx.DB, productID string) string {
+	var status string
+	require.NoError(t, db.Get(&status, `SELECT status FROM product WHERE id = $1`, productID))
+	return status
+}
+
+// seedStatuses adds a product in each of the other statuses next to the
+// active one made by seedProduct.
+func seedStatuses(t *testing.T, db *sqlx.DB, merchantID string) map[string]string {
+	ids := map[string]string{}
+	for _, status := range []string{entities.ProductStatusDraft, entities.ProductStatusInactive, entities.ProductStatusArchived} {
+		ids[status] = uuid.New().String()
+		db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock, status) VALUES ($1, $2, 'Kaos', 50000, 1, $3)`,
+			ids[status], merchantID, status)
+	}
+	return ids
+}
+
+func TestProductRepository_Visibility(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	_, merchantID, activeID := seedProduct(t, db, 1)
+	hidden := seedStatuses(t, db, merchantID)
+
+	t.Run("Customers only reach active products", func(t *testing.T) {
+		product, err := repo.FindActiveById(ctx, activeID)
+		require.NoError(t, err)
+		require.NotNil(t, product)
+
+		for status, id := range hidden {
+			product, err = repo.FindActiveById(ctx, id)
+			require.NoError(t, err)
+			assert.Nil(t, product, status)
+		}
+
+		products, err := repo.Search(ctx, ProductFilter{MerchantID: merchantID, Status: entities.ProductStatusActive}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, activeID, products[0].ID)
+	})
+
+	t.Run("The merchant lists all but archived by default", func(t *testing.T) {
+		count, err := repo.CountSearch(ctx, ProductFilter{MerchantID: merchantID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		products, err := repo.Search(ctx, ProductFilter{MerchantID: merchantID, Status: entities.ProductStatusArchived}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, hidden[entities.ProductStatusArchived], products[0].ID)
+	})
+}
+
+func TestProductRepository_Lifecycle(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	_, merchantID, activeID := seedProduct(t, db, 1)
+	draftID := seedStatuses(t, db, merchantID)[entities.ProductStatusDraft]
+
+	t.Run("Publishes and unpublishes on schedule", func(t *testing.T) {
+		db.MustExec(`UPDATE product SET publish_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, draftID)
+		ids, err := repo.PublishDue(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, ids, draftID)
+		assert.Equal(t, entities.ProductStatusActive, productStatus(t, db, draftID))
+
+		db.MustExec(`UPDATE product SET unpublish_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, draftID)
+		ids, err = repo.UnpublishDue(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, ids, draftID)
+		assert.Equal(t, entities.ProductStatusInactive, productStatus(t, db, draftID))
+
+		product, err := repo.FindActiveById(ctx, draftID)
+		require.NoError(t, err)
+		assert.Nil(t, product)
+	})
+
+	t.Run("Archives and restores as inactive", func(t *testing.T) {
+		db.MustExec(`UPDATE product SET publish_at = NOW() + INTERVAL '1 day' WHERE id = $1`, activeID)
+		require.NoError(t, repo.Archive(ctx, activeID))
+
+		product, err := repo.FindOneById(ctx, activeID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ProductStatusArchived, product.Status)
+		assert.NotNil(t, product.ArchivedAt)
+		assert.Nil(t, product.PublishAt)
+
+		require.NoError(t, repo.Restore(ctx, activeID))
+
+		product, err = repo.FindOneById(ctx, activeID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ProductStatusInactive, product.Status)
+		assert.Nil(t, product.ArchivedAt)
+	})
+
+	t.Run("Leaves archived products out of the schedule", func(t *testing.T) {
+		require.NoError(t, repo.Archive(ctx, activeID))
+		db.MustExec(`UPDATE product SET publish_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, activeID)
+
+		ids, err := repo.PublishDue(ctx)
+		require.NoError(t, err)
+		assert.NotContains(t, ids, activeID)
+		assert.Equal(t, entities.ProductStatusArchived, productStatus(t, db, activeID))
+	})
+}
+
+// embeddingNear returns a unit vector pointing mostly along the first axis,
+// tilted towards the second one by tilt.
+func embeddingNear(tilt float32) []float32 {
+	vector := make([]float32, 1024)
+	vector[0] = 1
+	vector[1] = tilt
+	return vector
+}
+
+func TestProductRepository_GetSimilarProductEmbeddingList(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	_, merchantID, sourceID := seedProduct(t, db, 5)
+	products := map[string]string{}
+	for _, name := range []string{"near", "far", "sold out", "draft"} {
+		products[name] = uuid.New().String()
+		db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock) VALUES ($1, $2, $3, 50000, 5)`, products[name], merchantID, name)
+	}
+	db.MustExec(`UPDATE product SET stock = 0 WHERE id = $1`, products["sold out"])
+	db.MustExec(`UPDATE product SET status = 'draft' WHERE id = $1`, products["draft"])
+	t.Cleanup(func() {
+		db.MustExec(`DELETE FROM product_embedding WHERE product_id IN (SELECT id FROM product WHERE merchant_id = $1)`, merchantID)
+	})
+
+	tilts := map[string]float32{"near": 0.1, "far": 0.9, "sold out": 0, "draft": 0}
+	require.NoError(t, repo.CreateProductEmbedding(ctx, &entities.ProductEmbedding{ProductId: sourceID, Embedding: embeddingNear(0)}))
+	for name, tilt := range tilts {
+		require.NoError(t, repo.CreateProductEmbedding(ctx, &entities.ProductEmbedding{ProductId: products[name], Embedding: embeddingNear(tilt)}))
+	}
+
+	matches, err := repo.GetSimilarProductEmbeddingList(ctx, sourceID)
+	require.NoError(t, err)
+
+	ranks := map[string]int{}
+	for i, match := range matches {
+		if _, seen := ranks[match.ProductId]; !seen {
+			ranks[match.ProductId] = i
+		}
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		included bool
+	}{
+		{"The product itself", sourceID, false},
+		{"A close product", products["near"], true},
+		{"A distant product", products["far"], true},
+		{"An out of stock product", products["sold out"], false},
+		{"A draft", products["draft"], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found := ranks[tt.id]
+			assert.Equal(t, tt.included, found)
+		})
+	}
+
+	assert.Less(t, ranks[products["near"]], ranks[products["far"]])
+}
+
+func TestProductRepository_FindBoughtTogether(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	customerID, merchantID, coffeeID := seedProduct(t, db, 50)
+	products := map[string]string{}
+	for _, name := range []string{"gula", "susu", "sold out", "cancelled only"} {
+		products[name] = uuid.New().String()
+		db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock) VALUES ($1, $2, $3, 50000, 50)`, products[name], merchantID, name)
+	}
+
+	place := func(status string, names ...string) {
+		orderID := uuid.New().String()
+		db.MustExec(`INSERT INTO orders (id, customer_id, merchant_id, status) VALUES ($1, $2, $3, $4)`, orderID, customerID, merchantID, status)
+		for _, id := range append([]string{coffeeID}, names...) {
+			db.MustExec(`INSERT INTO order_items (id, order_id, product_id, product_name, product_price, quantity, subtotal)
+				VALUES ($1, $2, $3, 'Item', 50000, 1, 50000)`, uuid.New().String(), orderID, id)
+		}
+	}
+	place(entities.OrderStatusDelivered, products["gula"], products["susu"])
+	place(entities.OrderStatusPaid, products["gula"], products["sold out"])
+	place(entities.OrderStatusCancelled, products["cancelled only"])
+	db.MustExec(`UPDATE product SET stock = 0 WHERE id = $1`, products["sold out"])
+
+	together, err := repo.FindBoughtTogether(ctx, coffeeID, 10)
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, product := range together {
+		ids = append(ids, product.ID)
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"Bought together twice", products["gula"], true},
+		{"Bought together once", products["susu"], true},
+		{"Out of stock", products["sold out"], false},
+		{"Only in a cancelled order", products["cancelled only"], false},
+		{"The product itself", coffeeID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slices.Contains(ids, tt.id))
+		})
+	}
+
+	require.Len(t, ids, 2)
+	assert.Equal(t, products["gula"], ids[0])
+}
+
+func TestProductRepository_BudgetUsesTheDiscountedPrice(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	_, merchantID, _ := seedProduct(t, db, 5)
+
+	parentID, categoryID := uuid.New().String(), uuid.New().String()
+	db.MustExec(`INSERT INTO product_categories (id, name) VALUES ($1, 'Elektronik')`, parentID)
+	db.MustExec(`INSERT INTO product_categories (id, name, parent_id) VALUES ($1, 'Laptop', $2)`, categoryID, parentID)
+
+	products := map[string]string{}
+	for _, name := range []string{"product discount", "category discount", "no discount", "ended discount", "sold out"} {
+		products[name] = uuid.New().String()
+		db.MustExec(`INSERT INTO product (id, merchant_id, name, price, stock) VALUES ($1, $2, $3, 150000, 5)`, products[name], merchantID, name)
+		require.NoError(t, repo.CreateProductEmbedding(ctx, &entities.ProductEmbedding{ProductId: products[name], Embedding: embeddingNear(0)}))
+	}
+	db.MustExec(`UPDATE product SET category_id = $1 WHERE id = $2`, categoryID, products["category discount"])
+	db.MustExec(`UPDATE product SET stock = 0 WHERE id = $1`, products["sold out"])
+
+	discount := func(productID, categoryID *string, startsAt, endsAt string) {
+		db.MustExec(`INSERT INTO discounts (merchant_id, product_id, category_id, name, type, value, starts_at, ends_at)
+			VALUES ($1, $2, $3, 'Promo', 'percentage', 20, NOW() + $4::interval, NOW() + $5::interval)`,
+			merchantID, productID, categoryID, startsAt, endsAt)
+	}
+	for _, name := range []string{"product discount", "sold out"} {
+		id := products[name]
+		discount(&id, nil, "-1 day", "1 day")
+	}
+	endedID := products["ended discount"]
+	discount(&endedID, nil, "-2 days", "-1 day")
+	discount(nil, &parentID, "-1 day", "1 day")
+
+	t.Cleanup(func() {
+		db.MustExec(`DELETE FROM product_embedding WHERE product_id IN (SELECT id FROM product WHERE merchant_id = $1)`, merchantID)
+		db.MustExec(`DELETE FROM discounts WHERE merchant_id = $1`, merchantID)
+		db.MustExec(`UPDATE product SET category_id = NULL WHERE merchant_id = $1`, merchantID)
+		db.MustExec(`DELETE FROM product_categories WHERE id = $1`, categoryID)
+		db.MustExec(`DELETE FROM product_categories WHERE id = $1`, parentID)
+	})
+
+	// A 20% discount brings 150.000 down to 120.000
+	const budget = 130000
+
+	matches, err := repo.GetProductEmbeddingListWithPrice(ctx, embeddingNear(0), budget, nil)
+	require.NoError(t, err)
+	found := []string{}
+	for _, match := range matches {
+		found = append(found, match.ProductId)
+	}
+
+	soldOut, err := repo.FindOutOfStockMatches(ctx, embeddingNear(0), budget, nil, 10)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"Discounted on the product", products["product discount"], true},
+		{"Discounted on a parent category", products["category discount"], true},
+		{"Without a discount", products["no discount"], false},
+		{"With a discount that has ended", products["ended discount"], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slices.Contains(found, tt.id))
+		})
+	}
+
+	t.Run("An out of stock product discounted into the budget", func(t *testing.T) {
+		assert.Contains(t, soldOut, products["sold out"])
+	})
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strings"
+)
+
+type ProductReviewRepository interface {
+	Create(ctx context.Context, review *entities.ProductReview) error
+	Reply(ctx context.Context, merchantId, id, reply string) (bool, error)
+	Exists(ctx context.Context, orderId, productId string) (bool, error)
+	FindOneById(ctx context.Context, id string) (*entities.ProductReview, error)
+	Find(ctx context.Context, filter ProductReviewFilter, limit, offset int) ([]entities.ProductReview, error)
+	Count(ctx context.Context, filter ProductReviewFilter) (int64, error)
+	FindImagesByReviewIDs(ctx context.Context, reviewIds []string) ([]entities.ProductReviewImage, error)
+	FindRatingsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductRating, error)
+}
+
+// ProductReviewFilter narrows down a review listing. Empty fields do not
+// filter.
+type ProductReviewFilter struct {
+	ProductID  string
+	MerchantID string
+	CustomerID string
+	Rating     int
+	WithBody   bool // only reviews with a text
+	Unreplied  bool // only reviews the merchant has not replied to
+}
+
+func (f ProductReviewFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s::text = $%d", column, len(args)))
+		}
+	}
+	add("r.product_id", f.ProductID)
+	add("p.merchant_id", f.MerchantID)
+	add("r.customer_id", f.CustomerID)
+
+	if f.Rating > 0 {
+		args = append(args, f.Rating)
+		conditions = append(conditions, fmt.Sprintf("r.rating = $%d", len(args)))
+	}
+	if f.WithBody {
+		conditions = append(conditions, "COALESCE(TRIM(r.body), '') <> ''")
+	}
+	if f.Unreplied {
+		conditions = append(conditions, "r.merchant_reply IS NULL")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+const productReviewSelect = `
+	SELECT
+		r.id, r.product_id, r.order_id, r.customer_id, r.rating, r.body, r.merchant_reply, r.replied_at,
+		r.created_at, r.updated_at, c.name AS customer_name, p.name AS product_name
+	FROM product_reviews r
+	JOIN customers c ON c.id = r.customer_id
+	JOIN product p ON p.id = r.product_id
+`
+
+type productReviewRepository struct {
+	DB *sqlx.DB
+}
+
+func NewProductReviewRepository(db *sqlx.DB) ProductReviewRepository {
+	return &productReviewRepository{DB: db}
+}
+
+// Create stores the review with its photos and notifies the merchant of the
+// product.
+func (r *productReviewRepository) Create(ctx context.Context, review *entities.ProductReview) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if review.ID == "" {
+		review.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO product_reviews (id, product_id, order_id, customer_id, rating, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at;
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		review.ID,
+		review.ProductID,
+		review.OrderID,
+		review.CustomerID,
+		review.Rating,
+		review.Body,
+	).Scan(&review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range review.Images {
+		image := &review.Images[i]
+		image.ID = uuid.New().String()
+		image.ReviewID = review.ID
+		image.Position = i
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO product_review_images (id, review_id, image_url, thumbnail_url, storage_key, thumbnail_key, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING created_at;
+		`, image.ID, image.ReviewID, image.ImageURL, image.ThumbnailURL, image.StorageKey, image.ThumbnailKey, image.Position,
+		).Scan(&image.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	var product struct {
+		MerchantID string `db:"merchant_id"`
+		Name       string `db:"name"`
+	}
+	if err = tx.GetContext(ctx, &product, `SELECT merchant_id, name FROM product WHERE id = $1`, review.ProductID); err != nil {
+		return err
+	}
+	review.ProductName = product.Name
+
+	referenceType := "product_review"
+	notification := &entities.MerchantNotification{
+		MerchantID:    product.MerchantID,
+		Type:          entities.NotificationReview,
+		Title:         fmt.Sprintf("New %d star review for %s", review.Rating, product.Name),
+		Message:       fmt.Sprintf("A customer rated %s %d out of 5.", product.Name, review.Rating),
+		ReferenceType: &referenceType,
+		ReferenceID:   &review.ID,
+	}
+	if err = notifyMerchant(ctx, tx, notification); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reply sets the reply of the merchant to a review of one of its products,
+// replacing an earlier reply. It reports false when the merchant has no
+// such review.
+func (r *productReviewRepository) Reply(ctx context.Context, merchantId, id, reply string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE product_reviews r
+		SET merchant_reply = $1, replied_at = NOW(), updated_at = NOW()
+		FROM product p
+		WHERE p.id = r.product_id AND r.id::text = $2 AND p.merchant_id = $3;
+	`, reply, id, merchantId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *productReviewRepository) Exists(ctx context.Context, orderId, productId string) (bool, error) {
+	var exists bool
+	err := r.DB.GetContext(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM product_reviews WHERE order_id = $1 AND product_id = $2)`,
+		orderId, productId,
+	)
+	return exists, err
+}
+
+func (r *productReviewRepository) FindOneById(ctx context.Context, id string) (*entities.ProductReview, error) {
+	var review entities.ProductReview
+
+	err := r.DB.GetContext(ctx, &review, productReviewSelect+` WHERE r.id::text = $1`, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *productReviewRepository) Find(ctx context.Context, filter ProductReviewFilter, limit, offset int) ([]entities.ProductReview, error) {
+	reviews := []entities.ProductReview{}
+	where, args := filter.where()
+
+	query := productReviewSelect + ` WHERE ` + where + fmt.Sprintf(`
+		ORDER BY r.created_at DESC, r.id
+		LIMIT $%d OFFSET $%d;
+	`, len(args)+1, len(args)+2)
+
+	err := r.DB.SelectContext(ctx, &reviews, query, append(args, limit, offset)...)
+	return reviews, err
+}
+
+func (r *productReviewRepository) Count(ctx context.Context, filter ProductReviewFilter) (int64, error) {
+	var count int64
+	where, args := filter.where()
+
+	query := `
+		SELECT COUNT(*)
+		FROM product_reviews r
+		JOIN product p ON p.id = r.product_id
+		WHERE ` + where
+
+	err := r.DB.GetContext(ctx, &count, query, args...)
+	return count, err
+}
+
+func (r *productReviewRepository) FindImagesByReviewIDs(ctx context.Context, reviewIds []string) ([]entities.ProductReviewImage, error) {
+	images := []entities.ProductReviewImage{}
+	if len(reviewIds) == 0 {
+		return images, nil
+	}
+
+	query := `
+		SELECT id, review_id, image_url, thumbnail_url, storage_key, thumbnail_key, position, created_at
+		FROM product_review_images
+		WHERE review_id::text = ANY($1)
+		ORDER BY review_id, position;
+	`
+
+	err := r.DB.SelectContext(ctx, &images, query, pq.Array(reviewIds))
+	return images, err
+}
+
+// FindRatingsByProductIDs returns the average rating and the number of
+// reviews of each product that has reviews.
+func (r *productReviewRepository) FindRatingsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductRating, error) {
+	ratings := []entities.ProductRating{}
+	if len(productIds) == 0 {
+		return ratings, nil
+	}
+
+	query := `
+		SELECT product_id, ROUND(AVG(rating), 2)::float8 AS average, COUNT(*) AS count
+		FROM product_reviews
+		WHERE product_id::text = ANY($1)
+		GROUP BY product_id;
+	`
+
+	err := r.DB.SelectContext(ctx, &ratings, query, pq.Array(productIds))
+	return ratings, err
+}
@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ProductVariantRepository manages the option axes and variants of a
+// product. While a product has variants, product.stock holds the sum of the
+// variant stocks so listings and search keep working on the product alone.
+type ProductVariantRepository interface {
+	Save(ctx context.Context, productId string, options []entities.ProductOption, variants []entities.ProductVariant, actorId *string) error
+	FindOptionsByProductID(ctx context.Context, productId string) ([]entities.ProductOption, error)
+	FindOptionsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductOption, error)
+	FindByProductID(ctx context.Context, productId string) ([]entities.ProductVariant, error)
+	FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductVariant, error)
+	FindOneById(ctx context.Context, id string) (*entities.ProductVariant, error)
+	CountByProductID(ctx context.Context, productId string) (int, error)
+}
+
+type productVariantRepository struct {
+	DB *sqlx.DB
+}
+
+func NewProductVariantRepository(db *sqlx.DB) ProductVariantRepository {
+	return &productVariantRepository{DB: db}
+}
+
+const variantColumns = `id, product_id, name, options, sku, price, stock, weight, image_url, position, created_at, updated_at`
+
+// Save replaces the options of a product and brings its variants in line
+// with the given list: variants with an id are updated, variants without
+// one are created and the others are deleted. Keeping the ids stable keeps
+// order items pointing at the variant they were bought as. Stock changes of
+// variants not stocked per outlet are recorded in the ledger.
+func (r *productVariantRepository) Save(ctx context.Context, productId string, options []entities.ProductOption, variants []entities.ProductVariant, actorId *string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Serialize variant changes of the same product
+	var product struct {
+		Stock int `db:"stock"`
+	}
+	if err = tx.GetContext(ctx, &product, `SELECT stock FROM product WHERE id = $1 FOR UPDATE`, productId); err != nil {
+		return err
+	}
+
+	previous := []struct {
+		ID        string `db:"id"`
+		Stock     int    `db:"stock"`
+		PerOutlet bool   `db:"per_outlet"`
+	}{}
+	query := `
+		SELECT id, stock, EXISTS (SELECT 1 FROM outlet_stocks s WHERE s.variant_id = v.id) AS per_outlet
+		FROM product_variants v
+		WHERE product_id = $1;
+	`
+	if err = tx.SelectContext(ctx, &previous, query, productId); err != nil {
+		return err
+	}
+
+	previousStock := map[string]int{}
+	perOutlet := map[string]bool{}
+	for _, variant := range previous {
+		previousStock[variant.ID] = variant.Stock
+		perOutlet[variant.ID] = variant.PerOutlet
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM product_options WHERE product_id = $1`, productId); err != nil {
+		return err
+	}
+
+	for position, option := range options {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO product_options (id, product_id, name, option_values, position) VALUES ($1, $2, $3, $4, $5)`,
+			uuid.New().String(), productId, option.Name, pq.Array(option.Values), position,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	keep := []string{}
+	for _, variant := range variants {
+		if variant.ID != "" {
+			keep = append(keep, variant.ID)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM product_variants WHERE product_id = $1 AND NOT (id::text = ANY($2))`,
+		productId, pq.Array(keep),
+	)
+	if err != nil {
+		return err
+	}
+
+	for position, variant := range variants {
+		if variant.ID != "" {
+			query := `
+				UPDATE product_variants SET
+					name = $1, options = $2, sku = $3, price = $4, stock = $5,
+					weight = $6, image_url = $7, position = $8, updated_at = NOW()
+				WHERE id = $9 AND product_id = $10;
+			`
+			_, err = tx.ExecContext(ctx, query,
+				variant.Name, variant.Options, variant.SKU, variant.Price, variant.Stock,
+				variant.Weight, variant.ImageURL, position, variant.ID, productId,
+			)
+		} else {
+			variant.ID = uuid.New().String()
+			query := `
+				INSERT INTO product_variants (
+					id, product_id, name, options, sku, price, stock, weight, image_url, position
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+			`
+			_, err = tx.ExecContext(ctx, query,
+				variant.ID, productId, variant.Name, variant.Options, variant.SKU,
+				variant.Price, variant.Stock, variant.Weight, variant.ImageURL, position,
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Stock held per outlet is reset to the outlet total below
+		if perOutlet[variant.ID] || variant.Stock == previousStock[variant.ID] {
+			continue
+		}
+		err = recordMovement(ctx, tx, &entities.StockMovement{
+			ProductID:  productId,
+			VariantID:  &variant.ID,
+			Type:       entities.StockMovementAdjustment,
+			Quantity:   variant.Stock - previousStock[variant.ID],
+			StockAfter: variant.Stock,
+			ActorType:  entities.StockActorMerchant,
+			ActorID:    actorId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stock moves between the product and its variants when the first
+	// variant is added or the last one removed
+	var (
+		productStock int
+		note         string
+	)
+	switch {
+	case len(previous) == 0 && len(variants) > 0:
+		productStock, note = -product.Stock, "moved to variants"
+	case len(previous) > 0 && len(variants) == 0:
+		productStock, note = product.Stock, "moved from variants"
+	}
+	if productStock != 0 {
+		err = recordMovement(ctx, tx, &entities.StockMovement{
+			ProductID:  productId,
+			Type:       entities.StockMovementAdjustment,
+			Quantity:   productStock,
+			StockAfter: max(productStock, 0),
+			ActorType:  entities.StockActorMerchant,
+			ActorID:    actorId,
+			Note:       &note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Variants stocked per outlet keep the outlet total
+	if err = syncOutletStock(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	if err = checkStockAlert(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productVariantRepository) FindOptionsByProductID(ctx context.Context, productId string) ([]entities.ProductOption, error) {
+	options := []entities.ProductOption{}
+
+	query := `
+		SELECT id, product_id, name, option_values, position
+		FROM product_options
+		WHERE product_id = $1
+		ORDER BY position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &options, query, productId)
+	return options, err
+}
+
+func (r *productVariantRepository) FindOptionsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductOption, error) {
+	options := []entities.ProductOption{}
+	if len(productIds) == 0 {
+		return options, nil
+	}
+
+	query := `
+		SELECT id, product_id, name, option_values, position
+		FROM product_options
+		WHERE product_id::text = ANY($1)
+		ORDER BY product_id, position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &options, query, pq.Array(productIds))
+	return options, err
+}
+
+func (r *productVariantRepository) FindByProductID(ctx context.Context, productId string) ([]entities.ProductVariant, error) {
+	variants := []entities.ProductVariant{}
+
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants
+		WHERE product_id = $1
+		ORDER BY position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &variants, query, productId)
+	return variants, err
+}
+
+func (r *productVariantRepository) FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductVariant, error) {
+	variants := []entities.ProductVariant{}
+	if len(productIds) == 0 {
+		return variants, nil
+	}
+
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants
+		WHERE product_id::text = ANY($1)
+		ORDER BY product_id, position ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &variants, query, pq.Array(productIds))
+	return variants, err
+}
+
+func (r *productVariantRepository) FindOneById(ctx context.Context, id string) (*entities.ProductVariant, error) {
+	var variant entities.ProductVariant
+
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants
+		WHERE id = $1
+		LIMIT 1;
+	`
+
+	err := r.DB.GetContext(ctx, &variant, query, id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *productVariantRepository) CountByProductID(ctx context.Context, productId string) (int, error) {
+	var count int
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM product_variants WHERE product_id = $1`, productId)
+	return count, err
+}
+
+// syncVariantStock sets product.stock to the total stock of its variants. A
+// product without variants keeps its own stock.
+func syncVariantStock(ctx context.Context, tx *sqlx.Tx, productId string) error {
+	query := `
+		UPDATE product SET stock = v.total, updated_at = NOW()
+		FROM (
+			SELECT SUM(stock) AS total FROM product_variants WHERE product_id = $1
+		) v
+		WHERE product.id = $1 AND v.total IS NOT NULL;
+	`
+	_, err := tx.ExecContext(ctx, query, productId)
+	return err
+}
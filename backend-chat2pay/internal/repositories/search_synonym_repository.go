@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type SearchSynonymRepository interface {
+	Create(ctx context.Context, synonym *entities.SearchSynonym) (*entities.SearchSynonym, error)
+	FindByMerchantID(ctx context.Context, merchantId string) ([]entities.SearchSynonym, error)
+	Delete(ctx context.Context, id, merchantId string) (bool, error)
+}
+
+type searchSynonymRepository struct {
+	DB *sqlx.DB
+}
+
+func NewSearchSynonymRepository(db *sqlx.DB) SearchSynonymRepository {
+	return &searchSynonymRepository{DB: db}
+}
+
+// Create inserts a synonym entry, replacing the synonyms when the merchant
+// already has an entry for the term.
+func (r *searchSynonymRepository) Create(ctx context.Context, synonym *entities.SearchSynonym) (*entities.SearchSynonym, error) {
+	query := `
+		INSERT INTO search_synonyms (id, merchant_id, term, synonyms)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (merchant_id, term) DO UPDATE SET synonyms = EXCLUDED.synonyms
+		RETURNING id, created_at;
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		synonym.MerchantID,
+		synonym.Term,
+		synonym.Synonyms,
+	).Scan(&synonym.ID, &synonym.CreatedAt)
+
+	return synonym, err
+}
+
+func (r *searchSynonymRepository) FindByMerchantID(ctx context.Context, merchantId string) ([]entities.SearchSynonym, error) {
+	synonyms := []entities.SearchSynonym{}
+
+	query := `
+		SELECT id, merchant_id, term, synonyms, created_at
+		FROM search_synonyms
+		WHERE merchant_id = $1
+		ORDER BY term ASC;
+	`
+
+	err := r.DB.SelectContext(ctx, &synonyms, query, merchantId)
+	return synonyms, err
+}
+
+// Delete removes a merchant's entry and reports whether it existed.
+func (r *searchSynonymRepository) Delete(ctx context.Context, id, merchantId string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM search_synonyms WHERE id = $1 AND merchant_id = $2`, id, merchantId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
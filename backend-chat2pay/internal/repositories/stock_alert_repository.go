@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+// StockAlertRepository reads the stock alerts, which are raised and
+// resolved by checkStockAlert as stock changes, and keeps track of the
+// products the chat could not offer because they were out of stock.
+type StockAlertRepository interface {
+	Find(ctx context.Context, filter StockAlertFilter, limit, offset int) ([]entities.StockAlert, error)
+	Count(ctx context.Context, filter StockAlertFilter) (int64, error)
+	RecordChatMisses(ctx context.Context, sessionId, query string, productIds []string) error
+	FindChatMisses(ctx context.Context, merchantId string, since time.Time, limit, offset int) ([]entities.ChatStockMissSummary, error)
+	CountChatMisses(ctx context.Context, merchantId string, since time.Time) (int64, error)
+}
+
+// StockAlertFilter narrows down the alerts. Empty fields do not filter,
+// Open only keeps the alerts that are not resolved yet.
+type StockAlertFilter struct {
+	MerchantID string
+	ProductID  string
+	Type       string
+	Open       bool
+}
+
+func (f StockAlertFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s::text = $%d", column, len(args)))
+		}
+	}
+	add("a.merchant_id", f.MerchantID)
+	add("a.product_id", f.ProductID)
+	add("a.type", f.Type)
+
+	if f.Open {
+		conditions = append(conditions, "a.resolved_at IS NULL")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+type stockAlertRepository struct {
+	DB *sqlx.DB
+}
+
+func NewStockAlertRepository(db *sqlx.DB) StockAlertRepository {
+	return &stockAlertRepository{DB: db}
+}
+
+func (r *stockAlertRepository) Find(ctx context.Context, filter StockAlertFilter, limit, offset int) ([]entities.StockAlert, error) {
+	alerts := []entities.StockAlert{}
+	where, args := filter.where()
+
+	query := `
+		SELECT
+			a.id, a.merchant_id, a.product_id, a.type, a.stock, a.threshold, a.created_at, a.resolved_at,
+			p.name AS product_name, p.stock AS current_stock
+		FROM stock_alerts a
+		JOIN product p ON p.id = a.product_id
+		WHERE ` + where + fmt.Sprintf(`
+		ORDER BY a.created_at DESC, a.id
+		LIMIT $%d OFFSET $%d;
+	`, len(args)+1, len(args)+2)
+
+	err := r.DB.SelectContext(ctx, &alerts, query, append(args, limit, offset)...)
+	return alerts, err
+}
+
+func (r *stockAlertRepository) Count(ctx context.Context, filter StockAlertFilter) (int64, error) {
+	var count int64
+	where, args := filter.where()
+
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM stock_alerts a WHERE `+where, args...)
+	return count, err
+}
+
+// RecordChatMisses records that the chat search for query matched the given
+// products but could not offer them because they were out of stock.
+func (r *stockAlertRepository) RecordChatMisses(ctx context.Context, sessionId, query string, productIds []string) error {
+	if len(productIds) == 0 {
+		return nil
+	}
+
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO chat_stock_misses (product_id, session_id, query)
+		SELECT id::uuid, NULLIF($1, ''), $2 FROM unnest($3::text[]) AS id;
+	`, sessionId, query, pq.Array(productIds))
+	return err
+}
+
+// FindChatMisses counts the misses per product of the merchant since the
+// given time, most missed first.
+func (r *stockAlertRepository) FindChatMisses(ctx context.Context, merchantId string, since time.Time, limit, offset int) ([]entities.ChatStockMissSummary, error) {
+	summaries := []entities.ChatStockMissSummary{}
+
+	query := `
+		SELECT
+			p.id AS product_id, p.name AS product_name, p.stock,
+			COUNT(*) AS misses,
+			COUNT(DISTINCT m.session_id) AS sessions,
+			MAX(m.created_at) AS last_miss_at
+		FROM chat_stock_misses m
+		JOIN product p ON p.id = m.product_id
+		WHERE p.merchant_id = $1
+		AND m.created_at >= $2
+		GROUP BY p.id
+		ORDER BY misses DESC, last_miss_at DESC
+		LIMIT $3 OFFSET $4;
+	`
+
+	err := r.DB.SelectContext(ctx, &summaries, query, merchantId, since, limit, offset)
+	return summaries, err
+}
+
+// CountChatMisses counts the products of the merchant missed since the
+// given time.
+func (r *stockAlertRepository) CountChatMisses(ctx context.Context, merchantId string, since time.Time) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT COUNT(DISTINCT m.product_id)
+		FROM chat_stock_misses m
+		JOIN product p ON p.id = m.product_id
+		WHERE p.merchant_id = $1
+		AND m.created_at >= $2;
+	`
+
+	err := r.DB.GetContext(ctx, &count, query, merchantId, since)
+	return count, err
+}
+
+// checkStockAlert compares the stock of the product with its low stock
+// threshold after a change. Crossing it raises an alert and notifies the
+// merchant, getting back above it resolves the open alert. The product
+// stock must be up to date, so it runs after the totals are synced.
+func checkStockAlert(ctx context.Context, tx *sqlx.Tx, productId string) error {
+	var product struct {
+		MerchantID string `db:"merchant_id"`
+		Name       string `db:"name"`
+		Stock      int    `db:"stock"`
+		Threshold  int    `db:"low_stock_threshold"`
+	}
+	err := tx.GetContext(ctx, &product,
+		`SELECT merchant_id, name, stock, low_stock_threshold FROM product WHERE id = $1`, productId,
+	)
+	if err != nil {
+		return err
+	}
+
+	alertType := ""
+	switch {
+	case product.Stock <= 0:
+		alertType = entities.StockAlertOutOfStock
+	case product.Stock <= product.Threshold:
+		alertType = entities.StockAlertLowStock
+	}
+
+	var open []entities.StockAlert
+	err = tx.SelectContext(ctx, &open,
+		`SELECT id, type FROM stock_alerts WHERE product_id = $1 AND resolved_at IS NULL FOR UPDATE`, productId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(open) > 0 {
+		if open[0].Type == alertType {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE stock_alerts SET resolved_at = NOW() WHERE id = $1`, open[0].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if alertType == "" {
+		return nil
+	}
+
+	alertId := uuid.New().String()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_alerts (id, merchant_id, product_id, type, stock, threshold)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`, alertId, product.MerchantID, productId, alertType, product.Stock, product.Threshold)
+	if err != nil {
+		return err
+	}
+
+	// A partial restock from out of stock to low stock is not worth a new
+	// notification
+	if len(open) > 0 && alertType == entities.StockAlertLowStock {
+		return nil
+	}
+
+	referenceType := "stock_alert"
+	notification := &entities.MerchantNotification{
+		MerchantID:    product.MerchantID,
+		Type:          entities.NotificationStockAlert,
+		Title:         fmt.Sprintf("%s is running low", product.Name),
+		Message:       fmt.Sprintf("%s has %d left in stock, at or below its threshold of %d.", product.Name, product.Stock, product.Threshold),
+		ReferenceType: &referenceType,
+		ReferenceID:   &alertId,
+	}
+	if alertType == entities.StockAlertOutOfStock {
+		notification.Title = fmt.Sprintf("%s is out of stock", product.Name)
+		notification.Message = fmt.Sprintf("%s is out of stock and no longer offered in chat.", product.Name)
+	}
+
+	return notifyMerchant(ctx, tx, notification)
+}
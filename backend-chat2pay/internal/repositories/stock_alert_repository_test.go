@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockAlert_CrossingTheThreshold(t *testing.T) {
+	db := testDB(t)
+	repo := NewOrderRepository(db)
+	customerID, merchantID, productID := seedProduct(t, db, 10)
+	db.MustExec(`UPDATE product SET low_stock_threshold = 5 WHERE id = $1`, productID)
+
+	counts := func() (alerts, notifications int) {
+		require.NoError(t, db.Get(&alerts, `SELECT COUNT(*) FROM stock_alerts WHERE product_id = $1 AND type = $2`,
+			productID, entities.StockAlertLowStock))
+		require.NoError(t, db.Get(&notifications, `SELECT COUNT(*) FROM merchant_notifications WHERE merchant_id = $1 AND type = $2`,
+			merchantID, entities.NotificationStockAlert))
+		return alerts, notifications
+	}
+
+	tests := []struct {
+		name          string
+		quantity      int
+		stock         int
+		alerts        int
+		notifications int
+	}{
+		{name: "Stays above the threshold", quantity: 4, stock: 6},
+		{name: "Crosses the threshold", quantity: 2, stock: 4, alerts: 1, notifications: 1},
+		{name: "Stays low", quantity: 1, stock: 3, alerts: 1, notifications: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, movements := newPlacement(customerID, merchantID, map[string]int{productID: tt.quantity})
+			require.NoError(t, repo.Place(context.Background(), order, movements))
+
+			assert.Equal(t, tt.stock, productStock(t, db, productID))
+			alerts, notifications := counts()
+			assert.Equal(t, tt.alerts, alerts)
+			assert.Equal(t, tt.notifications, notifications)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrVariantRequired is returned for a movement on a product with
+	// variants that does not name the variant.
+	ErrVariantRequired = errors.New("stock of a product with variants is held per variant")
+	// ErrOutletRequired is returned for a movement on stock held per outlet
+	// that does not name the outlet.
+	ErrOutletRequired = errors.New("stock is held per outlet")
+)
+
+// StockMovementRepository is the stock ledger. Every change to stock goes
+// through it, or through a repository that records its movement in the
+// same transaction, so the ledger always adds up to the stock held.
+type StockMovementRepository interface {
+	Apply(ctx context.Context, movements []entities.StockMovement) ([]entities.StockMovement, error)
+	Find(ctx context.Context, filter StockMovementFilter, limit, offset int) ([]entities.StockMovement, error)
+	Count(ctx context.Context, filter StockMovementFilter) (int64, error)
+}
+
+// StockMovementFilter narrows down the ledger. Empty fields do not filter.
+type StockMovementFilter struct {
+	MerchantID string
+	ProductID  string
+	VariantID  string
+	OutletID   string
+	Type       string
+}
+
+func (f StockMovementFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	add := func(column, value string) {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s::text = $%d", column, len(args)))
+		}
+	}
+	add("p.merchant_id", f.MerchantID)
+	add("m.product_id", f.ProductID)
+	add("m.variant_id", f.VariantID)
+	add("m.outlet_id", f.OutletID)
+	add("m.type", f.Type)
+
+	return strings.Join(conditions, " AND "), args
+}
+
+type stockMovementRepository struct {
+	DB *sqlx.DB
+}
+
+func NewStockMovementRepository(db *sqlx.DB) StockMovementRepository {
+	return &stockMovementRepository{DB: db}
+}
+
+// Apply changes stock by the quantity of each movement and records them, in
+// a single transaction. Nothing is changed when a movement would take stock
+// below zero. The recorded movements are returned with their stock after,
+// and the stock alerts of the products follow the new stock.
+func (r *stockMovementRepository) Apply(ctx context.Context, movements []entities.StockMovement) ([]entities.StockMovement, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	productIds := []string{}
+	seen := map[string]bool{}
+	for _, movement := range movements {
+		if !seen[movement.ProductID] {
+			seen[movement.ProductID] = true
+			productIds = append(productIds, movement.ProductID)
+		}
+	}
+
+	if err = lockProducts(ctx, tx, productIds); err != nil {
+		return nil, err
+	}
+
+	applied := make([]entities.StockMovement, len(movements))
+	for i, movement := range movements {
+		if err = moveStock(ctx, tx, &movement); err != nil {
+			return nil, err
+		}
+		applied[i] = movement
+	}
+
+	for _, productId := range productIds {
+		if err = syncOutletStock(ctx, tx, productId); err != nil {
+			return nil, err
+		}
+		if err = checkStockAlert(ctx, tx, productId); err != nil {
+			return nil, err
+		}
+	}
+
+	return applied, tx.Commit()
+}
+
+func (r *stockMovementRepository) Find(ctx context.Context, filter StockMovementFilter, limit, offset int) ([]entities.StockMovement, error) {
+	movements := []entities.StockMovement{}
+	where, args := filter.where()
+
+	query := `
+		SELECT
+			m.id, m.product_id, m.variant_id, m.outlet_id, m.type, m.quantity, m.stock_after,
+			m.actor_type, m.actor_id, m.reference_type, m.reference_id, m.note, m.created_at,
+			p.name AS product_name, v.name AS variant_name, o.name AS outlet_name
+		FROM stock_movements m
+		JOIN product p ON p.id = m.product_id
+		LEFT JOIN product_variants v ON v.id = m.variant_id
+		LEFT JOIN outlets o ON o.id = m.outlet_id
+		WHERE ` + where + fmt.Sprintf(`
+		ORDER BY m.created_at DESC, m.id
+		LIMIT $%d OFFSET $%d;
+	`, len(args)+1, len(args)+2)
+
+	err := r.DB.SelectContext(ctx, &movements, query, append(args, limit, offset)...)
+	return movements, err
+}
+
+func (r *stockMovementRepository) Count(ctx context.Context, filter StockMovementFilter) (int64, error) {
+	var count int64
+	where, args := filter.where()
+
+	query := `
+		SELECT COUNT(*)
+		FROM stock_movements m
+		JOIN product p ON p.id = m.product_id
+		WHERE ` + where
+
+	err := r.DB.GetContext(ctx, &count, query, args...)
+	return count, err
+}
+
+// moveStock changes the stock at the level of the movement and records the
+// movement. The product must be locked by the caller, and the product
+// totals synced afterwards.
+func moveStock(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement) error {
+	current, err := levelStock(ctx, tx, movement)
+	if err != nil {
+		return err
+	}
+	return changeStock(ctx, tx, movement, current)
+}
+
+// returnStock puts stock back at the level of the movement it reverses, even
+// when the product has since been split into variants or outlets and no
+// longer holds stock at that level. The product must be locked by the
+// caller, and the product totals synced afterwards.
+func returnStock(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement) error {
+	current, err := readLevelStock(ctx, tx, movement)
+	if err != nil {
+		return err
+	}
+	return changeStock(ctx, tx, movement, current)
+}
+
+// changeStock adds the quantity of the movement to the current stock at its
+// level and records the movement.
+func changeStock(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement, current int) error {
+	var err error
+
+	after := current + movement.Quantity
+	if after < 0 {
+		return ErrInsufficientStock
+	}
+
+	switch {
+	case movement.OutletID != nil:
+		err = upsertOutletStock(ctx, tx, *movement.OutletID, movement.ProductID, movement.VariantID, after)
+	case movement.VariantID != nil:
+		_, err = tx.ExecContext(ctx,
+			`UPDATE product_variants SET stock = $1, updated_at = NOW() WHERE id = $2`,
+			after, *movement.VariantID,
+		)
+	default:
+		_, err = tx.ExecContext(ctx,
+			`UPDATE product SET stock = $1, updated_at = NOW() WHERE id = $2`,
+			after, movement.ProductID,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	movement.StockAfter = after
+	return recordMovement(ctx, tx, movement)
+}
+
+// levelStock reads the stock held at the level of the movement, checking
+// that stock is actually held at that level.
+func levelStock(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement) (int, error) {
+	var hasVariants, perOutlet bool
+
+	err := tx.GetContext(ctx, &hasVariants,
+		`SELECT EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)`, movement.ProductID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if hasVariants && movement.VariantID == nil {
+		return 0, ErrVariantRequired
+	}
+
+	err = tx.GetContext(ctx, &perOutlet,
+		`SELECT EXISTS (SELECT 1 FROM outlet_stocks WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2::uuid)`,
+		movement.ProductID, movement.VariantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if perOutlet && movement.OutletID == nil {
+		return 0, ErrOutletRequired
+	}
+
+	return readLevelStock(ctx, tx, movement)
+}
+
+// readLevelStock reads the stock held at the level of the movement.
+func readLevelStock(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement) (int, error) {
+	var (
+		stock int
+		err   error
+	)
+	switch {
+	case movement.OutletID != nil:
+		err = tx.GetContext(ctx, &stock, `
+			SELECT COALESCE((
+				SELECT stock FROM outlet_stocks
+				WHERE outlet_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::uuid
+			), 0)`,
+			*movement.OutletID, movement.ProductID, movement.VariantID,
+		)
+	case movement.VariantID != nil:
+		err = tx.GetContext(ctx, &stock,
+			`SELECT stock FROM product_variants WHERE id = $1 AND product_id = $2`,
+			*movement.VariantID, movement.ProductID,
+		)
+	default:
+		err = tx.GetContext(ctx, &stock, `SELECT stock FROM product WHERE id = $1`, movement.ProductID)
+	}
+
+	return stock, err
+}
+
+// recordMovement adds the movement to the ledger. Callers that change the
+// stock themselves must set StockAfter.
+func recordMovement(ctx context.Context, tx *sqlx.Tx, movement *entities.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (
+			id, product_id, variant_id, outlet_id, type, quantity, stock_after,
+			actor_type, actor_id, reference_type, reference_id, note
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		uuid.New().String(),
+		movement.ProductID,
+		movement.VariantID,
+		movement.OutletID,
+		movement.Type,
+		movement.Quantity,
+		movement.StockAfter,
+		movement.ActorType,
+		movement.ActorID,
+		movement.ReferenceType,
+		movement.ReferenceID,
+		movement.Note,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func productStock(t *testing.T, db *sqlx.DB, productID string) int {
+	var stock int
+	require.NoError(t, db.Get(&stock, `SELECT stock FROM product WHERE id = $1`, productID))
+	return stock
+}
+
+func TestStockMovementRepository_Apply(t *testing.T) {
+	db := testDB(t)
+	repo := NewStockMovementRepository(db)
+	ctx := context.Background()
+
+	_, _, productID := seedProduct(t, db, 10)
+	userID := uuid.New().String()
+
+	t.Run("Records a manual adjustment with the merchant user", func(t *testing.T) {
+		note := "stock opname"
+		applied, err := repo.Apply(ctx, []entities.StockMovement{{
+			ProductID: productID,
+			Type:      entities.StockMovementRestock,
+			Quantity:  5,
+			ActorType: entities.StockActorMerchant,
+			ActorID:   &userID,
+			Note:      &note,
+		}})
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		assert.Equal(t, 15, applied[0].StockAfter)
+		assert.Equal(t, 15, productStock(t, db, productID))
+
+		movements, err := repo.Find(ctx, StockMovementFilter{ProductID: productID}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, movements, 1)
+		assert.Equal(t, entities.StockMovementRestock, movements[0].Type)
+		assert.Equal(t, 5, movements[0].Quantity)
+		assert.Equal(t, 15, movements[0].StockAfter)
+		require.NotNil(t, movements[0].ActorID)
+		assert.Equal(t, userID, *movements[0].ActorID)
+	})
+
+	t.Run("Takes no stock below zero", func(t *testing.T) {
+		_, err := repo.Apply(ctx, []entities.StockMovement{{
+			ProductID: productID,
+			Type:      entities.StockMovementAdjustment,
+			Quantity:  -20,
+			ActorType: entities.StockActorMerchant,
+			ActorID:   &userID,
+		}})
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, 15, productStock(t, db, productID))
+
+		count, err := repo.Count(ctx, StockMovementFilter{ProductID: productID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
+
+func TestProductVariantRepository_Save(t *testing.T) {
+	db := testDB(t)
+	repo := NewProductVariantRepository(db)
+	ledger := NewStockMovementRepository(db)
+	ctx := context.Background()
+
+	_, _, productID := seedProduct(t, db, 4)
+	userID := uuid.New().String()
+
+	options := []entities.ProductOption{{Name: "Ukuran", Values: pq.StringArray{"S", "M"}}}
+	variants := []entities.ProductVariant{
+		{Name: "S", Options: entities.VariantOptions{"Ukuran": "S"}, Stock: 3},
+		{Name: "M", Options: entities.VariantOptions{"Ukuran": "M"}, Stock: 2},
+	}
+
+	t.Run("Moves the product stock to the first variants", func(t *testing.T) {
+		require.NoError(t, repo.Save(ctx, productID, options, variants, &userID))
+		assert.Equal(t, 5, productStock(t, db, productID))
+
+		movements, err := ledger.Find(ctx, StockMovementFilter{ProductID: productID}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, movements, 3)
+
+		quantities := map[string]int{}
+		for _, movement := range movements {
+			require.NotNil(t, movement.ActorID)
+			assert.Equal(t, userID, *movement.ActorID)
+			assert.Equal(t, entities.StockMovementAdjustment, movement.Type)
+
+			name := "product"
+			if movement.VariantName != nil {
+				name = *movement.VariantName
+			}
+			quantities[name] = movement.Quantity
+		}
+		assert.Equal(t, map[string]int{"product": -4, "S": 3, "M": 2}, quantities)
+	})
+
+	t.Run("Records the stock change of a kept variant", func(t *testing.T) {
+		saved, err := repo.FindByProductID(ctx, productID)
+		require.NoError(t, err)
+		require.Len(t, saved, 2)
+		for i := range saved {
+			if saved[i].Name == "S" {
+				saved[i].Stock = 1
+			}
+		}
+
+		require.NoError(t, repo.Save(ctx, productID, options, saved, &userID))
+		assert.Equal(t, 3, productStock(t, db, productID))
+
+		movements, err := ledger.Find(ctx, StockMovementFilter{ProductID: productID, Type: entities.StockMovementAdjustment}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, movements, 4)
+
+		var change *entities.StockMovement
+		for i := range movements {
+			if movements[i].VariantName != nil && *movements[i].VariantName == "S" && movements[i].Quantity < 0 {
+				change = &movements[i]
+			}
+		}
+		require.NotNil(t, change)
+		assert.Equal(t, -2, change.Quantity)
+		assert.Equal(t, 1, change.StockAfter)
+	})
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"chat2pay/internal/entities"
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+type WishlistRepository interface {
+	Add(ctx context.Context, item *entities.WishlistItem) error
+	Remove(ctx context.Context, customerId, productId string) (bool, error)
+	FindOne(ctx context.Context, customerId, productId string) (*entities.WishlistItem, error)
+	FindByCustomerID(ctx context.Context, customerId string, limit, offset int) ([]entities.WishlistItem, error)
+	CountByCustomerID(ctx context.Context, customerId string) (int64, error)
+	FindWatched(ctx context.Context) ([]entities.WishlistItem, error)
+	Seen(ctx context.Context, item *entities.WishlistItem, notification *entities.CustomerNotification) error
+}
+
+const wishlistColumns = `id, customer_id, product_id, added_price, last_price, in_stock, created_at, updated_at`
+
+type wishlistRepository struct {
+	DB *sqlx.DB
+}
+
+func NewWishlistRepository(db *sqlx.DB) WishlistRepository {
+	return &wishlistRepository{DB: db}
+}
+
+func (r *wishlistRepository) Add(ctx context.Context, item *entities.WishlistItem) error {
+	query := `
+		INSERT INTO wishlists (customer_id, product_id, added_price, last_price, in_stock)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return r.DB.QueryRowContext(ctx, query,
+		item.CustomerID,
+		item.ProductID,
+		item.AddedPrice,
+		item.LastPrice,
+		item.InStock,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+}
+
+// Remove takes the product off the wishlist of the customer. It reports
+// false when it was not on it.
+func (r *wishlistRepository) Remove(ctx context.Context, customerId, productId string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx,
+		`DELETE FROM wishlists WHERE customer_id = $1 AND product_id::text = $2`,
+		customerId, productId,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *wishlistRepository) FindOne(ctx context.Context, customerId, productId string) (*entities.WishlistItem, error) {
+	var item entities.WishlistItem
+
+	err := r.DB.GetContext(ctx, &item,
+		`SELECT `+wishlistColumns+` FROM wishlists WHERE customer_id = $1 AND product_id::text = $2`,
+		customerId, productId,
+	)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *wishlistRepository) FindByCustomerID(ctx context.Context, customerId string, limit, offset int) ([]entities.WishlistItem, error) {
+	items := []entities.WishlistItem{}
+
+	query := `
+		SELECT ` + wishlistColumns + `
+		FROM wishlists
+		WHERE customer_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3;
+	`
+
+	err := r.DB.SelectContext(ctx, &items, query, customerId, limit, offset)
+	return items, err
+}
+
+func (r *wishlistRepository) CountByCustomerID(ctx context.Context, customerId string) (int64, error) {
+	var count int64
+	err := r.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM wishlists WHERE customer_id = $1`, customerId)
+	return count, err
+}
+
+// FindWatched returns the wishlist items of active products, the ones a
+// customer can be told about.
+func (r *wishlistRepository) FindWatched(ctx context.Context) ([]entities.WishlistItem, error) {
+	items := []entities.WishlistItem{}
+
+	query := `
+		SELECT w.id, w.customer_id, w.product_id, w.added_price, w.last_price, w.in_stock, w.created_at, w.updated_at
+		FROM wishlists w
+		JOIN product p ON p.id = w.product_id
+		WHERE p.status = 'active'
+		ORDER BY w.product_id, w.id;
+	`
+
+	err := r.DB.SelectContext(ctx, &items, query)
+	return items, err
+}
+
+// Seen stores the price and stock last seen for the item, with the
+// notification of the customer when there is one.
+func (r *wishlistRepository) Seen(ctx context.Context, item *entities.WishlistItem, notification *entities.CustomerNotification) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE wishlists SET last_price = $1, in_stock = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at;
+	`, item.LastPrice, item.InStock, item.ID).Scan(&item.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if notification != nil {
+		if err = notifyCustomer(ctx, tx, notification); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -1,3 +1,9 @@
+//go:build legacy_auth
+
+// These tests were written for the user auth service that the merchant and
+// customer auth services replaced, and no longer build. The tag keeps them
+// out of the default build so the rest of the package can be tested.
+
 package service
 
 import (
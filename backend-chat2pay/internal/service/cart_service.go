@@ -0,0 +1,312 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type CartService interface {
+	GetCart(ctx context.Context, customerId string) *presenter.Response
+	AddItem(ctx context.Context, customerId string, req *dto.AddCartItemRequest) *presenter.Response
+	UpdateItem(ctx context.Context, customerId, id string, req *dto.UpdateCartItemRequest) *presenter.Response
+	RemoveItem(ctx context.Context, customerId, id string) *presenter.Response
+}
+
+type cartService struct {
+	cartRepo     repositories.CartRepository
+	productRepo  repositories.ProductRepository
+	variantRepo  repositories.ProductVariantRepository
+	merchantRepo repositories.MerchantRepository
+	discountRepo repositories.DiscountRepository
+	cfg          *yaml.Config
+}
+
+func NewCartService(
+	cartRepo repositories.CartRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	merchantRepo repositories.MerchantRepository,
+	discountRepo repositories.DiscountRepository,
+	cfg *yaml.Config,
+) CartService {
+	return &cartService{
+		cartRepo:     cartRepo,
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		merchantRepo: merchantRepo,
+		discountRepo: discountRepo,
+		cfg:          cfg,
+	}
+}
+
+// GetCart shows the cart of the customer grouped by merchant, with the
+// prices and stock as they are now.
+func (s *cartService) GetCart(ctx context.Context, customerId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("cart_service_get_cart", s.cfg.Logger.Enable)
+	)
+
+	cart, err := s.view(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch cart"))
+	}
+
+	return response.WithCode(200).WithData(cart)
+}
+
+// AddItem puts an active product in the cart, or raises its quantity when
+// it is already there, as long as there is stock for the quantity in the
+// cart.
+func (s *cartService) AddItem(ctx context.Context, customerId string, req *dto.AddCartItemRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("cart_service_add_item", s.cfg.Logger.Enable)
+	)
+
+	productId := strings.TrimSpace(req.ProductID)
+	if productId == "" {
+		return response.WithCode(400).WithError(errors.New("product_id is required"))
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		return response.WithCode(400).WithError(errors.New("quantity must be at least 1"))
+	}
+
+	product, err := s.productRepo.FindByID(ctx, productId)
+	if err != nil || product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+	if product.Status != entities.ProductStatusActive {
+		return response.WithCode(400).WithError(errors.New("product is not available: " + product.Name))
+	}
+
+	item := entities.CartItem{CustomerID: customerId, ProductID: product.ID, Quantity: quantity}
+	stock := product.Stock
+
+	if variantId := strings.TrimSpace(req.VariantID); variantId != "" {
+		variant, err := s.variantRepo.FindOneById(ctx, variantId)
+		if err != nil || variant == nil || variant.ProductID != product.ID {
+			return response.WithCode(404).WithError(errors.New("variant not found"))
+		}
+		item.VariantID = &variant.ID
+		stock = variant.Stock
+	} else {
+		count, err := s.variantRepo.CountByProductID(ctx, product.ID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error counting variants: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to add to cart"))
+		}
+		if count > 0 {
+			return response.WithCode(400).WithError(errors.New("choose a variant for: " + product.Name))
+		}
+	}
+
+	cart, err := s.cartRepo.FindByCustomerID(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to add to cart"))
+	}
+	inCart := 0
+	for _, existing := range cart {
+		if stockKey(existing.ProductID, existing.VariantID) == stockKey(item.ProductID, item.VariantID) {
+			inCart = existing.Quantity
+		}
+	}
+	if inCart+quantity > stock {
+		return response.WithCode(400).WithError(fmt.Errorf("only %d left of: %s", stock, product.Name))
+	}
+
+	if err = s.cartRepo.Add(ctx, &item); err != nil {
+		log.Error(fmt.Sprintf("error adding to cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to add to cart"))
+	}
+
+	view, err := s.view(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch cart"))
+	}
+
+	return response.WithCode(201).WithData(view)
+}
+
+// UpdateItem sets the quantity of an item in the cart, a quantity of 0
+// takes it out.
+func (s *cartService) UpdateItem(ctx context.Context, customerId, id string, req *dto.UpdateCartItemRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("cart_service_update_item", s.cfg.Logger.Enable)
+	)
+
+	if req.Quantity < 0 {
+		return response.WithCode(400).WithError(errors.New("quantity cannot be negative"))
+	}
+	if req.Quantity == 0 {
+		return s.RemoveItem(ctx, customerId, id)
+	}
+
+	item, err := s.cartRepo.FindOne(ctx, customerId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart item: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update cart"))
+	}
+	if item == nil {
+		return response.WithCode(404).WithError(errors.New("item is not in the cart"))
+	}
+
+	items := []entities.CartItem{*item}
+	if err = s.loadItems(ctx, items); err != nil {
+		log.Error(fmt.Sprintf("error fetching cart products: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update cart"))
+	}
+	if items[0].Product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+	items[0].Quantity = req.Quantity
+
+	if line := dto.ToCartItemResponse(&items[0]); !line.Available {
+		return response.WithCode(400).WithError(fmt.Errorf("only %d left of: %s", line.Stock, line.ProductName))
+	}
+
+	if err = s.cartRepo.UpdateQuantity(ctx, customerId, id, req.Quantity); err != nil {
+		log.Error(fmt.Sprintf("error updating cart item: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update cart"))
+	}
+
+	view, err := s.view(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch cart"))
+	}
+
+	return response.WithCode(200).WithData(view)
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, customerId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("cart_service_remove_item", s.cfg.Logger.Enable)
+	)
+
+	found, err := s.cartRepo.Remove(ctx, customerId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error removing from cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to remove from cart"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("item is not in the cart"))
+	}
+
+	view, err := s.view(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch cart"))
+	}
+
+	return response.WithCode(200).WithData(view)
+}
+
+// view describes the cart of the customer as it is now.
+func (s *cartService) view(ctx context.Context, customerId string) (*dto.CartResponse, error) {
+	items, err := s.cartRepo.FindByCustomerID(ctx, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.loadItems(ctx, items); err != nil {
+		return nil, err
+	}
+
+	merchants := map[string]*entities.Merchant{}
+	for _, item := range items {
+		if item.Product == nil {
+			continue
+		}
+		if _, ok := merchants[item.Product.MerchantID]; ok {
+			continue
+		}
+		merchant, err := s.merchantRepo.FindOneById(ctx, item.Product.MerchantID)
+		if err != nil {
+			return nil, err
+		}
+		merchants[item.Product.MerchantID] = merchant
+	}
+
+	cart := dto.ToCartResponse(items, merchants)
+	return &cart, nil
+}
+
+// loadItems sets the product and variant of each item. Each item gets its
+// own copy of the product, with the discount running for the price of the
+// item, since variants of a product can be priced differently.
+func (s *cartService) loadItems(ctx context.Context, items []entities.CartItem) error {
+	productIds := make([]string, len(items))
+	for i, item := range items {
+		productIds[i] = item.ProductID
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	variants, err := s.variantRepo.FindByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	discounts, err := s.discountRepo.FindRunningByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	productsByID := map[string]entities.Product{}
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+	variantsByID := map[string]entities.ProductVariant{}
+	for _, variant := range variants {
+		variantsByID[variant.ID] = variant
+	}
+	discountsByProduct := map[string][]entities.Discount{}
+	for _, discount := range discounts {
+		discountsByProduct[discount.AppliesTo] = append(discountsByProduct[discount.AppliesTo], discount.Discount)
+	}
+
+	for i := range items {
+		product, ok := productsByID[items[i].ProductID]
+		if !ok {
+			continue
+		}
+
+		price := product.Price
+		if items[i].VariantID != nil {
+			variant, ok := variantsByID[*items[i].VariantID]
+			if !ok {
+				continue
+			}
+			items[i].Variant = &variant
+			price = variant.PriceOf(&product)
+		}
+
+		product.Discount = pricing.Best(price, discountsByProduct[product.ID])
+		items[i].Product = &product
+	}
+
+	return nil
+}
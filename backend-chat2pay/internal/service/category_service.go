@@ -0,0 +1,258 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/attributes"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type CategoryService interface {
+	Create(ctx context.Context, req *dto.CategoryRequest) *presenter.Response
+	GetAll(ctx context.Context) *presenter.Response
+	GetTree(ctx context.Context) *presenter.Response
+	GetById(ctx context.Context, id string) *presenter.Response
+	GetBreadcrumb(ctx context.Context, id string) *presenter.Response
+	Update(ctx context.Context, id string, req *dto.CategoryRequest) *presenter.Response
+	Delete(ctx context.Context, id string) *presenter.Response
+}
+
+type categoryService struct {
+	categoryRepo repositories.CategoryRepository
+	cfg          *yaml.Config
+}
+
+func NewCategoryService(categoryRepo repositories.CategoryRepository, cfg *yaml.Config) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+		cfg:          cfg,
+	}
+}
+
+func (s *categoryService) Create(ctx context.Context, req *dto.CategoryRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_create", s.cfg.Logger.Enable)
+	)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return response.WithCode(400).WithError(errors.New("name is required"))
+	}
+
+	parentId := emptyToNil(req.ParentID)
+	if parentId != nil {
+		parent, err := s.categoryRepo.FindOneById(ctx, *parentId)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching parent category: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		if parent == nil {
+			return response.WithCode(400).WithError(errors.New("parent category not found"))
+		}
+	}
+
+	schema := entities.AttributeSchema{}
+	if req.AttributeSchema != nil {
+		var err error
+		if schema, err = attributes.ValidateSchema(*req.AttributeSchema); err != nil {
+			return response.WithCode(400).WithError(err)
+		}
+	}
+
+	created, err := s.categoryRepo.Create(ctx, &entities.ProductCategory{
+		Name:            name,
+		ParentID:        parentId,
+		AttributeSchema: schema,
+	})
+	if err != nil {
+		log.Error(fmt.Sprintf("error creating category: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create category"))
+	}
+
+	data := dto.ToCategoryResponse(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *categoryService) GetAll(ctx context.Context) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_getall", s.cfg.Logger.Enable)
+	)
+
+	categories, err := s.categoryRepo.FindAll(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching categories: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch categories"))
+	}
+
+	data := dto.ToCategoryListResponse(categories)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *categoryService) GetTree(ctx context.Context) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_gettree", s.cfg.Logger.Enable)
+	)
+
+	categories, err := s.categoryRepo.FindAll(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching categories: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch categories"))
+	}
+
+	data := dto.ToCategoryTree(categories)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *categoryService) GetById(ctx context.Context, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_getbyid", s.cfg.Logger.Enable)
+	)
+
+	category, err := s.categoryRepo.FindOneById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if category == nil {
+		return response.WithCode(404).WithError(errors.New("category not found"))
+	}
+
+	data := dto.ToCategoryResponse(category)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *categoryService) GetBreadcrumb(ctx context.Context, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_getbreadcrumb", s.cfg.Logger.Enable)
+	)
+
+	ancestors, err := s.categoryRepo.FindAncestors(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category ancestors: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if len(ancestors) == 0 {
+		return response.WithCode(404).WithError(errors.New("category not found"))
+	}
+
+	data := dto.ToBreadcrumbResponse(ancestors)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *categoryService) Update(ctx context.Context, id string, req *dto.CategoryRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_update", s.cfg.Logger.Enable)
+	)
+
+	category, err := s.categoryRepo.FindOneById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if category == nil {
+		return response.WithCode(404).WithError(errors.New("category not found"))
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return response.WithCode(400).WithError(errors.New("name is required"))
+	}
+
+	parentId := emptyToNil(req.ParentID)
+	if parentId != nil {
+		// A category cannot move below itself or one of its own children
+		descendants, err := s.categoryRepo.FindDescendantIDs(ctx, id)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching category descendants: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		for _, descendantId := range descendants {
+			if descendantId == *parentId {
+				return response.WithCode(400).WithError(errors.New("category cannot be moved under itself"))
+			}
+		}
+
+		parent, err := s.categoryRepo.FindOneById(ctx, *parentId)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching parent category: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		if parent == nil {
+			return response.WithCode(400).WithError(errors.New("parent category not found"))
+		}
+	}
+
+	// Products already in the category are checked against a changed schema
+	// on their next update
+	if req.AttributeSchema != nil {
+		category.AttributeSchema, err = attributes.ValidateSchema(*req.AttributeSchema)
+		if err != nil {
+			return response.WithCode(400).WithError(err)
+		}
+	}
+
+	category.Name = name
+	category.ParentID = parentId
+
+	updated, err := s.categoryRepo.Update(ctx, category)
+	if err != nil {
+		log.Error(fmt.Sprintf("error updating category: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update category"))
+	}
+
+	data := dto.ToCategoryResponse(updated)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *categoryService) Delete(ctx context.Context, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("category_service_delete", s.cfg.Logger.Enable)
+	)
+
+	category, err := s.categoryRepo.FindOneById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if category == nil {
+		return response.WithCode(404).WithError(errors.New("category not found"))
+	}
+
+	children, err := s.categoryRepo.CountChildren(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting subcategories: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if children > 0 {
+		return response.WithCode(409).WithError(errors.New("category still has subcategories"))
+	}
+
+	// Products in the category are left without a category
+	if err = s.categoryRepo.Delete(ctx, id); err != nil {
+		log.Error(fmt.Sprintf("error deleting category: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete category"))
+	}
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+func emptyToNil(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	return s
+}
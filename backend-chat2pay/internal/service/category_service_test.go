@@ -0,0 +1,168 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/entities"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCategories returns Elektronik > Komputer > Laptop next to a separate
+// Fashion root.
+func testCategories() *fakeCategoryRepo {
+	return &fakeCategoryRepo{categories: []entities.ProductCategory{
+		{ID: "elektronik", Name: "Elektronik"},
+		{ID: "komputer", Name: "Komputer", ParentID: stringPtr("elektronik")},
+		{ID: "laptop", Name: "Laptop", ParentID: stringPtr("komputer")},
+		{ID: "fashion", Name: "Fashion"},
+	}}
+}
+
+func TestCategoryService_Create(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.CategoryRequest
+		code int
+		err  string
+	}{
+		{name: "A root category", req: dto.CategoryRequest{Name: "Olahraga"}, code: 201},
+		{name: "A subcategory", req: dto.CategoryRequest{Name: "Tablet", ParentID: stringPtr("elektronik")}, code: 201},
+		{name: "Without a name", req: dto.CategoryRequest{Name: "  "}, code: 400, err: "name is required"},
+		{name: "Under an unknown parent", req: dto.CategoryRequest{Name: "Tablet", ParentID: stringPtr("missing")}, code: 400, err: "parent category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testCategories()
+			response := NewCategoryService(repo, &yaml.Config{}).Create(context.Background(), &tt.req)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.err != "" {
+				assert.EqualError(t, response.Errors, tt.err)
+				assert.Len(t, repo.categories, 4)
+				return
+			}
+			created := repo.categories[len(repo.categories)-1]
+			assert.Equal(t, tt.req.Name, created.Name)
+			assert.Equal(t, tt.req.ParentID, created.ParentID)
+		})
+	}
+}
+
+func TestCategoryService_Update(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		req  dto.CategoryRequest
+		code int
+		err  string
+	}{
+		{name: "Moves under another parent", id: "komputer", req: dto.CategoryRequest{Name: "Komputer", ParentID: stringPtr("fashion")}, code: 200},
+		{name: "Becomes a root", id: "laptop", req: dto.CategoryRequest{Name: "Laptop"}, code: 200},
+		{name: "Under itself", id: "komputer", req: dto.CategoryRequest{Name: "Komputer", ParentID: stringPtr("komputer")}, code: 400, err: "category cannot be moved under itself"},
+		{name: "Under its own descendant", id: "elektronik", req: dto.CategoryRequest{Name: "Elektronik", ParentID: stringPtr("laptop")}, code: 400, err: "category cannot be moved under itself"},
+		{name: "Under an unknown parent", id: "komputer", req: dto.CategoryRequest{Name: "Komputer", ParentID: stringPtr("missing")}, code: 400, err: "parent category not found"},
+		{name: "Unknown category", id: "missing", req: dto.CategoryRequest{Name: "Tablet"}, code: 404, err: "category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testCategories()
+			response := NewCategoryService(repo, &yaml.Config{}).Update(context.Background(), tt.id, &tt.req)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.err != "" {
+				assert.EqualError(t, response.Errors, tt.err)
+				return
+			}
+			category, _ := repo.FindOneById(context.Background(), tt.id)
+			assert.Equal(t, tt.req.ParentID, category.ParentID)
+		})
+	}
+}
+
+func TestCategoryService_Delete(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		code int
+		err  string
+	}{
+		{name: "A leaf", id: "laptop", code: 200},
+		{name: "With subcategories", id: "komputer", code: 409, err: "category still has subcategories"},
+		{name: "Unknown category", id: "missing", code: 404, err: "category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testCategories()
+			response := NewCategoryService(repo, &yaml.Config{}).Delete(context.Background(), tt.id)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.err != "" {
+				assert.EqualError(t, response.Errors, tt.err)
+				assert.Len(t, repo.categories, 4)
+				return
+			}
+			assert.Len(t, repo.categories, 3)
+		})
+	}
+}
+
+func TestCategoryService_GetTree(t *testing.T) {
+	response := NewCategoryService(testCategories(), &yaml.Config{}).GetTree(context.Background())
+	require.Equal(t, 200, response.Code)
+
+	tree, ok := response.Data.([]dto.CategoryTreeResponse)
+	require.True(t, ok)
+	require.Len(t, tree, 2)
+
+	elektronik := tree[0]
+	assert.Equal(t, "elektronik", elektronik.ID)
+	require.Len(t, elektronik.Children, 1)
+	assert.Equal(t, "komputer", elektronik.Children[0].ID)
+	require.Len(t, elektronik.Children[0].Children, 1)
+	assert.Equal(t, "laptop", elektronik.Children[0].Children[0].ID)
+
+	assert.Equal(t, "fashion", tree[1].ID)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestCategoryService_GetBreadcrumb(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		code int
+		want []dto.ProductCategorySimple
+	}{
+		{
+			name: "Starts at the root",
+			id:   "laptop",
+			code: 200,
+			want: []dto.ProductCategorySimple{
+				{ID: "elektronik", Name: "Elektronik"},
+				{ID: "komputer", Name: "Komputer"},
+				{ID: "laptop", Name: "Laptop"},
+			},
+		},
+		{name: "A root", id: "fashion", code: 200, want: []dto.ProductCategorySimple{{ID: "fashion", Name: "Fashion"}}},
+		{name: "Unknown category", id: "missing", code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := NewCategoryService(testCategories(), &yaml.Config{}).GetBreadcrumb(context.Background(), tt.id)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "category not found")
+				return
+			}
+			assert.Equal(t, tt.want, response.Data)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type CustomerNotificationService interface {
+	GetAll(ctx context.Context, customerId string, unreadOnly bool, page, limit int) *presenter.Response
+	MarkRead(ctx context.Context, customerId, id string) *presenter.Response
+	MarkAllRead(ctx context.Context, customerId string) *presenter.Response
+}
+
+type customerNotificationService struct {
+	notificationRepo repositories.CustomerNotificationRepository
+	cfg              *yaml.Config
+}
+
+func NewCustomerNotificationService(
+	notificationRepo repositories.CustomerNotificationRepository,
+	cfg *yaml.Config,
+) CustomerNotificationService {
+	return &customerNotificationService{
+		notificationRepo: notificationRepo,
+		cfg:              cfg,
+	}
+}
+
+func (s *customerNotificationService) GetAll(ctx context.Context, customerId string, unreadOnly bool, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("customer_notification_service_get_all", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	notifications, err := s.notificationRepo.FindByCustomerID(ctx, customerId, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching notifications: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch notifications"))
+	}
+
+	total, err := s.notificationRepo.CountByCustomerID(ctx, customerId, unreadOnly)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting notifications: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch notifications"))
+	}
+
+	unread := total
+	if !unreadOnly {
+		unread, err = s.notificationRepo.CountByCustomerID(ctx, customerId, true)
+		if err != nil {
+			log.Error(fmt.Sprintf("error counting unread notifications: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to fetch notifications"))
+		}
+	}
+
+	data := dto.ToCustomerNotificationListResponse(notifications, unread, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *customerNotificationService) MarkRead(ctx context.Context, customerId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("customer_notification_service_mark_read", s.cfg.Logger.Enable)
+	)
+
+	found, err := s.notificationRepo.MarkRead(ctx, customerId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error marking notification read: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update notification"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("notification not found"))
+	}
+
+	return response.WithCode(200).WithData("read")
+}
+
+func (s *customerNotificationService) MarkAllRead(ctx context.Context, customerId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("customer_notification_service_mark_all_read", s.cfg.Logger.Enable)
+	)
+
+	if err := s.notificationRepo.MarkAllRead(ctx, customerId); err != nil {
+		log.Error(fmt.Sprintf("error marking notifications read: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update notifications"))
+	}
+
+	return response.WithCode(200).WithData("read")
+}
@@ -115,7 +115,7 @@ func (s *customerService) GetById(ctx context.Context, id string) *presenter.Res
 		log      = logger.NewLog("customer_service_getbyid", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("fetching customer with id: %d", id))
+	log.Info(fmt.Sprintf("fetching customer with id: %s", id))
 	customer, err := s.customerRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching customer: %v", err))
@@ -137,7 +137,7 @@ func (s *customerService) Update(ctx context.Context, id string, req *dto.Custom
 		log      = logger.NewLog("customer_service_update", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("fetching customer with id: %d", id))
+	log.Info(fmt.Sprintf("fetching customer with id: %s", id))
 	customer, err := s.customerRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching customer: %v", err))
@@ -189,7 +189,7 @@ func (s *customerService) Delete(ctx context.Context, id string) *presenter.Resp
 		log      = logger.NewLog("customer_service_delete", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("checking if customer exists: %d", id))
+	log.Info(fmt.Sprintf("checking if customer exists: %s", id))
 	customer, err := s.customerRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching customer: %v", err))
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DiscountService interface {
+	GetAll(ctx context.Context, merchantId string, filter repositories.DiscountFilter, page, limit int) *presenter.Response
+	GetById(ctx context.Context, merchantId, id string) *presenter.Response
+	Create(ctx context.Context, merchantId string, req *dto.DiscountRequest) *presenter.Response
+	Update(ctx context.Context, merchantId, id string, req *dto.DiscountRequest) *presenter.Response
+	Delete(ctx context.Context, merchantId, id string) *presenter.Response
+	GetPriceHistory(ctx context.Context, merchantId, productId string, page, limit int) *presenter.Response
+}
+
+type discountService struct {
+	discountRepo repositories.DiscountRepository
+	productRepo  repositories.ProductRepository
+	categoryRepo repositories.CategoryRepository
+	cfg          *yaml.Config
+}
+
+func NewDiscountService(
+	discountRepo repositories.DiscountRepository,
+	productRepo repositories.ProductRepository,
+	categoryRepo repositories.CategoryRepository,
+	cfg *yaml.Config,
+) DiscountService {
+	return &discountService{
+		discountRepo: discountRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		cfg:          cfg,
+	}
+}
+
+func (s *discountService) GetAll(ctx context.Context, merchantId string, filter repositories.DiscountFilter, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_get_all", s.cfg.Logger.Enable)
+	)
+
+	switch filter.State {
+	case "", entities.DiscountStateRunning, entities.DiscountStateScheduled, entities.DiscountStateEnded:
+	default:
+		return response.WithCode(400).WithError(errors.New("state must be running, scheduled or ended"))
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter.MerchantID = merchantId
+
+	discounts, err := s.discountRepo.Find(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching discounts: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch discounts"))
+	}
+
+	total, err := s.discountRepo.Count(ctx, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting discounts: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch discounts"))
+	}
+
+	data := dto.ToDiscountListResponse(discounts, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *discountService) GetById(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_get_by_id", s.cfg.Logger.Enable)
+	)
+
+	discount, err := s.discountRepo.FindOneById(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching discount: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if discount == nil {
+		return response.WithCode(404).WithError(errors.New("discount not found"))
+	}
+
+	data := dto.ToDiscountResponse(discount, time.Now())
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *discountService) Create(ctx context.Context, merchantId string, req *dto.DiscountRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_create", s.cfg.Logger.Enable)
+	)
+
+	discount := req.ToEntity(merchantId)
+	if errResponse := s.checkDiscount(ctx, log, &discount); errResponse != nil {
+		return errResponse
+	}
+
+	created, err := s.discountRepo.Create(ctx, &discount)
+	if err != nil {
+		log.Error(fmt.Sprintf("error creating discount: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create discount"))
+	}
+
+	data := dto.ToDiscountResponse(created, time.Now())
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *discountService) Update(ctx context.Context, merchantId, id string, req *dto.DiscountRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_update", s.cfg.Logger.Enable)
+	)
+
+	existing, err := s.discountRepo.FindOneById(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching discount: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if existing == nil {
+		return response.WithCode(404).WithError(errors.New("discount not found"))
+	}
+
+	discount := req.ToEntity(merchantId)
+	discount.ID = existing.ID
+	discount.CreatedAt = existing.CreatedAt
+	if errResponse := s.checkDiscount(ctx, log, &discount); errResponse != nil {
+		return errResponse
+	}
+
+	updated, err := s.discountRepo.Update(ctx, &discount)
+	if err != nil {
+		log.Error(fmt.Sprintf("error updating discount: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update discount"))
+	}
+
+	data := dto.ToDiscountResponse(updated, time.Now())
+	return response.WithCode(200).WithData(data)
+}
+
+// Delete removes a discount. A running discount stops at once, orders
+// already placed keep the price they were charged.
+func (s *discountService) Delete(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_delete", s.cfg.Logger.Enable)
+	)
+
+	found, err := s.discountRepo.Delete(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error deleting discount: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete discount"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("discount not found"))
+	}
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+// GetPriceHistory lists the changes of the base price of a product, latest
+// first. Discounts do not change the base price and are not listed.
+func (s *discountService) GetPriceHistory(ctx context.Context, merchantId, productId string, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("discount_service_get_price_history", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	product, err := s.productRepo.FindOneById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != merchantId {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	changes, err := s.productRepo.FindPriceHistory(ctx, productId, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching price history: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch price history"))
+	}
+
+	total, err := s.productRepo.CountPriceHistory(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting price history: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch price history"))
+	}
+
+	data := dto.ToPriceHistoryResponse(product, changes, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+// checkDiscount validates the rule of the discount and that its product
+// belongs to the merchant or its category exists.
+func (s *discountService) checkDiscount(ctx context.Context, log logger.LoggerLevel, discount *entities.Discount) *presenter.Response {
+	response := presenter.Response{}
+
+	discount.Name = strings.TrimSpace(discount.Name)
+	discount.Type = strings.ToLower(strings.TrimSpace(discount.Type))
+	if discount.Name == "" {
+		return response.WithCode(400).WithError(errors.New("name is required"))
+	}
+	if err := pricing.Validate(*discount); err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+
+	if discount.ProductID != nil && *discount.ProductID != "" {
+		discount.CategoryID = nil
+
+		product, err := s.productRepo.FindOneById(ctx, *discount.ProductID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching product: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		if product == nil || product.MerchantID != discount.MerchantID || product.Status == entities.ProductStatusArchived {
+			return response.WithCode(404).WithError(errors.New("product not found"))
+		}
+		return nil
+	}
+
+	discount.ProductID = nil
+
+	category, err := s.categoryRepo.FindOneById(ctx, *discount.CategoryID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if category == nil {
+		return response.WithCode(404).WithError(errors.New("category not found"))
+	}
+
+	return nil
+}
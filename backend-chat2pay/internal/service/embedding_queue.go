@@ -0,0 +1,65 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// embeddingQueueSize is the number of products that can wait for
+	// embedding before Enqueue has to wait for the worker.
+	embeddingQueueSize = 1000
+	// embeddingTimeout bounds the embedding of a single product.
+	embeddingTimeout = time.Minute
+)
+
+// EmbeddingQueue rebuilds product embeddings in the background, so bulk
+// changes such as an import return before every product is embedded. The
+// queue lives in memory; products still waiting when the server stops can
+// be embedded again with the search-reindex command.
+type EmbeddingQueue interface {
+	Enqueue(productIds ...string)
+}
+
+type embeddingQueue struct {
+	productService ProductService
+	cfg            *yaml.Config
+	jobs           chan string
+}
+
+func NewEmbeddingQueue(productService ProductService, cfg *yaml.Config) EmbeddingQueue {
+	q := &embeddingQueue{
+		productService: productService,
+		cfg:            cfg,
+		jobs:           make(chan string, embeddingQueueSize),
+	}
+
+	go q.run()
+
+	return q
+}
+
+// Enqueue never blocks the caller, a full queue is drained in the
+// background.
+func (q *embeddingQueue) Enqueue(productIds ...string) {
+	go func() {
+		for _, id := range productIds {
+			q.jobs <- id
+		}
+	}()
+}
+
+func (q *embeddingQueue) run() {
+	log := logger.NewLog("embedding_queue", q.cfg.Logger.Enable)
+
+	for id := range q.jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), embeddingTimeout)
+		if err := q.productService.Reembed(ctx, id); err != nil {
+			log.Error(fmt.Sprintf("error embedding product %s: %v", id, err))
+		}
+		cancel()
+	}
+}
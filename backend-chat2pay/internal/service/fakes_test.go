@@ -0,0 +1,320 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/llm"
+	"chat2pay/internal/pkg/productdoc"
+	"chat2pay/internal/repositories"
+	"context"
+	"fmt"
+)
+
+// The fakes below keep their data in memory. Each embeds the repository
+// interface it stands in for, so a method a test does not set up panics
+// when it is called.
+
+type fakeProductRepo struct {
+	repositories.ProductRepository
+	products       map[string]*entities.Product
+	similar        []entities.ProductEmbedding
+	boughtTogether []entities.Product
+	searched       *repositories.ProductFilter // the filter of the last search
+	matches        []entities.ProductEmbedding // in stock matches of a chat search
+	outOfStock     []string                    // out of stock matches of a chat search
+}
+
+func newFakeProductRepo(products ...entities.Product) *fakeProductRepo {
+	repo := &fakeProductRepo{products: map[string]*entities.Product{}}
+	for i := range products {
+		repo.products[products[i].ID] = &products[i]
+	}
+	return repo
+}
+
+func (r *fakeProductRepo) FindOneById(ctx context.Context, id string) (*entities.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *product
+	return &found, nil
+}
+
+func (r *fakeProductRepo) FindActiveById(ctx context.Context, id string) (*entities.Product, error) {
+	product, err := r.FindOneById(ctx, id)
+	if product == nil || product.Status != entities.ProductStatusActive {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (r *fakeProductRepo) FindByIDs(ctx context.Context, ids []string) ([]entities.Product, error) {
+	products := []entities.Product{}
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			products = append(products, *product)
+		}
+	}
+	return products, nil
+}
+
+func (r *fakeProductRepo) GetSimilarProductEmbeddingList(ctx context.Context, productId string) ([]entities.ProductEmbedding, error) {
+	return r.similar, nil
+}
+
+func (r *fakeProductRepo) FindBoughtTogether(ctx context.Context, productId string, limit int) ([]entities.Product, error) {
+	if len(r.boughtTogether) > limit {
+		return r.boughtTogether[:limit], nil
+	}
+	return r.boughtTogether, nil
+}
+
+func (r *fakeProductRepo) Search(ctx context.Context, filter repositories.ProductFilter, limit, offset int) ([]entities.Product, error) {
+	r.searched = &filter
+	return []entities.Product{}, nil
+}
+
+func (r *fakeProductRepo) CountSearch(ctx context.Context, filter repositories.ProductFilter) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeProductRepo) Update(ctx context.Context, product *entities.Product, actorId *string) (*entities.Product, error) {
+	updated := *product
+	r.products[product.ID] = &updated
+	return product, nil
+}
+
+func (r *fakeProductRepo) ReplaceProductEmbeddings(ctx context.Context, productId string, embeddings []entities.ProductEmbedding) error {
+	return nil
+}
+
+func (r *fakeProductRepo) CorrectTerms(ctx context.Context, merchantId string, words []string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (r *fakeProductRepo) GetProductEmbeddingList(ctx context.Context, vector []float32, categoryIds []string) ([]entities.ProductEmbedding, error) {
+	return r.matches, nil
+}
+
+func (r *fakeProductRepo) FindOutOfStockMatches(ctx context.Context, vector []float32, maxPrice float64, categoryIds []string, limit int) ([]string, error) {
+	return r.outOfStock, nil
+}
+
+type fakeImageRepo struct {
+	repositories.ProductImageRepository
+}
+
+func (r *fakeImageRepo) FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductImage, error) {
+	return nil, nil
+}
+
+type fakeDiscountRepo struct {
+	repositories.DiscountRepository
+	running []entities.ProductDiscount
+}
+
+func (r *fakeDiscountRepo) FindRunningByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductDiscount, error) {
+	return r.running, nil
+}
+
+type fakeReviewRepo struct {
+	repositories.ProductReviewRepository
+}
+
+func (r *fakeReviewRepo) FindRatingsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductRating, error) {
+	return nil, nil
+}
+
+type fakeMerchantRepo struct {
+	repositories.MerchantRepository
+}
+
+func (r *fakeMerchantRepo) FindOneById(ctx context.Context, id string) (*entities.Merchant, error) {
+	return nil, nil
+}
+
+// fakeVariantRepo stands in for products without variants.
+type fakeVariantRepo struct {
+	repositories.ProductVariantRepository
+}
+
+func (r *fakeVariantRepo) CountByProductID(ctx context.Context, productId string) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeVariantRepo) FindOptionsByProductID(ctx context.Context, productId string) ([]entities.ProductOption, error) {
+	return nil, nil
+}
+
+func (r *fakeVariantRepo) FindOptionsByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductOption, error) {
+	return nil, nil
+}
+
+func (r *fakeVariantRepo) FindByProductIDs(ctx context.Context, productIds []string) ([]entities.ProductVariant, error) {
+	return nil, nil
+}
+
+type fakeAlertRepo struct {
+	repositories.StockAlertRepository
+	misses []string // the products of the recorded chat misses
+}
+
+func (r *fakeAlertRepo) RecordChatMisses(ctx context.Context, sessionId, query string, productIds []string) error {
+	r.misses = append(r.misses, productIds...)
+	return nil
+}
+
+// fakeLLM classifies every message as intent, finds no budget in it and
+// answers every chat with the same text.
+type fakeLLM struct {
+	llm.LLM
+	intent string
+}
+
+func (l *fakeLLM) ClassifyIntent(ctx context.Context, userMessage string) (string, error) {
+	return l.intent, nil
+}
+
+func (l *fakeLLM) Chat(ctx context.Context, userMessage string) (string, error) {
+	return "Baik.", nil
+}
+
+func (l *fakeLLM) ChatWithHistory(ctx context.Context, userMessage string) (string, error) {
+	return "Baik.", nil
+}
+
+func (l *fakeLLM) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1}, nil
+}
+
+func (l *fakeLLM) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+// newTestProductService builds a product service over the fake repository,
+// with no categories, variants, discounts, images, ratings or synonyms.
+func newTestProductService(productRepo *fakeProductRepo) *productService {
+	return &productService{
+		productRepo:  productRepo,
+		merchantRepo: &fakeMerchantRepo{},
+		categoryRepo: &fakeCategoryRepo{},
+		imageRepo:    &fakeImageRepo{},
+		variantRepo:  &fakeVariantRepo{},
+		alertRepo:    &fakeAlertRepo{},
+		discountRepo: &fakeDiscountRepo{},
+		reviewRepo:   &fakeReviewRepo{},
+		llm:          &fakeLLM{},
+		cfg:          &yaml.Config{},
+		docBuilder:   productdoc.NewBuilder(yaml.SearchDocument{}),
+		normalizer:   newQueryNormalizer(productRepo, nil),
+	}
+}
+
+// fakeCategoryRepo keeps the category tree as a flat list linked by
+// parent ids.
+type fakeCategoryRepo struct {
+	repositories.CategoryRepository
+	categories []entities.ProductCategory
+}
+
+func (r *fakeCategoryRepo) Create(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error) {
+	category.ID = fmt.Sprintf("category-%d", len(r.categories)+1)
+	r.categories = append(r.categories, *category)
+	return category, nil
+}
+
+func (r *fakeCategoryRepo) FindAll(ctx context.Context) ([]entities.ProductCategory, error) {
+	return r.categories, nil
+}
+
+func (r *fakeCategoryRepo) FindOneById(ctx context.Context, id string) (*entities.ProductCategory, error) {
+	for _, category := range r.categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCategoryRepo) FindAncestors(ctx context.Context, id string) ([]entities.ProductCategory, error) {
+	ancestors := []entities.ProductCategory{}
+	for category, _ := r.FindOneById(ctx, id); category != nil; {
+		ancestors = append([]entities.ProductCategory{*category}, ancestors...)
+		if category.ParentID == nil {
+			break
+		}
+		category, _ = r.FindOneById(ctx, *category.ParentID)
+	}
+	return ancestors, nil
+}
+
+func (r *fakeCategoryRepo) FindDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	if category, _ := r.FindOneById(ctx, id); category == nil {
+		return []string{}, nil
+	}
+	ids := []string{id}
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			children, _ := r.FindDescendantIDs(ctx, category.ID)
+			ids = append(ids, children...)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeCategoryRepo) Update(ctx context.Context, category *entities.ProductCategory) (*entities.ProductCategory, error) {
+	for i := range r.categories {
+		if r.categories[i].ID == category.ID {
+			r.categories[i] = *category
+		}
+	}
+	return category, nil
+}
+
+func (r *fakeCategoryRepo) Delete(ctx context.Context, id string) error {
+	kept := []entities.ProductCategory{}
+	for _, category := range r.categories {
+		if category.ID != id {
+			kept = append(kept, category)
+		}
+	}
+	r.categories = kept
+	return nil
+}
+
+func (r *fakeCategoryRepo) CountChildren(ctx context.Context, id string) (int64, error) {
+	var count int64
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeOrderRepo struct {
+	repositories.OrderRepository
+	orders    map[string]*entities.Order
+	cancelled *entities.OrderStatusHistory // the change of the last cancel
+}
+
+func (r *fakeOrderRepo) FindByID(ctx context.Context, id string) (*entities.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", id)
+	}
+	found := *order
+	return &found, nil
+}
+
+func (r *fakeOrderRepo) GetOrderItems(ctx context.Context, orderID string) ([]entities.OrderItem, error) {
+	return nil, nil
+}
+
+func (r *fakeOrderRepo) Cancel(ctx context.Context, change *entities.OrderStatusHistory, reason *string) error {
+	r.cancelled = change
+	r.orders[change.OrderID].Status = change.ToStatus
+	r.orders[change.OrderID].CancelReason = reason
+	return nil
+}
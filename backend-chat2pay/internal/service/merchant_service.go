@@ -55,6 +55,8 @@ func (s *merchantService) Create(ctx context.Context, req *dto.MerchantRequest)
 		LegalName: stringPtr(req.LegalName),
 		Email:     req.Email,
 		Phone:     stringPtr(req.Phone),
+		CityID:    stringPtr(req.CityID),
+		CityName:  stringPtr(req.CityName),
 		Status:    "pending_verification",
 	}
 
@@ -111,7 +113,7 @@ func (s *merchantService) GetById(ctx context.Context, id string) *presenter.Res
 		log      = logger.NewLog("merchant_service_getbyid", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("fetching merchant with id: %d", id))
+	log.Info(fmt.Sprintf("fetching merchant with id: %s", id))
 	merchant, err := s.merchantRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching merchant: %v", err))
@@ -133,7 +135,7 @@ func (s *merchantService) Update(ctx context.Context, id string, req *dto.Mercha
 		log      = logger.NewLog("merchant_service_update", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("fetching merchant with id: %d", id))
+	log.Info(fmt.Sprintf("fetching merchant with id: %s", id))
 	merchant, err := s.merchantRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching merchant: %v", err))
@@ -161,6 +163,8 @@ func (s *merchantService) Update(ctx context.Context, id string, req *dto.Mercha
 	merchant.LegalName = stringPtr(req.LegalName)
 	merchant.Email = req.Email
 	merchant.Phone = stringPtr(req.Phone)
+	merchant.CityID = stringPtr(req.CityID)
+	merchant.CityName = stringPtr(req.CityName)
 
 	if req.Status != "" {
 		merchant.Status = req.Status
@@ -183,7 +187,7 @@ func (s *merchantService) Delete(ctx context.Context, id string) *presenter.Resp
 		log      = logger.NewLog("merchant_service_delete", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("checking if merchant exists: %d", id))
+	log.Info(fmt.Sprintf("checking if merchant exists: %s", id))
 	merchant, err := s.merchantRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching merchant: %v", err))
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// orderExpiryInterval is how often the expirer looks for unpaid orders
+	// whose payment window has passed.
+	orderExpiryInterval = time.Minute
+
+	// orderExpiryBatch is how many orders are expired per run at most, the
+	// rest are left for the next runs.
+	orderExpiryBatch = 100
+
+	defaultPaymentWindow = 24 * time.Hour
+)
+
+// OrderExpirer cancels orders that were not paid within the payment window,
+// returning their stock. It runs alongside the http server; orders whose
+// window passed while the server was down are expired on the next run.
+type OrderExpirer interface {
+	Run(ctx context.Context)
+}
+
+type orderExpirer struct {
+	orderRepo repositories.OrderRepository
+	cfg       *yaml.Config
+}
+
+func NewOrderExpirer(orderRepo repositories.OrderRepository, cfg *yaml.Config) OrderExpirer {
+	return &orderExpirer{
+		orderRepo: orderRepo,
+		cfg:       cfg,
+	}
+}
+
+// Run expires unpaid orders until the context is cancelled.
+func (e *orderExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(orderExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		e.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *orderExpirer) process(ctx context.Context) {
+	log := logger.NewLog("order_expirer", e.cfg.Logger.Enable)
+
+	window := defaultPaymentWindow
+	if minutes := e.cfg.Order.PaymentWindowMinutes; minutes > 0 {
+		window = time.Duration(minutes) * time.Minute
+	}
+
+	orders, err := e.orderRepo.FindUnpaidBefore(ctx, time.Now().Add(-window), orderExpiryBatch)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching unpaid orders: %v", err))
+		return
+	}
+
+	expired := 0
+	for _, order := range orders {
+		err = e.orderRepo.Expire(ctx, order.ID)
+		switch {
+		case errors.Is(err, repositories.ErrOrderStatusChanged):
+			// Paid or cancelled since it was fetched
+		case err != nil:
+			log.Error(fmt.Sprintf("error expiring order %s: %v", order.ID, err))
+		default:
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		log.Info(fmt.Sprintf("expired %d unpaid orders", expired))
+	}
+}
@@ -5,87 +5,117 @@ import (
 	"chat2pay/internal/api/dto"
 	"chat2pay/internal/api/presenter"
 	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/fulfillment"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/orderstatus"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/pkg/rajaongkir"
+	"chat2pay/internal/pkg/shipping"
 	"chat2pay/internal/repositories"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"strings"
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, customerID string, req *dto.CreateOrderRequest) *presenter.Response
-	GetOrderByID(ctx context.Context, id string) *presenter.Response
+	GetFulfillment(ctx context.Context, req *dto.FulfillmentRequest) *presenter.Response
+	Checkout(ctx context.Context, customerID string, req *dto.CheckoutRequest) *presenter.Response
+	GetCartFulfillment(ctx context.Context, customerID string, req *dto.CartFulfillmentRequest) *presenter.Response
+	GetCustomerOrder(ctx context.Context, customerID, id string) *presenter.Response
+	GetMerchantOrder(ctx context.Context, merchantID, id string) *presenter.Response
+	GetOrderTimeline(ctx context.Context, customerID, id string) *presenter.Response
 	GetCustomerOrders(ctx context.Context, customerID string, page, limit int) *presenter.Response
 	GetMerchantOrders(ctx context.Context, merchantID string, page, limit int) *presenter.Response
-	UpdateOrderStatus(ctx context.Context, orderID, status string) *presenter.Response
-	UpdateTrackingNumber(ctx context.Context, orderID, trackingNumber string) *presenter.Response
+	UpdateOrderStatus(ctx context.Context, merchantID, orderID, status string) *presenter.Response
+	UpdateTrackingNumber(ctx context.Context, merchantID, orderID, trackingNumber string) *presenter.Response
+	CancelOrder(ctx context.Context, customerID, orderID string, req *dto.CancelOrderRequest) *presenter.Response
+	CancelMerchantOrder(ctx context.Context, merchantID, userID, orderID string, req *dto.CancelOrderRequest) *presenter.Response
 }
 
 type orderService struct {
-	cfg         *yaml.Config
-	orderRepo   repositories.OrderRepository
-	productRepo repositories.ProductRepository
+	cfg          *yaml.Config
+	orderRepo    repositories.OrderRepository
+	productRepo  repositories.ProductRepository
+	variantRepo  repositories.ProductVariantRepository
+	outletRepo   repositories.OutletRepository
+	discountRepo repositories.DiscountRepository
+	cartRepo     repositories.CartRepository
+	merchantRepo repositories.MerchantRepository
+	quoter       *shipping.Quoter
 }
 
-func NewOrderService(cfg *yaml.Config, orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository) OrderService {
+func NewOrderService(cfg *yaml.Config, orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, variantRepo repositories.ProductVariantRepository, outletRepo repositories.OutletRepository, discountRepo repositories.DiscountRepository, cartRepo repositories.CartRepository, merchantRepo repositories.MerchantRepository, quoter *shipping.Quoter) OrderService {
 	return &orderService{
-		cfg:         cfg,
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
+		cfg:          cfg,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		outletRepo:   outletRepo,
+		discountRepo: discountRepo,
+		cartRepo:     cartRepo,
+		merchantRepo: merchantRepo,
+		quoter:       quoter,
 	}
 }
 
-func (s *orderService) CreateOrder(ctx context.Context, customerID string, req *dto.CreateOrderRequest) *presenter.Response {
-	response := presenter.NewResponse()
-
-	// Calculate subtotal and validate products
-	var subtotal float64
-	var merchantID string
-	orderItems := make([]entities.OrderItem, 0, len(req.Items))
-
-	for _, item := range req.Items {
-		product, err := s.productRepo.FindByID(ctx, item.ProductID)
-		if err != nil {
-			return response.WithCode(404).WithError(errors.New("product not found: " + item.ProductID))
-		}
+// outletPick is the outlet an order ships from, and which of the ordered
+// products and variants are stocked per outlet.
+type outletPick struct {
+	outlet    *entities.Outlet
+	perOutlet map[string]bool
+}
 
-		if product.Stock < item.Quantity {
-			return response.WithCode(400).WithError(errors.New("insufficient stock for: " + product.Name))
-		}
+// cartPart is the part of a cart ordered from one merchant.
+type cartPart struct {
+	merchantID string
+	items      []dto.OrderItemRequest
+}
 
-		// All products must be from the same merchant
-		if merchantID == "" {
-			merchantID = product.MerchantID
-		} else if merchantID != product.MerchantID {
-			return response.WithCode(400).WithError(errors.New("all products must be from the same merchant"))
-		}
+func (s *orderService) CreateOrder(ctx context.Context, customerID string, req *dto.CreateOrderRequest) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_create_order", s.cfg.Logger.Enable)
+	)
+
+	orderItems, merchantID, weight, resp := s.buildItems(ctx, req.Items)
+	if resp != nil {
+		return resp
+	}
 
-		itemSubtotal := product.Price * float64(item.Quantity)
-		subtotal += itemSubtotal
+	pick, resp := s.pickOutlet(ctx, merchantID, orderItems, fulfillment.Destination{
+		CityID:    req.ShippingCityID,
+		City:      req.ShippingCity,
+		Province:  req.ShippingProvince,
+		Latitude:  req.ShippingLatitude,
+		Longitude: req.ShippingLongitude,
+	})
+	if resp != nil {
+		return resp
+	}
 
-		orderItems = append(orderItems, entities.OrderItem{
-			ID:           uuid.New().String(),
-			ProductID:    product.ID,
-			ProductName:  product.Name,
-			ProductPrice: product.Price,
-			Quantity:     item.Quantity,
-			Subtotal:     itemSubtotal,
-		})
+	_, originCityID, err := s.shippingOrigin(ctx, merchantID, pick)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching shipping origin: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create order"))
 	}
 
-	total := subtotal + req.ShippingCost
+	cost, resp := s.quoteShipping(originCityID, req.ShippingCityID, weight, req.Courier, req.CourierService)
+	if resp != nil {
+		return resp
+	}
 
-	// Create order
 	order := &entities.Order{
 		ID:                 uuid.New().String(),
 		CustomerID:         customerID,
 		MerchantID:         merchantID,
 		Status:             entities.OrderStatusPending,
-		Subtotal:           subtotal,
-		ShippingCost:       req.ShippingCost,
-		Total:              total,
+		ShippingCost:       float64(cost.Value),
 		Courier:            &req.Courier,
 		CourierService:     &req.CourierService,
-		ShippingEtd:        &req.ShippingEtd,
+		ShippingEtd:        &cost.Etd,
 		ShippingAddress:    &req.ShippingAddress,
 		ShippingCity:       &req.ShippingCity,
 		ShippingProvince:   &req.ShippingProvince,
@@ -97,34 +127,558 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req *
 		order.Notes = &req.Notes
 	}
 
-	if err := s.orderRepo.Create(ctx, order); err != nil {
+	movements := completeOrder(order, orderItems, pick)
+
+	// The stock checked while building the items may have been taken by
+	// another order since, the order is only placed if it is still there
+	err = s.orderRepo.Place(ctx, order, movements)
+	switch {
+	case errors.Is(err, repositories.ErrInsufficientStock):
+		return response.WithCode(409).WithError(errors.New("insufficient stock, the items ran out while ordering"))
+	case err != nil:
+		log.Error(fmt.Sprintf("error placing order: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to create order"))
 	}
 
-	// Create order items
-	for i := range orderItems {
-		orderItems[i].OrderID = order.ID
-		if err := s.orderRepo.CreateItem(ctx, &orderItems[i]); err != nil {
-			return response.WithCode(500).WithError(errors.New("failed to create order item"))
+	return response.WithCode(201).WithData(dto.ToOrderResponse(order))
+}
+
+// completeOrder sets the items of the order, its totals and the outlet it
+// ships from, and returns the movements taking the stock of the items out,
+// from the outlet for items stocked per outlet.
+func completeOrder(order *entities.Order, items []entities.OrderItem, pick *outletPick) []entities.StockMovement {
+	order.Items = items
+	order.Subtotal = 0
+	for _, item := range items {
+		order.Subtotal += item.Subtotal
+	}
+	order.Total = order.Subtotal + order.ShippingCost
+
+	if pick != nil {
+		order.OutletID = &pick.outlet.ID
+	}
+
+	referenceType := "order"
+	movements := make([]entities.StockMovement, len(items))
+	for i, item := range items {
+		movements[i] = entities.StockMovement{
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Type:          entities.StockMovementSale,
+			Quantity:      -item.Quantity,
+			ActorType:     entities.StockActorCustomer,
+			ActorID:       &order.CustomerID,
+			ReferenceType: &referenceType,
+			ReferenceID:   &order.ID,
+		}
+		if pick != nil && pick.perOutlet[stockKey(item.ProductID, item.VariantID)] {
+			movements[i].OutletID = &pick.outlet.ID
 		}
 	}
 
-	// Update product stock
-	for _, item := range req.Items {
-		product, _ := s.productRepo.FindByID(ctx, item.ProductID)
-		newStock := product.Stock - item.Quantity
-		s.productRepo.UpdateStock(ctx, item.ProductID, newStock)
+	return movements
+}
+
+// GetFulfillment tells which outlet would ship the items to the given city
+// and the weight they ship at, so the shipping cost can be quoted from that
+// outlet's city, or from the merchant's city without outlets.
+func (s *orderService) GetFulfillment(ctx context.Context, req *dto.FulfillmentRequest) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_get_fulfillment", s.cfg.Logger.Enable)
+	)
+
+	if len(req.Items) == 0 {
+		return response.WithCode(400).WithError(errors.New("items are required"))
 	}
 
-	order.Items = orderItems
-	return response.WithCode(201).WithData(dto.ToOrderResponse(order))
+	orderItems, merchantID, weight, resp := s.buildItems(ctx, req.Items)
+	if resp != nil {
+		return resp
+	}
+
+	pick, resp := s.pickOutlet(ctx, merchantID, orderItems, fulfillment.Destination{
+		CityID:    req.ShippingCityID,
+		City:      req.ShippingCity,
+		Province:  req.ShippingProvince,
+		Latitude:  req.ShippingLatitude,
+		Longitude: req.ShippingLongitude,
+	})
+	if resp != nil {
+		return resp
+	}
+
+	originCity, originCityID, err := s.shippingOrigin(ctx, merchantID, pick)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching shipping origin: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to get fulfillment"))
+	}
+
+	data := dto.FulfillmentResponse{OriginCity: originCity, OriginCityID: originCityID, Weight: weight}
+	if pick != nil {
+		data.OutletID = &pick.outlet.ID
+		data.OutletName = &pick.outlet.Name
+	}
+
+	return response.WithCode(200).WithData(data)
 }
 
-func (s *orderService) GetOrderByID(ctx context.Context, id string) *presenter.Response {
+// Checkout places the cart of the customer as one order per merchant, all
+// under one checkout, each shipped with the courier service chosen for its
+// merchant at the price quoted for it. The ordered items are taken out of
+// the cart.
+func (s *orderService) Checkout(ctx context.Context, customerID string, req *dto.CheckoutRequest) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_checkout", s.cfg.Logger.Enable)
+	)
+
+	if strings.TrimSpace(req.ShippingAddress) == "" || req.ShippingCityID == "" {
+		return response.WithCode(400).WithError(errors.New("shipping address and city are required"))
+	}
+
+	cart, err := s.cartRepo.FindByCustomerID(ctx, customerID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to checkout"))
+	}
+	if len(cart) == 0 {
+		return response.WithCode(400).WithError(errors.New("cart is empty"))
+	}
+
+	parts, resp := s.splitCart(ctx, cart)
+	if resp != nil {
+		return resp
+	}
+
+	shipments := map[string]dto.CheckoutShipmentRequest{}
+	for _, shipment := range req.Shipments {
+		shipments[shipment.MerchantID] = shipment
+	}
+
+	destination := fulfillment.Destination{
+		CityID:    req.ShippingCityID,
+		City:      req.ShippingCity,
+		Province:  req.ShippingProvince,
+		Latitude:  req.ShippingLatitude,
+		Longitude: req.ShippingLongitude,
+	}
+
+	checkout := &entities.Checkout{
+		CustomerID: customerID,
+		Orders:     make([]entities.Order, len(parts)),
+	}
+	movements := []entities.StockMovement{}
+
+	for i, part := range parts {
+		shipment, ok := shipments[part.merchantID]
+		if !ok || shipment.Courier == "" || shipment.CourierService == "" {
+			return response.WithCode(400).WithError(errors.New("choose the shipping for merchant: " + part.merchantID))
+		}
+
+		orderItems, _, weight, resp := s.buildItems(ctx, part.items)
+		if resp != nil {
+			return resp
+		}
+
+		pick, resp := s.pickOutlet(ctx, part.merchantID, orderItems, destination)
+		if resp != nil {
+			return resp
+		}
+
+		_, originCityID, err := s.shippingOrigin(ctx, part.merchantID, pick)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching shipping origin: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to checkout"))
+		}
+
+		cost, resp := s.quoteShipping(originCityID, req.ShippingCityID, weight, shipment.Courier, shipment.CourierService)
+		if resp != nil {
+			return resp
+		}
+
+		order := &checkout.Orders[i]
+		*order = entities.Order{
+			ID:                 uuid.New().String(),
+			CustomerID:         customerID,
+			MerchantID:         part.merchantID,
+			Status:             entities.OrderStatusPending,
+			ShippingCost:       float64(cost.Value),
+			Courier:            &shipment.Courier,
+			CourierService:     &shipment.CourierService,
+			ShippingEtd:        &cost.Etd,
+			ShippingAddress:    &req.ShippingAddress,
+			ShippingCity:       &req.ShippingCity,
+			ShippingProvince:   &req.ShippingProvince,
+			ShippingPostalCode: &req.ShippingPostalCode,
+			PaymentStatus:      entities.PaymentStatusPending,
+		}
+		if shipment.Notes != "" {
+			order.Notes = &shipment.Notes
+		}
+
+		movements = append(movements, completeOrder(order, orderItems, pick)...)
+
+		checkout.Subtotal += order.Subtotal
+		checkout.ShippingCost += order.ShippingCost
+		checkout.Total += order.Total
+	}
+
+	cartItemIds := make([]string, len(cart))
+	for i, item := range cart {
+		cartItemIds[i] = item.ID
+	}
+
+	err = s.orderRepo.PlaceCheckout(ctx, checkout, movements, cartItemIds)
+	switch {
+	case errors.Is(err, repositories.ErrInsufficientStock):
+		return response.WithCode(409).WithError(errors.New("insufficient stock, the items ran out while ordering"))
+	case err != nil:
+		log.Error(fmt.Sprintf("error placing checkout: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to checkout"))
+	}
+
+	return response.WithCode(201).WithData(dto.ToCheckoutResponse(checkout))
+}
+
+// GetCartFulfillment tells, for each merchant in the cart of the customer,
+// which outlet would ship to the destination and the weight of the items,
+// so the shipping of each order can be quoted before the checkout.
+func (s *orderService) GetCartFulfillment(ctx context.Context, customerID string, req *dto.CartFulfillmentRequest) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_get_cart_fulfillment", s.cfg.Logger.Enable)
+	)
+
+	cart, err := s.cartRepo.FindByCustomerID(ctx, customerID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to get fulfillment"))
+	}
+	if len(cart) == 0 {
+		return response.WithCode(400).WithError(errors.New("cart is empty"))
+	}
+
+	parts, resp := s.splitCart(ctx, cart)
+	if resp != nil {
+		return resp
+	}
+
+	destination := fulfillment.Destination{
+		CityID:    req.ShippingCityID,
+		City:      req.ShippingCity,
+		Province:  req.ShippingProvince,
+		Latitude:  req.ShippingLatitude,
+		Longitude: req.ShippingLongitude,
+	}
+
+	data := make([]dto.CartFulfillmentResponse, len(parts))
+	for i, part := range parts {
+		orderItems, _, weight, resp := s.buildItems(ctx, part.items)
+		if resp != nil {
+			return resp
+		}
+
+		pick, resp := s.pickOutlet(ctx, part.merchantID, orderItems, destination)
+		if resp != nil {
+			return resp
+		}
+
+		originCity, originCityID, err := s.shippingOrigin(ctx, part.merchantID, pick)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching shipping origin: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to get fulfillment"))
+		}
+
+		data[i] = dto.CartFulfillmentResponse{MerchantID: part.merchantID}
+		data[i].OriginCity = originCity
+		data[i].OriginCityID = originCityID
+		data[i].Weight = weight
+
+		merchant, err := s.merchantRepo.FindOneById(ctx, part.merchantID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching merchant: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to get fulfillment"))
+		}
+		if merchant != nil {
+			data[i].MerchantName = merchant.Name
+		}
+		if pick != nil {
+			data[i].OutletID = &pick.outlet.ID
+			data[i].OutletName = &pick.outlet.Name
+		}
+	}
+
+	return response.WithCode(200).WithData(data)
+}
+
+// splitCart groups the items of the cart by merchant, in the order the
+// merchants were first added to the cart.
+func (s *orderService) splitCart(ctx context.Context, cart []entities.CartItem) ([]cartPart, *presenter.Response) {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_split_cart", s.cfg.Logger.Enable)
+	)
+
+	productIds := make([]string, len(cart))
+	for i, item := range cart {
+		productIds[i] = item.ProductID
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching cart products: %v", err))
+		return nil, response.WithCode(500).WithError(errors.New("failed to checkout"))
+	}
+
+	productsByID := map[string]*entities.Product{}
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	parts := []cartPart{}
+	byMerchant := map[string]int{}
+	for _, item := range cart {
+		product, ok := productsByID[item.ProductID]
+		if !ok {
+			return nil, response.WithCode(404).WithError(errors.New("product not found: " + item.ProductID))
+		}
+
+		part, ok := byMerchant[product.MerchantID]
+		if !ok {
+			part = len(parts)
+			byMerchant[product.MerchantID] = part
+			parts = append(parts, cartPart{merchantID: product.MerchantID})
+		}
+
+		request := dto.OrderItemRequest{ProductID: item.ProductID, Quantity: item.Quantity}
+		if item.VariantID != nil {
+			request.VariantID = *item.VariantID
+		}
+
+		parts[part].items = append(parts[part].items, request)
+	}
+
+	return parts, nil
+}
+
+// buildItems validates the requested items against the catalog and prices
+// them, with the weight in grams they ship at. All items must come from the
+// same merchant.
+func (s *orderService) buildItems(ctx context.Context, items []dto.OrderItemRequest) ([]entities.OrderItem, string, int, *presenter.Response) {
 	response := presenter.NewResponse()
 
-	order, err := s.orderRepo.FindByID(ctx, id)
+	var (
+		merchantID string
+		weight     int
+	)
+	orderItems := make([]entities.OrderItem, 0, len(items))
+
+	for _, item := range items {
+		product, err := s.productRepo.FindByID(ctx, item.ProductID)
+		if err != nil || product == nil {
+			return nil, "", 0, response.WithCode(404).WithError(errors.New("product not found: " + item.ProductID))
+		}
+		if product.Status != entities.ProductStatusActive {
+			return nil, "", 0, response.WithCode(400).WithError(errors.New("product is not available: " + product.Name))
+		}
+
+		price := product.Price
+		stock := product.Stock
+		itemWeight := product.Weight
+		var variantName *string
+
+		if item.VariantID != "" {
+			variant, err := s.variantRepo.FindOneById(ctx, item.VariantID)
+			if err != nil || variant == nil || variant.ProductID != product.ID {
+				return nil, "", 0, response.WithCode(404).WithError(errors.New("variant not found: " + item.VariantID))
+			}
+			price = variant.PriceOf(product)
+			stock = variant.Stock
+			itemWeight = variant.WeightOf(product)
+			variantName = &variant.Name
+		} else {
+			count, err := s.variantRepo.CountByProductID(ctx, product.ID)
+			if err != nil {
+				return nil, "", 0, response.WithCode(500).WithError(errors.New("failed to create order"))
+			}
+			if count > 0 {
+				return nil, "", 0, response.WithCode(400).WithError(errors.New("choose a variant for: " + product.Name))
+			}
+		}
+
+		if stock < item.Quantity {
+			if variantName != nil {
+				return nil, "", 0, response.WithCode(400).WithError(errors.New("insufficient stock for: " + product.Name + " (" + *variantName + ")"))
+			}
+			return nil, "", 0, response.WithCode(400).WithError(errors.New("insufficient stock for: " + product.Name))
+		}
+
+		// All products must be from the same merchant
+		if merchantID == "" {
+			merchantID = product.MerchantID
+		} else if merchantID != product.MerchantID {
+			return nil, "", 0, response.WithCode(400).WithError(errors.New("all products must be from the same merchant"))
+		}
+
+		// The item is charged the discounted price, the base price is kept
+		// alongside it
+		running, err := s.discountRepo.FindRunningByProductIDs(ctx, []string{product.ID})
+		if err != nil {
+			return nil, "", 0, response.WithCode(500).WithError(errors.New("failed to create order"))
+		}
+		discounts := make([]entities.Discount, len(running))
+		for i := range running {
+			discounts[i] = running[i].Discount
+		}
+
+		var (
+			originalPrice *float64
+			discountID    *string
+		)
+		if discount := pricing.Best(price, discounts); discount != nil {
+			basePrice := price
+			originalPrice = &basePrice
+			discountID = &discount.ID
+			price = discount.Apply(price)
+		}
+
+		itemSubtotal := price * float64(item.Quantity)
+		weight += itemWeight * item.Quantity
+
+		orderItems = append(orderItems, entities.OrderItem{
+			ID:            uuid.New().String(),
+			ProductID:     product.ID,
+			VariantID:     stringPtr(item.VariantID),
+			VariantName:   variantName,
+			ProductName:   product.Name,
+			ProductPrice:  price,
+			OriginalPrice: originalPrice,
+			DiscountID:    discountID,
+			Quantity:      item.Quantity,
+			Subtotal:      itemSubtotal,
+		})
+	}
+
+	return orderItems, merchantID, weight, nil
+}
+
+// shippingOrigin returns the city an order ships from, and its shipping
+// provider id: the city of the outlet it ships from, or the city of the
+// merchant when there is no outlet or the outlet has no city id. Both are
+// nil when neither is set.
+func (s *orderService) shippingOrigin(ctx context.Context, merchantID string, pick *outletPick) (*string, *string, error) {
+	if pick != nil && pick.outlet.CityID != nil {
+		return pick.outlet.City, pick.outlet.CityID, nil
+	}
+
+	merchant, err := s.merchantRepo.FindOneById(ctx, merchantID)
+	if err != nil || merchant == nil {
+		return nil, nil, err
+	}
+
+	return merchant.CityName, merchant.CityID, nil
+}
+
+// quoteShipping prices the chosen courier service for the weight, in grams,
+// from the origin to the destination city, the same way GET /shipping/cost
+// quotes it. Orders are charged this quote, never a cost sent by the client.
+func (s *orderService) quoteShipping(originCityID *string, destinationCityID string, weight int, courier, service string) (rajaongkir.Cost, *presenter.Response) {
+	response := presenter.NewResponse()
+
+	origin := ""
+	if originCityID != nil {
+		origin = *originCityID
+	}
+
+	quote := s.quoter.Quote(origin, destinationCityID, weight, strings.ToLower(strings.TrimSpace(courier)))
+	cost, ok := shipping.Find(quote, courier, service)
+	if !ok {
+		return cost, response.WithCode(400).WithError(fmt.Errorf("courier service is not available for this shipment: %s %s", courier, service))
+	}
+
+	return cost, nil
+}
+
+// pickOutlet chooses the outlet nearest to the destination among the active
+// outlets that hold enough stock of every item stocked per outlet. It
+// returns nil when the merchant has no active outlets.
+func (s *orderService) pickOutlet(ctx context.Context, merchantID string, items []entities.OrderItem, destination fulfillment.Destination) (*outletPick, *presenter.Response) {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_pick_outlet", s.cfg.Logger.Enable)
+	)
+
+	outlets, err := s.outletRepo.FindActiveByMerchantID(ctx, merchantID)
 	if err != nil {
+		log.Error(fmt.Sprintf("error fetching outlets: %v", err))
+		return nil, response.WithCode(500).WithError(errors.New("failed to create order"))
+	}
+	if len(outlets) == 0 {
+		return nil, nil
+	}
+
+	productIds := []string{}
+	needed := map[string]int{}
+	for _, item := range items {
+		productIds = append(productIds, item.ProductID)
+		needed[stockKey(item.ProductID, item.VariantID)] += item.Quantity
+	}
+
+	stocks, err := s.outletRepo.FindStocksByProductIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching outlet stock: %v", err))
+		return nil, response.WithCode(500).WithError(errors.New("failed to create order"))
+	}
+
+	// Items without outlet stock rows are not stocked per outlet and can be
+	// shipped from any outlet
+	managed := map[string]bool{}
+	held := map[string]map[string]int{}
+	for _, stock := range stocks {
+		key := stockKey(stock.ProductID, stock.VariantID)
+		managed[key] = true
+		if held[stock.OutletID] == nil {
+			held[stock.OutletID] = map[string]int{}
+		}
+		held[stock.OutletID][key] = stock.Stock
+	}
+
+	eligible := []entities.Outlet{}
+	for _, outlet := range outlets {
+		enough := true
+		for key, quantity := range needed {
+			if managed[key] && held[outlet.ID][key] < quantity {
+				enough = false
+				break
+			}
+		}
+		if enough {
+			eligible = append(eligible, outlet)
+		}
+	}
+
+	if len(eligible) == 0 {
+		return nil, response.WithCode(400).WithError(errors.New("no single outlet has stock for all items, try ordering them separately"))
+	}
+
+	outlet := fulfillment.Nearest(eligible, destination)
+
+	return &outletPick{outlet: outlet, perOutlet: managed}, nil
+}
+
+func stockKey(productID string, variantID *string) string {
+	if variantID == nil {
+		return productID
+	}
+	return productID + "/" + *variantID
+}
+
+func (s *orderService) GetCustomerOrder(ctx context.Context, customerID, id string) *presenter.Response {
+	response := presenter.NewResponse()
+
+	order, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil || order.CustomerID != customerID {
 		return response.WithCode(404).WithError(errors.New("order not found"))
 	}
 
@@ -134,6 +688,42 @@ func (s *orderService) GetOrderByID(ctx context.Context, id string) *presenter.R
 	return response.WithCode(200).WithData(dto.ToOrderResponse(order))
 }
 
+func (s *orderService) GetMerchantOrder(ctx context.Context, merchantID, id string) *presenter.Response {
+	response := presenter.NewResponse()
+
+	order, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil || order.MerchantID != merchantID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+
+	items, _ := s.orderRepo.GetOrderItems(ctx, id)
+	order.Items = items
+
+	return response.WithCode(200).WithData(dto.ToOrderResponse(order))
+}
+
+// GetOrderTimeline lists the status changes of an order of the customer,
+// oldest first.
+func (s *orderService) GetOrderTimeline(ctx context.Context, customerID, id string) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_get_order_timeline", s.cfg.Logger.Enable)
+	)
+
+	order, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil || order.CustomerID != customerID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+
+	history, err := s.orderRepo.FindStatusHistory(ctx, order.ID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching status history of order %s: %v", order.ID, err))
+		return response.WithCode(500).WithError(errors.New("failed to get order timeline"))
+	}
+
+	return response.WithCode(200).WithData(dto.ToOrderTimelineResponse(order, history))
+}
+
 func (s *orderService) GetCustomerOrders(ctx context.Context, customerID string, page, limit int) *presenter.Response {
 	response := presenter.NewResponse()
 
@@ -184,24 +774,149 @@ func (s *orderService) GetMerchantOrders(ctx context.Context, merchantID string,
 	return response.WithCode(200).WithData(dto.ToOrderListResponse(orders, total, page, limit))
 }
 
-func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID, status string) *presenter.Response {
+func (s *orderService) UpdateOrderStatus(ctx context.Context, merchantID, orderID, status string) *presenter.Response {
+	response := presenter.NewResponse()
+
+	if !orderstatus.Valid(status) {
+		return response.WithCode(400).WithError(errors.New("invalid status: " + status))
+	}
+	if status == entities.OrderStatusCancelled {
+		return response.WithCode(400).WithError(errors.New("cancel the order with a reason through its cancel endpoint"))
+	}
+
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil || order.MerchantID != merchantID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+
+	return s.moveOrder(ctx, order, status, nil, merchantID)
+}
+
+// UpdateTrackingNumber ships a processing order with the tracking number, or
+// corrects the tracking number of a shipped one.
+func (s *orderService) UpdateTrackingNumber(ctx context.Context, merchantID, orderID, trackingNumber string) *presenter.Response {
 	response := presenter.NewResponse()
 
-	err := s.orderRepo.UpdateStatus(ctx, orderID, status)
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil || order.MerchantID != merchantID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+
+	if order.Status == entities.OrderStatusProcessing {
+		return s.moveOrder(ctx, order, entities.OrderStatusShipped, &trackingNumber, merchantID)
+	}
+	if order.Status != entities.OrderStatusShipped {
+		return response.WithCode(400).WithError(errors.New("tracking number can only be set on processing or shipped orders"))
+	}
+
+	err = s.orderRepo.UpdateTrackingNumber(ctx, orderID, trackingNumber)
 	if err != nil {
+		return response.WithCode(500).WithError(errors.New("failed to update tracking number"))
+	}
+
+	return response.WithCode(200).WithData(map[string]string{"message": "Tracking number updated"})
+}
+
+// moveOrder changes the status of the order for the merchant, when its
+// current status allows it, and records the change.
+func (s *orderService) moveOrder(ctx context.Context, order *entities.Order, status string, trackingNumber *string, merchantID string) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_move_order", s.cfg.Logger.Enable)
+	)
+
+	if !orderstatus.CanMove(order.Status, status) {
+		return response.WithCode(400).WithError(fmt.Errorf("order cannot go from %s to %s", order.Status, status))
+	}
+
+	from := order.Status
+	err := s.orderRepo.Transition(ctx, &entities.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: &from,
+		ToStatus:   status,
+		ActorType:  entities.OrderActorMerchant,
+		ActorID:    &merchantID,
+	}, trackingNumber)
+	switch {
+	case errors.Is(err, repositories.ErrOrderStatusChanged):
+		return response.WithCode(409).WithError(errors.New("order status has changed, reload the order and try again"))
+	case err != nil:
+		log.Error(fmt.Sprintf("error updating status of order %s: %v", order.ID, err))
 		return response.WithCode(500).WithError(errors.New("failed to update order status"))
 	}
 
 	return response.WithCode(200).WithData(map[string]string{"message": "Order status updated"})
 }
 
-func (s *orderService) UpdateTrackingNumber(ctx context.Context, orderID, trackingNumber string) *presenter.Response {
+// CancelOrder cancels an order of the customer that has not been paid yet.
+func (s *orderService) CancelOrder(ctx context.Context, customerID, orderID string, req *dto.CancelOrderRequest) *presenter.Response {
+	response := presenter.NewResponse()
+
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil || order.CustomerID != customerID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+	if order.Status != entities.OrderStatusPending {
+		return response.WithCode(400).WithError(errors.New("only orders waiting for payment can be cancelled, contact the merchant"))
+	}
+
+	return s.cancelOrder(ctx, order, entities.OrderActorCustomer, customerID, strings.TrimSpace(req.Reason))
+}
+
+// CancelMerchantOrder cancels an order of the merchant that has not been
+// shipped yet. The reason is shown to the customer. The merchant user who
+// cancels is recorded as the actor of the change and of the returned stock.
+func (s *orderService) CancelMerchantOrder(ctx context.Context, merchantID, userID, orderID string, req *dto.CancelOrderRequest) *presenter.Response {
 	response := presenter.NewResponse()
 
-	err := s.orderRepo.UpdateTrackingNumber(ctx, orderID, trackingNumber)
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return response.WithCode(400).WithError(errors.New("reason is required"))
+	}
+
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil || order.MerchantID != merchantID {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+
+	return s.cancelOrder(ctx, order, entities.OrderActorMerchant, userID, reason)
+}
+
+// cancelOrder cancels the order when its current status allows it, which
+// returns its stock and notifies both parties, and responds with the
+// cancelled order.
+func (s *orderService) cancelOrder(ctx context.Context, order *entities.Order, actorType, actorID, reason string) *presenter.Response {
+	var (
+		response = presenter.NewResponse()
+		log      = logger.NewLog("order_service_cancel_order", s.cfg.Logger.Enable)
+	)
+
+	if !orderstatus.CanMove(order.Status, entities.OrderStatusCancelled) {
+		return response.WithCode(400).WithError(fmt.Errorf("a %s order cannot be cancelled", order.Status))
+	}
+
+	from := order.Status
+	err := s.orderRepo.Cancel(ctx, &entities.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: &from,
+		ToStatus:   entities.OrderStatusCancelled,
+		ActorType:  actorType,
+		ActorID:    stringPtr(actorID),
+	}, stringPtr(reason))
+	switch {
+	case errors.Is(err, repositories.ErrOrderStatusChanged):
+		return response.WithCode(409).WithError(errors.New("order status has changed, reload the order and try again"))
+	case err != nil:
+		log.Error(fmt.Sprintf("error cancelling order %s: %v", order.ID, err))
+		return response.WithCode(500).WithError(errors.New("failed to cancel order"))
+	}
+
+	cancelled, err := s.orderRepo.FindByID(ctx, order.ID)
 	if err != nil {
-		return response.WithCode(500).WithError(errors.New("failed to update tracking number"))
+		log.Error(fmt.Sprintf("error fetching cancelled order %s: %v", order.ID, err))
+		return response.WithCode(500).WithError(errors.New("failed to get order"))
 	}
+	cancelled.Items, _ = s.orderRepo.GetOrderItems(ctx, order.ID)
 
-	return response.WithCode(200).WithData(map[string]string{"message": "Tracking number updated"})
+	return response.WithCode(200).WithData(dto.ToOrderResponse(cancelled))
 }
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/entities"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderService_CancelMerchantOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		merchantID string
+		userID     string
+		reason     string
+		code       int
+		actor      *string
+	}{
+		{name: "Records the merchant user", merchantID: "merchant-1", userID: "user-1", reason: "Stok habis", code: 200, actor: stringPtr("user-1")},
+		{name: "Without a user", merchantID: "merchant-1", reason: "Stok habis", code: 200},
+		{name: "An order of another merchant", merchantID: "merchant-2", userID: "user-2", reason: "Stok habis", code: 404},
+		{name: "Without a reason", merchantID: "merchant-1", userID: "user-1", code: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeOrderRepo{orders: map[string]*entities.Order{
+				"order-1": {ID: "order-1", CustomerID: "customer-1", MerchantID: "merchant-1", Status: entities.OrderStatusPaid},
+			}}
+			service := &orderService{cfg: &yaml.Config{}, orderRepo: repo}
+
+			response := service.CancelMerchantOrder(context.Background(), tt.merchantID, tt.userID, "order-1", &dto.CancelOrderRequest{Reason: tt.reason})
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.Nil(t, repo.cancelled)
+				return
+			}
+			require.NotNil(t, repo.cancelled)
+			assert.Equal(t, entities.OrderActorMerchant, repo.cancelled.ActorType)
+			assert.Equal(t, tt.actor, repo.cancelled.ActorID)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type OutletService interface {
+	Create(ctx context.Context, merchantId string, req *dto.OutletRequest) *presenter.Response
+	GetAll(ctx context.Context, merchantId string) *presenter.Response
+	GetById(ctx context.Context, merchantId, id string) *presenter.Response
+	Update(ctx context.Context, merchantId, id string, req *dto.OutletRequest) *presenter.Response
+	Delete(ctx context.Context, merchantId, id string) *presenter.Response
+	GetStocks(ctx context.Context, merchantId, id string) *presenter.Response
+	SetStocks(ctx context.Context, merchantId, userId, id string, req *dto.SetOutletStocksRequest) *presenter.Response
+}
+
+type outletService struct {
+	outletRepo  repositories.OutletRepository
+	productRepo repositories.ProductRepository
+	variantRepo repositories.ProductVariantRepository
+	cfg         *yaml.Config
+}
+
+func NewOutletService(
+	outletRepo repositories.OutletRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	cfg *yaml.Config,
+) OutletService {
+	return &outletService{
+		outletRepo:  outletRepo,
+		productRepo: productRepo,
+		variantRepo: variantRepo,
+		cfg:         cfg,
+	}
+}
+
+func (s *outletService) Create(ctx context.Context, merchantId string, req *dto.OutletRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_create", s.cfg.Logger.Enable)
+	)
+
+	outlet := &entities.Outlet{MerchantID: merchantId}
+	if err := applyOutletRequest(outlet, req); err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+
+	created, err := s.outletRepo.Create(ctx, outlet)
+	if err != nil {
+		log.Error(fmt.Sprintf("error creating outlet: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create outlet"))
+	}
+
+	data := dto.ToOutletResponse(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *outletService) GetAll(ctx context.Context, merchantId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_getall", s.cfg.Logger.Enable)
+	)
+
+	outlets, err := s.outletRepo.FindByMerchantID(ctx, merchantId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching outlets: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch outlets"))
+	}
+
+	data := dto.ToOutletListResponse(outlets)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *outletService) GetById(ctx context.Context, merchantId, id string) *presenter.Response {
+	response := presenter.Response{}
+
+	outlet, resp := s.findOwned(ctx, merchantId, id)
+	if resp != nil {
+		return resp
+	}
+
+	data := dto.ToOutletResponse(outlet)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *outletService) Update(ctx context.Context, merchantId, id string, req *dto.OutletRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_update", s.cfg.Logger.Enable)
+	)
+
+	outlet, resp := s.findOwned(ctx, merchantId, id)
+	if resp != nil {
+		return resp
+	}
+
+	if err := applyOutletRequest(outlet, req); err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+
+	updated, err := s.outletRepo.Update(ctx, outlet)
+	if err != nil {
+		log.Error(fmt.Sprintf("error updating outlet: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update outlet"))
+	}
+
+	data := dto.ToOutletResponse(updated)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *outletService) Delete(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_delete", s.cfg.Logger.Enable)
+	)
+
+	if _, resp := s.findOwned(ctx, merchantId, id); resp != nil {
+		return resp
+	}
+
+	// Deleting stock along with the outlet would change product totals
+	// behind the merchant's back
+	total, err := s.outletRepo.SumStock(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting outlet stock: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if total > 0 {
+		return response.WithCode(409).WithError(errors.New("outlet still holds stock, set it to 0 or deactivate the outlet"))
+	}
+
+	if err = s.outletRepo.Delete(ctx, id); err != nil {
+		log.Error(fmt.Sprintf("error deleting outlet: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete outlet"))
+	}
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+func (s *outletService) GetStocks(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_get_stocks", s.cfg.Logger.Enable)
+	)
+
+	if _, resp := s.findOwned(ctx, merchantId, id); resp != nil {
+		return resp
+	}
+
+	stocks, err := s.outletRepo.FindStocksByOutletID(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching outlet stock: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch outlet stock"))
+	}
+
+	data := dto.ToOutletStockResponses(stocks)
+	return response.WithCode(200).WithData(data)
+}
+
+// SetStocks sets the outlet stock of the listed products. A product with
+// variants is stocked per variant, a product without variants as a whole.
+func (s *outletService) SetStocks(ctx context.Context, merchantId, userId, id string, req *dto.SetOutletStocksRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_set_stocks", s.cfg.Logger.Enable)
+	)
+
+	if _, resp := s.findOwned(ctx, merchantId, id); resp != nil {
+		return resp
+	}
+
+	if len(req.Items) == 0 {
+		return response.WithCode(400).WithError(errors.New("items are required"))
+	}
+
+	productIds := []string{}
+	for _, item := range req.Items {
+		productIds = append(productIds, item.ProductID)
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching products: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	variants, err := s.variantRepo.FindByProductIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	owned := map[string]bool{}
+	for _, product := range products {
+		owned[product.ID] = product.MerchantID == merchantId
+	}
+	variantProduct := map[string]string{}
+	hasVariants := map[string]bool{}
+	for _, variant := range variants {
+		variantProduct[variant.ID] = variant.ProductID
+		hasVariants[variant.ProductID] = true
+	}
+
+	stocks := make([]entities.OutletStock, 0, len(req.Items))
+	seen := map[string]bool{}
+	for _, item := range req.Items {
+		if !owned[item.ProductID] {
+			return response.WithCode(400).WithError(errors.New("product not found: " + item.ProductID))
+		}
+
+		switch {
+		case hasVariants[item.ProductID] && item.VariantID == "":
+			return response.WithCode(400).WithError(errors.New("variant_id is required for product: " + item.ProductID))
+		case item.VariantID != "" && variantProduct[item.VariantID] != item.ProductID:
+			return response.WithCode(400).WithError(errors.New("variant not found: " + item.VariantID))
+		case item.Stock < 0:
+			return response.WithCode(400).WithError(errors.New("stock cannot be negative"))
+		}
+
+		key := item.ProductID + "/" + item.VariantID
+		if seen[key] {
+			return response.WithCode(400).WithError(errors.New("product is listed twice: " + item.ProductID))
+		}
+		seen[key] = true
+
+		stocks = append(stocks, entities.OutletStock{
+			ProductID: item.ProductID,
+			VariantID: stringPtr(item.VariantID),
+			Stock:     item.Stock,
+		})
+	}
+
+	if err = s.outletRepo.SetStocks(ctx, id, stocks, stringPtr(userId)); err != nil {
+		log.Error(fmt.Sprintf("error saving outlet stock: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to save outlet stock"))
+	}
+
+	return s.GetStocks(ctx, merchantId, id)
+}
+
+func (s *outletService) findOwned(ctx context.Context, merchantId, id string) (*entities.Outlet, *presenter.Response) {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("outlet_service_find", s.cfg.Logger.Enable)
+	)
+
+	outlet, err := s.outletRepo.FindOneById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching outlet: %v", err))
+		return nil, response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if outlet == nil {
+		return nil, response.WithCode(404).WithError(errors.New("outlet not found"))
+	}
+	if outlet.MerchantID != merchantId {
+		return nil, response.WithCode(403).WithError(errors.New("outlet belongs to another merchant"))
+	}
+
+	return outlet, nil
+}
+
+// applyOutletRequest validates the request and copies it onto the outlet.
+func applyOutletRequest(outlet *entities.Outlet, req *dto.OutletRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status == "" {
+		status = entities.OutletStatusActive
+	}
+	if status != entities.OutletStatusActive && status != entities.OutletStatusInactive {
+		return errors.New("status must be active or inactive")
+	}
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return errors.New("latitude and longitude must be filled in together")
+	}
+	if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180) {
+		return errors.New("latitude or longitude is out of range")
+	}
+
+	outlet.Name = name
+	outlet.Address = stringPtr(strings.TrimSpace(req.Address))
+	outlet.City = stringPtr(strings.TrimSpace(req.City))
+	outlet.CityID = stringPtr(strings.TrimSpace(req.CityID))
+	outlet.Province = stringPtr(strings.TrimSpace(req.Province))
+	outlet.Latitude = req.Latitude
+	outlet.Longitude = req.Longitude
+	outlet.Phone = stringPtr(strings.TrimSpace(req.Phone))
+	outlet.Status = status
+
+	return nil
+}
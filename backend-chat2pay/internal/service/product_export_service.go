@@ -0,0 +1,78 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/pkg/catalogexport"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"fmt"
+	"io"
+)
+
+type ProductExportService interface {
+	Open(ctx context.Context, merchantId, format string) (*ProductExport, error)
+}
+
+type productExportService struct {
+	productRepo repositories.ProductRepository
+	cfg         *yaml.Config
+}
+
+func NewProductExportService(productRepo repositories.ProductRepository, cfg *yaml.Config) ProductExportService {
+	return &productExportService{
+		productRepo: productRepo,
+		cfg:         cfg,
+	}
+}
+
+// Open runs the catalog query of the merchant, so that a failing query is
+// reported before any of the export is written.
+func (s *productExportService) Open(ctx context.Context, merchantId, format string) (*ProductExport, error) {
+	log := logger.NewLog("product_export_service_open", s.cfg.Logger.Enable)
+
+	if format != catalogexport.FormatCSV && format != catalogexport.FormatJSON {
+		return nil, catalogexport.ErrUnsupportedFormat
+	}
+
+	rows, err := s.productRepo.QueryByMerchant(ctx, merchantId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error querying products: %v", err))
+		return nil, err
+	}
+
+	return &ProductExport{rows: rows, format: format}, nil
+}
+
+// ProductExport is an opened catalog export. Stream writes the catalog as
+// it is read from the database, so memory use does not grow with the
+// catalog.
+type ProductExport struct {
+	rows   *repositories.ProductRows
+	format string
+}
+
+// Stream writes the whole export to w and closes the rows.
+func (e *ProductExport) Stream(w io.Writer) error {
+	defer e.rows.Close()
+
+	writer, err := catalogexport.NewWriter(e.format, w)
+	if err != nil {
+		return err
+	}
+
+	for e.rows.Next() {
+		product, err := e.rows.Product()
+		if err != nil {
+			return err
+		}
+		if err = writer.Write(product); err != nil {
+			return err
+		}
+	}
+	if err = e.rows.Err(); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
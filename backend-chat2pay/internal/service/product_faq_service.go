@@ -0,0 +1,208 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/llm"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ProductFAQService interface {
+	GetAll(ctx context.Context, merchantId string, filter repositories.ProductFAQFilter, page, limit int) *presenter.Response
+	GetById(ctx context.Context, merchantId, id string) *presenter.Response
+	Create(ctx context.Context, merchantId string, req *dto.ProductFAQRequest) *presenter.Response
+	Update(ctx context.Context, merchantId, id string, req *dto.ProductFAQRequest) *presenter.Response
+	Delete(ctx context.Context, merchantId, id string) *presenter.Response
+}
+
+type productFAQService struct {
+	faqRepo     repositories.ProductFAQRepository
+	productRepo repositories.ProductRepository
+	llm         llm.LLM
+	cfg         *yaml.Config
+}
+
+func NewProductFAQService(
+	faqRepo repositories.ProductFAQRepository,
+	productRepo repositories.ProductRepository,
+	llm llm.LLM,
+	cfg *yaml.Config,
+) ProductFAQService {
+	return &productFAQService{
+		faqRepo:     faqRepo,
+		productRepo: productRepo,
+		llm:         llm,
+		cfg:         cfg,
+	}
+}
+
+func (s *productFAQService) GetAll(ctx context.Context, merchantId string, filter repositories.ProductFAQFilter, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_faq_service_get_all", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter.MerchantID = merchantId
+
+	faqs, err := s.faqRepo.Find(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching faqs: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch faqs"))
+	}
+
+	total, err := s.faqRepo.Count(ctx, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting faqs: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch faqs"))
+	}
+
+	data := dto.ToProductFAQListResponse(faqs, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productFAQService) GetById(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_faq_service_get_by_id", s.cfg.Logger.Enable)
+	)
+
+	faq, err := s.faqRepo.FindOneById(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if faq == nil {
+		return response.WithCode(404).WithError(errors.New("faq not found"))
+	}
+
+	data := dto.ToProductFAQResponse(faq)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productFAQService) Create(ctx context.Context, merchantId string, req *dto.ProductFAQRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_faq_service_create", s.cfg.Logger.Enable)
+	)
+
+	faq := req.ToEntity(merchantId)
+	if errResponse := s.checkFAQ(ctx, log, &faq); errResponse != nil {
+		return errResponse
+	}
+
+	embedding, err := s.llm.EmbedQuery(ctx, faq.Question)
+	if err != nil {
+		log.Error(fmt.Sprintf("error embedding faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create faq"))
+	}
+
+	created, err := s.faqRepo.Create(ctx, &faq, embedding)
+	if err != nil {
+		log.Error(fmt.Sprintf("error creating faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create faq"))
+	}
+
+	data := dto.ToProductFAQResponse(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *productFAQService) Update(ctx context.Context, merchantId, id string, req *dto.ProductFAQRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_faq_service_update", s.cfg.Logger.Enable)
+	)
+
+	existing, err := s.faqRepo.FindOneById(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if existing == nil {
+		return response.WithCode(404).WithError(errors.New("faq not found"))
+	}
+
+	faq := req.ToEntity(merchantId)
+	faq.ID = existing.ID
+	faq.CreatedAt = existing.CreatedAt
+	if errResponse := s.checkFAQ(ctx, log, &faq); errResponse != nil {
+		return errResponse
+	}
+
+	embedding, err := s.llm.EmbedQuery(ctx, faq.Question)
+	if err != nil {
+		log.Error(fmt.Sprintf("error embedding faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update faq"))
+	}
+
+	updated, err := s.faqRepo.Update(ctx, &faq, embedding)
+	if err != nil {
+		log.Error(fmt.Sprintf("error updating faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update faq"))
+	}
+
+	data := dto.ToProductFAQResponse(updated)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productFAQService) Delete(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_faq_service_delete", s.cfg.Logger.Enable)
+	)
+
+	found, err := s.faqRepo.Delete(ctx, merchantId, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error deleting faq: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete faq"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("faq not found"))
+	}
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+// checkFAQ validates the question and answer and that the product, when
+// set, belongs to the merchant.
+func (s *productFAQService) checkFAQ(ctx context.Context, log logger.LoggerLevel, faq *entities.ProductFAQ) *presenter.Response {
+	response := presenter.Response{}
+
+	faq.Question = strings.TrimSpace(faq.Question)
+	faq.Answer = strings.TrimSpace(faq.Answer)
+	if faq.Question == "" {
+		return response.WithCode(400).WithError(errors.New("question is required"))
+	}
+	if faq.Answer == "" {
+		return response.WithCode(400).WithError(errors.New("answer is required"))
+	}
+
+	if faq.ProductID == nil || *faq.ProductID == "" {
+		faq.ProductID = nil
+		return nil
+	}
+
+	product, err := s.productRepo.FindOneById(ctx, *faq.ProductID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != faq.MerchantID || product.Status == entities.ProductStatusArchived {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	return nil
+}
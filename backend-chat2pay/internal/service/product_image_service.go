@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/imageproc"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/storage"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultImageMaxSizeKB     = 5120
+	defaultImageMaxPerProduct = 10
+	defaultThumbnailSize      = 320
+)
+
+type ProductImageService interface {
+	Upload(ctx context.Context, merchantId, productId string, files []*multipart.FileHeader) *presenter.Response
+	GetAll(ctx context.Context, productId string) *presenter.Response
+	SetPrimary(ctx context.Context, merchantId, productId, imageId string) *presenter.Response
+	Reorder(ctx context.Context, merchantId, productId string, req *dto.ReorderImagesRequest) *presenter.Response
+	Delete(ctx context.Context, merchantId, productId, imageId string) *presenter.Response
+}
+
+type productImageService struct {
+	imageRepo     repositories.ProductImageRepository
+	productRepo   repositories.ProductRepository
+	storage       storage.Storage
+	cfg           *yaml.Config
+	maxSize       int64
+	maxPerProduct int
+	thumbnailSize int
+}
+
+func NewProductImageService(
+	imageRepo repositories.ProductImageRepository,
+	productRepo repositories.ProductRepository,
+	storage storage.Storage,
+	cfg *yaml.Config,
+) ProductImageService {
+	s := &productImageService{
+		imageRepo:     imageRepo,
+		productRepo:   productRepo,
+		storage:       storage,
+		cfg:           cfg,
+		maxSize:       int64(cfg.Storage.Image.MaxSizeKB) * 1024,
+		maxPerProduct: cfg.Storage.Image.MaxPerProduct,
+		thumbnailSize: cfg.Storage.Image.ThumbnailSize,
+	}
+
+	if s.maxSize <= 0 {
+		s.maxSize = defaultImageMaxSizeKB * 1024
+	}
+	if s.maxPerProduct <= 0 {
+		s.maxPerProduct = defaultImageMaxPerProduct
+	}
+	if s.thumbnailSize <= 0 {
+		s.thumbnailSize = defaultThumbnailSize
+	}
+
+	return s
+}
+
+// upload is a validated file waiting to be stored.
+type upload struct {
+	data        []byte
+	contentType string
+	ext         string
+	thumbnail   []byte
+}
+
+func (s *productImageService) Upload(ctx context.Context, merchantId, productId string, files []*multipart.FileHeader) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_image_service_upload", s.cfg.Logger.Enable)
+	)
+
+	if resp := s.checkOwner(ctx, merchantId, productId); resp != nil {
+		return resp
+	}
+
+	if len(files) == 0 {
+		return response.WithCode(400).WithError(errors.New("no image uploaded"))
+	}
+
+	count, err := s.imageRepo.CountByProductID(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting images: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if count+len(files) > s.maxPerProduct {
+		return response.WithCode(400).WithError(fmt.Errorf("a product can have at most %d images", s.maxPerProduct))
+	}
+
+	// Validate every file before storing any of them
+	uploads := make([]upload, 0, len(files))
+	for _, file := range files {
+		u, err := s.prepare(file)
+		if err != nil {
+			return response.WithCode(400).WithError(fmt.Errorf("%s: %v", file.Filename, err))
+		}
+		uploads = append(uploads, u)
+	}
+
+	created := []entities.ProductImage{}
+	for _, u := range uploads {
+		image, err := s.store(ctx, productId, u)
+		if err != nil {
+			log.Error(fmt.Sprintf("error storing image: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to upload image"))
+		}
+		created = append(created, *image)
+	}
+
+	data := dto.ToProductImageResponses(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *productImageService) prepare(file *multipart.FileHeader) (upload, error) {
+	return prepareImage(file, s.maxSize, s.thumbnailSize)
+}
+
+// prepareImage reads an uploaded image, checks its size and type and makes
+// its thumbnail.
+func prepareImage(file *multipart.FileHeader, maxSize int64, thumbnailSize int) (upload, error) {
+	if file.Size > maxSize {
+		return upload{}, fmt.Errorf("image is larger than %d KB", maxSize/1024)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return upload{}, err
+	}
+	defer f.Close()
+
+	// The header size comes from the client, so limit the read as well
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return upload{}, err
+	}
+	if int64(len(data)) > maxSize {
+		return upload{}, fmt.Errorf("image is larger than %d KB", maxSize/1024)
+	}
+
+	contentType, ext, err := imageproc.Detect(data)
+	if err != nil {
+		return upload{}, err
+	}
+
+	thumbnail, err := imageproc.Thumbnail(data, thumbnailSize)
+	if err != nil {
+		return upload{}, errors.New("image could not be decoded")
+	}
+
+	return upload{data: data, contentType: contentType, ext: ext, thumbnail: thumbnail}, nil
+}
+
+func (s *productImageService) store(ctx context.Context, productId string, u upload) (*entities.ProductImage, error) {
+	name := uuid.New().String()
+	image := &entities.ProductImage{
+		ProductID:    productId,
+		StorageKey:   fmt.Sprintf("products/%s/%s%s", productId, name, u.ext),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.jpg", productId, name),
+	}
+
+	var err error
+	image.ImageURL, err = s.storage.Put(ctx, image.StorageKey, bytes.NewReader(u.data), int64(len(u.data)), u.contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ThumbnailURL, err = s.storage.Put(ctx, image.ThumbnailKey, bytes.NewReader(u.thumbnail), int64(len(u.thumbnail)), "image/jpeg")
+	if err != nil {
+		s.removeFiles(ctx, image)
+		return nil, err
+	}
+
+	if _, err = s.imageRepo.Create(ctx, image); err != nil {
+		s.removeFiles(ctx, image)
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (s *productImageService) GetAll(ctx context.Context, productId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_image_service_getall", s.cfg.Logger.Enable)
+	)
+
+	product, err := s.productRepo.FindActiveById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	images, err := s.imageRepo.FindByProductID(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching images: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch images"))
+	}
+
+	data := dto.ToProductImageResponses(images)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productImageService) SetPrimary(ctx context.Context, merchantId, productId, imageId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_image_service_set_primary", s.cfg.Logger.Enable)
+	)
+
+	if resp := s.checkOwner(ctx, merchantId, productId); resp != nil {
+		return resp
+	}
+
+	image, err := s.imageRepo.FindOneById(ctx, productId, imageId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching image: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if image == nil {
+		return response.WithCode(404).WithError(errors.New("image not found"))
+	}
+
+	if err = s.imageRepo.SetPrimary(ctx, productId, imageId); err != nil {
+		log.Error(fmt.Sprintf("error setting primary image: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to set primary image"))
+	}
+
+	return s.GetAll(ctx, productId)
+}
+
+func (s *productImageService) Reorder(ctx context.Context, merchantId, productId string, req *dto.ReorderImagesRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_image_service_reorder", s.cfg.Logger.Enable)
+	)
+
+	if resp := s.checkOwner(ctx, merchantId, productId); resp != nil {
+		return resp
+	}
+
+	images, err := s.imageRepo.FindByProductID(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching images: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	// The new order must list every image of the product exactly once
+	remaining := map[string]bool{}
+	for _, image := range images {
+		remaining[image.ID] = true
+	}
+	for _, id := range req.ImageIDs {
+		if !remaining[id] {
+			return response.WithCode(400).WithError(errors.New("image_ids must list each image of the product once"))
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		return response.WithCode(400).WithError(errors.New("image_ids must list each image of the product once"))
+	}
+
+	if err = s.imageRepo.Reorder(ctx, productId, req.ImageIDs); err != nil {
+		log.Error(fmt.Sprintf("error reordering images: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to reorder images"))
+	}
+
+	return s.GetAll(ctx, productId)
+}
+
+func (s *productImageService) Delete(ctx context.Context, merchantId, productId, imageId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_image_service_delete", s.cfg.Logger.Enable)
+	)
+
+	if resp := s.checkOwner(ctx, merchantId, productId); resp != nil {
+		return resp
+	}
+
+	image, err := s.imageRepo.FindOneById(ctx, productId, imageId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching image: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if image == nil {
+		return response.WithCode(404).WithError(errors.New("image not found"))
+	}
+
+	if err = s.imageRepo.Delete(ctx, productId, imageId); err != nil {
+		log.Error(fmt.Sprintf("error deleting image: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete image"))
+	}
+
+	s.removeFiles(ctx, image)
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+// checkOwner returns an error response unless the product exists and belongs
+// to the merchant.
+func (s *productImageService) checkOwner(ctx context.Context, merchantId, productId string) *presenter.Response {
+	response := presenter.Response{}
+	log := logger.NewLog("product_image_service_check_owner", s.cfg.Logger.Enable)
+
+	product, err := s.productRepo.FindOneById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != merchantId {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	return nil
+}
+
+// removeFiles deletes stored files, images hosted elsewhere have no keys.
+// Failures only leave orphaned files behind, so they are logged.
+func (s *productImageService) removeFiles(ctx context.Context, image *entities.ProductImage) {
+	log := logger.NewLog("product_image_service_remove_files", s.cfg.Logger.Enable)
+
+	for _, key := range []string{image.StorageKey, image.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Error(fmt.Sprintf("error deleting file %s: %v", key, err))
+		}
+	}
+}
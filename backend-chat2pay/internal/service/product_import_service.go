@@ -0,0 +1,188 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/catalogimport"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// importMaxSize is the largest import file accepted.
+	importMaxSize = 10 * 1024 * 1024
+	// importRowLimit is the largest number of products in one import.
+	importRowLimit = 5000
+)
+
+type ProductImportService interface {
+	Import(ctx context.Context, merchantId, userId string, file *multipart.FileHeader, dryRun bool) *presenter.Response
+}
+
+type productImportService struct {
+	productRepo    repositories.ProductRepository
+	categoryRepo   repositories.CategoryRepository
+	embeddingQueue EmbeddingQueue
+	cfg            *yaml.Config
+}
+
+func NewProductImportService(
+	productRepo repositories.ProductRepository,
+	categoryRepo repositories.CategoryRepository,
+	embeddingQueue EmbeddingQueue,
+	cfg *yaml.Config,
+) ProductImportService {
+	return &productImportService{
+		productRepo:    productRepo,
+		categoryRepo:   categoryRepo,
+		embeddingQueue: embeddingQueue,
+		cfg:            cfg,
+	}
+}
+
+// Import validates every row of a CSV or XLSX file and reports on each of
+// them. Unless it is a dry run, the products are then created or updated by
+// SKU in one transaction, and nothing is written when any row is invalid.
+// Embeddings are built in the background afterwards.
+func (s *productImportService) Import(ctx context.Context, merchantId, userId string, file *multipart.FileHeader, dryRun bool) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_import_service_import", s.cfg.Logger.Enable)
+	)
+
+	if file.Size > importMaxSize {
+		return response.WithCode(400).WithError(fmt.Errorf("file is larger than %d MB", importMaxSize/1024/1024))
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+	defer f.Close()
+
+	sheet, err := catalogimport.Parse(file.Filename, f)
+	if err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+	if len(sheet.Rows) > importRowLimit {
+		return response.WithCode(400).WithError(fmt.Errorf("a file can have at most %d products", importRowLimit))
+	}
+
+	if err = s.checkCategories(ctx, sheet.Rows); err != nil {
+		log.Error(fmt.Sprintf("error fetching categories: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	skus := make([]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		if row.SKU != "" {
+			skus = append(skus, row.SKU)
+		}
+	}
+
+	existing, err := s.productRepo.FindIDsBySKU(ctx, merchantId, skus)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching products by sku: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	report := dto.ImportReport{DryRun: dryRun, Total: len(sheet.Rows), Rows: make([]dto.ImportRowResult, len(sheet.Rows))}
+	for i, row := range sheet.Rows {
+		result := dto.ImportRowResult{Line: row.Line, SKU: row.SKU, Name: row.Name, Errors: row.Errors}
+		switch _, found := existing[row.SKU]; {
+		case !row.Valid():
+			result.Action = dto.ImportActionError
+			report.Invalid++
+		case found:
+			result.Action = dto.ImportActionUpdate
+			report.Updated++
+		default:
+			result.Action = dto.ImportActionCreate
+			report.Created++
+		}
+		report.Rows[i] = result
+	}
+
+	if dryRun {
+		return response.WithCode(200).WithData(report)
+	}
+
+	if report.Invalid > 0 {
+		report.Created, report.Updated = 0, 0
+		return response.WithCode(422).
+			WithError(fmt.Errorf("%d rows have errors, nothing was imported", report.Invalid)).
+			WithData(report)
+	}
+
+	products := make([]entities.Product, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		products[i] = entities.Product{
+			MerchantID:  merchantId,
+			CategoryID:  stringPtr(row.CategoryID),
+			Name:        row.Name,
+			Description: stringPtr(row.Description),
+			SKU:         stringPtr(row.SKU),
+			Price:       row.Price,
+			Stock:       row.Stock,
+			Status:      row.Status,
+			Weight:      row.Weight,
+			Length:      row.Length,
+			Width:       row.Width,
+			Height:      row.Height,
+		}
+	}
+
+	// Columns missing from the file keep their current values
+	columns := []string{}
+	for column := range sheet.Columns {
+		columns = append(columns, column)
+	}
+
+	ids, err := s.productRepo.UpsertBySKU(ctx, products, columns, stringPtr(userId))
+	if err != nil {
+		log.Error(fmt.Sprintf("error importing products: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to import products"))
+	}
+
+	s.embeddingQueue.Enqueue(ids...)
+
+	return response.WithCode(200).WithData(report)
+}
+
+// checkCategories adds an error to the rows whose category does not exist.
+func (s *productImportService) checkCategories(ctx context.Context, rows []catalogimport.Row) error {
+	found := map[string]bool{}
+
+	for i := range rows {
+		id := rows[i].CategoryID
+		if id == "" {
+			continue
+		}
+
+		exists, checked := found[id]
+		if !checked {
+			if _, err := uuid.Parse(id); err == nil {
+				category, err := s.categoryRepo.FindOneById(ctx, id)
+				if err != nil {
+					return err
+				}
+				exists = category != nil
+			}
+			found[id] = exists
+		}
+
+		if !exists {
+			rows[i].Errors = append(rows[i].Errors, "category_id "+id+" does not exist")
+		}
+	}
+
+	return nil
+}
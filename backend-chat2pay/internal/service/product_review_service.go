@@ -0,0 +1,351 @@
+package service
+
+import (
+	"bytes"
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/storage"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxReviewImages     = 5
+	maxReviewBodyLength = 2000
+)
+
+type ProductReviewService interface {
+	Create(ctx context.Context, customerId string, req *dto.ProductReviewRequest, files []*multipart.FileHeader) *presenter.Response
+	GetByProduct(ctx context.Context, productId string, rating, page, limit int) *presenter.Response
+	GetByCustomer(ctx context.Context, customerId string, page, limit int) *presenter.Response
+	GetByMerchant(ctx context.Context, merchantId string, filter repositories.ProductReviewFilter, page, limit int) *presenter.Response
+	Reply(ctx context.Context, merchantId, id string, req *dto.ReviewReplyRequest) *presenter.Response
+}
+
+type productReviewService struct {
+	reviewRepo    repositories.ProductReviewRepository
+	orderRepo     repositories.OrderRepository
+	productRepo   repositories.ProductRepository
+	storage       storage.Storage
+	cfg           *yaml.Config
+	maxSize       int64
+	thumbnailSize int
+}
+
+func NewProductReviewService(
+	reviewRepo repositories.ProductReviewRepository,
+	orderRepo repositories.OrderRepository,
+	productRepo repositories.ProductRepository,
+	storage storage.Storage,
+	cfg *yaml.Config,
+) ProductReviewService {
+	s := &productReviewService{
+		reviewRepo:    reviewRepo,
+		orderRepo:     orderRepo,
+		productRepo:   productRepo,
+		storage:       storage,
+		cfg:           cfg,
+		maxSize:       int64(cfg.Storage.Image.MaxSizeKB) * 1024,
+		thumbnailSize: cfg.Storage.Image.ThumbnailSize,
+	}
+
+	if s.maxSize <= 0 {
+		s.maxSize = defaultImageMaxSizeKB * 1024
+	}
+	if s.thumbnailSize <= 0 {
+		s.thumbnailSize = defaultThumbnailSize
+	}
+
+	return s
+}
+
+// Create reviews a product of a delivered order of the customer. A product
+// is reviewed once per order.
+func (s *productReviewService) Create(ctx context.Context, customerId string, req *dto.ProductReviewRequest, files []*multipart.FileHeader) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_review_service_create", s.cfg.Logger.Enable)
+	)
+
+	body := strings.TrimSpace(req.Body)
+	switch {
+	case req.Rating < 1 || req.Rating > 5:
+		return response.WithCode(400).WithError(errors.New("rating must be between 1 and 5"))
+	case utf8.RuneCountInString(body) > maxReviewBodyLength:
+		return response.WithCode(400).WithError(fmt.Errorf("review is longer than %d characters", maxReviewBodyLength))
+	case len(files) > maxReviewImages:
+		return response.WithCode(400).WithError(fmt.Errorf("a review can have at most %d photos", maxReviewImages))
+	}
+
+	order, err := s.orderRepo.FindByID(ctx, req.OrderID)
+	if err != nil || order.CustomerID != customerId {
+		return response.WithCode(404).WithError(errors.New("order not found"))
+	}
+	if order.Status != entities.OrderStatusDelivered {
+		return response.WithCode(400).WithError(errors.New("only products of delivered orders can be reviewed"))
+	}
+
+	items, err := s.orderRepo.GetOrderItems(ctx, order.ID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching order items: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	ordered := false
+	for _, item := range items {
+		if item.ProductID == req.ProductID {
+			ordered = true
+			break
+		}
+	}
+	if !ordered {
+		return response.WithCode(400).WithError(errors.New("product is not in this order"))
+	}
+
+	reviewed, err := s.reviewRepo.Exists(ctx, order.ID, req.ProductID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error checking review: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if reviewed {
+		return response.WithCode(409).WithError(errors.New("product is already reviewed for this order"))
+	}
+
+	// Validate every photo before storing any of them
+	uploads := make([]upload, 0, len(files))
+	for _, file := range files {
+		u, err := prepareImage(file, s.maxSize, s.thumbnailSize)
+		if err != nil {
+			return response.WithCode(400).WithError(fmt.Errorf("%s: %v", file.Filename, err))
+		}
+		uploads = append(uploads, u)
+	}
+
+	review := &entities.ProductReview{
+		ID:         uuid.New().String(),
+		ProductID:  req.ProductID,
+		OrderID:    order.ID,
+		CustomerID: customerId,
+		Rating:     req.Rating,
+		Body:       stringPtr(body),
+	}
+
+	for _, u := range uploads {
+		image, err := s.store(ctx, review.ID, u)
+		if err != nil {
+			log.Error(fmt.Sprintf("error storing review photo: %v", err))
+			s.removeFiles(ctx, review.Images)
+			return response.WithCode(500).WithError(errors.New("failed to upload photo"))
+		}
+		review.Images = append(review.Images, *image)
+	}
+
+	if err = s.reviewRepo.Create(ctx, review); err != nil {
+		log.Error(fmt.Sprintf("error creating review: %v", err))
+		s.removeFiles(ctx, review.Images)
+		return response.WithCode(500).WithError(errors.New("failed to create review"))
+	}
+
+	created, err := s.reviewRepo.FindOneById(ctx, review.ID)
+	if err != nil || created == nil {
+		log.Error(fmt.Sprintf("error fetching review: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	created.Images = review.Images
+
+	data := dto.ToProductReviewResponse(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *productReviewService) store(ctx context.Context, reviewId string, u upload) (*entities.ProductReviewImage, error) {
+	name := uuid.New().String()
+	image := &entities.ProductReviewImage{
+		StorageKey:   fmt.Sprintf("reviews/%s/%s%s", reviewId, name, u.ext),
+		ThumbnailKey: fmt.Sprintf("reviews/%s/%s_thumb.jpg", reviewId, name),
+	}
+
+	var err error
+	image.ImageURL, err = s.storage.Put(ctx, image.StorageKey, bytes.NewReader(u.data), int64(len(u.data)), u.contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ThumbnailURL, err = s.storage.Put(ctx, image.ThumbnailKey, bytes.NewReader(u.thumbnail), int64(len(u.thumbnail)), "image/jpeg")
+	if err != nil {
+		s.removeFiles(ctx, []entities.ProductReviewImage{*image})
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// removeFiles deletes stored photos of a review that could not be saved.
+func (s *productReviewService) removeFiles(ctx context.Context, images []entities.ProductReviewImage) {
+	log := logger.NewLog("product_review_service_remove_files", s.cfg.Logger.Enable)
+
+	for _, image := range images {
+		for _, key := range []string{image.StorageKey, image.ThumbnailKey} {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				log.Error(fmt.Sprintf("error deleting file %s: %v", key, err))
+			}
+		}
+	}
+}
+
+// GetByProduct lists the reviews of a product, latest first, with its
+// rating. Rating filters on a number of stars when it is set.
+func (s *productReviewService) GetByProduct(ctx context.Context, productId string, rating, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_review_service_get_by_product", s.cfg.Logger.Enable)
+	)
+
+	if rating < 0 || rating > 5 {
+		return response.WithCode(400).WithError(errors.New("rating must be between 1 and 5"))
+	}
+
+	product, err := s.productRepo.FindOneById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	ratings, err := s.reviewRepo.FindRatingsByProductIDs(ctx, []string{productId})
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product rating: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch reviews"))
+	}
+	productRating := entities.ProductRating{ProductID: productId}
+	if len(ratings) > 0 {
+		productRating = ratings[0]
+	}
+
+	filter := repositories.ProductReviewFilter{ProductID: productId, Rating: rating}
+	return s.list(ctx, log, filter, &productRating, page, limit)
+}
+
+func (s *productReviewService) GetByCustomer(ctx context.Context, customerId string, page, limit int) *presenter.Response {
+	log := logger.NewLog("product_review_service_get_by_customer", s.cfg.Logger.Enable)
+
+	filter := repositories.ProductReviewFilter{CustomerID: customerId}
+	return s.list(ctx, log, filter, nil, page, limit)
+}
+
+func (s *productReviewService) GetByMerchant(ctx context.Context, merchantId string, filter repositories.ProductReviewFilter, page, limit int) *presenter.Response {
+	response := presenter.Response{}
+	log := logger.NewLog("product_review_service_get_by_merchant", s.cfg.Logger.Enable)
+
+	if filter.Rating < 0 || filter.Rating > 5 {
+		return response.WithCode(400).WithError(errors.New("rating must be between 1 and 5"))
+	}
+
+	filter.MerchantID = merchantId
+	return s.list(ctx, log, filter, nil, page, limit)
+}
+
+func (s *productReviewService) list(ctx context.Context, log logger.LoggerLevel, filter repositories.ProductReviewFilter, rating *entities.ProductRating, page, limit int) *presenter.Response {
+	response := presenter.Response{}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	reviews, err := s.reviewRepo.Find(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching reviews: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch reviews"))
+	}
+
+	if err = s.loadImages(ctx, reviews); err != nil {
+		log.Error(fmt.Sprintf("error fetching review photos: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch reviews"))
+	}
+
+	total, err := s.reviewRepo.Count(ctx, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting reviews: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch reviews"))
+	}
+
+	data := dto.ToProductReviewListResponse(reviews, rating, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+// Reply sets the reply of the merchant to a review of one of its products.
+// Replying again replaces the reply.
+func (s *productReviewService) Reply(ctx context.Context, merchantId, id string, req *dto.ReviewReplyRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_review_service_reply", s.cfg.Logger.Enable)
+	)
+
+	reply := strings.TrimSpace(req.Reply)
+	switch {
+	case reply == "":
+		return response.WithCode(400).WithError(errors.New("reply is required"))
+	case utf8.RuneCountInString(reply) > maxReviewBodyLength:
+		return response.WithCode(400).WithError(fmt.Errorf("reply is longer than %d characters", maxReviewBodyLength))
+	}
+
+	found, err := s.reviewRepo.Reply(ctx, merchantId, id, reply)
+	if err != nil {
+		log.Error(fmt.Sprintf("error replying to review: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to reply to review"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("review not found"))
+	}
+
+	review, err := s.reviewRepo.FindOneById(ctx, id)
+	if err != nil || review == nil {
+		log.Error(fmt.Sprintf("error fetching review: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	reviews := []entities.ProductReview{*review}
+	if err = s.loadImages(ctx, reviews); err != nil {
+		log.Error(fmt.Sprintf("error fetching review photos: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	data := dto.ToProductReviewResponse(&reviews[0])
+	return response.WithCode(200).WithData(data)
+}
+
+// loadImages fills in the photos of each review with a single query.
+func (s *productReviewService) loadImages(ctx context.Context, reviews []entities.ProductReview) error {
+	reviewIds := make([]string, len(reviews))
+	for i, review := range reviews {
+		reviewIds[i] = review.ID
+	}
+
+	images, err := s.reviewRepo.FindImagesByReviewIDs(ctx, reviewIds)
+	if err != nil {
+		return err
+	}
+
+	byReview := map[string][]entities.ProductReviewImage{}
+	for _, image := range images {
+		byReview[image.ReviewID] = append(byReview[image.ReviewID], image)
+	}
+	for i := range reviews {
+		reviews[i].Images = byReview[reviews[i].ID]
+	}
+
+	return nil
+}
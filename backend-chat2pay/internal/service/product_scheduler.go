@@ -0,0 +1,69 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"fmt"
+	"time"
+)
+
+// productScheduleInterval is how often the scheduler looks for products
+// to publish or unpublish.
+const productScheduleInterval = time.Minute
+
+// ProductScheduler publishes and unpublishes products at the times set by
+// their merchant. It runs alongside the http server; times that passed
+// while the server was down are handled on the next run.
+type ProductScheduler interface {
+	Run(ctx context.Context)
+}
+
+type productScheduler struct {
+	productRepo repositories.ProductRepository
+	cfg         *yaml.Config
+}
+
+func NewProductScheduler(productRepo repositories.ProductRepository, cfg *yaml.Config) ProductScheduler {
+	return &productScheduler{
+		productRepo: productRepo,
+		cfg:         cfg,
+	}
+}
+
+// Run processes the schedule until the context is cancelled.
+func (s *productScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(productScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		s.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process publishes before unpublishing, so a product whose whole window
+// has passed ends up inactive.
+func (s *productScheduler) process(ctx context.Context) {
+	log := logger.NewLog("product_scheduler", s.cfg.Logger.Enable)
+
+	published, err := s.productRepo.PublishDue(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("error publishing scheduled products: %v", err))
+	} else if len(published) > 0 {
+		log.Info(fmt.Sprintf("published %d scheduled products", len(published)))
+	}
+
+	unpublished, err := s.productRepo.UnpublishDue(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("error unpublishing scheduled products: %v", err))
+	} else if len(unpublished) > 0 {
+		log.Info(fmt.Sprintf("unpublished %d scheduled products", len(unpublished)))
+	}
+}
@@ -5,37 +5,79 @@ import (
 	"chat2pay/internal/api/dto"
 	"chat2pay/internal/api/presenter"
 	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/attributes"
+	"chat2pay/internal/pkg/faq"
 	"chat2pay/internal/pkg/llm"
 	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/pkg/productdoc"
 	"chat2pay/internal/pkg/redis"
+	"chat2pay/internal/pkg/reviews"
 	"chat2pay/internal/repositories"
 	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type ProductService interface {
 	Create(ctx context.Context, req *dto.ProductRequest) *presenter.Response
 	CreateMultiple(ctx context.Context, req *[]dto.ProductRequest) *presenter.Response
-	GetAll(ctx context.Context, merchantId string, page, limit int) *presenter.Response
-	GetById(ctx context.Context, id string) *presenter.Response
-	Update(ctx context.Context, id string, req *dto.ProductRequest) *presenter.Response
-	Delete(ctx context.Context, id string) *presenter.Response
+	GetAll(ctx context.Context, query *dto.ProductListQuery, page, limit int) *presenter.Response
+	GetById(ctx context.Context, merchantId, id string) *presenter.Response
+	Update(ctx context.Context, merchantId, id string, req *dto.ProductRequest) *presenter.Response
+	Delete(ctx context.Context, merchantId, id string) *presenter.Response
+	Restore(ctx context.Context, merchantId, id string) *presenter.Response
 	AskProduct(ctx context.Context, req *dto.AskProduct) *presenter.Response
+	Retrieve(ctx context.Context, query string) ([]entities.Product, error)
+	Reembed(ctx context.Context, id string) error
+	GetSimilar(ctx context.Context, id string) *presenter.Response
+	GetBoughtTogether(ctx context.Context, id string) *presenter.Response
 }
 
+const (
+	// searchResultLimit is the number of products returned by a search.
+	searchResultLimit = 10
+	// relatedResultLimit is the number of related products shown for a product.
+	relatedResultLimit = 6
+	// reviewSummaryLimit is the number of recent reviews given to the model
+	// when answering a question about a product.
+	reviewSummaryLimit = 5
+)
+
 type productService struct {
 	productRepo  repositories.ProductRepository
 	merchantRepo repositories.MerchantRepository
+	categoryRepo repositories.CategoryRepository
+	imageRepo    repositories.ProductImageRepository
+	variantRepo  repositories.ProductVariantRepository
+	alertRepo    repositories.StockAlertRepository
+	discountRepo repositories.DiscountRepository
+	reviewRepo   repositories.ProductReviewRepository
+	faqRepo      repositories.ProductFAQRepository
+	wishlist     WishlistService
+	cart         CartService
 	llm          llm.LLM
 	redisClient  redis.RedisClient
 	cfg          *yaml.Config
+	docBuilder   *productdoc.Builder
+	normalizer   *queryNormalizer
 }
 
 func NewProductService(
 	productRepo repositories.ProductRepository,
 	merchantRepo repositories.MerchantRepository,
+	categoryRepo repositories.CategoryRepository,
+	imageRepo repositories.ProductImageRepository,
+	variantRepo repositories.ProductVariantRepository,
+	synonymRepo repositories.SearchSynonymRepository,
+	alertRepo repositories.StockAlertRepository,
+	discountRepo repositories.DiscountRepository,
+	reviewRepo repositories.ProductReviewRepository,
+	faqRepo repositories.ProductFAQRepository,
+	wishlist WishlistService,
+	cart CartService,
 	llm llm.LLM,
 	redisClient redis.RedisClient,
 	cfg *yaml.Config,
@@ -43,9 +85,20 @@ func NewProductService(
 	return &productService{
 		productRepo:  productRepo,
 		merchantRepo: merchantRepo,
+		categoryRepo: categoryRepo,
+		imageRepo:    imageRepo,
+		variantRepo:  variantRepo,
+		alertRepo:    alertRepo,
+		discountRepo: discountRepo,
+		reviewRepo:   reviewRepo,
+		faqRepo:      faqRepo,
+		wishlist:     wishlist,
+		cart:         cart,
 		llm:          llm,
 		redisClient:  redisClient,
 		cfg:          cfg,
+		docBuilder:   productdoc.NewBuilder(cfg.Search.Document),
+		normalizer:   newQueryNormalizer(productRepo, synonymRepo),
 	}
 }
 
@@ -56,44 +109,54 @@ func (s *productService) Create(ctx context.Context, req *dto.ProductRequest) *p
 	)
 
 	product := &entities.Product{
-		MerchantID: req.MerchantID,
-		OutletID:   req.OutletID,
-		//CategoryID:  req.CategoryID,
-		Name:        req.Name,
-		Description: stringPtr(req.Description),
-		SKU:         stringPtr(req.SKU),
-		Price:       req.Price,
-		Stock:       req.Stock,
-		Status:      "active",
-		Image:       stringPtr(req.Image),
+		MerchantID:        req.MerchantID,
+		OutletID:          req.OutletID,
+		CategoryID:        req.CategoryID,
+		Name:              req.Name,
+		Description:       stringPtr(req.Description),
+		SKU:               stringPtr(req.SKU),
+		Price:             req.Price,
+		Stock:             req.Stock,
+		Status:            entities.ProductStatusActive,
+		LowStockThreshold: req.LowStockThreshold,
+		Weight:            req.Weight,
+		Length:            req.Length,
+		Width:             req.Width,
+		Height:            req.Height,
+		PublishAt:         req.PublishAt,
+		UnpublishAt:       req.UnpublishAt,
 	}
 
+	// A product scheduled for later waits as a draft
+	if req.PublishAt != nil {
+		product.Status = entities.ProductStatusDraft
+	}
 	if req.Status != "" {
 		product.Status = req.Status
 	}
 
-	log.Info("creating product")
-	created, err := s.productRepo.Create(ctx, product)
-	if err != nil {
-		log.Error(fmt.Sprintf("error creating product: %v", err))
-		return response.WithCode(500).WithError(errors.New("failed to create product"))
+	if resp := checkLifecycle(product); resp != nil {
+		return resp
 	}
 
-	//Embedding product
-	emb, err := s.llm.EmbedQuery(ctx, formatProductForEmbedding(*product))
+	if resp := s.checkCategory(ctx, product.CategoryID); resp != nil {
+		return resp
+	}
+
+	var resp *presenter.Response
+	if product.Attributes, resp = s.checkAttributes(ctx, product.CategoryID, req.Attributes); resp != nil {
+		return resp
+	}
 
+	log.Info("creating product")
+	created, err := s.productRepo.Create(ctx, product, stringPtr(req.UserID))
 	if err != nil {
 		log.Error(fmt.Sprintf("error creating product: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to create product"))
 	}
 
-	err = s.productRepo.CreateProductEmbedding(ctx, &entities.ProductEmbedding{
-		ProductId: created.ID,
-		Content:   fmt.Sprintf(`%s - %s`, product.Name, *product.Description),
-		Embedding: emb,
-	})
-
-	if err != nil {
+	//Embedding product
+	if err = s.embedProduct(ctx, created); err != nil {
 		log.Error(fmt.Sprintf("error creating product: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to create product"))
 	}
@@ -110,39 +173,53 @@ func (s *productService) CreateMultiple(ctx context.Context, req *[]dto.ProductR
 
 	for _, productPayload := range *req {
 		product := &entities.Product{
-			MerchantID: productPayload.MerchantID,
-			OutletID:   productPayload.OutletID,
-			//CategoryID:  productPayload.CategoryID,
-			Name:        productPayload.Name,
-			Description: stringPtr(productPayload.Description),
-			SKU:         stringPtr(productPayload.SKU),
-			Price:       productPayload.Price,
-			Stock:       productPayload.Stock,
-			Status:      "active",
+			MerchantID:        productPayload.MerchantID,
+			OutletID:          productPayload.OutletID,
+			CategoryID:        productPayload.CategoryID,
+			Name:              productPayload.Name,
+			Description:       stringPtr(productPayload.Description),
+			SKU:               stringPtr(productPayload.SKU),
+			Price:             productPayload.Price,
+			Stock:             productPayload.Stock,
+			Status:            entities.ProductStatusActive,
+			LowStockThreshold: productPayload.LowStockThreshold,
+			Weight:            productPayload.Weight,
+			Length:            productPayload.Length,
+			Width:             productPayload.Width,
+			Height:            productPayload.Height,
+			PublishAt:         productPayload.PublishAt,
+			UnpublishAt:       productPayload.UnpublishAt,
 		}
 
-		log.Info("creating product")
-		created, err := s.productRepo.Create(ctx, product)
-		if err != nil {
-			log.Error(fmt.Sprintf("error creating product: %v", err))
-			return response.WithCode(500).WithError(errors.New("failed to create product"))
+		if productPayload.PublishAt != nil {
+			product.Status = entities.ProductStatusDraft
+		}
+		if productPayload.Status != "" {
+			product.Status = productPayload.Status
 		}
 
-		//Embedding product
-		emb, err := s.llm.EmbedQuery(ctx, formatProductForEmbedding(*product))
+		if resp := checkLifecycle(product); resp != nil {
+			return resp
+		}
+
+		if resp := s.checkCategory(ctx, product.CategoryID); resp != nil {
+			return resp
+		}
 
+		var resp *presenter.Response
+		if product.Attributes, resp = s.checkAttributes(ctx, product.CategoryID, productPayload.Attributes); resp != nil {
+			return resp
+		}
+
+		log.Info("creating product")
+		created, err := s.productRepo.Create(ctx, product, stringPtr(productPayload.UserID))
 		if err != nil {
 			log.Error(fmt.Sprintf("error creating product: %v", err))
 			return response.WithCode(500).WithError(errors.New("failed to create product"))
 		}
 
-		err = s.productRepo.CreateProductEmbedding(ctx, &entities.ProductEmbedding{
-			ProductId: created.ID,
-			Content:   fmt.Sprintf(`%s - %s`, product.Name, *product.Description),
-			Embedding: emb,
-		})
-
-		if err != nil {
+		//Embedding product
+		if err = s.embedProduct(ctx, created); err != nil {
 			log.Error(fmt.Sprintf("error creating product: %v", err))
 			return response.WithCode(500).WithError(errors.New("failed to create product"))
 		}
@@ -162,6 +239,19 @@ func (s *productService) AskProduct(ctx context.Context, req *dto.AskProduct) *p
 		return response.WithCode(500).WithError(errors.New("failed classify intent"))
 	}
 
+	// Searches stay within the selected category and its subcategories
+	var categoryIds []string
+	if req.CategoryID != "" {
+		categoryIds, err = s.categoryRepo.FindDescendantIDs(ctx, req.CategoryID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching category descendants: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed get product"))
+		}
+		if len(categoryIds) == 0 {
+			return response.WithCode(404).WithError(errors.New("category not found"))
+		}
+	}
+
 	switch classify {
 	case "chit_chat":
 
@@ -183,52 +273,27 @@ func (s *productService) AskProduct(ctx context.Context, req *dto.AskProduct) *p
 		data := dto.ToLLM(nil, answer)
 		return response.WithCode(200).WithData(data)
 	case "specific_product_search":
-		// Extract budget from prompt using LLM
-		budgetPrompt := fmt.Sprintf(`Dari pesan user: "%s"
-
-Ekstrak budget/harga maksimal yang disebutkan dalam Rupiah.
-Jika ada angka seperti "15 juta", "15jt", "15.000.000", konversi ke angka.
-Jika tidak ada budget disebutkan, output: 0
-
-Output HANYA angka saja tanpa format (contoh: 15000000), tanpa penjelasan.`, req.Prompt)
-
-		budgetStr, _ := s.llm.Chat(ctx, budgetPrompt)
-		maxPrice := extractBudget(budgetStr)
-
-		// Embedding product
-		emb, err := s.llm.EmbedQuery(ctx, req.Prompt)
-		if err != nil {
-			log.Error(fmt.Sprintf("error creating product: %v", err))
-			return response.WithCode(500).WithError(errors.New("failed get product"))
-		}
-
-		// Use price filter if budget was detected
-		var embedding []entities.ProductEmbedding
-		if maxPrice > 0 {
-			embedding, err = s.productRepo.GetProductEmbeddingListWithPrice(ctx, emb, maxPrice)
-		} else {
-			embedding, err = s.productRepo.GetProductEmbeddingList(ctx, emb)
-		}
+		maxPrice := s.extractMaxPrice(ctx, req.Prompt)
 
+		query, emb, err := s.embedQuery(ctx, req.Prompt)
 		if err != nil {
-			log.Error(fmt.Sprintf("error creating product: %v", err))
+			log.Error(fmt.Sprintf("error searching product: %v", err))
 			return response.WithCode(500).WithError(errors.New("failed get product"))
 		}
 
-		productIds := []string{}
-		for _, productEmbedding := range embedding {
-			productIds = append(productIds, productEmbedding.ProductId)
-		}
-
-		products, err := s.productRepo.FindByIDs(ctx, productIds)
+		products, err := s.searchByEmbedding(ctx, emb, maxPrice, categoryIds)
 		if err != nil {
-			log.Error(fmt.Sprintf("error creating product: %v", err))
+			log.Error(fmt.Sprintf("error searching product: %v", err))
 			return response.WithCode(500).WithError(errors.New("failed get product"))
 		}
 
 		// Generate reasoning/recommendation based on products found
 		var message string
 		if len(products) == 0 {
+			// Only what customers ask for and cannot be offered counts
+			// towards the sold out in chat report
+			s.recordChatMisses(ctx, query, emb, maxPrice, categoryIds)
+
 			if maxPrice > 0 {
 				message = fmt.Sprintf("Maaf, saya tidak menemukan produk yang sesuai dengan budget Rp %s. Coba naikkan budget atau ubah kriteria pencarian.", formatPrice(maxPrice))
 			} else {
@@ -244,6 +309,8 @@ Saya menemukan %d produk yang relevan. Berikan rekomendasi singkat (2-3 kalimat)
 
 Jawab dalam Bahasa Indonesia, singkat dan informatif.`, req.Prompt, len(products))
 
+			recommendationPrompt += s.comparison(ctx, products)
+
 			recommendation, err := s.llm.Chat(ctx, recommendationPrompt)
 			if err != nil {
 				message = "Berikut produk yang saya temukan untuk Anda:"
@@ -262,7 +329,7 @@ Jawab dalam Bahasa Indonesia, singkat dan informatif.`, req.Prompt, len(products
 		// User is asking about a product that was already shown
 		// Use chat history to answer questions about the product
 		lastMsg, err := s.llm.GetLastMessageContext(ctx)
-		if err != nil || lastMsg == "" {
+		if (err != nil || lastMsg == "") && req.ProductID == "" && req.MerchantID == "" {
 			answer, _ := s.llm.ChatWithHistory(ctx, req.Prompt)
 			data := dto.ToLLM(nil, answer)
 			return response.WithCode(200).WithData(data)
@@ -278,8 +345,40 @@ Jika user bertanya "kenapa menyarankan ini", jelaskan alasan berdasarkan spesifi
 Jika user bertanya "speknya apa", jelaskan spesifikasi utama produk.
 Jawab dalam Bahasa Indonesia, ramah dan membantu.`, lastMsg, req.Prompt)
 
-		answer, err := s.llm.Chat(ctx, questionPrompt)
-		if err != nil {
+		// Specifications are answered from the product data, not the prose
+		var faqs []entities.ProductFAQ
+		if req.ProductID != "" {
+			products := []entities.Product{}
+			if product, err := s.productRepo.FindOneById(ctx, req.ProductID); err == nil && product != nil {
+				products = append(products, *product)
+			}
+			if err = s.loadDiscounts(ctx, products); err != nil {
+				log.Error(fmt.Sprintf("error fetching product discounts: %v", err))
+			}
+			questionPrompt += s.comparison(ctx, products)
+
+			// Questions such as "review nya gimana?" are answered from the
+			// reviews of customers
+			if len(products) > 0 {
+				questionPrompt += s.reviewSummary(ctx, &products[0])
+
+				// Questions the merchant answered in the FAQ keep their answer
+				faqs = s.findFAQs(ctx, products[0].MerchantID, products[0].ID, req.Prompt)
+				questionPrompt += faq.Context(faqs)
+			}
+		} else if req.MerchantID != "" {
+			// Without a product only the entries for the whole store apply,
+			// e.g. payment or return questions
+			faqs = s.findFAQs(ctx, req.MerchantID, "", req.Prompt)
+			questionPrompt += faq.Context(faqs)
+		}
+
+		var answer string
+		if quote := faq.Quote(faqs); quote != nil {
+			// An answer of the merchant matching the question well is
+			// given as written
+			answer = quote.Answer
+		} else if answer, err = s.llm.Chat(ctx, questionPrompt); err != nil {
 			answer, _ = s.llm.ChatWithHistory(ctx, req.Prompt)
 		}
 
@@ -287,6 +386,10 @@ Jawab dalam Bahasa Indonesia, ramah dan membantu.`, lastMsg, req.Prompt)
 		s.llm.ChatWithHistory(ctx, fmt.Sprintf("User bertanya: %s", req.Prompt))
 
 		data := dto.ToLLM(nil, answer)
+		if req.ProductID != "" {
+			s.attachVariants(ctx, &data, req.ProductID)
+			s.attachRelated(ctx, &data, req.ProductID)
+		}
 		return response.WithCode(200).WithData(data)
 
 	case "product_clarification":
@@ -316,39 +419,20 @@ Hanya output query saja, tanpa penjelasan.`, lastMsg, req.Prompt)
 		}
 
 		// Search products with combined query
-		emb, err := s.llm.EmbedQuery(ctx, searchQuery)
-		if err != nil {
-			log.Error(fmt.Sprintf("error embedding clarification: %v", err))
-			answer, _ := s.llm.ChatWithHistory(ctx, req.Prompt)
-			data := dto.ToLLM(nil, answer)
-			return response.WithCode(200).WithData(data)
-		}
-
-		embedding, err := s.productRepo.GetProductEmbeddingList(ctx, emb)
+		products, err := s.searchProducts(ctx, searchQuery, 0, categoryIds)
 		if err != nil {
-			log.Error(fmt.Sprintf("error getting embeddings: %v", err))
+			log.Error(fmt.Sprintf("error searching clarification: %v", err))
 			answer, _ := s.llm.ChatWithHistory(ctx, req.Prompt)
 			data := dto.ToLLM(nil, answer)
 			return response.WithCode(200).WithData(data)
 		}
 
-		if len(embedding) == 0 {
+		if len(products) == 0 {
 			answer, _ := s.llm.ChatWithHistory(ctx, fmt.Sprintf("User mencari: %s. Tidak ada produk yang cocok, berikan saran alternatif.", searchQuery))
 			data := dto.ToLLM(nil, answer)
 			return response.WithCode(200).WithData(data)
 		}
 
-		productIds := []string{}
-		for _, productEmbedding := range embedding {
-			productIds = append(productIds, productEmbedding.ProductId)
-		}
-
-		products, err := s.productRepo.FindByIDs(ctx, productIds)
-		if err != nil {
-			log.Error(fmt.Sprintf("error finding products: %v", err))
-			return response.WithCode(500).WithError(errors.New("failed get product"))
-		}
-
 		// Generate recommendation with context
 		recommendationPrompt := fmt.Sprintf(`User awalnya bertanya: "%s"
 Kemudian user memberikan preferensi: "%s"
@@ -359,6 +443,8 @@ Saya menemukan %d produk yang relevan. Berikan rekomendasi singkat (2-3 kalimat)
 
 Jawab dalam Bahasa Indonesia, ramah dan informatif.`, lastMsg, req.Prompt, len(products))
 
+		recommendationPrompt += s.comparison(ctx, products)
+
 		recommendation, err := s.llm.Chat(ctx, recommendationPrompt)
 		if err != nil {
 			recommendation = "Berdasarkan preferensi Anda, berikut produk yang saya rekomendasikan:"
@@ -379,6 +465,9 @@ Jawab dalam Bahasa Indonesia, ramah dan informatif.`, lastMsg, req.Prompt, len(p
 				return response.WithCode(500).WithError(errors.New("failed get product"))
 			}
 			data := dto.ToLLM(nil, answer)
+			if req.ProductID != "" {
+				s.attachVariants(ctx, &data, req.ProductID)
+			}
 			return response.WithCode(200).WithData(data)
 		}
 
@@ -396,41 +485,28 @@ Hanya output query saja, tanpa penjelasan.`, lastMsg, req.Prompt)
 		}
 
 		// Now search products with combined query
-		emb, err := s.llm.EmbedQuery(ctx, searchQuery)
+		products, err := s.searchProducts(ctx, searchQuery, 0, categoryIds)
 		if err != nil {
-			log.Error(fmt.Sprintf("error embedding follow-up: %v", err))
+			log.Error(fmt.Sprintf("error searching follow-up: %v", err))
 			// Fallback to chat response
 			answer, _ := s.llm.ChatWithHistory(ctx, req.Prompt)
 			data := dto.ToLLM(nil, answer)
+			if req.ProductID != "" {
+				s.attachVariants(ctx, &data, req.ProductID)
+			}
 			return response.WithCode(200).WithData(data)
 		}
 
-		embedding, err := s.productRepo.GetProductEmbeddingList(ctx, emb)
-		if err != nil {
-			log.Error(fmt.Sprintf("error getting embeddings: %v", err))
-			answer, _ := s.llm.ChatWithHistory(ctx, req.Prompt)
-			data := dto.ToLLM(nil, answer)
-			return response.WithCode(200).WithData(data)
-		}
-
-		if len(embedding) == 0 {
+		if len(products) == 0 {
 			// No products found, give helpful response
 			answer, _ := s.llm.ChatWithHistory(ctx, fmt.Sprintf("User mencari: %s. Tidak ada produk yang cocok, berikan saran alternatif.", searchQuery))
 			data := dto.ToLLM(nil, answer)
+			if req.ProductID != "" {
+				s.attachVariants(ctx, &data, req.ProductID)
+			}
 			return response.WithCode(200).WithData(data)
 		}
 
-		productIds := []string{}
-		for _, productEmbedding := range embedding {
-			productIds = append(productIds, productEmbedding.ProductId)
-		}
-
-		products, err := s.productRepo.FindByIDs(ctx, productIds)
-		if err != nil {
-			log.Error(fmt.Sprintf("error finding products: %v", err))
-			return response.WithCode(500).WithError(errors.New("failed get product"))
-		}
-
 		// Generate recommendation with context
 		recommendationPrompt := fmt.Sprintf(`User awalnya bertanya: "%s"
 Kemudian user memberikan preferensi: "%s"
@@ -441,6 +517,8 @@ Saya menemukan %d produk yang relevan. Berikan rekomendasi singkat (2-3 kalimat)
 
 Jawab dalam Bahasa Indonesia, ramah dan informatif.`, lastMsg, req.Prompt, len(products))
 
+		recommendationPrompt += s.comparison(ctx, products)
+
 		recommendation, err := s.llm.Chat(ctx, recommendationPrompt)
 		if err != nil {
 			recommendation = "Berdasarkan preferensi Anda, berikut produk yang saya rekomendasikan:"
@@ -450,13 +528,201 @@ Jawab dalam Bahasa Indonesia, ramah dan informatif.`, lastMsg, req.Prompt, len(p
 		s.llm.ChatWithHistory(ctx, fmt.Sprintf("User: %s. Saya menemukan %d produk yang cocok.", req.Prompt, len(products)))
 
 		data := dto.ToLLM(&products, recommendation)
+		if req.ProductID != "" {
+			s.attachVariants(ctx, &data, req.ProductID)
+		}
+		return response.WithCode(200).WithData(data)
+
+	case "save_to_wishlist":
+		// The product the customer is looking at is kept for later
+		data := dto.ToLLM(nil, s.saveToWishlist(ctx, req))
+		return response.WithCode(200).WithData(data)
+
+	case "add_to_cart":
+		// The product the customer is looking at goes to the cart, its
+		// options are shown when a variant has to be chosen first
+		message, chooseVariant := s.addToCart(ctx, req)
+		data := dto.ToLLM(nil, message)
+		if chooseVariant {
+			s.attachVariants(ctx, &data, req.ProductID)
+		}
 		return response.WithCode(200).WithData(data)
 	}
 
 	return response.WithCode(200).WithData("ok")
 }
 
-func (s *productService) GetAll(ctx context.Context, merchantId string, page, limit int) *presenter.Response {
+// Retrieve runs the same retrieval path as a specific product search in
+// AskProduct and returns the products ranked by similarity. It is used by the
+// search evaluation command.
+func (s *productService) Retrieve(ctx context.Context, query string) ([]entities.Product, error) {
+	return s.searchProducts(ctx, query, s.extractMaxPrice(ctx, query), nil)
+}
+
+func (s *productService) extractMaxPrice(ctx context.Context, prompt string) float64 {
+	// Extract budget from prompt using LLM
+	budgetPrompt := fmt.Sprintf(`Dari pesan user: "%s"
+
+Ekstrak budget/harga maksimal yang disebutkan dalam Rupiah.
+Jika ada angka seperti "15 juta", "15jt", "15.000.000", konversi ke angka.
+Jika tidak ada budget disebutkan, output: 0
+
+Output HANYA angka saja tanpa format (contoh: 15000000), tanpa penjelasan.`, prompt)
+
+	budgetStr, _ := s.llm.Chat(ctx, budgetPrompt)
+	return extractBudget(budgetStr)
+}
+
+func (s *productService) searchProducts(ctx context.Context, query string, maxPrice float64, categoryIds []string) ([]entities.Product, error) {
+	_, emb, err := s.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.searchByEmbedding(ctx, emb, maxPrice, categoryIds)
+}
+
+// embedQuery normalizes the query and embeds it, returning both.
+func (s *productService) embedQuery(ctx context.Context, query string) (string, []float32, error) {
+	log := logger.NewLog("product_service_embed_query", s.cfg.Logger.Enable)
+
+	// A failed lookup still leaves a usable query, so search with it
+	query, err := s.normalizer.Normalize(ctx, "", query)
+	if err != nil {
+		log.Error(fmt.Sprintf("error normalizing query: %v", err))
+	}
+
+	emb, err := s.llm.EmbedQuery(ctx, query)
+	if err != nil {
+		return query, nil, err
+	}
+
+	return query, emb, nil
+}
+
+// searchByEmbedding returns the products closest to the query embedding,
+// ranked for the chat.
+func (s *productService) searchByEmbedding(ctx context.Context, emb []float32, maxPrice float64, categoryIds []string) ([]entities.Product, error) {
+	var (
+		embedding []entities.ProductEmbedding
+		err       error
+	)
+
+	// Use price filter if budget was detected
+	if maxPrice > 0 {
+		embedding, err = s.productRepo.GetProductEmbeddingListWithPrice(ctx, emb, maxPrice, categoryIds)
+	} else {
+		embedding, err = s.productRepo.GetProductEmbeddingList(ctx, emb, categoryIds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A product can match with several chunks, keep one score per product
+	embedding = productdoc.Aggregate(embedding, s.cfg.Search.Aggregation, searchResultLimit)
+
+	productIds := []string{}
+	for _, productEmbedding := range embedding {
+		productIds = append(productIds, productEmbedding.ProductId)
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	products = orderProductsByIDs(products, productIds)
+	if err = s.loadImages(ctx, products); err != nil {
+		return nil, err
+	}
+
+	// The chat lists the available options of each product it shows
+	if err = s.loadVariants(ctx, products); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		return nil, err
+	}
+
+	// Among close matches, better reviewed products come first
+	if err = s.loadRatings(ctx, products); err != nil {
+		return nil, err
+	}
+	similarities := map[string]float64{}
+	for _, productEmbedding := range embedding {
+		similarities[productEmbedding.ProductId] = productEmbedding.Similarity
+	}
+	reviews.Rank(products, similarities)
+
+	return products, nil
+}
+
+// comparison lists the products with their price and specifications, so
+// the model can compare them and answer questions such as "RAM berapa?"
+// from the data. A discounted price is told with the discount and until
+// when it runs, and a reviewed product with its rating. It is empty when
+// there are no products.
+func (s *productService) comparison(ctx context.Context, products []entities.Product) string {
+	log := logger.NewLog("product_service_comparison", s.cfg.Logger.Enable)
+
+	if len(products) == 0 {
+		return ""
+	}
+
+	schemas := map[string]entities.AttributeSchema{}
+	lines := []string{}
+	for _, product := range products {
+		categoryId := ""
+		if product.CategoryID != nil {
+			categoryId = *product.CategoryID
+		}
+
+		schema, ok := schemas[categoryId]
+		if !ok {
+			var err error
+			if schema, err = s.attributeSchema(ctx, product.CategoryID); err != nil {
+				log.Error(fmt.Sprintf("error fetching attribute schema: %v", err))
+			}
+			schemas[categoryId] = schema
+		}
+
+		line := fmt.Sprintf("- %s (Rp %.0f)", product.Name, product.Price)
+		if product.Discount != nil {
+			line = fmt.Sprintf("- %s (Rp %.0f, harga normal Rp %.0f, lagi %s)",
+				product.Name, product.Discounted(product.Price), product.Price, pricing.Label(*product.Discount, time.Now()))
+		}
+		if rating := reviews.Short(product.Rating); rating != "" {
+			line += " [" + rating + "]"
+		}
+		if specs := attributes.Format(product.Attributes, schema); specs != "" {
+			line += ": " + specs
+		}
+		lines = append(lines, line)
+	}
+
+	return "\n\nData produk:\n" + strings.Join(lines, "\n")
+}
+
+// recordChatMisses records the out of stock products that match a search
+// that found nothing to offer, for the sold out in chat report. Failing to record does not fail the
+// search.
+func (s *productService) recordChatMisses(ctx context.Context, query string, emb []float32, maxPrice float64, categoryIds []string) {
+	log := logger.NewLog("product_service_record_chat_misses", s.cfg.Logger.Enable)
+
+	productIds, err := s.productRepo.FindOutOfStockMatches(ctx, emb, maxPrice, categoryIds, searchResultLimit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching out of stock matches: %v", err))
+		return
+	}
+
+	sessionId, _ := ctx.Value("session_id").(string)
+	if err = s.alertRepo.RecordChatMisses(ctx, sessionId, query, productIds); err != nil {
+		log.Error(fmt.Sprintf("error recording chat stock misses: %v", err))
+	}
+}
+
+func (s *productService) GetAll(ctx context.Context, query *dto.ProductListQuery, page, limit int) *presenter.Response {
 	var (
 		response = presenter.Response{}
 		log      = logger.NewLog("product_service_getall", s.cfg.Logger.Enable)
@@ -471,13 +737,56 @@ func (s *productService) GetAll(ctx context.Context, merchantId string, page, li
 
 	offset := (page - 1) * limit
 
-	products, err := s.productRepo.FindAll(ctx, merchantId, limit, offset)
+	filter := repositories.ProductFilter{MerchantID: query.MerchantID, Status: query.Status}
+
+	if strings.TrimSpace(query.Query) != "" {
+		normalized, err := s.normalizer.Normalize(ctx, query.MerchantID, query.Query)
+		if err != nil {
+			log.Error(fmt.Sprintf("error normalizing query: %v", err))
+		}
+		filter.Terms = strings.Fields(normalized)
+	}
+
+	if query.CategoryID != "" {
+		categoryIds, err := s.categoryRepo.FindDescendantIDs(ctx, query.CategoryID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching category descendants: %v", err))
+			return response.WithCode(500).WithError(errors.New("failed to fetch products"))
+		}
+		if len(categoryIds) == 0 {
+			return response.WithCode(404).WithError(errors.New("category not found"))
+		}
+		filter.CategoryIDs = categoryIds
+	}
+
+	filters, err := attributes.ParseFilters(query.Attributes)
+	if err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+	filter.Attributes = filters
+
+	products, err := s.productRepo.Search(ctx, filter, limit, offset)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching products: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to fetch products"))
 	}
 
-	total, err := s.productRepo.Count(ctx, merchantId)
+	if err = s.loadImages(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product images: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch products"))
+	}
+
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product discounts: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch products"))
+	}
+
+	if err = s.loadRatings(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product ratings: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch products"))
+	}
+
+	total, err := s.productRepo.CountSearch(ctx, filter)
 	if err != nil {
 		log.Error(fmt.Sprintf("error counting products: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to count products"))
@@ -487,116 +796,805 @@ func (s *productService) GetAll(ctx context.Context, merchantId string, page, li
 	return response.WithCode(200).WithData(data)
 }
 
-func (s *productService) GetById(ctx context.Context, id string) *presenter.Response {
+// GetById returns the product with its images and variants. Without a
+// merchant only active products are found, a merchant finds any of its own.
+func (s *productService) GetById(ctx context.Context, merchantId, id string) *presenter.Response {
 	var (
 		response = presenter.Response{}
 		log      = logger.NewLog("product_service_getbyid", s.cfg.Logger.Enable)
+		product  *entities.Product
+		err      error
 	)
 
-	log.Info(fmt.Sprintf("fetching product with id: %d", id))
-	product, err := s.productRepo.FindOneById(ctx, id)
+	log.Info(fmt.Sprintf("fetching product with id: %s", id))
+	if merchantId == "" {
+		product, err = s.productRepo.FindActiveById(ctx, id)
+	} else {
+		product, err = s.productRepo.FindOneById(ctx, id)
+	}
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching product: %v", err))
 		return response.WithCode(500).WithError(errors.New("something went wrong"))
 	}
 
-	if product == nil {
+	if product == nil || (merchantId != "" && product.MerchantID != merchantId) {
 		log.Warn("product not found")
 		return response.WithCode(404).WithError(errors.New("product not found"))
 	}
 
+	product.Images, err = s.imageRepo.FindByProductID(ctx, product.ID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product images: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	products := []entities.Product{*product}
+	if err = s.loadVariants(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product discounts: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if err = s.loadRatings(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product ratings: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	product = &products[0]
+
 	data := dto.ToProductResponse(product)
 	return response.WithCode(200).WithData(data)
 }
 
-func (s *productService) Update(ctx context.Context, id string, req *dto.ProductRequest) *presenter.Response {
+func (s *productService) GetSimilar(ctx context.Context, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_service_get_similar", s.cfg.Logger.Enable)
+	)
+
+	product, err := s.productRepo.FindActiveById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	products, err := s.findSimilar(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching similar products: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch similar products"))
+	}
+
+	data := dto.ToProductResponses(products)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productService) GetBoughtTogether(ctx context.Context, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_service_get_bought_together", s.cfg.Logger.Enable)
+	)
+
+	product, err := s.productRepo.FindActiveById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	products, err := s.findBoughtTogether(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching bought together products: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch bought together products"))
+	}
+
+	data := dto.ToProductResponses(products)
+	return response.WithCode(200).WithData(data)
+}
+
+// findSimilar ranks other products by how close their chunks are to the
+// chunks of the given product.
+func (s *productService) findSimilar(ctx context.Context, id string) ([]entities.Product, error) {
+	embedding, err := s.productRepo.GetSimilarProductEmbeddingList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding = productdoc.Aggregate(embedding, s.cfg.Search.Aggregation, relatedResultLimit)
+
+	productIds := []string{}
+	for _, productEmbedding := range embedding {
+		productIds = append(productIds, productEmbedding.ProductId)
+	}
+	if len(productIds) == 0 {
+		return []entities.Product{}, nil
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	products = orderProductsByIDs(products, productIds)
+	if err = s.loadImages(ctx, products); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadRatings(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (s *productService) findBoughtTogether(ctx context.Context, id string) ([]entities.Product, error) {
+	products, err := s.productRepo.FindBoughtTogether(ctx, id, relatedResultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.loadImages(ctx, products); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadRatings(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// loadImages fills in the gallery of each product with a single query.
+func (s *productService) loadImages(ctx context.Context, products []entities.Product) error {
+	productIds := make([]string, len(products))
+	for i, product := range products {
+		productIds[i] = product.ID
+	}
+
+	images, err := s.imageRepo.FindByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	byProduct := map[string][]entities.ProductImage{}
+	for _, image := range images {
+		byProduct[image.ProductID] = append(byProduct[image.ProductID], image)
+	}
+	for i := range products {
+		products[i].Images = byProduct[products[i].ID]
+	}
+
+	return nil
+}
+
+// loadVariants fills in the options and variants of each product.
+func (s *productService) loadVariants(ctx context.Context, products []entities.Product) error {
+	productIds := make([]string, len(products))
+	for i, product := range products {
+		productIds[i] = product.ID
+	}
+
+	options, err := s.variantRepo.FindOptionsByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	variants, err := s.variantRepo.FindByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	optionsByProduct := map[string][]entities.ProductOption{}
+	for _, option := range options {
+		optionsByProduct[option.ProductID] = append(optionsByProduct[option.ProductID], option)
+	}
+	variantsByProduct := map[string][]entities.ProductVariant{}
+	for _, variant := range variants {
+		variantsByProduct[variant.ProductID] = append(variantsByProduct[variant.ProductID], variant)
+	}
+	for i := range products {
+		products[i].Options = optionsByProduct[products[i].ID]
+		products[i].Variants = variantsByProduct[products[i].ID]
+	}
+
+	return nil
+}
+
+// loadDiscounts sets the discount running for each product, the one giving
+// the lowest price when several run at once.
+func (s *productService) loadDiscounts(ctx context.Context, products []entities.Product) error {
+	productIds := make([]string, len(products))
+	for i, product := range products {
+		productIds[i] = product.ID
+	}
+
+	discounts, err := s.discountRepo.FindRunningByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	byProduct := map[string][]entities.Discount{}
+	for _, discount := range discounts {
+		byProduct[discount.AppliesTo] = append(byProduct[discount.AppliesTo], discount.Discount)
+	}
+	for i := range products {
+		products[i].Discount = pricing.Best(products[i].Price, byProduct[products[i].ID])
+	}
+
+	return nil
+}
+
+// loadRatings sets the average rating and review count of each product.
+func (s *productService) loadRatings(ctx context.Context, products []entities.Product) error {
+	productIds := make([]string, len(products))
+	for i, product := range products {
+		productIds[i] = product.ID
+	}
+
+	ratings, err := s.reviewRepo.FindRatingsByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	byProduct := map[string]entities.ProductRating{}
+	for _, rating := range ratings {
+		byProduct[rating.ProductID] = rating
+	}
+	for i := range products {
+		products[i].Rating = byProduct[products[i].ID]
+		products[i].Rating.ProductID = products[i].ID
+	}
+
+	return nil
+}
+
+// reviewSummary gives the model the rating and the latest reviews of the
+// product. It is best effort, failures leave the prompt as it is.
+func (s *productService) reviewSummary(ctx context.Context, product *entities.Product) string {
+	log := logger.NewLog("product_service_review_summary", s.cfg.Logger.Enable)
+
+	products := []entities.Product{*product}
+	if err := s.loadRatings(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product rating: %v", err))
+		return ""
+	}
+
+	filter := repositories.ProductReviewFilter{ProductID: product.ID, WithBody: true}
+	recent, err := s.reviewRepo.Find(ctx, filter, reviewSummaryLimit, 0)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching reviews: %v", err))
+		return ""
+	}
+
+	return "\n\nUlasan pembeli " + product.Name + ":\n" + reviews.Summary(products[0].Rating, recent)
+}
+
+// findFAQs returns the FAQ entries for the product or its whole store whose
+// question is closest to the one of the customer. It is best effort,
+// failures leave the answer to the model.
+func (s *productService) findFAQs(ctx context.Context, merchantId, productId, question string) []entities.ProductFAQ {
+	log := logger.NewLog("product_service_find_faqs", s.cfg.Logger.Enable)
+
+	emb, err := s.llm.EmbedQuery(ctx, question)
+	if err != nil {
+		log.Error(fmt.Sprintf("error embedding question: %v", err))
+		return nil
+	}
+
+	faqs, err := s.faqRepo.FindMatches(ctx, emb, merchantId, productId, faq.MaxEntries)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching faqs: %v", err))
+		return nil
+	}
+
+	return faqs
+}
+
+// saveToWishlist adds the product the customer is looking at to their
+// wishlist and tells how it went.
+func (s *productService) saveToWishlist(ctx context.Context, req *dto.AskProduct) string {
+	log := logger.NewLog("product_service_save_to_wishlist", s.cfg.Logger.Enable)
+
+	if req.CustomerID == "" {
+		return "Login dulu ya, supaya produknya bisa disimpan ke wishlist kamu."
+	}
+	if req.ProductID == "" {
+		return "Produk mana yang mau disimpan ke wishlist? Buka dulu produknya, lalu minta simpan lagi ya."
+	}
+
+	result := s.wishlist.Add(ctx, req.CustomerID, req.ProductID)
+	switch {
+	case result.Errors == nil:
+		return "Sudah disimpan ke wishlist. Nanti kami kabari kalau harganya turun atau stoknya tersedia lagi."
+	case result.Code == 404:
+		return "Maaf, produk itu sudah tidak tersedia, jadi belum bisa disimpan ke wishlist."
+	}
+
+	log.Error(fmt.Sprintf("error saving to wishlist: %v", result.Errors))
+	return "Maaf, wishlist sedang tidak bisa disimpan. Coba lagi sebentar lagi ya."
+}
+
+// addToCart puts the product the customer is looking at, in the variant
+// they picked, in their cart and tells how it went. It reports whether a
+// variant has to be chosen first.
+func (s *productService) addToCart(ctx context.Context, req *dto.AskProduct) (string, bool) {
+	log := logger.NewLog("product_service_add_to_cart", s.cfg.Logger.Enable)
+
+	if req.CustomerID == "" {
+		return "Login dulu ya, supaya produknya bisa dimasukkan ke keranjang kamu.", false
+	}
+	if req.ProductID == "" {
+		return "Produk mana yang mau dimasukkan ke keranjang? Buka dulu produknya, lalu minta lagi ya.", false
+	}
+
+	if req.VariantID == "" {
+		count, err := s.variantRepo.CountByProductID(ctx, req.ProductID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error counting variants: %v", err))
+			return "Maaf, keranjang sedang tidak bisa diubah. Coba lagi sebentar lagi ya.", false
+		}
+		if count > 0 {
+			return "Produk ini punya beberapa pilihan. Pilih dulu variannya, lalu minta masukin keranjang lagi ya.", true
+		}
+	}
+
+	result := s.cart.AddItem(ctx, req.CustomerID, &dto.AddCartItemRequest{ProductID: req.ProductID, VariantID: req.VariantID})
+	switch {
+	case result.Errors == nil:
+		return "Sudah masuk keranjang. Checkout kapan saja, pesanan dari beberapa toko dibuat sekaligus.", false
+	case result.Code == 404:
+		return "Maaf, produk itu sudah tidak tersedia, jadi belum bisa dimasukkan ke keranjang.", false
+	case result.Code == 400:
+		return "Maaf, stoknya tidak cukup untuk ditambahkan ke keranjang.", false
+	}
+
+	log.Error(fmt.Sprintf("error adding to cart: %v", result.Errors))
+	return "Maaf, keranjang sedang tidak bisa diubah. Coba lagi sebentar lagi ya.", false
+}
+
+// attachVariants lists the options still in stock for the product the
+// customer is looking at, which answers questions like "ada warna lain?".
+// It is best effort, failures leave the answer as it is.
+func (s *productService) attachVariants(ctx context.Context, data *dto.LLMResponse, productId string) {
+	log := logger.NewLog("product_service_attach_variants", s.cfg.Logger.Enable)
+
+	product, err := s.productRepo.FindOneById(ctx, productId)
+	if err != nil || product == nil {
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching product: %v", err))
+		}
+		return
+	}
+
+	products := []entities.Product{*product}
+	if err = s.loadVariants(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product variants: %v", err))
+		return
+	}
+	if err = s.loadDiscounts(ctx, products); err != nil {
+		log.Error(fmt.Sprintf("error fetching product discounts: %v", err))
+	}
+	product = &products[0]
+
+	if len(product.Variants) == 0 {
+		return
+	}
+
+	variants := dto.ToProductVariantsResponse(product)
+	data.Variants = &variants
+	data.Message += "\n\n" + variantSummary(product)
+}
+
+// attachRelated offers similar and frequently bought together products
+// alongside an answer about the product the customer is looking at. It is
+// best effort, failures leave the answer as it is.
+func (s *productService) attachRelated(ctx context.Context, data *dto.LLMResponse, productId string) {
+	log := logger.NewLog("product_service_attach_related", s.cfg.Logger.Enable)
+
+	similar, err := s.findSimilar(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching similar products: %v", err))
+	}
+
+	boughtTogether, err := s.findBoughtTogether(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching bought together products: %v", err))
+	}
+
+	if len(similar) > 0 {
+		data.Similar = dto.ToProductResponses(similar)
+	}
+	if len(boughtTogether) > 0 {
+		data.BoughtTogether = dto.ToProductResponses(boughtTogether)
+	}
+
+	switch {
+	case len(similar) > 0 && len(boughtTogether) > 0:
+		data.Message += "\n\nAnda juga bisa melihat produk serupa, atau produk yang sering dibeli bersamaan dengan produk ini."
+	case len(similar) > 0:
+		data.Message += "\n\nAnda juga bisa melihat produk serupa di bawah ini."
+	case len(boughtTogether) > 0:
+		data.Message += "\n\nProduk ini sering dibeli bersamaan dengan produk di bawah ini."
+	}
+}
+
+func (s *productService) Update(ctx context.Context, merchantId, id string, req *dto.ProductRequest) *presenter.Response {
 	var (
 		response = presenter.Response{}
 		log      = logger.NewLog("product_service_update", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("fetching product with id: %d", id))
+	log.Info(fmt.Sprintf("fetching product with id: %s", id))
 	product, err := s.productRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching product: %v", err))
 		return response.WithCode(500).WithError(errors.New("something went wrong"))
 	}
 
-	if product == nil {
+	// Products of other merchants are reported as missing
+	if product == nil || product.MerchantID != merchantId {
 		log.Warn("product not found")
 		return response.WithCode(404).WithError(errors.New("product not found"))
 	}
 
+	if product.Status == entities.ProductStatusArchived {
+		return response.WithCode(409).WithError(errors.New("product is archived, restore it first"))
+	}
+
 	product.Name = req.Name
 	product.Description = stringPtr(req.Description)
 	product.SKU = stringPtr(req.SKU)
 	product.Price = req.Price
-	product.Stock = req.Stock
 	product.OutletID = req.OutletID
 	product.CategoryID = req.CategoryID
-
+	product.Weight = req.Weight
+	product.Length = req.Length
+	product.Width = req.Width
+	product.Height = req.Height
+	product.LowStockThreshold = req.LowStockThreshold
+	product.PublishAt = req.PublishAt
+	product.UnpublishAt = req.UnpublishAt
+
+	// As on create, a product scheduled for later waits as a draft
+	if req.PublishAt != nil {
+		product.Status = entities.ProductStatusDraft
+	}
 	if req.Status != "" {
 		product.Status = req.Status
 	}
 
+	if resp := checkLifecycle(product); resp != nil {
+		return resp
+	}
+
+	// The stock of a product with variants is the total of its variants
+	variantCount, err := s.variantRepo.CountByProductID(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting product variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if variantCount == 0 {
+		product.Stock = req.Stock
+	}
+
+	if resp := s.checkCategory(ctx, product.CategoryID); resp != nil {
+		return resp
+	}
+
+	// Attributes are kept when omitted, but still checked against the
+	// schema of the category
+	if req.Attributes != nil {
+		product.Attributes = req.Attributes
+	}
+	var resp *presenter.Response
+	if product.Attributes, resp = s.checkAttributes(ctx, product.CategoryID, product.Attributes); resp != nil {
+		return resp
+	}
+
 	log.Info("updating product")
-	updated, err := s.productRepo.Update(ctx, product)
+	updated, err := s.productRepo.Update(ctx, product, stringPtr(req.UserID))
 	if err != nil {
 		log.Error(fmt.Sprintf("error updating product: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to update product"))
 	}
 
+	if err = s.embedProduct(ctx, updated); err != nil {
+		log.Error(fmt.Sprintf("error embedding product: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to update product"))
+	}
+
 	data := dto.ToProductResponse(updated)
 	return response.WithCode(200).WithData(data)
 }
 
-func (s *productService) Delete(ctx context.Context, id string) *presenter.Response {
+func (s *productService) Delete(ctx context.Context, merchantId, id string) *presenter.Response {
 	var (
 		response = presenter.Response{}
 		log      = logger.NewLog("product_service_delete", s.cfg.Logger.Enable)
 	)
 
-	log.Info(fmt.Sprintf("checking if product exists: %d", id))
+	log.Info(fmt.Sprintf("checking if product exists: %s", id))
 	product, err := s.productRepo.FindOneById(ctx, id)
 	if err != nil {
 		log.Error(fmt.Sprintf("error fetching product: %v", err))
 		return response.WithCode(500).WithError(errors.New("something went wrong"))
 	}
 
-	if product == nil {
+	if product == nil || product.MerchantID != merchantId {
 		log.Warn("product not found")
 		return response.WithCode(404).WithError(errors.New("product not found"))
 	}
 
-	log.Info("deleting product")
-	err = s.productRepo.Delete(ctx, id)
+	// Orders keep referring to the product, so it is archived rather than
+	// removed
+	log.Info("archiving product")
+	err = s.productRepo.Archive(ctx, id)
 	if err != nil {
-		log.Error(fmt.Sprintf("error deleting product: %v", err))
+		log.Error(fmt.Sprintf("error archiving product: %v", err))
 		return response.WithCode(500).WithError(errors.New("failed to delete product"))
 	}
 
 	return response.WithCode(200).WithData(map[string]string{"message": "product deleted successfully"})
 }
 
-func formatProductForEmbedding(p entities.Product) string {
-	return fmt.Sprintf(`
-Nama: %s
-Deskripsi: %s
-Harga: %.0f
-Brand: %s
-`,
-		p.Name,
-		ifnil(p.Description),
-		p.Price,
-		p.Name,
+// Restore brings back a deleted product of the merchant. It returns as
+// inactive, to be published again by the merchant.
+func (s *productService) Restore(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_service_restore", s.cfg.Logger.Enable)
 	)
+
+	product, err := s.productRepo.FindOneById(ctx, id)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != merchantId {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+	if product.Status != entities.ProductStatusArchived {
+		return response.WithCode(400).WithError(errors.New("product is not deleted"))
+	}
+
+	if err = s.productRepo.Restore(ctx, id); err != nil {
+		log.Error(fmt.Sprintf("error restoring product: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to restore product"))
+	}
+
+	restored, err := s.productRepo.FindOneById(ctx, id)
+	if err != nil || restored == nil {
+		log.Error(fmt.Sprintf("error fetching restored product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	data := dto.ToProductResponse(restored)
+	return response.WithCode(200).WithData(data)
 }
 
-func ifnil(s *string) string {
-	if s == nil {
-		return ""
+// Reembed rebuilds the embedding documents of a product, e.g. after the
+// document builder configuration has changed.
+func (s *productService) Reembed(ctx context.Context, id string) error {
+	product, err := s.productRepo.FindOneById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("product not found")
+	}
+
+	return s.embedProduct(ctx, product)
+}
+
+// checkCategory returns an error response when the product is assigned to a
+// category that does not exist.
+func (s *productService) checkCategory(ctx context.Context, categoryId *string) *presenter.Response {
+	if categoryId == nil || *categoryId == "" {
+		return nil
 	}
-	return *s
+
+	response := presenter.Response{}
+	log := logger.NewLog("product_service_check_category", s.cfg.Logger.Enable)
+
+	category, err := s.categoryRepo.FindOneById(ctx, *categoryId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching category: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if category == nil {
+		return response.WithCode(400).WithError(errors.New("category not found"))
+	}
+
+	return nil
+}
+
+// checkLifecycle returns an error response when the product is given a
+// status merchants cannot set directly, or a schedule that ends before it
+// starts. Archiving is done by deleting the product.
+func checkLifecycle(product *entities.Product) *presenter.Response {
+	response := presenter.Response{}
+
+	switch product.Status {
+	case entities.ProductStatusDraft, entities.ProductStatusActive, entities.ProductStatusInactive:
+	default:
+		return response.WithCode(400).WithError(errors.New("status must be draft, active or inactive"))
+	}
+
+	if product.PublishAt != nil && product.UnpublishAt != nil && !product.UnpublishAt.After(*product.PublishAt) {
+		return response.WithCode(400).WithError(errors.New("unpublish_at must be after publish_at"))
+	}
+
+	return nil
+}
+
+// checkAttributes validates the attributes against the schema of the
+// category. It returns them normalized, or an error response.
+func (s *productService) checkAttributes(ctx context.Context, categoryId *string, values entities.ProductAttributes) (entities.ProductAttributes, *presenter.Response) {
+	response := presenter.Response{}
+	log := logger.NewLog("product_service_check_attributes", s.cfg.Logger.Enable)
+
+	schema, err := s.attributeSchema(ctx, categoryId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching attribute schema: %v", err))
+		return nil, response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	values, err = attributes.Validate(values, schema)
+	if err != nil {
+		return nil, response.WithCode(400).WithError(err)
+	}
+
+	return values, nil
+}
+
+// attributeSchema returns the attribute schema of the category merged with
+// the schemas of its ancestors.
+func (s *productService) attributeSchema(ctx context.Context, categoryId *string) (entities.AttributeSchema, error) {
+	if categoryId == nil || *categoryId == "" {
+		return nil, nil
+	}
+
+	ancestors, err := s.categoryRepo.FindAncestors(ctx, *categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]entities.AttributeSchema, len(ancestors))
+	for i, category := range ancestors {
+		schemas[i] = category.AttributeSchema
+	}
+
+	return attributes.Merge(schemas...), nil
+}
+
+// embedProduct replaces the embeddings of a product with one embedding per
+// document produced by the document builder. Every document is embedded
+// before the old embeddings are replaced, so a failure keeps them.
+func (s *productService) embedProduct(ctx context.Context, product *entities.Product) error {
+	input := productdoc.Input{
+		Product:      *product,
+		CategoryPath: productdoc.CategoryPath(product.Category),
+	}
+
+	if product.CategoryID != nil {
+		ancestors, err := s.categoryRepo.FindAncestors(ctx, *product.CategoryID)
+		if err != nil {
+			return err
+		}
+
+		schemas := make([]entities.AttributeSchema, len(ancestors))
+		for i, category := range ancestors {
+			schemas[i] = category.AttributeSchema
+			if product.Category == nil {
+				input.CategoryPath = append(input.CategoryPath, category.Name)
+			}
+		}
+		input.AttributeSchema = attributes.Merge(schemas...)
+	}
+
+	merchant, err := s.merchantRepo.FindOneById(ctx, product.MerchantID)
+	if err != nil {
+		return err
+	}
+	if merchant != nil {
+		input.MerchantName = merchant.Name
+	}
+
+	if input.Product.Options == nil {
+		input.Product.Options, err = s.variantRepo.FindOptionsByProductID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	docs := s.docBuilder.Build(input)
+	embeddings, err := s.llm.EmbedDocuments(ctx, docs)
+	if err != nil {
+		return err
+	}
+	if len(embeddings) != len(docs) {
+		return fmt.Errorf("got %d embeddings for %d documents", len(embeddings), len(docs))
+	}
+
+	chunks := make([]entities.ProductEmbedding, len(docs))
+	for i, doc := range docs {
+		chunks[i] = entities.ProductEmbedding{
+			ProductId:  product.ID,
+			ChunkIndex: i,
+			Content:    doc,
+			Embedding:  embeddings[i],
+		}
+	}
+
+	return s.productRepo.ReplaceProductEmbeddings(ctx, product.ID, chunks)
+}
+
+// orderProductsByIDs restores the ranking of ids, since FindByIDs returns
+// products ordered by creation time.
+func orderProductsByIDs(products []entities.Product, ids []string) []entities.Product {
+	byID := make(map[string]entities.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	ordered := make([]entities.Product, 0, len(products))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			ordered = append(ordered, p)
+			delete(byID, id)
+		}
+	}
+
+	return ordered
+}
+
+// variantSummary describes the option values that still have stock, e.g.
+// "Pilihan yang tersedia: Warna: Hitam, Putih; Ukuran: M, L."
+func variantSummary(product *entities.Product) string {
+	inStock := map[string]map[string]bool{}
+	for _, variant := range product.Variants {
+		if variant.Stock <= 0 {
+			continue
+		}
+		for name, value := range variant.Options {
+			if inStock[name] == nil {
+				inStock[name] = map[string]bool{}
+			}
+			inStock[name][value] = true
+		}
+	}
+
+	if len(inStock) == 0 {
+		return "Semua pilihan untuk produk ini sedang habis."
+	}
+
+	parts := []string{}
+	for _, option := range product.Options {
+		values := []string{}
+		for _, value := range option.Values {
+			if inStock[option.Name][value] {
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			parts = append(parts, option.Name+": "+strings.Join(values, ", "))
+		}
+	}
+
+	return "Pilihan yang tersedia: " + strings.Join(parts, "; ") + "."
 }
 
 func extractBudget(s string) float64 {
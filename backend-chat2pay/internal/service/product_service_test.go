@@ -0,0 +1,262 @@
+package service
+
+import (
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/entities"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProduct(id, status string) entities.Product {
+	return entities.Product{ID: id, MerchantID: "merchant-1", Name: "Produk " + id, Price: 100000, Stock: 5, Status: status}
+}
+
+func responseIDs(t *testing.T, data interface{}) []string {
+	products, ok := data.([]dto.ProductResponse)
+	require.True(t, ok)
+	ids := []string{}
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func TestProductService_GetSimilar(t *testing.T) {
+	catalog := []entities.Product{
+		testProduct("laptop", entities.ProductStatusActive),
+		testProduct("draft", entities.ProductStatusDraft),
+		testProduct("mouse", entities.ProductStatusActive),
+		testProduct("tas", entities.ProductStatusActive),
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		similar []entities.ProductEmbedding
+		code    int
+		want    []string
+	}{
+		{
+			name: "Ranks the neighbours by their best chunk",
+			id:   "laptop",
+			similar: []entities.ProductEmbedding{
+				{ProductId: "tas", Similarity: 0.91},
+				{ProductId: "mouse", Similarity: 0.85},
+				{ProductId: "tas", Similarity: 0.40},
+			},
+			code: 200,
+			want: []string{"tas", "mouse"},
+		},
+		{
+			name: "No neighbours",
+			id:   "laptop",
+			code: 200,
+			want: []string{},
+		},
+		{
+			name: "Unknown product",
+			id:   "missing",
+			code: 404,
+		},
+		{
+			name:    "Draft product",
+			id:      "draft",
+			similar: []entities.ProductEmbedding{{ProductId: "mouse", Similarity: 0.9}},
+			code:    404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo(catalog...)
+			repo.similar = tt.similar
+
+			response := newTestProductService(repo).GetSimilar(context.Background(), tt.id)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "product not found")
+				return
+			}
+			assert.Equal(t, tt.want, responseIDs(t, response.Data))
+		})
+	}
+}
+
+func TestProductService_GetBoughtTogether(t *testing.T) {
+	catalog := []entities.Product{
+		testProduct("kopi", entities.ProductStatusActive),
+		testProduct("arsip", entities.ProductStatusArchived),
+	}
+	together := []entities.Product{
+		testProduct("gula", entities.ProductStatusActive),
+		testProduct("susu", entities.ProductStatusActive),
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		code int
+		want []string
+	}{
+		{name: "Keeps the order of the co-occurrence ranking", id: "kopi", code: 200, want: []string{"gula", "susu"}},
+		{name: "Unknown product", id: "missing", code: 404},
+		{name: "Archived product", id: "arsip", code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo(catalog...)
+			repo.boughtTogether = together
+
+			response := newTestProductService(repo).GetBoughtTogether(context.Background(), tt.id)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "product not found")
+				return
+			}
+			assert.Equal(t, tt.want, responseIDs(t, response.Data))
+		})
+	}
+}
+
+func TestProductService_GetAll_Category(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		code     int
+		want     []string
+	}{
+		{name: "Includes the subcategories", category: "elektronik", code: 200, want: []string{"elektronik", "komputer", "laptop"}},
+		{name: "A leaf", category: "laptop", code: 200, want: []string{"laptop"}},
+		{name: "No category filter", code: 200},
+		{name: "Unknown category", category: "missing", code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo()
+			service := newTestProductService(repo)
+			service.categoryRepo = testCategories()
+
+			response := service.GetAll(context.Background(), &dto.ProductListQuery{CategoryID: tt.category}, 1, 10)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "category not found")
+				assert.Nil(t, repo.searched)
+				return
+			}
+			require.NotNil(t, repo.searched)
+			assert.Equal(t, tt.want, repo.searched.CategoryIDs)
+		})
+	}
+}
+
+func TestProductService_Update_Category(t *testing.T) {
+	tests := []struct {
+		name     string
+		category *string
+		code     int
+	}{
+		{name: "Assigns a known category", category: stringPtr("laptop"), code: 200},
+		{name: "Clears the category", code: 200},
+		{name: "Rejects an unknown category", category: stringPtr("missing"), code: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo(testProduct("laptop-1", entities.ProductStatusActive))
+			service := newTestProductService(repo)
+			service.categoryRepo = testCategories()
+
+			req := &dto.ProductRequest{Name: "Laptop", Price: 100000, Stock: 5, CategoryID: tt.category}
+			response := service.Update(context.Background(), "merchant-1", "laptop-1", req)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "category not found")
+				assert.Nil(t, repo.products["laptop-1"].CategoryID)
+				return
+			}
+			assert.Equal(t, tt.category, repo.products["laptop-1"].CategoryID)
+		})
+	}
+}
+
+func TestProductService_AskProduct_ChatMisses(t *testing.T) {
+	tests := []struct {
+		name    string
+		intent  string
+		matches []entities.ProductEmbedding
+		want    []string
+	}{
+		{name: "A search with nothing in stock", intent: "specific_product_search", want: []string{"sold-out"}},
+		{
+			name:    "A search with products in stock",
+			intent:  "specific_product_search",
+			matches: []entities.ProductEmbedding{{ProductId: "laptop", Similarity: 0.9}},
+		},
+		{name: "Chit chat", intent: "chit_chat"},
+		{name: "A general request", intent: "general_product_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo(testProduct("laptop", entities.ProductStatusActive))
+			repo.matches = tt.matches
+			repo.outOfStock = []string{"sold-out"}
+			alerts := &fakeAlertRepo{}
+
+			service := newTestProductService(repo)
+			service.llm = &fakeLLM{intent: tt.intent}
+			service.alertRepo = alerts
+
+			response := service.AskProduct(context.Background(), &dto.AskProduct{Prompt: "laptop gaming"})
+
+			require.Equal(t, 200, response.Code)
+			assert.Equal(t, tt.want, alerts.misses)
+		})
+	}
+}
+
+func TestProductService_Update_Schedule(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		status      string
+		publishAt   *time.Time
+		unpublishAt *time.Time
+		code        int
+		want        string
+	}{
+		{name: "Scheduled without a status waits as a draft", publishAt: &tomorrow, code: 200, want: entities.ProductStatusDraft},
+		{name: "Scheduled with a status keeps it", status: entities.ProductStatusInactive, publishAt: &tomorrow, code: 200, want: entities.ProductStatusInactive},
+		{name: "Not scheduled keeps the current status", code: 200, want: entities.ProductStatusActive},
+		{name: "Unpublished before it is published", publishAt: &tomorrow, unpublishAt: &yesterday, code: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeProductRepo(testProduct("laptop-1", entities.ProductStatusActive))
+
+			req := &dto.ProductRequest{Name: "Laptop", Price: 100000, Stock: 5, Status: tt.status, PublishAt: tt.publishAt, UnpublishAt: tt.unpublishAt}
+			response := newTestProductService(repo).Update(context.Background(), "merchant-1", "laptop-1", req)
+
+			require.Equal(t, tt.code, response.Code)
+			if tt.code != 200 {
+				assert.EqualError(t, response.Errors, "unpublish_at must be after publish_at")
+				assert.Equal(t, entities.ProductStatusActive, repo.products["laptop-1"].Status)
+				return
+			}
+			assert.Equal(t, tt.want, repo.products["laptop-1"].Status)
+		})
+	}
+}
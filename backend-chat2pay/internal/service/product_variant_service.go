@@ -0,0 +1,210 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ProductVariantService interface {
+	GetAll(ctx context.Context, productId string) *presenter.Response
+	Save(ctx context.Context, merchantId, userId, productId string, req *dto.SaveVariantsRequest) *presenter.Response
+}
+
+type productVariantService struct {
+	variantRepo    repositories.ProductVariantRepository
+	productRepo    repositories.ProductRepository
+	productService ProductService
+	cfg            *yaml.Config
+}
+
+func NewProductVariantService(
+	variantRepo repositories.ProductVariantRepository,
+	productRepo repositories.ProductRepository,
+	productService ProductService,
+	cfg *yaml.Config,
+) ProductVariantService {
+	return &productVariantService{
+		variantRepo:    variantRepo,
+		productRepo:    productRepo,
+		productService: productService,
+		cfg:            cfg,
+	}
+}
+
+func (s *productVariantService) GetAll(ctx context.Context, productId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_variant_service_getall", s.cfg.Logger.Enable)
+	)
+
+	product, err := s.productRepo.FindActiveById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	if product.Options, err = s.variantRepo.FindOptionsByProductID(ctx, productId); err != nil {
+		log.Error(fmt.Sprintf("error fetching options: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch variants"))
+	}
+	if product.Variants, err = s.variantRepo.FindByProductID(ctx, productId); err != nil {
+		log.Error(fmt.Sprintf("error fetching variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch variants"))
+	}
+
+	data := dto.ToProductVariantsResponse(product)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *productVariantService) Save(ctx context.Context, merchantId, userId, productId string, req *dto.SaveVariantsRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("product_variant_service_save", s.cfg.Logger.Enable)
+	)
+
+	product, err := s.productRepo.FindOneById(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != merchantId {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	existing, err := s.variantRepo.FindByProductID(ctx, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	existingIds := map[string]bool{}
+	for _, variant := range existing {
+		existingIds[variant.ID] = true
+	}
+
+	options, variants, err := buildVariants(req, existingIds)
+	if err != nil {
+		return response.WithCode(400).WithError(err)
+	}
+
+	if err = s.variantRepo.Save(ctx, productId, options, variants, stringPtr(userId)); err != nil {
+		log.Error(fmt.Sprintf("error saving variants: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to save variants"))
+	}
+
+	// Option values are part of the embedded document
+	if err = s.productService.Reembed(ctx, productId); err != nil {
+		log.Error(fmt.Sprintf("error embedding product: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to save variants"))
+	}
+
+	return s.GetAll(ctx, productId)
+}
+
+// buildVariants validates the request and turns it into entities. Every
+// variant must pick exactly one listed value of every option, and no two
+// variants may pick the same combination.
+func buildVariants(req *dto.SaveVariantsRequest, existingIds map[string]bool) ([]entities.ProductOption, []entities.ProductVariant, error) {
+	if len(req.Options) == 0 && len(req.Variants) > 0 {
+		return nil, nil, errors.New("variants need at least one option")
+	}
+
+	options := make([]entities.ProductOption, 0, len(req.Options))
+	allowed := map[string]map[string]bool{}
+	for _, o := range req.Options {
+		name := strings.TrimSpace(o.Name)
+		if name == "" {
+			return nil, nil, errors.New("option name is required")
+		}
+		if allowed[name] != nil {
+			return nil, nil, fmt.Errorf("option %s is listed twice", name)
+		}
+
+		values := []string{}
+		allowed[name] = map[string]bool{}
+		for _, v := range o.Values {
+			v = strings.TrimSpace(v)
+			if v == "" || allowed[name][v] {
+				continue
+			}
+			allowed[name][v] = true
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil, nil, fmt.Errorf("option %s needs at least one value", name)
+		}
+
+		options = append(options, entities.ProductOption{Name: name, Values: values})
+	}
+
+	variants := make([]entities.ProductVariant, 0, len(req.Variants))
+	combinations := map[string]bool{}
+	skus := map[string]bool{}
+	for _, v := range req.Variants {
+		if v.ID != "" && !existingIds[v.ID] {
+			return nil, nil, fmt.Errorf("variant %s does not belong to this product", v.ID)
+		}
+		if len(v.Options) != len(options) {
+			return nil, nil, errors.New("each variant must choose a value for every option")
+		}
+
+		chosen := entities.VariantOptions{}
+		names := make([]string, len(options))
+		for i, option := range options {
+			value := strings.TrimSpace(v.Options[option.Name])
+			if !allowed[option.Name][value] {
+				return nil, nil, fmt.Errorf("invalid value %q for option %s", value, option.Name)
+			}
+			chosen[option.Name] = value
+			names[i] = value
+		}
+
+		name := strings.Join(names, " / ")
+		if combinations[name] {
+			return nil, nil, fmt.Errorf("variant %s is listed twice", name)
+		}
+		combinations[name] = true
+
+		if v.Stock < 0 {
+			return nil, nil, fmt.Errorf("stock of variant %s cannot be negative", name)
+		}
+		if v.Price != nil && *v.Price <= 0 {
+			return nil, nil, fmt.Errorf("price of variant %s must be greater than 0", name)
+		}
+		if v.Weight != nil && *v.Weight < 0 {
+			return nil, nil, fmt.Errorf("weight of variant %s cannot be negative", name)
+		}
+
+		sku := strings.TrimSpace(v.SKU)
+		if sku != "" {
+			if skus[sku] {
+				return nil, nil, fmt.Errorf("sku %s is used by more than one variant", sku)
+			}
+			skus[sku] = true
+		}
+
+		variants = append(variants, entities.ProductVariant{
+			ID:       v.ID,
+			Name:     name,
+			Options:  chosen,
+			SKU:      stringPtr(sku),
+			Price:    v.Price,
+			Stock:    v.Stock,
+			Weight:   v.Weight,
+			ImageURL: stringPtr(strings.TrimSpace(v.ImageURL)),
+		})
+	}
+
+	return options, variants, nil
+}
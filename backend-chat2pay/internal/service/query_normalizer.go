@@ -0,0 +1,56 @@
+package service
+
+import (
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/querynorm"
+	"chat2pay/internal/repositories"
+	"context"
+)
+
+// queryNormalizer rewrites search queries before they reach the embedding
+// model or the keyword search: slang and synonyms are expanded with the
+// dictionaries, then misspelled words get the closest product name word
+// appended.
+type queryNormalizer struct {
+	productRepo repositories.ProductRepository
+	synonymRepo repositories.SearchSynonymRepository
+}
+
+func newQueryNormalizer(productRepo repositories.ProductRepository, synonymRepo repositories.SearchSynonymRepository) *queryNormalizer {
+	return &queryNormalizer{
+		productRepo: productRepo,
+		synonymRepo: synonymRepo,
+	}
+}
+
+// Normalize returns the rewritten query. A merchant scoped search puts that
+// merchant's entries ahead of the global dictionary. A search across all
+// merchants only uses the global dictionary, so no merchant can change how
+// the other shops are found.
+func (n *queryNormalizer) Normalize(ctx context.Context, merchantId, query string) (string, error) {
+	dictionaries := []querynorm.Dictionary{querynorm.DefaultDictionary}
+	if merchantId != "" {
+		synonyms, err := n.synonymRepo.FindByMerchantID(ctx, merchantId)
+		if err != nil {
+			return query, err
+		}
+		dictionaries = []querynorm.Dictionary{toDictionary(synonyms), querynorm.DefaultDictionary}
+	}
+
+	result := querynorm.Normalize(query, dictionaries...)
+
+	corrections, err := n.productRepo.CorrectTerms(ctx, merchantId, querynorm.TypoCandidates(result.Unmatched))
+	if err != nil {
+		return result.Query, err
+	}
+
+	return querynorm.WithCorrections(result.Query, corrections), nil
+}
+
+func toDictionary(synonyms []entities.SearchSynonym) querynorm.Dictionary {
+	dict := make(querynorm.Dictionary, len(synonyms))
+	for i, s := range synonyms {
+		dict[i] = querynorm.Entry{Term: s.Term, Synonyms: s.Synonyms}
+	}
+	return dict
+}
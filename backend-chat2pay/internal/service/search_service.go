@@ -0,0 +1,131 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/querynorm"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type SearchService interface {
+	GetSynonyms(ctx context.Context, merchantId string) *presenter.Response
+	CreateSynonym(ctx context.Context, merchantId string, req *dto.SynonymRequest) *presenter.Response
+	DeleteSynonym(ctx context.Context, merchantId, id string) *presenter.Response
+	NormalizeQuery(ctx context.Context, merchantId, query string) *presenter.Response
+}
+
+type searchService struct {
+	synonymRepo repositories.SearchSynonymRepository
+	normalizer  *queryNormalizer
+	cfg         *yaml.Config
+}
+
+func NewSearchService(
+	synonymRepo repositories.SearchSynonymRepository,
+	productRepo repositories.ProductRepository,
+	cfg *yaml.Config,
+) SearchService {
+	return &searchService{
+		synonymRepo: synonymRepo,
+		normalizer:  newQueryNormalizer(productRepo, synonymRepo),
+		cfg:         cfg,
+	}
+}
+
+func (s *searchService) GetSynonyms(ctx context.Context, merchantId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("search_service_get_synonyms", s.cfg.Logger.Enable)
+	)
+
+	synonyms, err := s.synonymRepo.FindByMerchantID(ctx, merchantId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching synonyms: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch synonyms"))
+	}
+
+	data := dto.ToSynonymListResponse(querynorm.DefaultDictionary, synonyms)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *searchService) CreateSynonym(ctx context.Context, merchantId string, req *dto.SynonymRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("search_service_create_synonym", s.cfg.Logger.Enable)
+	)
+
+	// Store terms the same way queries are tokenized so they can match
+	term := strings.Join(querynorm.Tokenize(req.Term), " ")
+	if term == "" {
+		return response.WithCode(400).WithError(errors.New("term is required"))
+	}
+
+	synonyms := []string{}
+	for _, synonym := range req.Synonyms {
+		synonym = strings.ToLower(strings.TrimSpace(synonym))
+		if synonym != "" && synonym != term {
+			synonyms = append(synonyms, synonym)
+		}
+	}
+	if len(synonyms) == 0 {
+		return response.WithCode(400).WithError(errors.New("at least one synonym is required"))
+	}
+
+	created, err := s.synonymRepo.Create(ctx, &entities.SearchSynonym{
+		MerchantID: merchantId,
+		Term:       term,
+		Synonyms:   synonyms,
+	})
+	if err != nil {
+		log.Error(fmt.Sprintf("error creating synonym: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to create synonym"))
+	}
+
+	data := dto.ToSynonymResponse(created)
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *searchService) DeleteSynonym(ctx context.Context, merchantId, id string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("search_service_delete_synonym", s.cfg.Logger.Enable)
+	)
+
+	deleted, err := s.synonymRepo.Delete(ctx, id, merchantId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error deleting synonym: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to delete synonym"))
+	}
+	if !deleted {
+		return response.WithCode(404).WithError(errors.New("synonym not found"))
+	}
+
+	return response.WithCode(200).WithData("deleted")
+}
+
+func (s *searchService) NormalizeQuery(ctx context.Context, merchantId, query string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("search_service_normalize_query", s.cfg.Logger.Enable)
+	)
+
+	if strings.TrimSpace(query) == "" {
+		return response.WithCode(400).WithError(errors.New("query is required"))
+	}
+
+	normalized, err := s.normalizer.Normalize(ctx, merchantId, query)
+	if err != nil {
+		log.Error(fmt.Sprintf("error normalizing query: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to normalize query"))
+	}
+
+	data := dto.NormalizeQueryResponse{Query: query, Normalized: normalized}
+	return response.WithCode(200).WithData(data)
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type StockService interface {
+	Adjust(ctx context.Context, merchantId, userId string, req *dto.StockAdjustmentRequest) *presenter.Response
+	GetMovements(ctx context.Context, merchantId string, filter repositories.StockMovementFilter, page, limit int) *presenter.Response
+	GetAlerts(ctx context.Context, merchantId string, filter repositories.StockAlertFilter, page, limit int) *presenter.Response
+	GetChatMisses(ctx context.Context, merchantId string, days, page, limit int) *presenter.Response
+}
+
+// chatMissDays is the default period of the sold out in chat report.
+const chatMissDays = 30
+
+type stockService struct {
+	stockRepo   repositories.StockMovementRepository
+	alertRepo   repositories.StockAlertRepository
+	productRepo repositories.ProductRepository
+	variantRepo repositories.ProductVariantRepository
+	outletRepo  repositories.OutletRepository
+	cfg         *yaml.Config
+}
+
+func NewStockService(
+	stockRepo repositories.StockMovementRepository,
+	alertRepo repositories.StockAlertRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	outletRepo repositories.OutletRepository,
+	cfg *yaml.Config,
+) StockService {
+	return &stockService{
+		stockRepo:   stockRepo,
+		alertRepo:   alertRepo,
+		productRepo: productRepo,
+		variantRepo: variantRepo,
+		outletRepo:  outletRepo,
+		cfg:         cfg,
+	}
+}
+
+// Adjust records a restock or a manual adjustment and changes the stock by
+// its quantity.
+func (s *stockService) Adjust(ctx context.Context, merchantId, userId string, req *dto.StockAdjustmentRequest) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("stock_service_adjust", s.cfg.Logger.Enable)
+	)
+
+	movementType := strings.ToLower(strings.TrimSpace(req.Type))
+	if movementType == "" {
+		movementType = entities.StockMovementAdjustment
+	}
+	switch {
+	case movementType != entities.StockMovementRestock && movementType != entities.StockMovementAdjustment:
+		return response.WithCode(400).WithError(errors.New("type must be restock or adjustment"))
+	case req.Quantity == 0:
+		return response.WithCode(400).WithError(errors.New("quantity cannot be 0"))
+	case movementType == entities.StockMovementRestock && req.Quantity < 0:
+		return response.WithCode(400).WithError(errors.New("restock quantity must be positive"))
+	}
+
+	product, err := s.productRepo.FindOneById(ctx, req.ProductID)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	if product == nil || product.MerchantID != merchantId {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	movement := entities.StockMovement{
+		ProductID: product.ID,
+		Type:      movementType,
+		Quantity:  req.Quantity,
+		ActorType: entities.StockActorMerchant,
+		ActorID:   stringPtr(userId),
+		Note:      stringPtr(strings.TrimSpace(req.Note)),
+	}
+
+	if req.VariantID != "" {
+		variant, err := s.variantRepo.FindOneById(ctx, req.VariantID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching variant: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		if variant == nil || variant.ProductID != product.ID {
+			return response.WithCode(404).WithError(errors.New("variant not found"))
+		}
+		movement.VariantID = &variant.ID
+	}
+
+	if req.OutletID != "" {
+		outlet, err := s.outletRepo.FindOneById(ctx, req.OutletID)
+		if err != nil {
+			log.Error(fmt.Sprintf("error fetching outlet: %v", err))
+			return response.WithCode(500).WithError(errors.New("something went wrong"))
+		}
+		if outlet == nil || outlet.MerchantID != merchantId {
+			return response.WithCode(404).WithError(errors.New("outlet not found"))
+		}
+		movement.OutletID = &outlet.ID
+	}
+
+	if reference := strings.TrimSpace(req.Reference); reference != "" {
+		referenceType := "manual"
+		movement.ReferenceType = &referenceType
+		movement.ReferenceID = &reference
+	}
+
+	applied, err := s.stockRepo.Apply(ctx, []entities.StockMovement{movement})
+	switch {
+	case errors.Is(err, repositories.ErrInsufficientStock):
+		return response.WithCode(400).WithError(errors.New("stock cannot go below 0"))
+	case errors.Is(err, repositories.ErrVariantRequired), errors.Is(err, repositories.ErrOutletRequired):
+		return response.WithCode(400).WithError(err)
+	case err != nil:
+		log.Error(fmt.Sprintf("error applying stock movement: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to adjust stock"))
+	}
+
+	applied[0].ProductName = product.Name
+	data := dto.ToStockMovementResponse(&applied[0])
+	return response.WithCode(201).WithData(data)
+}
+
+func (s *stockService) GetMovements(ctx context.Context, merchantId string, filter repositories.StockMovementFilter, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("stock_service_get_movements", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Merchants only see their own products
+	filter.MerchantID = merchantId
+
+	movements, err := s.stockRepo.Find(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching stock movements: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch stock movements"))
+	}
+
+	total, err := s.stockRepo.Count(ctx, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting stock movements: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch stock movements"))
+	}
+
+	data := dto.ToStockMovementListResponse(movements, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+func (s *stockService) GetAlerts(ctx context.Context, merchantId string, filter repositories.StockAlertFilter, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("stock_service_get_alerts", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if filter.Type != "" && filter.Type != entities.StockAlertLowStock && filter.Type != entities.StockAlertOutOfStock {
+		return response.WithCode(400).WithError(errors.New("type must be low_stock or out_of_stock"))
+	}
+
+	filter.MerchantID = merchantId
+
+	alerts, err := s.alertRepo.Find(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching stock alerts: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch stock alerts"))
+	}
+
+	total, err := s.alertRepo.Count(ctx, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting stock alerts: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch stock alerts"))
+	}
+
+	data := dto.ToStockAlertListResponse(alerts, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+// GetChatMisses reports the products of the merchant the chat could not
+// offer because they were out of stock over the last days, most missed
+// first.
+func (s *stockService) GetChatMisses(ctx context.Context, merchantId string, days, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("stock_service_get_chat_misses", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if days < 1 || days > 365 {
+		days = chatMissDays
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+
+	summaries, err := s.alertRepo.FindChatMisses(ctx, merchantId, since, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching chat stock misses: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch sold out report"))
+	}
+
+	total, err := s.alertRepo.CountChatMisses(ctx, merchantId, since)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting chat stock misses: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch sold out report"))
+	}
+
+	data := dto.ToChatStockMissReportResponse(summaries, since, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/api/dto"
+	"chat2pay/internal/api/presenter"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type WishlistService interface {
+	GetAll(ctx context.Context, customerId string, page, limit int) *presenter.Response
+	Add(ctx context.Context, customerId, productId string) *presenter.Response
+	Remove(ctx context.Context, customerId, productId string) *presenter.Response
+}
+
+type wishlistService struct {
+	wishlistRepo repositories.WishlistRepository
+	productRepo  repositories.ProductRepository
+	imageRepo    repositories.ProductImageRepository
+	discountRepo repositories.DiscountRepository
+	cfg          *yaml.Config
+}
+
+func NewWishlistService(
+	wishlistRepo repositories.WishlistRepository,
+	productRepo repositories.ProductRepository,
+	imageRepo repositories.ProductImageRepository,
+	discountRepo repositories.DiscountRepository,
+	cfg *yaml.Config,
+) WishlistService {
+	return &wishlistService{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		imageRepo:    imageRepo,
+		discountRepo: discountRepo,
+		cfg:          cfg,
+	}
+}
+
+// GetAll lists the wishlist of the customer, latest first, with the
+// products as they are now.
+func (s *wishlistService) GetAll(ctx context.Context, customerId string, page, limit int) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("wishlist_service_get_all", s.cfg.Logger.Enable)
+	)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	items, err := s.wishlistRepo.FindByCustomerID(ctx, customerId, limit, (page-1)*limit)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching wishlist: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch wishlist"))
+	}
+
+	total, err := s.wishlistRepo.CountByCustomerID(ctx, customerId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error counting wishlist: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch wishlist"))
+	}
+
+	if err = s.loadProducts(ctx, items); err != nil {
+		log.Error(fmt.Sprintf("error fetching wishlist products: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to fetch wishlist"))
+	}
+
+	data := dto.ToWishlistResponse(items, total, page, limit)
+	return response.WithCode(200).WithData(data)
+}
+
+// Add saves an active product to the wishlist of the customer with its
+// effective price, later drops are told against it. Adding a product
+// already on the wishlist keeps it as it is.
+func (s *wishlistService) Add(ctx context.Context, customerId, productId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("wishlist_service_add", s.cfg.Logger.Enable)
+	)
+
+	productId = strings.TrimSpace(productId)
+	if productId == "" {
+		return response.WithCode(400).WithError(errors.New("product_id is required"))
+	}
+
+	existing, err := s.wishlistRepo.FindOne(ctx, customerId, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching wishlist item: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+
+	item := entities.WishlistItem{CustomerID: customerId, ProductID: productId}
+	if existing != nil {
+		item = *existing
+	}
+
+	items := []entities.WishlistItem{item}
+	if err = s.loadProducts(ctx, items); err != nil {
+		log.Error(fmt.Sprintf("error fetching product: %v", err))
+		return response.WithCode(500).WithError(errors.New("something went wrong"))
+	}
+	item = items[0]
+
+	if existing != nil {
+		if item.Product == nil {
+			return response.WithCode(404).WithError(errors.New("product not found"))
+		}
+		return response.WithCode(200).WithData(dto.ToWishlistItemResponse(&item))
+	}
+
+	if item.Product == nil || item.Product.Status != entities.ProductStatusActive {
+		return response.WithCode(404).WithError(errors.New("product not found"))
+	}
+
+	price := item.Product.Discounted(item.Product.Price)
+	item.AddedPrice = price
+	item.LastPrice = price
+	item.InStock = item.Product.Stock > 0
+
+	if err = s.wishlistRepo.Add(ctx, &item); err != nil {
+		log.Error(fmt.Sprintf("error adding to wishlist: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to add to wishlist"))
+	}
+
+	return response.WithCode(201).WithData(dto.ToWishlistItemResponse(&item))
+}
+
+func (s *wishlistService) Remove(ctx context.Context, customerId, productId string) *presenter.Response {
+	var (
+		response = presenter.Response{}
+		log      = logger.NewLog("wishlist_service_remove", s.cfg.Logger.Enable)
+	)
+
+	found, err := s.wishlistRepo.Remove(ctx, customerId, productId)
+	if err != nil {
+		log.Error(fmt.Sprintf("error removing from wishlist: %v", err))
+		return response.WithCode(500).WithError(errors.New("failed to remove from wishlist"))
+	}
+	if !found {
+		return response.WithCode(404).WithError(errors.New("product is not on the wishlist"))
+	}
+
+	return response.WithCode(200).WithData("removed")
+}
+
+// loadProducts sets the product of each item, with its gallery and the
+// discount running now. Items whose product no longer exists are left
+// without one.
+func (s *wishlistService) loadProducts(ctx context.Context, items []entities.WishlistItem) error {
+	productIds := make([]string, len(items))
+	for i, item := range items {
+		productIds[i] = item.ProductID
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	images, err := s.imageRepo.FindByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	discounts, err := s.discountRepo.FindRunningByProductIDs(ctx, productIds)
+	if err != nil {
+		return err
+	}
+
+	imagesByProduct := map[string][]entities.ProductImage{}
+	for _, image := range images {
+		imagesByProduct[image.ProductID] = append(imagesByProduct[image.ProductID], image)
+	}
+	discountsByProduct := map[string][]entities.Discount{}
+	for _, discount := range discounts {
+		discountsByProduct[discount.AppliesTo] = append(discountsByProduct[discount.AppliesTo], discount.Discount)
+	}
+
+	byID := map[string]*entities.Product{}
+	for i := range products {
+		product := &products[i]
+		product.Images = imagesByProduct[product.ID]
+		product.Discount = pricing.Best(product.Price, discountsByProduct[product.ID])
+		byID[product.ID] = product
+	}
+	for i := range items {
+		items[i].Product = byID[items[i].ProductID]
+	}
+
+	return nil
+}
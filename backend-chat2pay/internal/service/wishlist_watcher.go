@@ -0,0 +1,131 @@
+package service
+
+import (
+	"chat2pay/config/yaml"
+	"chat2pay/internal/entities"
+	"chat2pay/internal/pkg/logger"
+	"chat2pay/internal/pkg/pricing"
+	"chat2pay/internal/pkg/wishlist"
+	"chat2pay/internal/repositories"
+	"context"
+	"fmt"
+	"time"
+)
+
+// wishlistWatchInterval is how often the watcher compares wishlisted
+// products with what their customers last saw.
+const wishlistWatchInterval = 5 * time.Minute
+
+// WishlistWatcher notifies customers when a product on their wishlist
+// gets cheaper or comes back in stock. It compares the effective price and
+// stock with what was last seen rather than hooking every change, so price
+// edits, imports, discounts starting and stock movements are all caught.
+type WishlistWatcher interface {
+	Run(ctx context.Context)
+}
+
+type wishlistWatcher struct {
+	wishlistRepo repositories.WishlistRepository
+	productRepo  repositories.ProductRepository
+	discountRepo repositories.DiscountRepository
+	cfg          *yaml.Config
+}
+
+func NewWishlistWatcher(
+	wishlistRepo repositories.WishlistRepository,
+	productRepo repositories.ProductRepository,
+	discountRepo repositories.DiscountRepository,
+	cfg *yaml.Config,
+) WishlistWatcher {
+	return &wishlistWatcher{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		discountRepo: discountRepo,
+		cfg:          cfg,
+	}
+}
+
+// Run watches the wishlists until the context is cancelled.
+func (w *wishlistWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(wishlistWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		w.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *wishlistWatcher) process(ctx context.Context) {
+	log := logger.NewLog("wishlist_watcher", w.cfg.Logger.Enable)
+
+	items, err := w.wishlistRepo.FindWatched(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching wishlists: %v", err))
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	productIds := []string{}
+	for i, item := range items {
+		if i == 0 || item.ProductID != items[i-1].ProductID {
+			productIds = append(productIds, item.ProductID)
+		}
+	}
+
+	products, err := w.productRepo.FindByIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching wishlisted products: %v", err))
+		return
+	}
+
+	discounts, err := w.discountRepo.FindRunningByProductIDs(ctx, productIds)
+	if err != nil {
+		log.Error(fmt.Sprintf("error fetching product discounts: %v", err))
+		return
+	}
+
+	discountsByProduct := map[string][]entities.Discount{}
+	for _, discount := range discounts {
+		discountsByProduct[discount.AppliesTo] = append(discountsByProduct[discount.AppliesTo], discount.Discount)
+	}
+	byID := map[string]entities.Product{}
+	for _, product := range products {
+		product.Discount = pricing.Best(product.Price, discountsByProduct[product.ID])
+		byID[product.ID] = product
+	}
+
+	notified := 0
+	for _, item := range items {
+		product, ok := byID[item.ProductID]
+		if !ok {
+			continue
+		}
+
+		price := product.Discounted(product.Price)
+		seen := wishlist.Seen(item, product, price)
+		if seen.LastPrice == item.LastPrice && seen.InStock == item.InStock {
+			continue
+		}
+
+		notification := wishlist.Notification(item, product, price)
+		if err = w.wishlistRepo.Seen(ctx, &seen, notification); err != nil {
+			log.Error(fmt.Sprintf("error updating wishlist item %s: %v", item.ID, err))
+			continue
+		}
+		if notification != nil {
+			notified++
+		}
+	}
+
+	if notified > 0 {
+		log.Info(fmt.Sprintf("sent %d wishlist notifications", notified))
+	}
+}
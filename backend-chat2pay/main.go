@@ -5,6 +5,7 @@ import (
 	command "chat2pay/cmd"
 	"chat2pay/config/yaml"
 	"chat2pay/internal/api"
+	"chat2pay/internal/service"
 	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
@@ -47,9 +48,16 @@ func main() {
 		&cli.Command{
 			Name:  "http",
 			Usage: "Run Chat2Pay http",
-			Action: func(context.Context, *cli.Command) error {
+			Action: func(ctx context.Context, _ *cli.Command) error {
 				config := ctn.Get(bootstrap.ConfigDefName).(*yaml.Config)
 
+				// Scheduled publishing runs as long as the server does
+				go ctn.Get(bootstrap.ProductSchedulerName).(service.ProductScheduler).Run(ctx)
+				// Wishlisted products are watched for price drops and restocks
+				go ctn.Get(bootstrap.WishlistWatcherName).(service.WishlistWatcher).Run(ctx)
+				// Unpaid orders are cancelled once their payment window passes
+				go ctn.Get(bootstrap.OrderExpirerName).(service.OrderExpirer).Run(ctx)
+
 				app := fiber.New()
 				app.Use(cors.New())
 
@@ -71,6 +79,8 @@ func main() {
 	)
 
 	cmd.Commands = append(cmd.Commands, command.Migration(&ctn)...)
+	cmd.Commands = append(cmd.Commands, command.SearchEval(&ctn)...)
+	cmd.Commands = append(cmd.Commands, command.SearchReindex(&ctn)...)
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
 		log.Fatal(err)
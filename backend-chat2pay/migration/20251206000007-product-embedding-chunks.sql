@@ -0,0 +1,11 @@
+-- +migrate Up
+
+-- A product can now have several embeddings, one per description chunk
+ALTER TABLE product_embedding ADD COLUMN IF NOT EXISTS chunk_index INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_product_embedding_product_id ON product_embedding (product_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_product_embedding_product_id;
+
+ALTER TABLE product_embedding DROP COLUMN IF EXISTS chunk_index;
@@ -0,0 +1,20 @@
+-- +migrate Up
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_product_name_trgm ON product USING gin (lower(name) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    term VARCHAR(100) NOT NULL,
+    synonyms TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_synonyms_merchant_id ON search_synonyms(merchant_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS search_synonyms;
+DROP INDEX IF EXISTS idx_product_name_trgm;
@@ -0,0 +1,6 @@
+-- +migrate Up
+
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items(product_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_items_product_id;
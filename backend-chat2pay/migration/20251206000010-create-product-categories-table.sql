@@ -0,0 +1,24 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(150) NOT NULL,
+    parent_id UUID NULL REFERENCES product_categories(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_parent_id ON product_categories(parent_id);
+
+-- Category ids were never validated before this table existed
+UPDATE product SET category_id = NULL
+WHERE category_id IS NOT NULL
+AND category_id NOT IN (SELECT id FROM product_categories);
+
+ALTER TABLE product
+    ADD CONSTRAINT fk_product_category_id
+    FOREIGN KEY (category_id) REFERENCES product_categories(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE product DROP CONSTRAINT IF EXISTS fk_product_category_id;
+DROP TABLE IF EXISTS product_categories;
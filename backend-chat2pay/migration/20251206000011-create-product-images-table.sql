@@ -0,0 +1,27 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS product_images (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    image_url TEXT NOT NULL,
+    thumbnail_url TEXT NOT NULL,
+    storage_key TEXT NOT NULL DEFAULT '', -- empty for images hosted elsewhere
+    thumbnail_key TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images(product_id, position);
+
+-- At most one primary image per product
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_images_primary ON product_images(product_id) WHERE is_primary;
+
+-- Existing image urls become the primary image of their product
+INSERT INTO product_images (product_id, image_url, thumbnail_url, position, is_primary)
+SELECT id, image, image, 0, TRUE
+FROM product
+WHERE image IS NOT NULL AND image <> '';
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_images;
@@ -0,0 +1,42 @@
+-- +migrate Up
+
+-- Option axes of a product, e.g. Warna: Hitam, Putih and Ukuran: S, M, L
+CREATE TABLE IF NOT EXISTS product_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    option_values TEXT[] NOT NULL DEFAULT '{}',
+    position INT NOT NULL DEFAULT 0,
+    UNIQUE(product_id, name)
+);
+
+-- One row per sellable combination of option values
+CREATE TABLE IF NOT EXISTS product_variants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL, -- option values joined in axis order, e.g. "Hitam / M"
+    options JSONB NOT NULL DEFAULT '{}',
+    sku VARCHAR(100) NULL,
+    price DECIMAL(15,2) NULL, -- overrides the product price when set
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    weight INT NULL, -- overrides the product weight when set
+    image_url TEXT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(product_id, options) DEFERRABLE INITIALLY DEFERRED -- lets a save swap options between variants
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_options_product_id ON product_options(product_id, position);
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_variants_sku ON product_variants(product_id, sku) WHERE sku IS NOT NULL;
+
+-- Order items keep the variant they were bought as
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id UUID NULL REFERENCES product_variants(id) ON DELETE SET NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_name VARCHAR(200) NULL;
+
+-- +migrate Down
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_name;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_options;
@@ -0,0 +1,54 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS outlets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    name VARCHAR(150) NOT NULL,
+    address TEXT NULL,
+    city VARCHAR(100) NULL,
+    city_id VARCHAR(20) NULL, -- shipping provider city id, used as the shipping origin
+    province VARCHAR(100) NULL,
+    latitude DECIMAL(10,7) NULL,
+    longitude DECIMAL(10,7) NULL,
+    phone VARCHAR(50) NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outlets_merchant_id ON outlets(merchant_id);
+
+-- Stock held by an outlet, for a product without variants (variant_id NULL)
+-- or for one variant
+CREATE TABLE IF NOT EXISTS outlet_stocks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    outlet_id UUID NOT NULL REFERENCES outlets(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    variant_id UUID NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_outlet_stocks_product
+    ON outlet_stocks(outlet_id, product_id) WHERE variant_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_outlet_stocks_variant
+    ON outlet_stocks(outlet_id, variant_id) WHERE variant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_outlet_stocks_product_id ON outlet_stocks(product_id);
+
+-- Outlet ids were never validated before this table existed
+UPDATE product SET outlet_id = NULL
+WHERE outlet_id IS NOT NULL
+AND outlet_id NOT IN (SELECT id FROM outlets);
+
+ALTER TABLE product
+    ADD CONSTRAINT fk_product_outlet_id
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE SET NULL;
+
+-- Outlet the order is shipped from
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS outlet_id UUID NULL REFERENCES outlets(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE orders DROP COLUMN IF EXISTS outlet_id;
+ALTER TABLE product DROP CONSTRAINT IF EXISTS fk_product_outlet_id;
+DROP TABLE IF EXISTS outlet_stocks;
+DROP TABLE IF EXISTS outlets;
@@ -0,0 +1,44 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    variant_id UUID NULL REFERENCES product_variants(id) ON DELETE SET NULL,
+    outlet_id UUID NULL REFERENCES outlets(id) ON DELETE SET NULL,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('sale', 'cancellation_return', 'restock', 'adjustment', 'import')),
+    quantity INT NOT NULL, -- change in stock, negative when stock goes out
+    stock_after INT NOT NULL,
+    actor_type VARCHAR(20) NOT NULL CHECK (actor_type IN ('merchant', 'customer', 'system')),
+    actor_id UUID NULL,
+    reference_type VARCHAR(30) NULL,
+    reference_id VARCHAR(100) NULL,
+    note TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements(product_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+
+-- Opening balances, so the ledger adds up to the stock held today. Stock is
+-- held per outlet when there are outlet rows, else per variant, else on
+-- the product.
+INSERT INTO stock_movements (product_id, variant_id, outlet_id, type, quantity, stock_after, actor_type, note)
+SELECT product_id, variant_id, outlet_id, 'adjustment', stock, stock, 'system', 'opening balance'
+FROM outlet_stocks
+WHERE stock > 0;
+
+INSERT INTO stock_movements (product_id, variant_id, type, quantity, stock_after, actor_type, note)
+SELECT v.product_id, v.id, 'adjustment', v.stock, v.stock, 'system', 'opening balance'
+FROM product_variants v
+WHERE v.stock > 0
+AND NOT EXISTS (SELECT 1 FROM outlet_stocks s WHERE s.variant_id = v.id);
+
+INSERT INTO stock_movements (product_id, type, quantity, stock_after, actor_type, note)
+SELECT p.id, 'adjustment', p.stock, p.stock, 'system', 'opening balance'
+FROM product p
+WHERE p.stock > 0
+AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id)
+AND NOT EXISTS (SELECT 1 FROM outlet_stocks s WHERE s.product_id = p.id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS stock_movements;
@@ -0,0 +1,58 @@
+-- +migrate Up
+
+-- Stock at or below the threshold raises a low stock alert, 0 disables it.
+-- Running out of stock always raises an alert.
+ALTER TABLE product ADD COLUMN IF NOT EXISTS low_stock_threshold INT NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0);
+
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('low_stock', 'out_of_stock')),
+    stock INT NOT NULL, -- stock when the alert was raised
+    threshold INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL -- set once stock is back above the threshold
+);
+
+-- A product has at most one open alert
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_alerts_open ON stock_alerts(product_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_merchant_id ON stock_alerts(merchant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS merchant_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    reference_type VARCHAR(30) NULL,
+    reference_id VARCHAR(100) NULL,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_notifications_merchant_id ON merchant_notifications(merchant_id, created_at DESC);
+
+-- Products the chat search matched but could not offer because they were
+-- out of stock
+CREATE TABLE IF NOT EXISTS chat_stock_misses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    session_id VARCHAR(100) NULL,
+    query TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_stock_misses_product_id ON chat_stock_misses(product_id, created_at DESC);
+
+-- Products already sold out start with an open alert, without notifying
+INSERT INTO stock_alerts (merchant_id, product_id, type, stock, threshold)
+SELECT merchant_id, id, 'out_of_stock', stock, low_stock_threshold
+FROM product
+WHERE stock = 0;
+
+-- +migrate Down
+DROP TABLE IF EXISTS chat_stock_misses;
+DROP TABLE IF EXISTS merchant_notifications;
+DROP TABLE IF EXISTS stock_alerts;
+ALTER TABLE product DROP COLUMN IF EXISTS low_stock_threshold;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- Specifications of a product keyed by attribute, e.g. {"ram": 16, "bahan": "Katun"}
+ALTER TABLE product ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Attributes expected of the products in a category, inherited by its
+-- subcategories: [{"key": "ram", "label": "RAM", "type": "number", "unit": "GB"}]
+ALTER TABLE product_categories ADD COLUMN IF NOT EXISTS attribute_schema JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- +migrate Down
+ALTER TABLE product_categories DROP COLUMN IF EXISTS attribute_schema;
+ALTER TABLE product DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,26 @@
+-- +migrate Up notransaction
+
+-- A draft is being prepared and has never been published. Adding an enum
+-- value cannot run inside a transaction on older PostgreSQL versions.
+ALTER TYPE product_status_enum ADD VALUE IF NOT EXISTS 'draft' BEFORE 'active';
+
+-- The scheduler publishes a product at publish_at and unpublishes it at
+-- unpublish_at, clearing the time once done.
+ALTER TABLE product ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ NULL;
+ALTER TABLE product ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ NULL;
+
+-- Deleted products are archived, orders keep referring to them
+ALTER TABLE product ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL;
+UPDATE product SET archived_at = updated_at WHERE status = 'archived' AND archived_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_product_publish_at ON product(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_product_unpublish_at ON product(unpublish_at) WHERE unpublish_at IS NOT NULL;
+
+-- +migrate Down notransaction
+DROP INDEX IF EXISTS idx_product_unpublish_at;
+DROP INDEX IF EXISTS idx_product_publish_at;
+ALTER TABLE product DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE product DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE product DROP COLUMN IF EXISTS publish_at;
+-- PostgreSQL cannot drop an enum value, drafts become inactive
+UPDATE product SET status = 'inactive' WHERE status = 'draft';
@@ -0,0 +1,49 @@
+-- +migrate Up
+
+-- A discount applies to one product, or to every product of the merchant in
+-- a category and its subcategories, between starts_at and ends_at.
+CREATE TABLE IF NOT EXISTS discounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    product_id UUID NULL REFERENCES product(id) ON DELETE CASCADE,
+    category_id UUID NULL REFERENCES product_categories(id) ON DELETE CASCADE,
+    name VARCHAR(150) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed')),
+    value DECIMAL(15,2) NOT NULL CHECK (value > 0),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((product_id IS NULL) <> (category_id IS NULL)),
+    CHECK (type <> 'percentage' OR value < 100),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_discounts_merchant_id ON discounts(merchant_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_discounts_product_id ON discounts(product_id) WHERE product_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_discounts_category_id ON discounts(category_id) WHERE category_id IS NOT NULL;
+
+-- Every change of the base price of a product
+CREATE TABLE IF NOT EXISTS price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    price DECIMAL(15,2) NOT NULL,
+    previous_price DECIMAL(15,2) NULL, -- NULL for the first price
+    source VARCHAR(20) NOT NULL CHECK (source IN ('manual', 'import', 'opening')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product_id ON price_history(product_id, created_at DESC);
+
+INSERT INTO price_history (product_id, price, source)
+SELECT id, price, 'opening' FROM product;
+
+-- product_price is the price charged, after the discount
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS original_price DECIMAL(15,2) NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_id UUID NULL REFERENCES discounts(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS original_price;
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS discounts;
@@ -0,0 +1,34 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS wishlists (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    added_price DECIMAL(15,2) NOT NULL, -- effective price when added
+    last_price DECIMAL(15,2) NOT NULL, -- effective price last seen by the watcher
+    in_stock BOOLEAN NOT NULL, -- whether the product was in stock when last seen
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (customer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlists_customer_id ON wishlists(customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_wishlists_product_id ON wishlists(product_id);
+
+CREATE TABLE IF NOT EXISTS customer_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    reference_type VARCHAR(30) NULL,
+    reference_id VARCHAR(100) NULL,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_notifications_customer_id ON customer_notifications(customer_id, created_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS customer_notifications;
+DROP TABLE IF EXISTS wishlists;
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- A question customers often ask with the merchant's answer, for one
+-- product or for the whole store (product_id NULL). The question is
+-- embedded so the assistant can find it from a customer question.
+CREATE TABLE IF NOT EXISTS product_faqs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    product_id UUID NULL REFERENCES product(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    embedding vector(1024) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_faqs_merchant_id ON product_faqs(merchant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_faqs_product_id ON product_faqs(product_id) WHERE product_id IS NOT NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS product_faqs;
@@ -0,0 +1,20 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NULL, -- NULL for the entry of a new order
+    to_status VARCHAR(50) NOT NULL,
+    actor_type VARCHAR(20) NOT NULL, -- customer, merchant or system
+    actor_id UUID NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+
+-- Existing orders start their history at their current status
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, created_at)
+SELECT id, NULL, status, 'system', updated_at FROM orders;
+
+-- +migrate Down
+DROP TABLE IF EXISTS order_status_history;
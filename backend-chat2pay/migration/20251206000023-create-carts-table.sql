@@ -0,0 +1,37 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    variant_id UUID NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One line per product and variant, adding the same again raises its quantity
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_items_product ON cart_items(
+    customer_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)
+);
+
+-- A checkout places one order per merchant of the cart
+CREATE TABLE IF NOT EXISTS checkouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    subtotal DECIMAL(15,2) NOT NULL,
+    shipping_cost DECIMAL(15,2) NOT NULL,
+    total DECIMAL(15,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkouts_customer_id ON checkouts(customer_id, created_at DESC);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS checkout_id UUID NULL REFERENCES checkouts(id);
+CREATE INDEX IF NOT EXISTS idx_orders_checkout_id ON orders(checkout_id) WHERE checkout_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_orders_checkout_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS checkout_id;
+DROP TABLE IF EXISTS checkouts;
+DROP TABLE IF EXISTS cart_items;
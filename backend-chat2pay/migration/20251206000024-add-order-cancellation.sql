@@ -0,0 +1,12 @@
+-- +migrate Up
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancel_reason TEXT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP NULL;
+
+-- Unpaid orders are expired once their payment window has passed
+CREATE INDEX IF NOT EXISTS idx_orders_unpaid ON orders(created_at) WHERE status = 'pending';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_orders_unpaid;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancel_reason;
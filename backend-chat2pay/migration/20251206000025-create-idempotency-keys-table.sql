@@ -0,0 +1,17 @@
+-- +migrate Up
+
+-- Responses kept per Idempotency-Key, so retried requests are replayed
+-- instead of run again. The status code is null while the first request runs.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT NULL,
+    response_body BYTEA NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS idempotency_keys;